@@ -0,0 +1,106 @@
+// Package alert dispatches alerts on safety and liveness violations detected by
+// the monitor and the BTC staking tracker to external sinks, such as webhooks,
+// files, and scripts.
+package alert
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kind is the kind of violation an alert is raised for
+type Kind string
+
+const (
+	// KindInconsistentCheckpoint means that a valid checkpoint on BTC commits to a
+	// different Babylon block than the checkpoint on Babylon, i.e., Babylon is forked
+	KindInconsistentCheckpoint Kind = "inconsistent_checkpoint"
+	// KindLivenessAttack means that a checkpoint on BTC is not reported to Babylon
+	// within the expected number of BTC blocks, i.e., it is likely censored
+	KindLivenessAttack Kind = "liveness_attack"
+	// KindStuckEpoch means that checkpoints of later epochs are found on BTC, but
+	// the checkpoint of the current epoch is missing or invalid, so that the
+	// monitor cannot verify any later checkpoint
+	KindStuckEpoch Kind = "stuck_epoch"
+	// KindDeepReorg means that BTC blocks deeper than the confirmation depth are
+	// reorged, so that checkpoints considered final may no longer be on BTC
+	KindDeepReorg Kind = "deep_reorg"
+	// KindLightClientDivergence means that the k-deep header of Babylon's BTC light
+	// client is not on the canonical chain of the BTC node
+	KindLightClientDivergence Kind = "light_client_divergence"
+	// KindUnknownStakingSpend means that the staking output of an active BTC
+	// delegation is spent by a tx that is neither its unbonding tx, a withdrawal,
+	// nor its slashing tx
+	KindUnknownStakingSpend Kind = "unknown_staking_spend"
+	// KindStalledSlashingTx means that a slashing tx submitted by the BTC slasher
+	// stays out of the best chain, and its fee cannot be bumped
+	KindStalledSlashingTx Kind = "stalled_slashing_tx"
+)
+
+// Alert is a violation detected by the monitor or the BTC staking tracker, together with its evidence
+type Alert struct {
+	Kind     Kind     `json:"kind"`
+	Epoch    uint64   `json:"epoch"`
+	Message  string   `json:"message"`
+	Evidence Evidence `json:"evidence"`
+	// when the alert was raised for the first time
+	FirstRaisedAt time.Time `json:"first_raised_at"`
+	// number of times the alert has been sent, including this time
+	Attempt uint `json:"attempt"`
+}
+
+// Evidence is the data to verify an alert independently
+type Evidence struct {
+	// hash of the checkpoint
+	CheckpointID string `json:"checkpoint_id"`
+	// Babylon block hash committed by the checkpoint on Babylon
+	BabylonBlockHash string `json:"babylon_block_hash,omitempty"`
+	// Babylon block hash committed by the checkpoint on BTC
+	BTCCheckpointBlockHash string `json:"btc_checkpoint_block_hash,omitempty"`
+	// BTC height at which the checkpoint first appears
+	FirstSeenBTCHeight uint64 `json:"first_seen_btc_height"`
+	// the BTC txs carrying the segments of the checkpoint
+	Txs []TxEvidence `json:"txs,omitempty"`
+	// path of the evidence bundle written by the monitor, which can be
+	// verified offline with `vigilante monitor verify-evidence`
+	BundleFile string `json:"bundle_file,omitempty"`
+	// staking tx of the BTC delegation, for alerts on BTC staking
+	StakingTxHash string `json:"staking_tx_hash,omitempty"`
+	// tx spending the staking output of the BTC delegation
+	SpendingTxHash string `json:"spending_tx_hash,omitempty"`
+	// slashing tx of the BTC delegation submitted by the BTC slasher
+	SlashingTxHash string `json:"slashing_tx_hash,omitempty"`
+	// the last confirmed BTC block replaced by a deep reorg
+	ReorgedTipHash string `json:"reorged_tip_hash,omitempty"`
+}
+
+// TxEvidence locates a BTC tx carrying a segment of a checkpoint
+type TxEvidence struct {
+	TxId        string `json:"txid"`
+	BlockHash   string `json:"block_hash"`
+	BlockHeight uint64 `json:"block_height"`
+}
+
+// Key identifies an alert for de-duplication. Alerts of the same kind on the
+// same checkpoint, on the same BTC delegation, on the same slashing tx, or on
+// the same replaced BTC block, are considered the same alert. The staking and
+// unbonding slashing txs of a BTC delegation are different slashing txs.
+func (a *Alert) Key() string {
+	if a.Evidence.ReorgedTipHash != "" {
+		return Key(a.Kind, a.Epoch, a.Evidence.ReorgedTipHash)
+	}
+	if a.Evidence.SlashingTxHash != "" {
+		return Key(a.Kind, a.Epoch, a.Evidence.SlashingTxHash)
+	}
+	if a.Evidence.StakingTxHash != "" {
+		return Key(a.Kind, a.Epoch, a.Evidence.StakingTxHash)
+	}
+	return Key(a.Kind, a.Epoch, a.Evidence.CheckpointID)
+}
+
+// Key returns the key of the alert of the given kind on the given checkpoint,
+// on the BTC delegation with the given staking tx hash, on the slashing tx
+// with the given hash, or on the BTC block with the given hash replaced by a reorg
+func Key(kind Kind, epoch uint64, id string) string {
+	return fmt.Sprintf("%s/%d/%s", kind, epoch, id)
+}
@@ -0,0 +1,223 @@
+package alert
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+)
+
+// activeAlert is an alert that is not resolved yet, together with its re-send schedule
+type activeAlert struct {
+	alert    *Alert
+	interval time.Duration
+	nextSend time.Time
+}
+
+// Dispatcher sends alerts to all sinks. An alert is sent once when it is raised,
+// and then re-sent at an escalating interval until it is resolved. Raising an
+// alert that is already active has no effect. An alert that is never resolved
+// expires after a while, so that alerts do not pile up.
+type Dispatcher struct {
+	cfg     *config.AlertConfig
+	logger  *zap.SugaredLogger
+	sinks   []Sink
+	metrics *metrics.AlertMetrics
+
+	mu     sync.Mutex
+	active map[string]*activeAlert
+
+	// signals the dispatch loop that a new alert is raised
+	wake chan struct{}
+
+	wg      sync.WaitGroup
+	started bool
+	quit    chan struct{}
+}
+
+func NewDispatcher(
+	cfg *config.AlertConfig,
+	sinks []Sink,
+	parentLogger *zap.Logger,
+	metrics *metrics.AlertMetrics,
+) *Dispatcher {
+	return &Dispatcher{
+		cfg:     cfg,
+		logger:  parentLogger.With(zap.String("module", "alert")).Sugar(),
+		sinks:   sinks,
+		metrics: metrics,
+		active:  make(map[string]*activeAlert),
+		wake:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+	}
+}
+
+// NewSinksFromConfig creates all sinks defined in the config
+func NewSinksFromConfig(cfg *config.AlertConfig) []Sink {
+	var sinks []Sink
+	for _, url := range cfg.WebhookURLs {
+		sinks = append(sinks, NewWebhookSink(url))
+	}
+	if cfg.File != "" {
+		sinks = append(sinks, NewFileSink(cfg.File))
+	}
+	if cfg.ExecScript != "" {
+		sinks = append(sinks, NewExecSink(cfg.ExecScript))
+	}
+	return sinks
+}
+
+// Start starts sending alerts
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started {
+		return
+	}
+	d.started = true
+
+	d.wg.Add(1)
+	go d.dispatchLoop()
+
+	d.logger.Infof("Successfully started the alert dispatcher with %d sinks", len(d.sinks))
+}
+
+// Stop stops sending alerts
+func (d *Dispatcher) Stop() {
+	select {
+	case <-d.quit:
+		return
+	default:
+		close(d.quit)
+	}
+	d.wg.Wait()
+}
+
+// Raise raises the alert. It is sent right away, unless an alert with the same key is already active.
+func (d *Dispatcher) Raise(alert *Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := alert.Key()
+	if _, ok := d.active[key]; ok {
+		return
+	}
+	if alert.FirstRaisedAt.IsZero() {
+		alert.FirstRaisedAt = time.Now()
+	}
+	d.active[key] = &activeAlert{
+		alert:    alert,
+		interval: d.cfg.ResendInitialInterval,
+		nextSend: time.Now(),
+	}
+	d.metrics.AlertsRaisedCounterVec.WithLabelValues(string(alert.Kind)).Inc()
+	d.metrics.ActiveAlertsGauge.Set(float64(len(d.active)))
+	d.logger.Errorf("Raised %s alert at epoch %d: %s", alert.Kind, alert.Epoch, alert.Message)
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Resolve stops re-sending the alert with the given key
+func (d *Dispatcher) Resolve(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.active[key]; !ok {
+		return
+	}
+	delete(d.active, key)
+	d.metrics.ActiveAlertsGauge.Set(float64(len(d.active)))
+	d.logger.Infof("Resolved alert %s", key)
+}
+
+// NumActive returns the number of alerts that are not resolved yet
+func (d *Dispatcher) NumActive() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.active)
+}
+
+func (d *Dispatcher) dispatchLoop() {
+	defer d.wg.Done()
+
+	for {
+		d.sendDue(time.Now())
+
+		timer := time.NewTimer(d.untilNextSend(time.Now()))
+		select {
+		case <-timer.C:
+		case <-d.wake:
+			timer.Stop()
+		case <-d.quit:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// untilNextSend returns the duration until the next alert is due
+func (d *Dispatcher) untilNextSend(now time.Time) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	next := d.cfg.ResendMaxInterval
+	for _, a := range d.active {
+		if until := a.nextSend.Sub(now); until < next {
+			next = until
+		}
+	}
+	if next < 0 {
+		next = 0
+	}
+	return next
+}
+
+// sendDue sends all alerts that are due, and schedules their next re-send.
+// Alerts unresolved for longer than the expiry are dropped instead.
+func (d *Dispatcher) sendDue(now time.Time) {
+	var due []Alert
+	d.mu.Lock()
+	for key, a := range d.active {
+		if now.Sub(a.alert.FirstRaisedAt) >= d.cfg.ExpireAfter {
+			delete(d.active, key)
+			d.metrics.AlertsExpiredCounterVec.WithLabelValues(string(a.alert.Kind)).Inc()
+			d.metrics.ActiveAlertsGauge.Set(float64(len(d.active)))
+			d.logger.Warnf("Alert %s expired without being resolved after %v", key, d.cfg.ExpireAfter)
+			continue
+		}
+		if a.nextSend.After(now) {
+			continue
+		}
+		a.alert.Attempt++
+		due = append(due, *a.alert)
+		a.nextSend = now.Add(a.interval)
+		a.interval = min(2*a.interval, d.cfg.ResendMaxInterval)
+	}
+	d.mu.Unlock()
+
+	for i := range due {
+		d.send(&due[i])
+	}
+}
+
+func (d *Dispatcher) send(alert *Alert) {
+	for _, sink := range d.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), d.cfg.SinkTimeout)
+		err := sink.Send(ctx, alert)
+		cancel()
+		if err != nil {
+			d.logger.Errorf("Failed to send %s alert at epoch %d to sink %s: %v", alert.Kind, alert.Epoch, sink.Name(), err)
+			d.metrics.AlertSendFailuresCounterVec.WithLabelValues(sink.Name()).Inc()
+			continue
+		}
+		d.metrics.AlertsSentCounterVec.WithLabelValues(sink.Name()).Inc()
+		d.logger.Infof("Sent %s alert at epoch %d to sink %s (attempt %d)", alert.Kind, alert.Epoch, sink.Name(), alert.Attempt)
+	}
+}
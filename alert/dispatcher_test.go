@@ -0,0 +1,243 @@
+package alert_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/alert"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+)
+
+// webhookStandIn is a local HTTP server recording the alerts POSTed to it
+type webhookStandIn struct {
+	*httptest.Server
+	mu     sync.Mutex
+	alerts []*alert.Alert
+}
+
+func newWebhookStandIn(t *testing.T) *webhookStandIn {
+	w := &webhookStandIn{}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var a alert.Alert
+		if err := json.NewDecoder(req.Body).Decode(&a); err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.mu.Lock()
+		w.alerts = append(w.alerts, &a)
+		w.mu.Unlock()
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+func (w *webhookStandIn) received() []*alert.Alert {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]*alert.Alert{}, w.alerts...)
+}
+
+func newTestAlert(epoch uint64) *alert.Alert {
+	return &alert.Alert{
+		Kind:    alert.KindInconsistentCheckpoint,
+		Epoch:   epoch,
+		Message: "conflicting checkpoints",
+		Evidence: alert.Evidence{
+			CheckpointID:           "ckpt",
+			BabylonBlockHash:       "aa",
+			BTCCheckpointBlockHash: "bb",
+			FirstSeenBTCHeight:     100,
+			Txs: []alert.TxEvidence{
+				{TxId: "tx1", BlockHash: "block1", BlockHeight: 100},
+				{TxId: "tx2", BlockHash: "block2", BlockHeight: 101},
+			},
+		},
+	}
+}
+
+func newTestDispatcher(t *testing.T, cfg *config.AlertConfig) *alert.Dispatcher {
+	d := alert.NewDispatcher(cfg, alert.NewSinksFromConfig(cfg), zap.NewNop(), metrics.NewMonitorMetrics().AlertMetrics)
+	d.Start()
+	t.Cleanup(d.Stop)
+	return d
+}
+
+func TestDispatcherSendsToWebhookAndFile(t *testing.T) {
+	webhook := newWebhookStandIn(t)
+	cfg := config.DefaultAlertConfig()
+	cfg.WebhookURLs = []string{webhook.URL}
+	cfg.File = filepath.Join(t.TempDir(), "alerts.jsonl")
+	require.NoError(t, cfg.Validate())
+
+	d := newTestDispatcher(t, &cfg)
+	d.Raise(newTestAlert(1))
+	d.Raise(newTestAlert(2))
+
+	require.Eventually(t, func() bool {
+		return len(webhook.received()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	received := webhook.received()
+	for _, a := range received {
+		require.Equal(t, alert.KindInconsistentCheckpoint, a.Kind)
+		require.Equal(t, uint(1), a.Attempt)
+		require.Equal(t, "aa", a.Evidence.BabylonBlockHash)
+		require.Equal(t, "bb", a.Evidence.BTCCheckpointBlockHash)
+		require.Len(t, a.Evidence.Txs, 2)
+		require.False(t, a.FirstRaisedAt.IsZero())
+	}
+
+	// the file sink has one alert per line
+	require.Eventually(t, func() bool {
+		f, err := os.Open(cfg.File)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		var lines int
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var a alert.Alert
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &a))
+			lines++
+		}
+		return lines == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcherDeduplicatesAndEscalates(t *testing.T) {
+	webhook := newWebhookStandIn(t)
+	cfg := config.DefaultAlertConfig()
+	cfg.WebhookURLs = []string{webhook.URL}
+	cfg.ResendInitialInterval = 100 * time.Millisecond
+	cfg.ResendMaxInterval = 400 * time.Millisecond
+	require.NoError(t, cfg.Validate())
+
+	d := newTestDispatcher(t, &cfg)
+	a := newTestAlert(1)
+	d.Raise(a)
+	// the same alert raised again is de-duplicated
+	d.Raise(newTestAlert(1))
+	require.Equal(t, 1, d.NumActive())
+
+	// the alert is re-sent with increasing attempts
+	require.Eventually(t, func() bool {
+		return len(webhook.received()) >= 4
+	}, 5*time.Second, 10*time.Millisecond)
+	received := webhook.received()
+	for i, r := range received {
+		require.Equal(t, uint(i+1), r.Attempt)
+		require.Equal(t, a.FirstRaisedAt.Unix(), r.FirstRaisedAt.Unix())
+	}
+
+	// the interval between re-sends escalates, up to the max interval
+	// at 0, 100ms, 300ms, 700ms, ...
+	require.GreaterOrEqual(t, time.Since(a.FirstRaisedAt), 700*time.Millisecond)
+
+	// resolved alerts are no longer sent
+	d.Resolve(a.Key())
+	require.Equal(t, 0, d.NumActive())
+	numReceived := len(webhook.received())
+	time.Sleep(2 * cfg.ResendMaxInterval)
+	require.Equal(t, numReceived, len(webhook.received()))
+}
+
+func TestDispatcherKeepsResendingOnSinkFailure(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cfg := config.DefaultAlertConfig()
+	cfg.WebhookURLs = []string{server.URL}
+	cfg.ResendInitialInterval = 50 * time.Millisecond
+	cfg.ResendMaxInterval = 50 * time.Millisecond
+
+	d := newTestDispatcher(t, &cfg)
+	d.Raise(newTestAlert(1))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcherKeysStalledSlashingTxAlertsBySlashingTx(t *testing.T) {
+	cfg := config.DefaultAlertConfig()
+	cfg.File = filepath.Join(t.TempDir(), "alerts.jsonl")
+	require.NoError(t, cfg.Validate())
+	d := newTestDispatcher(t, &cfg)
+
+	// the staking and unbonding slashing txs of a BTC delegation stall
+	// independently of each other
+	for _, slashingTxHash := range []string{"slashing", "unbonding_slashing"} {
+		d.Raise(&alert.Alert{
+			Kind:    alert.KindStalledSlashingTx,
+			Message: "stalled slashing tx",
+			Evidence: alert.Evidence{
+				StakingTxHash:  "staking",
+				SlashingTxHash: slashingTxHash,
+			},
+		})
+	}
+	require.Equal(t, 2, d.NumActive())
+
+	d.Resolve(alert.Key(alert.KindStalledSlashingTx, 0, "slashing"))
+	require.Equal(t, 1, d.NumActive())
+	d.Resolve(alert.Key(alert.KindStalledSlashingTx, 0, "unbonding_slashing"))
+	require.Equal(t, 0, d.NumActive())
+}
+
+func TestDispatcherExpiresUnresolvedAlerts(t *testing.T) {
+	webhook := newWebhookStandIn(t)
+	cfg := config.DefaultAlertConfig()
+	cfg.WebhookURLs = []string{webhook.URL}
+	cfg.ResendInitialInterval = 50 * time.Millisecond
+	cfg.ResendMaxInterval = 50 * time.Millisecond
+	cfg.ExpireAfter = 300 * time.Millisecond
+	require.NoError(t, cfg.Validate())
+
+	d := newTestDispatcher(t, &cfg)
+	d.Raise(newTestAlert(1))
+	require.Equal(t, 1, d.NumActive())
+
+	// the alert is dropped once it expires, and is no longer sent
+	require.Eventually(t, func() bool {
+		return d.NumActive() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	numReceived := len(webhook.received())
+	require.NotZero(t, numReceived)
+	time.Sleep(2 * cfg.ResendMaxInterval)
+	require.Equal(t, numReceived, len(webhook.received()))
+
+	// the alert is raised again if the violation is detected again
+	d.Raise(newTestAlert(1))
+	require.Equal(t, 1, d.NumActive())
+	require.Eventually(t, func() bool {
+		received := webhook.received()
+		return len(received) > numReceived && received[numReceived].Attempt == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
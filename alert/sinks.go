@@ -0,0 +1,135 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// Sink is a destination of alerts
+type Sink interface {
+	// Name identifies the sink in logs and metrics
+	Name() string
+	// Send delivers the alert to the sink
+	Send(ctx context.Context, alert *Alert) error
+}
+
+var (
+	_ Sink = &WebhookSink{}
+	_ Sink = &FileSink{}
+	_ Sink = &ExecSink{}
+)
+
+// WebhookSink POSTs alerts as JSON to a URL.
+// Any response status other than 2xx is considered a failure.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{},
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook:" + s.url
+}
+
+func (s *WebhookSink) Send(ctx context.Context, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// FileSink appends alerts to a file, one JSON object per line
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Name() string {
+	return "file:" + s.path
+}
+
+func (s *FileSink) Send(_ context.Context, alert *Alert) error {
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ExecSink runs an executable for each alert. The alert is passed as JSON on
+// stdin, and its kind and epoch in the environment variables VIGILANTE_ALERT_KIND
+// and VIGILANTE_ALERT_EPOCH. A non-zero exit status is considered a failure.
+type ExecSink struct {
+	path string
+}
+
+func NewExecSink(path string) *ExecSink {
+	return &ExecSink{path: path}
+}
+
+func (s *ExecSink) Name() string {
+	return "exec:" + s.path
+}
+
+func (s *ExecSink) Send(ctx context.Context, alert *Alert) error {
+	input, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, s.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Env = append(os.Environ(),
+		"VIGILANTE_ALERT_KIND="+string(alert.Kind),
+		"VIGILANTE_ALERT_EPOCH="+strconv.FormatUint(alert.Epoch, 10),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return nil
+}
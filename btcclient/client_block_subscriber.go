@@ -134,6 +134,17 @@ func (c *Client) mustSubscribeBlocksByZmq() {
 	}
 }
 
+// subscribeBlocks subscribes to newly connected/disconnected blocks without retries
+func (c *Client) subscribeBlocks() error {
+	switch c.Cfg.BtcBackend {
+	case types.Btcd:
+		return c.subscribeBlocksByWebSocket()
+	case types.Bitcoind:
+		return c.zmqClient.SubscribeSequence()
+	}
+	return nil
+}
+
 func (c *Client) MustSubscribeBlocks() {
 	switch c.Cfg.BtcBackend {
 	case types.Btcd:
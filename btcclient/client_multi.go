@@ -0,0 +1,577 @@
+package btcclient
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/types"
+)
+
+var _ BTCClient = &MultiClient{}
+
+// nodeClient is the connection to one of the BTC nodes of a MultiClient
+type nodeClient interface {
+	BTCClient
+	GetBlockHash(height int64) (*chainhash.Hash, error)
+	GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error)
+	GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error)
+	// subscribeBlocks subscribes to the new blocks of the node without retries
+	subscribeBlocks() error
+}
+
+var _ nodeClient = &Client{}
+
+// backend is a connection to one of the BTC nodes of a MultiClient,
+// together with the latest known state of the node
+type backend struct {
+	name string
+	cfg  *config.BTCConfig
+	// nil until the node is connected
+	client nodeClient
+
+	healthy   bool
+	tipHash   string
+	tipHeight int32
+	chainWork *big.Int
+	lastErr   error
+}
+
+// hasMoreWork returns whether the best chain of the backend has more work
+// than the best chain of the other backend. Heights are compared if any of
+// the nodes does not report its chain work (e.g., btcd).
+func (b *backend) hasMoreWork(other *backend) bool {
+	if b.chainWork != nil && other.chainWork != nil {
+		return b.chainWork.Cmp(other.chainWork) > 0
+	}
+	return b.tipHeight > other.tipHeight
+}
+
+// MultiClient is a BTC client that connects to several BTC nodes and uses
+// the one with the best chain. It fails over to another node when the node
+// in use returns RPC errors or its tip lags behind the other nodes. Nodes
+// that cannot be connected are connected again upon each poll of the tips.
+// Block events are only relayed from the node in use.
+type MultiClient struct {
+	Cfg    *config.BTCConfig
+	logger *zap.SugaredLogger
+	// connect creates the connection to a BTC node
+	connect func(cfg *config.BTCConfig) (nodeClient, error)
+
+	mu       sync.RWMutex
+	backends []*backend
+	active   int
+	// whether the nodes are subscribed to new blocks
+	subscribed bool
+
+	metrics *metrics.BTCBackendMetrics
+
+	// channel for notifying new BTC blocks of the node in use to reporter
+	blockEventChan chan *types.BlockEvent
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// NewMultiWithBlockSubscriber creates a new BTC client that connects to the primary BTC node and
+// all failover BTC nodes in the config, and subscribes to their newly connected/disconnected blocks.
+// Nodes that cannot be connected are skipped until they can be, unless none of the nodes can be connected.
+// Each RPC call is retried only once on the same node before failing over to another node,
+// as retrying a failing node up to the max retry sleep time would delay the failover.
+// used by vigilant reporter
+func NewMultiWithBlockSubscriber(
+	cfg *config.BTCConfig,
+	retrySleepTime time.Duration,
+	parentLogger *zap.Logger,
+	metrics *metrics.BTCBackendMetrics,
+) (*MultiClient, error) {
+	connect := func(nodeCfg *config.BTCConfig) (nodeClient, error) {
+		client, err := NewWithBlockSubscriber(nodeCfg, retrySleepTime, retrySleepTime, parentLogger)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+	return newMultiClient(cfg, connect, parentLogger, metrics)
+}
+
+func newMultiClient(
+	cfg *config.BTCConfig,
+	connect func(cfg *config.BTCConfig) (nodeClient, error),
+	parentLogger *zap.Logger,
+	metrics *metrics.BTCBackendMetrics,
+) (*MultiClient, error) {
+	m := &MultiClient{
+		Cfg:            cfg,
+		logger:         parentLogger.With(zap.String("module", "btcclient_multi")).Sugar(),
+		connect:        connect,
+		active:         -1,
+		metrics:        metrics,
+		blockEventChan: make(chan *types.BlockEvent, 10000), // TODO: parameterise buffer size
+		quit:           make(chan struct{}),
+	}
+
+	for _, nodeCfg := range cfg.NodeConfigs() {
+		b := &backend{
+			name: nodeCfg.Endpoint,
+			cfg:  nodeCfg,
+		}
+		m.backends = append(m.backends, b)
+		if err := m.connectBackend(b); err != nil {
+			m.logger.Errorf("Failed to connect to BTC node %s, skipping it until it can be connected: %v", b.name, err)
+			continue
+		}
+		if m.active == -1 {
+			m.active = len(m.backends) - 1
+		}
+	}
+	if m.active == -1 {
+		return nil, fmt.Errorf("failed to connect to any of the %d BTC nodes", len(m.backends))
+	}
+
+	// find the node with the best chain before serving any request
+	m.pollTips()
+
+	m.wg.Add(1)
+	go m.tipTracker()
+
+	m.logger.Infof("Successfully connected to BTC nodes, using %s", m.activeBackend().name)
+
+	return m, nil
+}
+
+// connectBackend connects to the BTC node of the backend and relays its block events.
+// The node is subscribed to new blocks if the nodes already are.
+func (m *MultiClient) connectBackend(b *backend) error {
+	client, err := m.connect(b.cfg)
+	if err != nil {
+		m.mu.Lock()
+		b.lastErr = err
+		m.mu.Unlock()
+		m.metrics.BackendHealthyGaugeVec.WithLabelValues(b.name).Set(0)
+		return err
+	}
+
+	m.mu.Lock()
+	select {
+	case <-m.quit:
+		m.mu.Unlock()
+		client.Stop()
+		return errors.New("the BTC client is stopped")
+	default:
+	}
+	b.client = client
+	subscribed := m.subscribed
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	go m.forwardBlockEvents(b, client)
+	if subscribed {
+		if err := client.subscribeBlocks(); err != nil {
+			m.logger.Errorf("Failed to subscribe to new blocks of BTC node %s: %v", b.name, err)
+		}
+	}
+	return nil
+}
+
+// connectedBackends returns the backends whose node is connected
+func (m *MultiClient) connectedBackends() []*backend {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var connected []*backend
+	for _, b := range m.backends {
+		if b.client != nil {
+			connected = append(connected, b)
+		}
+	}
+	return connected
+}
+
+func (m *MultiClient) activeBackend() *backend {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.backends[m.active]
+}
+
+// activeClient returns the backend in use together with its connection, which
+// are read together as failovers swap them
+func (m *MultiClient) activeClient() (*backend, nodeClient) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b := m.backends[m.active]
+	return b, b.client
+}
+
+// forwardBlockEvents relays the block events of the node of the backend if it is the
+// node in use, and drops them otherwise
+func (m *MultiClient) forwardBlockEvents(b *backend, client nodeClient) {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case event, open := <-client.BlockEventChan():
+			if !open {
+				return
+			}
+			m.mu.RLock()
+			isActive := m.backends[m.active] == b
+			m.mu.RUnlock()
+			if !isActive {
+				continue
+			}
+			select {
+			case m.blockEventChan <- event:
+			case <-m.quit:
+				return
+			}
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// tipTracker periodically polls the tips of all nodes and switches to the
+// node with the best chain if the node in use lags behind
+func (m *MultiClient) tipTracker() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.Cfg.FailoverPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.pollTips()
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+func (m *MultiClient) pollTips() {
+	for _, b := range m.backends {
+		m.mu.RLock()
+		client := b.client
+		m.mu.RUnlock()
+		if client == nil {
+			if err := m.connectBackend(b); err != nil {
+				m.logger.Warnf("Failed to connect to BTC node %s: %v", b.name, err)
+				continue
+			}
+			m.logger.Infof("Connected to BTC node %s", b.name)
+			m.mu.RLock()
+			client = b.client
+			m.mu.RUnlock()
+		}
+
+		// the RPC is called without retries, as an unresponsive node is the one we want to detect
+		info, err := client.GetBlockChainInfo()
+
+		m.mu.Lock()
+		if err != nil {
+			m.logger.Warnf("Failed to poll the tip of BTC node %s: %v", b.name, err)
+			b.healthy = false
+			b.lastErr = err
+		} else {
+			b.healthy = true
+			b.lastErr = nil
+			b.tipHash = info.BestBlockHash
+			b.tipHeight = info.Blocks
+			b.chainWork = nil
+			if work, ok := new(big.Int).SetString(info.ChainWork, 16); ok {
+				b.chainWork = work
+			}
+		}
+		m.metrics.BackendHealthyGaugeVec.WithLabelValues(b.name).Set(boolToFloat(b.healthy))
+		m.metrics.BackendTipHeightGaugeVec.WithLabelValues(b.name).Set(float64(b.tipHeight))
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	best := m.bestBackend()
+	if best == -1 {
+		m.logger.Errorf("None of the %d BTC nodes is healthy", len(m.backends))
+		return
+	}
+	active := m.backends[m.active]
+	switch {
+	case !active.healthy:
+		m.switchTo(best, fmt.Sprintf("BTC node %s is unhealthy: %v", active.name, active.lastErr))
+	case m.backends[best].hasMoreWork(active) && m.backends[best].tipHeight-active.tipHeight > int32(m.Cfg.FailoverMaxTipLag):
+		m.switchTo(best, fmt.Sprintf("the tip of BTC node %s (height %d) lags behind the tip of BTC node %s (height %d)",
+			active.name, active.tipHeight, m.backends[best].name, m.backends[best].tipHeight))
+	default:
+		m.updateActiveMetrics()
+	}
+}
+
+// bestBackend returns the index of the healthy node with the best chain, or -1
+// if no node is healthy. The node in use is preferred among nodes with the same tip.
+// Thread-unsafe.
+func (m *MultiClient) bestBackend() int {
+	best := -1
+	if m.backends[m.active].healthy {
+		best = m.active
+	}
+	for i, b := range m.backends {
+		if !b.healthy {
+			continue
+		}
+		if best == -1 || b.hasMoreWork(m.backends[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// switchTo makes the i-th node the node in use. Thread-unsafe.
+func (m *MultiClient) switchTo(i int, reason string) {
+	if i == m.active {
+		return
+	}
+	m.logger.Warnf("Failing over from BTC node %s to BTC node %s, as %s", m.backends[m.active].name, m.backends[i].name, reason)
+	m.active = i
+	m.metrics.BackendFailoversCounter.Inc()
+	m.updateActiveMetrics()
+}
+
+// Thread-unsafe.
+func (m *MultiClient) updateActiveMetrics() {
+	for i, b := range m.backends {
+		m.metrics.BackendActiveGaugeVec.WithLabelValues(b.name).Set(boolToFloat(i == m.active))
+	}
+}
+
+// failover marks the given node as unhealthy after an RPC error, and switches to the next best
+// healthy node. It returns false if there is no other healthy node to switch to.
+func (m *MultiClient) failover(failed *backend, err error) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	failed.healthy = false
+	failed.lastErr = err
+	m.metrics.BackendHealthyGaugeVec.WithLabelValues(failed.name).Set(0)
+
+	best := m.bestBackend()
+	if best == -1 {
+		return false
+	}
+	if m.backends[best] != failed {
+		m.switchTo(best, fmt.Sprintf("BTC node %s returned an error: %v", failed.name, err))
+	}
+	return true
+}
+
+// withFailover runs the given RPC call on the node in use. If the node cannot serve the call,
+// the call is retried on the next best healthy node, until every node has been tried.
+func (m *MultiClient) withFailover(call func(c nodeClient) error) error {
+	var err error
+	for range m.backends {
+		b, client := m.activeClient()
+		if err = call(client); err == nil {
+			return nil
+		}
+		// the node rejected the request itself (e.g., an unknown tx), which
+		// means the node is responsive and other nodes would reject it as well
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) {
+			return err
+		}
+		if !m.failover(b, err) {
+			break
+		}
+	}
+	return err
+}
+
+// MustSubscribeBlocks subscribes to the new blocks of all connected nodes, and of the
+// nodes connected later on
+func (m *MultiClient) MustSubscribeBlocks() {
+	m.mu.Lock()
+	m.subscribed = true
+	m.mu.Unlock()
+
+	var numSubscribed int
+	for _, b := range m.connectedBackends() {
+		if err := b.client.subscribeBlocks(); err != nil {
+			m.logger.Errorf("Failed to subscribe to new blocks of BTC node %s: %v", b.name, err)
+			continue
+		}
+		numSubscribed++
+	}
+	if numSubscribed == 0 {
+		panic(fmt.Errorf("failed to subscribe to new blocks of any of the %d BTC nodes", len(m.backends)))
+	}
+}
+
+func (m *MultiClient) BlockEventChan() <-chan *types.BlockEvent {
+	return m.blockEventChan
+}
+
+func (m *MultiClient) GetBestBlock() (*chainhash.Hash, uint64, error) {
+	var (
+		hash   *chainhash.Hash
+		height uint64
+	)
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		hash, height, err = c.GetBestBlock()
+		return err
+	})
+	return hash, height, err
+}
+
+func (m *MultiClient) GetBlockHash(height int64) (*chainhash.Hash, error) {
+	var hash *chainhash.Hash
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		hash, err = c.GetBlockHash(height)
+		return err
+	})
+	return hash, err
+}
+
+func (m *MultiClient) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	var header *wire.BlockHeader
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		header, err = c.GetBlockHeader(blockHash)
+		return err
+	})
+	return header, err
+}
+
+func (m *MultiClient) GetBlockByHash(blockHash *chainhash.Hash) (*types.IndexedBlock, *wire.MsgBlock, error) {
+	var (
+		ib     *types.IndexedBlock
+		mBlock *wire.MsgBlock
+	)
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		ib, mBlock, err = c.GetBlockByHash(blockHash)
+		return err
+	})
+	return ib, mBlock, err
+}
+
+func (m *MultiClient) FindTailBlocksByHeight(height uint64) ([]*types.IndexedBlock, error) {
+	var ibs []*types.IndexedBlock
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		ibs, err = c.FindTailBlocksByHeight(height)
+		return err
+	})
+	return ibs, err
+}
+
+func (m *MultiClient) GetBlockByHeight(height uint64) (*types.IndexedBlock, *wire.MsgBlock, error) {
+	var (
+		ib     *types.IndexedBlock
+		mBlock *wire.MsgBlock
+	)
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		ib, mBlock, err = c.GetBlockByHeight(height)
+		return err
+	})
+	return ib, mBlock, err
+}
+
+func (m *MultiClient) GetBlockHeaderByHeight(height uint64) (*wire.BlockHeader, error) {
+	var header *wire.BlockHeader
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		header, err = c.GetBlockHeaderByHeight(height)
+		return err
+	})
+	return header, err
+}
+
+func (m *MultiClient) GetTxOut(txHash *chainhash.Hash, index uint32, mempool bool) (*btcjson.GetTxOutResult, error) {
+	var res *btcjson.GetTxOutResult
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		res, err = c.GetTxOut(txHash, index, mempool)
+		return err
+	})
+	return res, err
+}
+
+func (m *MultiClient) SendRawTransaction(tx *wire.MsgTx, allowHighFees bool) (*chainhash.Hash, error) {
+	var txHash *chainhash.Hash
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		txHash, err = c.SendRawTransaction(tx, allowHighFees)
+		return err
+	})
+	return txHash, err
+}
+
+func (m *MultiClient) GetTransaction(txHash *chainhash.Hash) (*btcjson.GetTransactionResult, error) {
+	var res *btcjson.GetTransactionResult
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		res, err = c.GetTransaction(txHash)
+		return err
+	})
+	return res, err
+}
+
+func (m *MultiClient) GetRawTransaction(txHash *chainhash.Hash) (*btcutil.Tx, error) {
+	var tx *btcutil.Tx
+	err := m.withFailover(func(c nodeClient) error {
+		var err error
+		tx, err = c.GetRawTransaction(txHash)
+		return err
+	})
+	return tx, err
+}
+
+// Stop stops the connections to all BTC nodes and closes the block event channel
+func (m *MultiClient) Stop() {
+	m.mu.Lock()
+	select {
+	case <-m.quit:
+		m.mu.Unlock()
+		return
+	default:
+		close(m.quit)
+	}
+	m.mu.Unlock()
+
+	for _, b := range m.connectedBackends() {
+		b.client.Stop()
+	}
+	m.wg.Wait()
+	close(m.blockEventChan)
+}
+
+// WaitForShutdown blocks until the connections to all BTC nodes are shut down
+func (m *MultiClient) WaitForShutdown() {
+	for _, b := range m.connectedBackends() {
+		b.client.WaitForShutdown()
+	}
+	m.wg.Wait()
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,238 @@
+package btcclient
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/types"
+)
+
+var errNodeDown = errors.New("connection refused")
+
+// testNode is a BTC node whose tip and availability are set by the test
+type testNode struct {
+	nodeClient
+
+	mu         sync.Mutex
+	height     int32
+	err        error
+	subscribed bool
+
+	blockEventChan chan *types.BlockEvent
+	stopOnce       sync.Once
+}
+
+func newTestNode(height int32) *testNode {
+	return &testNode{
+		height:         height,
+		blockEventChan: make(chan *types.BlockEvent, 10),
+	}
+}
+
+func (n *testNode) set(height int32, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.height = height
+	n.err = err
+}
+
+func (n *testNode) isSubscribed() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.subscribed
+}
+
+func (n *testNode) GetBlockChainInfo() (*btcjson.GetBlockChainInfoResult, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return nil, n.err
+	}
+	// btcd does not report the chain work
+	return &btcjson.GetBlockChainInfoResult{Blocks: n.height}, nil
+}
+
+func (n *testNode) GetBestBlock() (*chainhash.Hash, uint64, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil {
+		return nil, 0, n.err
+	}
+	return &chainhash.Hash{}, uint64(n.height), nil
+}
+
+func (n *testNode) subscribeBlocks() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subscribed = true
+	return nil
+}
+
+func (n *testNode) BlockEventChan() <-chan *types.BlockEvent {
+	return n.blockEventChan
+}
+
+func (n *testNode) Stop() {
+	n.stopOnce.Do(func() { close(n.blockEventChan) })
+}
+
+func (n *testNode) WaitForShutdown() {}
+
+// testNodes connects to the test nodes by endpoint, and fails to connect to the
+// nodes that are down
+type testNodes struct {
+	mu    sync.Mutex
+	nodes map[string]*testNode
+	down  map[string]bool
+}
+
+func (tn *testNodes) setDown(endpoint string, down bool) {
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	tn.down[endpoint] = down
+}
+
+func (tn *testNodes) connect(cfg *config.BTCConfig) (nodeClient, error) {
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	if tn.down[cfg.Endpoint] {
+		return nil, errNodeDown
+	}
+	return tn.nodes[cfg.Endpoint], nil
+}
+
+// newTestMultiClient connects to a primary node and a failover node with the given tips
+func newTestMultiClient(t *testing.T, primaryHeight, failoverHeight int32, failoverDown bool) (*MultiClient, *testNodes, *metrics.BTCBackendMetrics, error) {
+	cfg := config.DefaultBTCConfig()
+	cfg.FailoverNodes = []config.BTCNodeConfig{{Endpoint: "failover"}}
+	// tips are polled by the tests
+	cfg.FailoverPollInterval = time.Hour
+	nodes := &testNodes{
+		nodes: map[string]*testNode{
+			cfg.Endpoint: newTestNode(primaryHeight),
+			"failover":   newTestNode(failoverHeight),
+		},
+		down: map[string]bool{"failover": failoverDown},
+	}
+	backendMetrics := metrics.NewReporterMetrics().BTCBackendMetrics
+	m, err := newMultiClient(&cfg, nodes.connect, zap.NewNop(), backendMetrics)
+	if err == nil {
+		t.Cleanup(m.Stop)
+	}
+	return m, nodes, backendMetrics, err
+}
+
+func requireActive(t *testing.T, m *MultiClient, backendMetrics *metrics.BTCBackendMetrics, name string) {
+	require.Equal(t, name, m.activeBackend().name)
+	require.Equal(t, float64(1), testutil.ToFloat64(backendMetrics.BackendActiveGaugeVec.WithLabelValues(name)))
+}
+
+func requireBlockEvent(t *testing.T, m *MultiClient, expected *types.BlockEvent) {
+	select {
+	case event := <-m.BlockEventChan():
+		require.Equal(t, expected, event)
+	case <-time.After(time.Second):
+		t.Fatal("block event is not relayed")
+	}
+}
+
+func TestMultiClientFailsOverOnError(t *testing.T) {
+	m, nodes, backendMetrics, err := newTestMultiClient(t, 100, 100, false)
+	require.NoError(t, err)
+	primary := m.Cfg.Endpoint
+	requireActive(t, m, backendMetrics, primary)
+
+	// a node that cannot be reached fails over to the other node
+	nodes.nodes[primary].set(100, errNodeDown)
+	_, height, err := m.GetBestBlock()
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), height)
+	requireActive(t, m, backendMetrics, "failover")
+	require.Zero(t, testutil.ToFloat64(backendMetrics.BackendHealthyGaugeVec.WithLabelValues(primary)))
+	require.Equal(t, float64(1), testutil.ToFloat64(backendMetrics.BackendFailoversCounter))
+
+	// a request rejected by the node does not fail over
+	rpcErr := &btcjson.RPCError{Code: btcjson.ErrRPCBlockNotFound, Message: "block not found"}
+	nodes.nodes["failover"].set(100, rpcErr)
+	_, _, err = m.GetBestBlock()
+	require.ErrorIs(t, err, rpcErr)
+	requireActive(t, m, backendMetrics, "failover")
+
+	// the call fails once no node can serve it
+	nodes.nodes["failover"].set(100, errNodeDown)
+	_, _, err = m.GetBestBlock()
+	require.ErrorIs(t, err, errNodeDown)
+
+	// the recovered node is used again once its tip is polled
+	nodes.nodes[primary].set(101, nil)
+	m.pollTips()
+	requireActive(t, m, backendMetrics, primary)
+	require.Equal(t, float64(1), testutil.ToFloat64(backendMetrics.BackendHealthyGaugeVec.WithLabelValues(primary)))
+	require.Equal(t, float64(2), testutil.ToFloat64(backendMetrics.BackendFailoversCounter))
+}
+
+func TestMultiClientFailsOverOnTipLag(t *testing.T) {
+	maxTipLag := int32(config.DefaultFailoverMaxTipLag)
+
+	// the node with the best tip is used from the start
+	m, nodes, backendMetrics, err := newTestMultiClient(t, 100, 100+maxTipLag+1, false)
+	require.NoError(t, err)
+	requireActive(t, m, backendMetrics, "failover")
+
+	// a node lagging by at most the max tip lag is still used
+	primary := m.Cfg.Endpoint
+	nodes.nodes[primary].set(200+maxTipLag, nil)
+	nodes.nodes["failover"].set(200, nil)
+	m.pollTips()
+	requireActive(t, m, backendMetrics, "failover")
+	require.Equal(t, float64(200+maxTipLag), testutil.ToFloat64(backendMetrics.BackendTipHeightGaugeVec.WithLabelValues(primary)))
+
+	nodes.nodes[primary].set(201+maxTipLag, nil)
+	m.pollTips()
+	requireActive(t, m, backendMetrics, primary)
+	require.Equal(t, float64(2), testutil.ToFloat64(backendMetrics.BackendFailoversCounter))
+}
+
+func TestMultiClientReconnects(t *testing.T) {
+	// a node that cannot be connected is skipped
+	m, nodes, backendMetrics, err := newTestMultiClient(t, 100, 200, true)
+	require.NoError(t, err)
+	primary := m.Cfg.Endpoint
+	requireActive(t, m, backendMetrics, primary)
+	require.Zero(t, testutil.ToFloat64(backendMetrics.BackendHealthyGaugeVec.WithLabelValues("failover")))
+	m.MustSubscribeBlocks()
+	require.True(t, nodes.nodes[primary].isSubscribed())
+
+	// the node is connected and subscribed to new blocks once it is up
+	m.pollTips()
+	requireActive(t, m, backendMetrics, primary)
+	nodes.setDown("failover", false)
+	m.pollTips()
+	require.True(t, nodes.nodes["failover"].isSubscribed())
+	requireActive(t, m, backendMetrics, "failover")
+	require.Equal(t, float64(1), testutil.ToFloat64(backendMetrics.BackendHealthyGaugeVec.WithLabelValues("failover")))
+
+	// only the block events of the node in use are relayed
+	nodes.nodes[primary].blockEventChan <- types.NewBlockEvent(types.BlockConnected, 101, &wire.BlockHeader{})
+	event := types.NewBlockEvent(types.BlockConnected, 201, &wire.BlockHeader{Nonce: 1})
+	nodes.nodes["failover"].blockEventChan <- event
+	requireBlockEvent(t, m, event)
+}
+
+func TestMultiClientNoNodeConnected(t *testing.T) {
+	cfg := config.DefaultBTCConfig()
+	nodes := &testNodes{down: map[string]bool{cfg.Endpoint: true}}
+	_, err := newMultiClient(&cfg, nodes.connect, zap.NewNop(), metrics.NewReporterMetrics().BTCBackendMetrics)
+	require.Error(t, err)
+}
@@ -0,0 +1,237 @@
+package btcclient
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	spendHintBucket   = []byte("spend-hints")
+	confirmHintBucket = []byte("confirm-hints")
+)
+
+// BoltHintCache is a HintCache persisted in a bbolt database. The chain notifier
+// commits, at every new block, the height up to which each spend and confirmation
+// request is known to be unresolved, so that requests registered again after a
+// restart rescan from that height instead of from their original height hint.
+type BoltHintCache struct {
+	db *bolt.DB
+}
+
+var _ HintCache = (*BoltHintCache)(nil)
+
+// NewBoltHintCache opens, or creates, the hint cache database at the given path
+func NewBoltHintCache(path string) (*BoltHintCache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// a timeout avoids blocking forever if another process holds the database
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hint cache %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(spendHintBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(confirmHintBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialise hint cache %s: %w", path, err)
+	}
+	return &BoltHintCache{db: db}, nil
+}
+
+// spendRequestKey identifies a spend request by its outpoint and script. For
+// taproot outputs the script is zeroed by lnd, so the outpoint is what matters.
+func spendRequestKey(r chainntnfs.SpendRequest) []byte {
+	script := r.PkScript.Script()
+	key := make([]byte, 0, len(r.OutPoint.Hash)+4+len(script))
+	key = append(key, r.OutPoint.Hash[:]...)
+	key = binary.BigEndian.AppendUint32(key, r.OutPoint.Index)
+	return append(key, script...)
+}
+
+// confRequestKey identifies a confirmation request by its txid and script
+func confRequestKey(r chainntnfs.ConfRequest) []byte {
+	script := r.PkScript.Script()
+	key := make([]byte, 0, len(r.TxID)+len(script))
+	key = append(key, r.TxID[:]...)
+	return append(key, script...)
+}
+
+func encodeHeight(height uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, height)
+}
+
+func (c *BoltHintCache) commit(bucket []byte, height uint32, keys [][]byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		for _, key := range keys {
+			if err := b.Put(key, encodeHeight(height)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *BoltHintCache) query(bucket []byte, key []byte) (uint32, bool, error) {
+	var (
+		height uint32
+		found  bool
+	)
+	err := c.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucket).Get(key)
+		if v == nil {
+			return nil
+		}
+		if len(v) != 4 {
+			return fmt.Errorf("invalid hint of %d bytes", len(v))
+		}
+		height = binary.BigEndian.Uint32(v)
+		found = true
+		return nil
+	})
+	return height, found, err
+}
+
+func (c *BoltHintCache) purge(bucket []byte, keys [][]byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		for _, key := range keys {
+			if err := b.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (c *BoltHintCache) CommitSpendHint(height uint32, spendRequests ...chainntnfs.SpendRequest) error {
+	keys := make([][]byte, len(spendRequests))
+	for i, r := range spendRequests {
+		keys[i] = spendRequestKey(r)
+	}
+	return c.commit(spendHintBucket, height, keys)
+}
+
+func (c *BoltHintCache) QuerySpendHint(spendRequest chainntnfs.SpendRequest) (uint32, error) {
+	height, found, err := c.query(spendHintBucket, spendRequestKey(spendRequest))
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, chainntnfs.ErrSpendHintNotFound
+	}
+	return height, nil
+}
+
+func (c *BoltHintCache) PurgeSpendHint(spendRequests ...chainntnfs.SpendRequest) error {
+	keys := make([][]byte, len(spendRequests))
+	for i, r := range spendRequests {
+		keys[i] = spendRequestKey(r)
+	}
+	return c.purge(spendHintBucket, keys)
+}
+
+func (c *BoltHintCache) CommitConfirmHint(height uint32, confRequests ...chainntnfs.ConfRequest) error {
+	keys := make([][]byte, len(confRequests))
+	for i, r := range confRequests {
+		keys[i] = confRequestKey(r)
+	}
+	return c.commit(confirmHintBucket, height, keys)
+}
+
+func (c *BoltHintCache) QueryConfirmHint(confRequest chainntnfs.ConfRequest) (uint32, error) {
+	height, found, err := c.query(confirmHintBucket, confRequestKey(confRequest))
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, chainntnfs.ErrConfirmHintNotFound
+	}
+	return height, nil
+}
+
+func (c *BoltHintCache) PurgeConfirmHint(confRequests ...chainntnfs.ConfRequest) error {
+	keys := make([][]byte, len(confRequests))
+	for i, r := range confRequests {
+		keys[i] = confRequestKey(r)
+	}
+	return c.purge(confirmHintBucket, keys)
+}
+
+// Prune removes the hints lagging more than depth blocks behind the highest hint.
+// The notifier moves the hints of unresolved requests forward at every block, so
+// such hints belong to requests that are no longer registered, e.g. of delegations
+// that stopped being active while the tracker was down. The highest hint rather
+// than the BTC tip is the reference, so that hints are not pruned just because the
+// tracker was down for a while.
+func (c *BoltHintCache) Prune(depth uint32) (int, error) {
+	pruned := 0
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		buckets := []*bolt.Bucket{tx.Bucket(spendHintBucket), tx.Bucket(confirmHintBucket)}
+
+		var highest uint32
+		for _, b := range buckets {
+			err := b.ForEach(func(_, v []byte) error {
+				if len(v) == 4 {
+					highest = max(highest, binary.BigEndian.Uint32(v))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if highest <= depth {
+			return nil
+		}
+
+		for _, b := range buckets {
+			var stale [][]byte
+			err := b.ForEach(func(k, v []byte) error {
+				if len(v) != 4 || binary.BigEndian.Uint32(v) < highest-depth {
+					// keys are only valid until the end of the transaction
+					stale = append(stale, append([]byte(nil), k...))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			// deleting while iterating with ForEach is not supported by bbolt
+			for _, k := range stale {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			pruned += len(stale)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+// Close closes the hint cache database
+func (c *BoltHintCache) Close() error {
+	return c.db.Close()
+}
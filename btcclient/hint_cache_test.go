@@ -0,0 +1,96 @@
+package btcclient_test
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/btcclient"
+)
+
+// p2wsh script, i.e. OP_0 followed by 32 bytes
+func randomPkScript(r *rand.Rand) []byte {
+	return append([]byte{0x00, 0x20}, datagen.GenRandomByteArray(r, 32)...)
+}
+
+func FuzzBoltHintCache(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		path := filepath.Join(t.TempDir(), "hints.db")
+
+		hintCache, err := btcclient.NewBoltHintCache(path)
+		require.NoError(t, err)
+
+		numRequests := r.Intn(20) + 2
+		spendRequests := make([]chainntnfs.SpendRequest, numRequests)
+		confRequests := make([]chainntnfs.ConfRequest, numRequests)
+		for i := range spendRequests {
+			outPoint := &wire.OutPoint{Hash: chainhash.Hash(datagen.GenRandomByteArray(r, 32)), Index: r.Uint32()}
+			spendRequests[i], err = chainntnfs.NewSpendRequest(outPoint, randomPkScript(r))
+			require.NoError(t, err)
+			txid := chainhash.Hash(datagen.GenRandomByteArray(r, 32))
+			confRequests[i], err = chainntnfs.NewConfRequest(&txid, randomPkScript(r))
+			require.NoError(t, err)
+		}
+
+		// unknown requests have no hint
+		_, err = hintCache.QuerySpendHint(spendRequests[0])
+		require.ErrorIs(t, err, chainntnfs.ErrSpendHintNotFound)
+		_, err = hintCache.QueryConfirmHint(confRequests[0])
+		require.ErrorIs(t, err, chainntnfs.ErrConfirmHintNotFound)
+
+		// the first request lags behind the others
+		staleHeight := uint32(r.Intn(1000)) + 1
+		depth := uint32(r.Intn(100)) + 1
+		tipHeight := staleHeight + depth + uint32(r.Intn(100)) + 1
+		require.NoError(t, hintCache.CommitSpendHint(staleHeight, spendRequests[0]))
+		require.NoError(t, hintCache.CommitConfirmHint(staleHeight, confRequests[0]))
+		require.NoError(t, hintCache.CommitSpendHint(tipHeight, spendRequests[1:]...))
+		require.NoError(t, hintCache.CommitConfirmHint(tipHeight, confRequests[1:]...))
+
+		// hints survive a restart
+		require.NoError(t, hintCache.Close())
+		hintCache, err = btcclient.NewBoltHintCache(path)
+		require.NoError(t, err)
+		defer hintCache.Close()
+
+		height, err := hintCache.QuerySpendHint(spendRequests[0])
+		require.NoError(t, err)
+		require.Equal(t, staleHeight, height)
+		for i := 1; i < numRequests; i++ {
+			height, err = hintCache.QuerySpendHint(spendRequests[i])
+			require.NoError(t, err)
+			require.Equal(t, tipHeight, height)
+			height, err = hintCache.QueryConfirmHint(confRequests[i])
+			require.NoError(t, err)
+			require.Equal(t, tipHeight, height)
+		}
+
+		// only the lagging hints are pruned
+		pruned, err := hintCache.Prune(depth)
+		require.NoError(t, err)
+		require.Equal(t, 2, pruned)
+		_, err = hintCache.QuerySpendHint(spendRequests[0])
+		require.ErrorIs(t, err, chainntnfs.ErrSpendHintNotFound)
+		_, err = hintCache.QueryConfirmHint(confRequests[0])
+		require.ErrorIs(t, err, chainntnfs.ErrConfirmHintNotFound)
+		pruned, err = hintCache.Prune(depth)
+		require.NoError(t, err)
+		require.Zero(t, pruned)
+
+		// purged hints are gone
+		require.NoError(t, hintCache.PurgeSpendHint(spendRequests[1]))
+		_, err = hintCache.QuerySpendHint(spendRequests[1])
+		require.ErrorIs(t, err, chainntnfs.ErrSpendHintNotFound)
+		require.NoError(t, hintCache.PurgeConfirmHint(confRequests[1]))
+		_, err = hintCache.QueryConfirmHint(confRequests[1])
+		require.ErrorIs(t, err, chainntnfs.ErrConfirmHintNotFound)
+	})
+}
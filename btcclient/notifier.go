@@ -108,11 +108,13 @@ type NodeBackend struct {
 type HintCache interface {
 	chainntnfs.SpendHintCache
 	chainntnfs.ConfirmHintCache
+	// Prune removes the hints lagging more than depth blocks behind the most recent
+	// hint, and returns the number of removed hints
+	Prune(depth uint32) (int, error)
 }
 
-// type for disabled hint cache
-// TODO: Determine if we need hint cache backed up by database which is provided
-// by lnd.
+// type for disabled hint cache, with which every request rescans from its own
+// height hint. See BoltHintCache for the persistent one.
 type EmptyHintCache struct{}
 
 var _ HintCache = (*EmptyHintCache)(nil)
@@ -137,6 +139,10 @@ func (c *EmptyHintCache) PurgeConfirmHint(confRequests ...chainntnfs.ConfRequest
 	return nil
 }
 
+func (c *EmptyHintCache) Prune(depth uint32) (int, error) {
+	return 0, nil
+}
+
 // // TODO  This should be moved to a more appropriate place, most probably to config
 // // and be connected to validation of rpc host/port.
 // // According to chain.BitcoindConfig docs it should also support tor if node backend
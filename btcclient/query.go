@@ -60,6 +60,21 @@ func (c *Client) GetBlockByHeight(height uint64) (*types.IndexedBlock, *wire.Msg
 	return types.NewIndexedBlock(int32(height), &mBlock.Header, btcTxs), mBlock, nil
 }
 
+// GetBlockHeaderByHeight returns the header of the block with the given height
+func (c *Client) GetBlockHeaderByHeight(height uint64) (*wire.BlockHeader, error) {
+	blockHash, err := c.getBlockHashWithRetry(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block hash by height %d: %w", height, err)
+	}
+
+	header, err := c.getBlockHeaderWithRetry(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block header by hash %s: %w", blockHash.String(), err)
+	}
+
+	return header, nil
+}
+
 func (c *Client) getBestBlockHashWithRetry() (*chainhash.Hash, error) {
 	var (
 		blockHash *chainhash.Hash
@@ -75,6 +90,7 @@ func (c *Client) getBestBlockHashWithRetry() (*chainhash.Hash, error) {
 	}); err != nil {
 		c.logger.Debug(
 			"failed to query the best block hash", zap.Error(err))
+		return nil, err
 	}
 
 	return blockHash, nil
@@ -95,6 +111,7 @@ func (c *Client) getBlockHashWithRetry(height uint64) (*chainhash.Hash, error) {
 	}); err != nil {
 		c.logger.Debug(
 			"failed to query the block hash", zap.Uint64("height", height), zap.Error(err))
+		return nil, err
 	}
 
 	return blockHash, nil
@@ -115,11 +132,33 @@ func (c *Client) getBlockWithRetry(hash *chainhash.Hash) (*wire.MsgBlock, error)
 	}); err != nil {
 		c.logger.Debug(
 			"failed to query the block", zap.String("hash", hash.String()), zap.Error(err))
+		return nil, err
 	}
 
 	return block, nil
 }
 
+func (c *Client) getBlockHeaderWithRetry(hash *chainhash.Hash) (*wire.BlockHeader, error) {
+	var (
+		header *wire.BlockHeader
+		err    error
+	)
+
+	if err := retry.Do(c.retrySleepTime, c.maxRetrySleepTime, func() error {
+		header, err = c.GetBlockHeader(hash)
+		if err != nil {
+			return err
+		}
+		return nil
+	}); err != nil {
+		c.logger.Debug(
+			"failed to query the block header", zap.String("hash", hash.String()), zap.Error(err))
+		return nil, err
+	}
+
+	return header, nil
+}
+
 func (c *Client) getBlockVerboseWithRetry(hash *chainhash.Hash) (*btcjson.GetBlockVerboseResult, error) {
 	var (
 		blockVerbose *btcjson.GetBlockVerboseResult
@@ -135,6 +174,7 @@ func (c *Client) getBlockVerboseWithRetry(hash *chainhash.Hash) (*btcjson.GetBlo
 	}); err != nil {
 		c.logger.Debug(
 			"failed to query the block verbose", zap.String("hash", hash.String()), zap.Error(err))
+		return nil, err
 	}
 
 	return blockVerbose, nil
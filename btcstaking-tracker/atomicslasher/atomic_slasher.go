@@ -6,11 +6,10 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
 	"github.com/btcsuite/btcd/btcec/v2"
-	notifier "github.com/lightningnetwork/lnd/chainntnfs"
 	"go.uber.org/zap"
 )
 
@@ -23,8 +22,7 @@ type AtomicSlasher struct {
 
 	// internal components
 	logger      *zap.Logger
-	btcClient   btcclient.BTCClient
-	btcNotifier notifier.ChainNotifier
+	blockStream *blockstream.BlockStream
 	bbnAdapter  *BabylonAdapter
 
 	// config parameters
@@ -47,8 +45,7 @@ func New(
 	parentLogger *zap.Logger,
 	retrySleepTime time.Duration,
 	maxRetrySleepTime time.Duration,
-	btcClient btcclient.BTCClient,
-	btcNotifier notifier.ChainNotifier,
+	blockStream *blockstream.BlockStream,
 	bbnClient BabylonClient,
 	slashedFPSKChan chan *btcec.PrivateKey,
 	metrics *metrics.AtomicSlasherMetrics,
@@ -61,8 +58,7 @@ func New(
 		retrySleepTime:    retrySleepTime,
 		maxRetrySleepTime: maxRetrySleepTime,
 		logger:            logger,
-		btcClient:         btcClient,
-		btcNotifier:       btcNotifier,
+		blockStream:       blockStream,
 		bbnAdapter:        bbnAdapter,
 		btcDelIndex:       NewBTCDelegationIndex(),
 		slashingTxChan:    make(chan *SlashingTxInfo, 100), // TODO: parameterise
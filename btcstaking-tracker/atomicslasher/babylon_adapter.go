@@ -9,12 +9,16 @@ import (
 	"github.com/avast/retry-go/v4"
 	bbn "github.com/babylonchain/babylon/types"
 	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnevents"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	"go.uber.org/zap"
 )
 
+// name of the subscription to BTC delegations becoming active on Babylon
+const activatedDelegationsSubscriber = "atomic-slasher-delegations"
+
 type BabylonAdapter struct {
 	logger            *zap.Logger
 	cfg               *config.BTCStakingTrackerConfig
@@ -77,6 +81,12 @@ func (ba *BabylonAdapter) BTCDelegation(ctx context.Context, stakingTxHashHex st
 	return resp, err
 }
 
+// SubscribeActivatedBTCDelegations subscribes to the BTC delegations becoming
+// active on Babylon, until the context is done
+func (ba *BabylonAdapter) SubscribeActivatedBTCDelegations(ctx context.Context) *bbnevents.ActivatedDelegations {
+	return bbnevents.SubscribeActivatedDelegations(ctx, ba.bbnClient, activatedDelegationsSubscriber, ba.logger.Sugar())
+}
+
 // TODO: avoid getting expired BTC delegations
 func (ba *BabylonAdapter) HandleAllBTCDelegations(handleFunc func(btcDel *bstypes.BTCDelegationResponse) error) error {
 	pagination := query.PageRequest{Limit: ba.cfg.NewDelegationsBatchSize}
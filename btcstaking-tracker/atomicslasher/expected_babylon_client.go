@@ -5,12 +5,15 @@ import (
 
 	"cosmossdk.io/errors"
 	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnevents"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	sdkquerytypes "github.com/cosmos/cosmos-sdk/types/query"
 	pv "github.com/cosmos/relayer/v2/relayer/provider"
 )
 
 type BabylonClient interface {
+	// Subscriber subscribes to events of BTC delegations becoming active
+	bbnevents.Subscriber
 	FinalityProvider(fpBtcPkHex string) (*bstypes.QueryFinalityProviderResponse, error)
 	BTCDelegations(status bstypes.BTCDelegationStatus, pagination *sdkquerytypes.PageRequest) (*bstypes.QueryBTCDelegationsResponse, error)
 	BTCDelegation(stakingTxHashHex string) (*bstypes.QueryBTCDelegationResponse, error)
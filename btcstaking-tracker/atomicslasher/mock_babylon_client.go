@@ -10,6 +10,7 @@ import (
 
 	errors "cosmossdk.io/errors"
 	types "github.com/babylonchain/babylon/x/btcstaking/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	types0 "github.com/cosmos/cosmos-sdk/types"
 	query "github.com/cosmos/cosmos-sdk/types/query"
 	provider "github.com/cosmos/relayer/v2/relayer/provider"
@@ -127,3 +128,37 @@ func (mr *MockBabylonClientMockRecorder) ReliablySendMsg(ctx, msg, expectedError
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReliablySendMsg", reflect.TypeOf((*MockBabylonClient)(nil).ReliablySendMsg), ctx, msg, expectedErrors, unrecoverableErrors)
 }
+
+// Subscribe mocks base method.
+func (m *MockBabylonClient) Subscribe(subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{subscriber, query}
+	for _, a := range outCapacity {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Subscribe", varargs...)
+	ret0, _ := ret[0].(<-chan coretypes.ResultEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockBabylonClientMockRecorder) Subscribe(subscriber, query interface{}, outCapacity ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{subscriber, query}, outCapacity...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockBabylonClient)(nil).Subscribe), varargs...)
+}
+
+// UnsubscribeAll mocks base method.
+func (m *MockBabylonClient) UnsubscribeAll(subscriber string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeAll", subscriber)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsubscribeAll indicates an expected call of UnsubscribeAll.
+func (mr *MockBabylonClientMockRecorder) UnsubscribeAll(subscriber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeAll", reflect.TypeOf((*MockBabylonClient)(nil).UnsubscribeAll), subscriber)
+}
@@ -4,32 +4,57 @@ import (
 	"time"
 
 	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/types"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"go.uber.org/zap"
 )
 
-// btcDelegationTracker is a routine that periodically polls BTC delegations
-// and updates the BTC delegation index
+// btcDelegationTracker is a routine that keeps the BTC delegation index up to
+// date. BTC delegations are added as they become active on Babylon, and by
+// rescans of all BTC delegations at startup, upon resubscribing to Babylon events,
+// and then every CheckDelegationsInterval, which catch up on the events missed
+// while disconnected from Babylon.
 func (as *AtomicSlasher) btcDelegationTracker() {
 	defer as.wg.Done()
+	ctx, cancel := as.quitContext()
+	defer cancel()
 
-	ticker := time.NewTicker(as.cfg.CheckDelegationsInterval)
-	defer ticker.Stop()
+	activated := as.bbnAdapter.SubscribeActivatedBTCDelegations(ctx)
+
+	rescanTimer := time.NewTimer(0)
+	defer rescanTimer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			err := as.bbnAdapter.HandleAllBTCDelegations(func(btcDel *bstypes.BTCDelegationResponse) error {
-				trackedDel, err := NewTrackedBTCDelegation(btcDel)
-				if err != nil {
-					return err
-				}
-				as.btcDelIndex.Add(trackedDel)
-				as.metrics.TrackedBTCDelegationsGauge.Inc()
-				return nil
-			})
+		case <-activated.Resubscribed():
+			// catch up on the BTC delegations activated while the subscription was down
+			if !rescanTimer.Stop() {
+				<-rescanTimer.C
+			}
+			rescanTimer.Reset(0)
+		case <-rescanTimer.C:
+			err := as.bbnAdapter.HandleAllBTCDelegations(as.addBTCDelegation)
 			if err != nil {
 				as.logger.Error("failed to handle all BTC delegations", zap.Error(err))
+				rescanTimer.Reset(min(as.cfg.CheckDelegationsInterval, as.retrySleepTime))
+				continue
+			}
+			rescanTimer.Reset(as.cfg.CheckDelegationsInterval)
+		case stakingTxHash := <-activated.Activated():
+			if as.btcDelIndex.Get(stakingTxHash) != nil {
+				continue
+			}
+			resp, err := as.bbnAdapter.BTCDelegation(ctx, stakingTxHash.String())
+			if err != nil {
+				as.logger.Error(
+					"failed to get activated BTC delegation, leaving it to the next rescan",
+					zap.String("staking_tx_hash", stakingTxHash.String()),
+					zap.Error(err),
+				)
+				continue
+			}
+			if err := as.addBTCDelegation(resp.BtcDelegation); err != nil {
+				as.logger.Error("failed to handle activated BTC delegation", zap.Error(err))
 			}
 		case <-as.quit:
 			return
@@ -37,43 +62,44 @@ func (as *AtomicSlasher) btcDelegationTracker() {
 	}
 }
 
+func (as *AtomicSlasher) addBTCDelegation(btcDel *bstypes.BTCDelegationResponse) error {
+	trackedDel, err := NewTrackedBTCDelegation(btcDel)
+	if err != nil {
+		return err
+	}
+	as.btcDelIndex.Add(trackedDel)
+	as.metrics.TrackedBTCDelegationsGauge.Set(float64(as.btcDelIndex.Len()))
+	return nil
+}
+
 // slashingTxTracker is a routine that keeps tracking new BTC blocks and
 // filtering out slashing tx and unbonding slashing tx
 func (as *AtomicSlasher) slashingTxTracker() {
 	defer as.wg.Done()
 
-	blockNotifier, err := as.btcNotifier.RegisterBlockEpochNtfn(nil)
-	if err != nil {
-		as.logger.Error("failed to register block notifier", zap.Error(err))
-		return
-	}
-	defer blockNotifier.Cancel()
+	blockSubscription := as.blockStream.Subscribe("atomic_slasher")
+	defer blockSubscription.Cancel()
 
 	// TODO: trim the expired/slashed BTC delegations
 
 	for {
 		select {
-		case blockEpoch, ok := <-blockNotifier.Epochs:
+		case blockEvent, ok := <-blockSubscription.Events():
 			if !ok {
 				return
 			}
-			// record BTC tip
-			as.btcTipHeight.Store(uint32(blockEpoch.Height))
-			as.logger.Debug("Received new best btc block", zap.Int32("height", blockEpoch.Height))
-			// get full BTC block
-			// TODO: ensure the tx witness is retrieved as well
-			_, block, err := as.btcClient.GetBlockByHash(blockEpoch.Hash)
-			if err != nil {
-				as.logger.Error(
-					"failed to get block by hash",
-					zap.String("block_hash", blockEpoch.Hash.String()),
-					zap.Error(err),
-				)
+			// a slashing tx in a disconnected block is found again once it is
+			// included in a block of the new best chain
+			if blockEvent.EventType == types.BlockDisconnected {
+				as.btcTipHeight.Store(uint32(blockEvent.Height - 1))
 				continue
 			}
+			// record BTC tip
+			as.btcTipHeight.Store(uint32(blockEvent.Height))
+			as.logger.Debug("Received new best btc block", zap.Int32("height", blockEvent.Height))
 			// filter out slashing tx / unbonding slashing tx, and
 			// enqueue them to the slashed BTC delegation channel
-			for _, tx := range block.Transactions {
+			for _, tx := range blockEvent.Block.Transactions {
 				txHash := tx.TxHash()
 				trackedBTCDel, slashingPath := as.btcDelIndex.FindSlashedBTCDelegation(txHash)
 				if trackedBTCDel != nil {
@@ -145,6 +171,7 @@ func (as *AtomicSlasher) selectiveSlashingReporter() {
 			// skip if the finality provider is already slashed
 			ctx, cancel = as.quitContext()
 			isSlashed, err := as.bbnAdapter.IsFPSlashed(ctx, fpPK)
+			cancel()
 			if err != nil {
 				as.logger.Error(
 					"failed to query slashing status of finality provider",
@@ -154,7 +181,6 @@ func (as *AtomicSlasher) selectiveSlashingReporter() {
 				)
 				continue
 			}
-			cancel()
 			if isSlashed {
 				as.logger.Info(
 					"the finality provider hosting BTC delegation is already slashed",
@@ -204,6 +230,7 @@ func (as *AtomicSlasher) selectiveSlashingReporter() {
 
 			// stop tracking the delegations under this finality provider
 			as.btcDelIndex.Remove(stakingTxHash)
+			as.metrics.TrackedBTCDelegationsGauge.Set(float64(as.btcDelIndex.Len()))
 
 		case <-as.quit:
 			return
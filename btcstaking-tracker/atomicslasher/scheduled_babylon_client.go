@@ -0,0 +1,82 @@
+package atomicslasher
+
+import (
+	"context"
+
+	"cosmossdk.io/errors"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnscheduler"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkquerytypes "github.com/cosmos/cosmos-sdk/types/query"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+)
+
+// ScheduledBabylonClient sends the requests of a BabylonClient through the Babylon
+// request scheduler shared by the routines of the BTC staking tracker. Requests
+// made upon a selective slashing offence are of the highest priority, while
+// indexing all BTC delegations is not urgent.
+type ScheduledBabylonClient struct {
+	client    BabylonClient
+	scheduler *bbnscheduler.Scheduler
+	// requests not bound to a caller context wait for a slot until the tracker quits
+	quitCtx context.Context
+}
+
+var _ BabylonClient = (*ScheduledBabylonClient)(nil)
+
+func NewScheduledBabylonClient(quitCtx context.Context, client BabylonClient, scheduler *bbnscheduler.Scheduler) *ScheduledBabylonClient {
+	return &ScheduledBabylonClient{
+		client:    client,
+		scheduler: scheduler,
+		quitCtx:   quitCtx,
+	}
+}
+
+func (sc *ScheduledBabylonClient) FinalityProvider(fpBtcPkHex string) (*bstypes.QueryFinalityProviderResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointFinalityProvider, bbnscheduler.PriorityHigh,
+		func() (*bstypes.QueryFinalityProviderResponse, error) {
+			return sc.client.FinalityProvider(fpBtcPkHex)
+		})
+}
+
+func (sc *ScheduledBabylonClient) BTCDelegations(status bstypes.BTCDelegationStatus, pagination *sdkquerytypes.PageRequest) (*bstypes.QueryBTCDelegationsResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointBTCDelegations, bbnscheduler.PriorityNormal,
+		func() (*bstypes.QueryBTCDelegationsResponse, error) {
+			return sc.client.BTCDelegations(status, pagination)
+		})
+}
+
+func (sc *ScheduledBabylonClient) BTCDelegation(stakingTxHashHex string) (*bstypes.QueryBTCDelegationResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointBTCDelegation, bbnscheduler.PriorityHigh,
+		func() (*bstypes.QueryBTCDelegationResponse, error) {
+			return sc.client.BTCDelegation(stakingTxHashHex)
+		})
+}
+
+func (sc *ScheduledBabylonClient) BTCStakingParamsByVersion(version uint32) (*bstypes.QueryParamsByVersionResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointBTCStakingParams, bbnscheduler.PriorityHigh,
+		func() (*bstypes.QueryParamsByVersionResponse, error) {
+			return sc.client.BTCStakingParamsByVersion(version)
+		})
+}
+
+func (sc *ScheduledBabylonClient) ReliablySendMsg(ctx context.Context, msg sdk.Msg, expectedErrors []*errors.Error, unrecoverableErrors []*errors.Error) (*pv.RelayerTxResponse, error) {
+	return bbnscheduler.Call(ctx, sc.scheduler, bbnscheduler.EndpointSendMsg, bbnscheduler.PriorityHigh,
+		func() (*pv.RelayerTxResponse, error) {
+			return sc.client.ReliablySendMsg(ctx, msg, expectedErrors, unrecoverableErrors)
+		})
+}
+
+func (sc *ScheduledBabylonClient) MustGetAddr() string {
+	return sc.client.MustGetAddr()
+}
+
+// Subscribe is long-lived and not scheduled
+func (sc *ScheduledBabylonClient) Subscribe(subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	return sc.client.Subscribe(subscriber, query, outCapacity...)
+}
+
+func (sc *ScheduledBabylonClient) UnsubscribeAll(subscriber string) error {
+	return sc.client.UnsubscribeAll(subscriber)
+}
@@ -125,6 +125,14 @@ func (bdi *BTCDelegationIndex) Add(trackedDel *TrackedDelegation) {
 	bdi.unbondingSlashingTxMap[trackedDel.UnbondingSlashingTxHash] = trackedDel.StakingTxHash
 }
 
+// Len returns the number of tracked BTC delegations
+func (bdi *BTCDelegationIndex) Len() int {
+	bdi.Lock()
+	defer bdi.Unlock()
+
+	return len(bdi.delMap)
+}
+
 func (bdi *BTCDelegationIndex) Remove(stakingTxHash chainhash.Hash) {
 	bdi.Lock()
 	defer bdi.Unlock()
@@ -156,8 +164,8 @@ func (bdi *BTCDelegationIndex) FindSlashedBTCDelegation(txHash chainhash.Hash) (
 
 // parseSlashingTxWitness is a private helper function for extracting
 // the PK/signature pairs of covenant members and the index of the
-// slashed finality provider who signs the slashing tx
-// TODO: fuzz test
+// slashed finality provider who signs the slashing tx. The index is the
+// position of the finality provider among the sorted restaked ones.
 func parseSlashingTxWitness(
 	witnessStack wire.TxWitness,
 	covPKs []bbn.BIP340PubKey,
@@ -166,6 +174,14 @@ func parseSlashingTxWitness(
 	// sort covenant PKs and finality provider PKs as per the tx script structure
 	orderedCovPKs := bbn.SortBIP340PKs(covPKs)
 	orderedfpPKs := bbn.SortBIP340PKs(fpPKs)
+	if len(witnessStack) < len(orderedCovPKs)+len(orderedfpPKs) {
+		return nil, 0, nil, fmt.Errorf(
+			"the witness has %d elements, fewer than the %d covenant members and %d finality providers",
+			len(witnessStack),
+			len(orderedCovPKs),
+			len(orderedfpPKs),
+		)
+	}
 
 	// decode covenant signatures
 	covSigMap := make(map[string]*bbn.BIP340Signature)
@@ -179,25 +195,22 @@ func parseSlashingTxWitness(
 		if err != nil {
 			return nil, 0, nil, err
 		}
-		covSigMap[covPKs[i].MarshalHex()] = sig
+		covSigMap[orderedCovPKs[i].MarshalHex()] = sig
 	}
 
-	// decode finality provider signatures
-	var fpIdx int
-	var fpPK *bbn.BIP340PubKey
+	// find the finality provider who signs
 	fpWitnessStack := witnessStack[len(orderedCovPKs) : len(orderedCovPKs)+len(orderedfpPKs)]
 	for i := range fpWitnessStack {
 		if len(fpWitnessStack[i]) != 0 {
-			fpIdx = i
-			fpPK = &fpPKs[i]
-			break
+			return covSigMap, i, &orderedfpPKs[i], nil
 		}
 	}
 
-	return covSigMap, fpIdx, fpPK, nil
+	return nil, 0, nil, fmt.Errorf("no finality provider signs the slashing tx")
 }
 
-// TODO: fuzz test
+// tryExtractFPSK extracts the SK of the finality provider at the given index
+// from the covenant adaptor signatures and the covenant Schnorr signatures
 func tryExtractFPSK(
 	covSigMap map[string]*bbn.BIP340Signature,
 	fpIdx int,
@@ -218,6 +231,15 @@ func tryExtractFPSK(
 		if err != nil {
 			return nil, err
 		}
+		// the adaptor signatures are ordered as the sorted restaked finality providers
+		if fpIdx >= len(covASigList.AdaptorSigs) {
+			return nil, fmt.Errorf(
+				"covenant member %s has %d adaptor signatures, but the finality provider is at index %d",
+				covASigList.CovPk.MarshalHex(),
+				len(covASigList.AdaptorSigs),
+				fpIdx,
+			)
+		}
 		covAdaptorSigBytes := covASigList.AdaptorSigs[fpIdx]
 		covAdaptorSig, err := asig.NewAdaptorSignatureFromBytes(covAdaptorSigBytes)
 		if err != nil {
@@ -0,0 +1,97 @@
+package atomicslasher
+
+import (
+	"math/rand"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bbn "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/btcslasher"
+	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
+)
+
+func FuzzExtractFPSKFromSlashingTx(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		net := &chaincfg.SimNetParams
+
+		// covenant committee
+		numCovenants := r.Intn(5) + 1
+		covenantSks := make([]*btcec.PrivateKey, 0, numCovenants)
+		covenantBtcPks := make([]*btcec.PublicKey, 0, numCovenants)
+		for i := 0; i < numCovenants; i++ {
+			covenantSk, covenantPk, err := datagen.GenRandomBTCKeyPair(r)
+			require.NoError(t, err)
+			covenantSks = append(covenantSks, covenantSk)
+			covenantBtcPks = append(covenantBtcPks, covenantPk)
+		}
+		bsParams := &bstypes.Params{
+			CovenantQuorum: 1,
+			CovenantPks:    vdatagen.NewBIP340PKsFromBTCPKs(covenantBtcPks),
+			SlashingRate:   sdkmath.LegacyMustNewDecFromStr("0.1"),
+		}
+		slashingAddr, err := datagen.GenRandomBTCAddress(r, net)
+		require.NoError(t, err)
+
+		// a BTC delegation restaked to several finality providers, one of which
+		// signs the slashing txs
+		fpSKs, fpPKs, err := vdatagen.GenRandomFPKeyPairs(r, r.Intn(3)+3)
+		require.NoError(t, err)
+		slashedIdx := r.Intn(len(fpSKs))
+		slashedFPSK := fpSKs[slashedIdx]
+		delSK, _, err := datagen.GenRandomBTCKeyPair(r)
+		require.NoError(t, err)
+		btcDel := vdatagen.GenRandomUnbondedBTCDelegation(
+			r,
+			t,
+			net,
+			fpPKs,
+			delSK,
+			covenantSks,
+			covenantBtcPks,
+			bsParams.CovenantQuorum,
+			slashingAddr.String(),
+			bsParams.SlashingRate,
+			1000,
+		)
+		// Babylon lists the slashed finality provider neither first nor at its sorted position
+		btcDel.FpBtcPkList = vdatagen.GenUnsortedFPPKList(r, fpPKs, slashedIdx)
+		btcDelResp := bstypes.NewBTCDelegationResponse(btcDel, bstypes.BTCDelegationStatus_UNBONDED)
+
+		slashingTx, err := btcslasher.BuildSlashingTxWithWitness(btcDelResp, bsParams, net, slashedFPSK)
+		require.NoError(t, err)
+		unbondingSlashingTx, err := btcslasher.BuildUnbondingSlashingTxWithWitness(btcDelResp, bsParams, net, slashedFPSK)
+		require.NoError(t, err)
+
+		for _, tc := range []struct {
+			witness     [][]byte
+			covASigList []*bstypes.CovenantAdaptorSignatures
+		}{
+			{slashingTx.TxIn[0].Witness, btcDelResp.CovenantSigs},
+			{unbondingSlashingTx.TxIn[0].Witness, btcDelResp.UndelegationResponse.CovenantSlashingSigs},
+		} {
+			covSigMap, fpIdx, fpPK, err := parseSlashingTxWitness(tc.witness, bsParams.CovenantPks, btcDelResp.FpBtcPkList)
+			require.NoError(t, err)
+			require.Equal(t, slashedIdx, fpIdx)
+			require.True(t, fpPK.Equals(bbn.NewBIP340PubKeyFromBTCPK(slashedFPSK.PubKey())))
+			require.NotEmpty(t, covSigMap)
+
+			fpSK, err := tryExtractFPSK(covSigMap, fpIdx, fpPK, tc.covASigList)
+			require.NoError(t, err)
+			require.Equal(t, slashedFPSK.Serialize(), fpSK.Serialize())
+		}
+
+		// a witness without any finality provider signature is rejected
+		witness := append([][]byte{}, slashingTx.TxIn[0].Witness...)
+		witness[numCovenants+slashedIdx] = []byte{}
+		_, _, _, err = parseSlashingTxWitness(witness, bsParams.CovenantPks, btcDelResp.FpBtcPkList)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,199 @@
+package bbnevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"go.uber.org/zap"
+)
+
+const (
+	delegationStateUpdateEventName = "babylon.btcstaking.v1.EventBTCDelegationStateUpdate"
+	stakingTxHashAttr              = delegationStateUpdateEventName + ".staking_tx_hash"
+	newStateAttr                   = delegationStateUpdateEventName + ".new_state"
+
+	// capacity of the channel of events of a subscription
+	eventsCapacity = 1000
+
+	// bounds of the backoff between attempts to renew a closed subscription
+	minResubscribeBackoff = time.Second
+	maxResubscribeBackoff = time.Minute
+)
+
+// activatedDelegationsQuery matches txs changing the state of BTC delegations.
+// Babylon emits the state update to ACTIVE once a BTC delegation has a quorum of
+// covenant signatures.
+var activatedDelegationsQuery = fmt.Sprintf("tm.event = 'Tx' AND %s EXISTS", newStateAttr)
+
+// Subscriber subscribes to events of the Babylon node
+type Subscriber interface {
+	Subscribe(subscriber, query string, outCapacity ...int) (out <-chan coretypes.ResultEvent, err error)
+	UnsubscribeAll(subscriber string) error
+}
+
+// ParseActivatedDelegations returns the staking tx hashes of the BTC delegations
+// that became active in the tx of the event
+func ParseActivatedDelegations(resultEvent *coretypes.ResultEvent) ([]chainhash.Hash, error) {
+	stakingTxHashes := resultEvent.Events[stakingTxHashAttr]
+	newStates := resultEvent.Events[newStateAttr]
+	if len(stakingTxHashes) != len(newStates) {
+		return nil, fmt.Errorf("%d staking tx hashes and %d states in state update events", len(stakingTxHashes), len(newStates))
+	}
+
+	var activated []chainhash.Hash
+	for i := range newStates {
+		// attributes of typed events are JSON encoded
+		var newState, stakingTxHashHex string
+		if err := json.Unmarshal([]byte(newStates[i]), &newState); err != nil {
+			return nil, fmt.Errorf("invalid state %s: %w", newStates[i], err)
+		}
+		if newState != bstypes.BTCDelegationStatus_ACTIVE.String() {
+			continue
+		}
+		if err := json.Unmarshal([]byte(stakingTxHashes[i]), &stakingTxHashHex); err != nil {
+			return nil, fmt.Errorf("invalid staking tx hash %s: %w", stakingTxHashes[i], err)
+		}
+		stakingTxHash, err := chainhash.NewHashFromStr(stakingTxHashHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid staking tx hash %s: %w", stakingTxHashHex, err)
+		}
+		activated = append(activated, *stakingTxHash)
+	}
+	return activated, nil
+}
+
+// ActivatedDelegations is a subscription to the BTC delegations becoming active
+// on Babylon. The subscription is renewed with backoff whenever the Babylon node
+// closes it, e.g., as the websocket connection is lost.
+type ActivatedDelegations struct {
+	client     Subscriber
+	subscriber string
+	logger     *zap.SugaredLogger
+
+	activatedChan    chan chainhash.Hash
+	resubscribedChan chan struct{}
+	// backoff before the next attempt to renew the subscription
+	backoff time.Duration
+}
+
+// SubscribeActivatedDelegations subscribes to the BTC delegations becoming active
+// on Babylon until the context is done. Events are lost while the subscription is
+// down, so subscribers need to rescan the BTC delegations upon Resubscribed, and
+// from time to time. A failed subscription is retried in the background.
+func SubscribeActivatedDelegations(
+	ctx context.Context,
+	client Subscriber,
+	subscriber string,
+	logger *zap.SugaredLogger,
+) *ActivatedDelegations {
+	s := &ActivatedDelegations{
+		client:           client,
+		subscriber:       subscriber,
+		logger:           logger,
+		activatedChan:    make(chan chainhash.Hash, eventsCapacity),
+		resubscribedChan: make(chan struct{}, 1),
+		backoff:          minResubscribeBackoff,
+	}
+	events, err := s.subscribe()
+	if err != nil {
+		logger.Errorf("failed to subscribe %s, relying on rescans until resubscribed: %v", subscriber, err)
+	}
+	go s.run(ctx, events)
+	return s
+}
+
+// Activated returns the channel of the staking tx hashes of BTC delegations as
+// they become active
+func (s *ActivatedDelegations) Activated() <-chan chainhash.Hash {
+	return s.activatedChan
+}
+
+// Resubscribed returns the channel signalled once the subscription is renewed,
+// after which the BTC delegations activated meanwhile need to be rescanned
+func (s *ActivatedDelegations) Resubscribed() <-chan struct{} {
+	return s.resubscribedChan
+}
+
+func (s *ActivatedDelegations) subscribe() (<-chan coretypes.ResultEvent, error) {
+	events, err := s.client.Subscribe(s.subscriber, activatedDelegationsQuery, eventsCapacity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", activatedDelegationsQuery, err)
+	}
+	return events, nil
+}
+
+// resubscribe renews the subscription, with an exponential backoff between the
+// attempts. It returns nil once the context is done.
+func (s *ActivatedDelegations) resubscribe(ctx context.Context) <-chan coretypes.ResultEvent {
+	for {
+		select {
+		case <-time.After(s.backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		s.backoff = min(2*s.backoff, maxResubscribeBackoff)
+
+		// the Babylon node may still consider the closed subscription active
+		if err := s.client.UnsubscribeAll(s.subscriber); err != nil {
+			s.logger.Debugf("failed to unsubscribe %s before resubscribing: %v", s.subscriber, err)
+		}
+		events, err := s.subscribe()
+		if err != nil {
+			s.logger.Warnf("failed to resubscribe %s, retrying in %v: %v", s.subscriber, s.backoff, err)
+			continue
+		}
+		s.logger.Infof("resubscribed %s", s.subscriber)
+		select {
+		case s.resubscribedChan <- struct{}{}:
+		default:
+			// a rescan is already pending
+		}
+		return events
+	}
+}
+
+func (s *ActivatedDelegations) run(ctx context.Context, events <-chan coretypes.ResultEvent) {
+	defer func() {
+		if err := s.client.UnsubscribeAll(s.subscriber); err != nil {
+			s.logger.Errorf("failed to unsubscribe %s: %v", s.subscriber, err)
+		}
+	}()
+
+	for {
+		if events == nil {
+			if events = s.resubscribe(ctx); events == nil {
+				return
+			}
+		}
+
+		select {
+		case resultEvent, ok := <-events:
+			if !ok {
+				s.logger.Warnf("subscription %s is closed, resubscribing in %v", s.subscriber, s.backoff)
+				events = nil
+				continue
+			}
+			// the subscription is healthy again once it delivers events
+			s.backoff = minResubscribeBackoff
+			activated, err := ParseActivatedDelegations(&resultEvent)
+			if err != nil {
+				s.logger.Errorf("failed to parse BTC delegation state update events: %v", err)
+				continue
+			}
+			for _, stakingTxHash := range activated {
+				select {
+				case s.activatedChan <- stakingTxHash:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package bbnevents_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnevents"
+)
+
+func FuzzParseActivatedDelegations(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+
+		// a tx with state updates of several delegations, only some of them to active
+		var (
+			stakingTxHashes []string
+			newStates       []string
+			expected        []chainhash.Hash
+		)
+		states := []bstypes.BTCDelegationStatus{
+			bstypes.BTCDelegationStatus_ACTIVE,
+			bstypes.BTCDelegationStatus_UNBONDED,
+		}
+		numEvents := r.Intn(10)
+		for i := 0; i < numEvents; i++ {
+			stakingTxHash := chainhash.Hash(datagen.GenRandomByteArray(r, 32))
+			state := states[r.Intn(len(states))]
+			stakingTxHashes = append(stakingTxHashes, fmt.Sprintf("%q", stakingTxHash.String()))
+			newStates = append(newStates, fmt.Sprintf("%q", state.String()))
+			if state == bstypes.BTCDelegationStatus_ACTIVE {
+				expected = append(expected, stakingTxHash)
+			}
+		}
+		resultEvent := &coretypes.ResultEvent{Events: map[string][]string{
+			"babylon.btcstaking.v1.EventBTCDelegationStateUpdate.staking_tx_hash": stakingTxHashes,
+			"babylon.btcstaking.v1.EventBTCDelegationStateUpdate.new_state":       newStates,
+			"tm.event": {"Tx"},
+		}}
+
+		activated, err := bbnevents.ParseActivatedDelegations(resultEvent)
+		require.NoError(t, err)
+		require.Equal(t, expected, activated)
+
+		// attributes of different events do not line up
+		resultEvent.Events["babylon.btcstaking.v1.EventBTCDelegationStateUpdate.new_state"] = append(newStates, newStates...)
+		if numEvents > 0 {
+			_, err = bbnevents.ParseActivatedDelegations(resultEvent)
+			require.Error(t, err)
+		}
+	})
+}
+
+// testSubscriber hands out the given subscriptions in order. A nil subscription,
+// or running out of subscriptions, fails the subscription as if the websocket
+// connection was down.
+type testSubscriber struct {
+	subscriptions   chan chan coretypes.ResultEvent
+	numUnsubscribes atomic.Int32
+}
+
+func newTestSubscriber(subscriptions ...chan coretypes.ResultEvent) *testSubscriber {
+	s := &testSubscriber{subscriptions: make(chan chan coretypes.ResultEvent, len(subscriptions))}
+	for _, events := range subscriptions {
+		s.subscriptions <- events
+	}
+	return s
+}
+
+func (s *testSubscriber) Subscribe(_, _ string, _ ...int) (<-chan coretypes.ResultEvent, error) {
+	select {
+	case events := <-s.subscriptions:
+		if events != nil {
+			return events, nil
+		}
+	default:
+	}
+	return nil, errors.New("websocket connection is down")
+}
+
+func (s *testSubscriber) UnsubscribeAll(_ string) error {
+	s.numUnsubscribes.Add(1)
+	return nil
+}
+
+func genActivatedEvent(r *rand.Rand) (coretypes.ResultEvent, chainhash.Hash) {
+	stakingTxHash := chainhash.Hash(datagen.GenRandomByteArray(r, 32))
+	return coretypes.ResultEvent{Events: map[string][]string{
+		"babylon.btcstaking.v1.EventBTCDelegationStateUpdate.staking_tx_hash": {fmt.Sprintf("%q", stakingTxHash.String())},
+		"babylon.btcstaking.v1.EventBTCDelegationStateUpdate.new_state":       {fmt.Sprintf("%q", bstypes.BTCDelegationStatus_ACTIVE.String())},
+	}}, stakingTxHash
+}
+
+func requireActivated(t *testing.T, activated *bbnevents.ActivatedDelegations, expected chainhash.Hash) {
+	select {
+	case stakingTxHash := <-activated.Activated():
+		require.Equal(t, expected, stakingTxHash)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the activated delegation")
+	}
+}
+
+func requireResubscribed(t *testing.T, activated *bbnevents.ActivatedDelegations) {
+	select {
+	case <-activated.Resubscribed():
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the resubscription")
+	}
+}
+
+func TestActivatedDelegationsResubscribe(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events1, events2 := make(chan coretypes.ResultEvent, 1), make(chan coretypes.ResultEvent, 1)
+	subscriber := newTestSubscriber(events1, events2)
+	activated := bbnevents.SubscribeActivatedDelegations(ctx, subscriber, "test", zap.NewNop().Sugar())
+
+	resultEvent, stakingTxHash := genActivatedEvent(r)
+	events1 <- resultEvent
+	requireActivated(t, activated, stakingTxHash)
+	require.Empty(t, activated.Resubscribed())
+
+	// the subscription is renewed once it is closed, and the subscriber is
+	// signalled to rescan the delegations activated meanwhile
+	close(events1)
+	requireResubscribed(t, activated)
+	resultEvent, stakingTxHash = genActivatedEvent(r)
+	events2 <- resultEvent
+	requireActivated(t, activated, stakingTxHash)
+
+	// the subscription is cancelled with the context
+	numUnsubscribes := subscriber.numUnsubscribes.Load()
+	cancel()
+	require.Eventually(t, func() bool {
+		return subscriber.numUnsubscribes.Load() == numUnsubscribes+1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestActivatedDelegationsRetryFailedSubscription(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the failed subscription is retried in the background
+	events := make(chan coretypes.ResultEvent, 1)
+	activated := bbnevents.SubscribeActivatedDelegations(ctx, newTestSubscriber(nil, events), "test", zap.NewNop().Sugar())
+	requireResubscribed(t, activated)
+	resultEvent, stakingTxHash := genActivatedEvent(r)
+	events <- resultEvent
+	requireActivated(t, activated, stakingTxHash)
+}
@@ -0,0 +1,284 @@
+package bbnscheduler
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// Endpoints of the Babylon node queried by the BTC staking tracker. They are the
+// keys of the endpoint-concurrency config.
+const (
+	EndpointBTCDelegation               = "btc_delegation"
+	EndpointBTCDelegations              = "btc_delegations"
+	EndpointFinalityProvider            = "finality_provider"
+	EndpointFinalityProviderDelegations = "finality_provider_delegations"
+	EndpointListEvidences               = "list_evidences"
+	EndpointBTCStakingParams            = "btc_staking_params"
+	EndpointBTCCheckpointParams         = "btc_checkpoint_params"
+	EndpointBTCHeaderChainTip           = "btc_header_chain_tip"
+	EndpointSendMsg                     = "send_msg"
+)
+
+// Priority orders the requests waiting for a free slot. Requests of a higher
+// priority are always served first, requests of the same priority in FIFO order.
+type Priority int
+
+const (
+	// PriorityLow is for periodic bookkeeping, e.g. polling delegations
+	PriorityLow Priority = iota
+	// PriorityNormal is for reporting events to Babylon, e.g. unbonding
+	PriorityNormal
+	// PriorityHigh is for requests which slashing depends on
+	PriorityHigh
+
+	numPriorities
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrStopped = errors.New("babylon request scheduler is stopped")
+
+type request struct {
+	endpoint string
+	priority Priority
+	// closed once the request is granted a slot or failed
+	ready chan struct{}
+	// set when the request failed, before ready is closed
+	err     error
+	granted bool
+	// position in the queue of the endpoint, nil once out of the queue
+	elem *list.Element
+}
+
+type endpointState struct {
+	limit    int
+	inFlight int
+	queues   [numPriorities]*list.List
+}
+
+// Scheduler bounds the requests of the BTC staking tracker to the Babylon node.
+// A request waits for a free slot, both of the endpoint and across endpoints, and
+// then for its turn under the rate limit before being sent.
+type Scheduler struct {
+	cfg     *config.BabylonSchedulerConfig
+	logger  *zap.SugaredLogger
+	metrics *metrics.BabylonSchedulerMetrics
+	limiter *rate.Limiter
+
+	mu        sync.Mutex
+	inFlight  int
+	endpoints map[string]*endpointState
+	stopped   bool
+	quit      chan struct{}
+}
+
+func New(
+	cfg *config.BabylonSchedulerConfig,
+	parentLogger *zap.Logger,
+	metrics *metrics.BabylonSchedulerMetrics,
+) *Scheduler {
+	limit := rate.Inf
+	if cfg.RequestsPerSecond > 0 {
+		limit = rate.Limit(cfg.RequestsPerSecond)
+	}
+	return &Scheduler{
+		cfg:       cfg,
+		logger:    parentLogger.With(zap.String("module", "babylon_scheduler")).Sugar(),
+		metrics:   metrics,
+		limiter:   rate.NewLimiter(limit, cfg.Burst),
+		endpoints: make(map[string]*endpointState),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Do runs fn, which sends a single request to the endpoint, once the request is
+// granted a slot. It returns ErrStopped if the scheduler is stopped, and the
+// context error if the context is done, before fn is run.
+func (s *Scheduler) Do(ctx context.Context, endpoint string, priority Priority, fn func() error) error {
+	start := time.Now()
+	req, err := s.enqueue(endpoint, priority)
+	if err != nil {
+		return err
+	}
+	if err := s.wait(ctx, req); err != nil {
+		s.metrics.RequestsCounterVec.WithLabelValues(endpoint, "cancelled").Inc()
+		return err
+	}
+	defer s.release(endpoint)
+	s.metrics.QueueWaitTimeHistogramVec.WithLabelValues(priority.String()).Observe(time.Since(start).Seconds())
+
+	if err := fn(); err != nil {
+		s.metrics.RequestsCounterVec.WithLabelValues(endpoint, "failure").Inc()
+		return err
+	}
+	s.metrics.RequestsCounterVec.WithLabelValues(endpoint, "success").Inc()
+	return nil
+}
+
+// Call is Do for requests returning a response
+func Call[T any](ctx context.Context, s *Scheduler, endpoint string, priority Priority, fn func() (T, error)) (T, error) {
+	var resp T
+	err := s.Do(ctx, endpoint, priority, func() error {
+		var err error
+		resp, err = fn()
+		return err
+	})
+	return resp, err
+}
+
+func (s *Scheduler) enqueue(endpoint string, priority Priority) (*request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, ErrStopped
+	}
+	es, ok := s.endpoints[endpoint]
+	if !ok {
+		es = &endpointState{limit: s.cfg.EndpointLimit(endpoint)}
+		for i := range es.queues {
+			es.queues[i] = list.New()
+		}
+		s.endpoints[endpoint] = es
+	}
+
+	req := &request{endpoint: endpoint, priority: priority, ready: make(chan struct{})}
+	req.elem = es.queues[priority].PushBack(req)
+	s.metrics.QueuedRequestsGaugeVec.WithLabelValues(endpoint, priority.String()).Inc()
+	s.dispatchLocked()
+
+	return req, nil
+}
+
+// wait waits until the request is granted a slot and is within the rate limit.
+// If it returns an error, the request holds no slot.
+func (s *Scheduler) wait(ctx context.Context, req *request) error {
+	select {
+	case <-req.ready:
+		if req.err != nil {
+			return req.err
+		}
+	case <-ctx.Done():
+		s.mu.Lock()
+		granted := req.granted
+		s.dequeueLocked(req)
+		s.mu.Unlock()
+		if granted {
+			s.release(req.endpoint)
+		}
+		return ctx.Err()
+	}
+
+	// slots are granted by priority, so the rate limit is applied in priority order as well
+	reservation := s.limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		s.release(req.endpoint)
+		return ctx.Err()
+	case <-s.quit:
+		reservation.Cancel()
+		s.release(req.endpoint)
+		return ErrStopped
+	}
+}
+
+// dequeueLocked removes the request from the queue of its endpoint, if it is still queued
+func (s *Scheduler) dequeueLocked(req *request) {
+	if req.elem == nil {
+		return
+	}
+	s.endpoints[req.endpoint].queues[req.priority].Remove(req.elem)
+	req.elem = nil
+	s.metrics.QueuedRequestsGaugeVec.WithLabelValues(req.endpoint, req.priority.String()).Dec()
+}
+
+func (s *Scheduler) release(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.endpoints[endpoint].inFlight--
+	s.inFlight--
+	s.metrics.InFlightRequestsGaugeVec.WithLabelValues(endpoint).Dec()
+	s.dispatchLocked()
+}
+
+// dispatchLocked grants free slots to the queued requests, in priority order.
+// A request of an endpoint with no free slot does not block requests of other
+// endpoints.
+func (s *Scheduler) dispatchLocked() {
+	if s.stopped {
+		return
+	}
+	for p := numPriorities - 1; p >= 0; p-- {
+		for endpoint, es := range s.endpoints {
+			queue := es.queues[p]
+			for queue.Len() > 0 && es.inFlight < es.limit {
+				if s.inFlight >= s.cfg.MaxConcurrentRequests {
+					return
+				}
+				req := queue.Front().Value.(*request)
+				s.dequeueLocked(req)
+				req.granted = true
+				es.inFlight++
+				s.inFlight++
+				s.metrics.InFlightRequestsGaugeVec.WithLabelValues(endpoint).Inc()
+				close(req.ready)
+			}
+		}
+	}
+}
+
+// Stop fails all queued and future requests with ErrStopped. Requests in flight
+// are not interrupted.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.quit)
+
+	failed := 0
+	for _, es := range s.endpoints {
+		for _, queue := range es.queues {
+			for queue.Len() > 0 {
+				req := queue.Front().Value.(*request)
+				s.dequeueLocked(req)
+				req.err = ErrStopped
+				close(req.ready)
+				failed++
+			}
+		}
+	}
+	s.logger.Infof("babylon request scheduler stopped, failed %d queued requests", failed)
+}
@@ -0,0 +1,153 @@
+package bbnscheduler_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnscheduler"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+)
+
+func FuzzSchedulerLimits(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+
+		numEndpoints := r.Intn(4) + 1
+		cfg := config.DefaultBabylonSchedulerConfig()
+		cfg.RequestsPerSecond = 0
+		cfg.MaxConcurrentRequests = r.Intn(8) + 1
+		cfg.MaxConcurrentRequestsPerEndpoint = r.Intn(4) + 1
+		cfg.EndpointConcurrency = map[string]int{"endpoint-0": r.Intn(2) + 1}
+		scheduler := bbnscheduler.New(&cfg, zap.NewNop(), metrics.NewBTCStakingTrackerMetrics().BabylonSchedulerMetrics)
+		defer scheduler.Stop()
+
+		var (
+			inFlight    atomic.Int32
+			maxInFlight atomic.Int32
+			endpoints   = make([]struct{ inFlight, maxInFlight atomic.Int32 }, numEndpoints)
+			wg          sync.WaitGroup
+		)
+		recordMax := func(counter, maxCounter *atomic.Int32) {
+			n := counter.Add(1)
+			for {
+				m := maxCounter.Load()
+				if n <= m || maxCounter.CompareAndSwap(m, n) {
+					return
+				}
+			}
+		}
+
+		numRequests := r.Intn(50) + 10
+		for i := 0; i < numRequests; i++ {
+			idx := r.Intn(numEndpoints)
+			priority := bbnscheduler.Priority(r.Intn(3))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := bbnscheduler.Call(context.Background(), scheduler, fmt.Sprintf("endpoint-%d", idx), priority, func() (int, error) {
+					recordMax(&inFlight, &maxInFlight)
+					recordMax(&endpoints[idx].inFlight, &endpoints[idx].maxInFlight)
+					time.Sleep(time.Millisecond)
+					endpoints[idx].inFlight.Add(-1)
+					inFlight.Add(-1)
+					return idx, nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, idx, resp)
+			}()
+		}
+		wg.Wait()
+
+		require.LessOrEqual(t, int(maxInFlight.Load()), cfg.MaxConcurrentRequests)
+		for idx := range endpoints {
+			limit := cfg.EndpointLimit(fmt.Sprintf("endpoint-%d", idx))
+			require.LessOrEqual(t, int(endpoints[idx].maxInFlight.Load()), limit)
+		}
+	})
+}
+
+func TestSchedulerPriorityAndStop(t *testing.T) {
+	cfg := config.DefaultBabylonSchedulerConfig()
+	cfg.RequestsPerSecond = 0
+	cfg.MaxConcurrentRequests = 1
+	schedulerMetrics := metrics.NewBTCStakingTrackerMetrics().BabylonSchedulerMetrics
+	scheduler := bbnscheduler.New(&cfg, zap.NewNop(), schedulerMetrics)
+	endpoint := bbnscheduler.EndpointBTCDelegation
+
+	// occupy the only slot
+	blocked := make(chan struct{})
+	unblock := make(chan struct{})
+	go func() {
+		_ = scheduler.Do(context.Background(), endpoint, bbnscheduler.PriorityLow, func() error {
+			close(blocked)
+			<-unblock
+			return nil
+		})
+	}()
+	<-blocked
+
+	queued := func(priority bbnscheduler.Priority) float64 {
+		return testutil.ToFloat64(schedulerMetrics.QueuedRequestsGaugeVec.WithLabelValues(endpoint, priority.String()))
+	}
+
+	// a low priority request queued first is served after a high priority one
+	order := make(chan bbnscheduler.Priority, 2)
+	var wg sync.WaitGroup
+	for _, priority := range []bbnscheduler.Priority{bbnscheduler.PriorityLow, bbnscheduler.PriorityHigh} {
+		priority := priority
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, scheduler.Do(context.Background(), endpoint, priority, func() error {
+				order <- priority
+				return nil
+			}))
+		}()
+		require.Eventually(t, func() bool { return queued(priority) == 1 }, time.Second, time.Millisecond)
+	}
+
+	// a cancelled request leaves the queue
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := scheduler.Do(ctx, endpoint, bbnscheduler.PriorityHigh, func() error { return nil })
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, float64(1), queued(bbnscheduler.PriorityHigh))
+
+	close(unblock)
+	wg.Wait()
+	require.Equal(t, bbnscheduler.PriorityHigh, <-order)
+	require.Equal(t, bbnscheduler.PriorityLow, <-order)
+
+	// stopping fails queued and new requests
+	blocked = make(chan struct{})
+	unblock = make(chan struct{})
+	go func() {
+		_ = scheduler.Do(context.Background(), endpoint, bbnscheduler.PriorityLow, func() error {
+			close(blocked)
+			<-unblock
+			return nil
+		})
+	}()
+	<-blocked
+	errChan := make(chan error)
+	go func() {
+		errChan <- scheduler.Do(context.Background(), endpoint, bbnscheduler.PriorityLow, func() error { return nil })
+	}()
+	require.Eventually(t, func() bool { return queued(bbnscheduler.PriorityLow) == 1 }, time.Second, time.Millisecond)
+	scheduler.Stop()
+	require.ErrorIs(t, <-errChan, bbnscheduler.ErrStopped)
+	require.ErrorIs(t, scheduler.Do(context.Background(), endpoint, bbnscheduler.PriorityHigh, func() error { return nil }), bbnscheduler.ErrStopped)
+	close(unblock)
+}
@@ -0,0 +1,310 @@
+package blockstream
+
+import (
+	"sync"
+
+	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"go.uber.org/zap"
+)
+
+// maxReorgDepth is the number of most recent blocks remembered to find the fork
+// point of a reorg, and thus the blocks to disconnect
+const maxReorgDepth = 100
+
+// BlockEvent is a block connected to or disconnected from the best chain
+type BlockEvent struct {
+	EventType types.EventType
+	Height    int32
+	Hash      chainhash.Hash
+	// the full block including the witnesses of its txs, only set for connected blocks
+	Block *wire.MsgBlock
+}
+
+type blockRef struct {
+	height int32
+	hash   chainhash.Hash
+}
+
+// BlockStream registers for BTC blocks once, fetches each block once, and fans
+// out connected and disconnected blocks to all subscribers in order. A subscriber
+// that falls behind by more than its buffer holds up the others until it catches up.
+type BlockStream struct {
+	startOnce sync.Once
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+	quit      chan struct{}
+
+	logger      *zap.SugaredLogger
+	btcNotifier notifier.ChainNotifier
+	btcClient   btcclient.BTCClient
+	bufferSize  uint32
+	metrics     *metrics.BlockStreamMetrics
+
+	mu            sync.Mutex
+	stopped       bool
+	subscriptions map[*Subscription]struct{}
+	// the best block, sent to new subscribers
+	tip *BlockEvent
+	// connected blocks of the best chain, oldest first
+	chain []blockRef
+}
+
+func New(
+	btcNotifier notifier.ChainNotifier,
+	btcClient btcclient.BTCClient,
+	bufferSize uint32,
+	parentLogger *zap.Logger,
+	metrics *metrics.BlockStreamMetrics,
+) *BlockStream {
+	return &BlockStream{
+		quit:          make(chan struct{}),
+		logger:        parentLogger.With(zap.String("module", "block_stream")).Sugar(),
+		btcNotifier:   btcNotifier,
+		btcClient:     btcClient,
+		bufferSize:    bufferSize,
+		metrics:       metrics,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+func (bs *BlockStream) Start() error {
+	var startErr error
+	bs.startOnce.Do(func() {
+		bs.logger.Info("starting block stream")
+
+		// registering with nil sends the best block first
+		blockNotifier, err := bs.btcNotifier.RegisterBlockEpochNtfn(nil)
+		if err != nil {
+			startErr = err
+			return
+		}
+
+		bs.wg.Add(1)
+		go bs.streamBlocks(blockNotifier)
+
+		bs.logger.Info("block stream started")
+	})
+	return startErr
+}
+
+// Stop stops the stream and closes the channels of all subscriptions
+func (bs *BlockStream) Stop() error {
+	var stopErr error
+	bs.stopOnce.Do(func() {
+		bs.logger.Info("stopping block stream")
+		close(bs.quit)
+		bs.wg.Wait()
+
+		bs.mu.Lock()
+		bs.stopped = true
+		for sub := range bs.subscriptions {
+			close(sub.events)
+		}
+		bs.subscriptions = nil
+		bs.mu.Unlock()
+
+		bs.logger.Info("stopped block stream")
+	})
+	return stopErr
+}
+
+// Subscribe returns a subscription to the blocks of the stream. The first event
+// is the best block, which is sent as soon as it is known.
+func (bs *BlockStream) Subscribe(name string) *Subscription {
+	sub := &Subscription{
+		name:   name,
+		events: make(chan *BlockEvent, bs.bufferSize),
+		cancel: make(chan struct{}),
+		stream: bs,
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.stopped {
+		close(sub.events)
+		return sub
+	}
+	// the buffer of a new subscription has room for the best block
+	if bs.tip != nil {
+		sub.events <- bs.tip
+	}
+	bs.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+func (bs *BlockStream) unsubscribe(sub *Subscription) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	delete(bs.subscriptions, sub)
+}
+
+func (bs *BlockStream) streamBlocks(blockNotifier *notifier.BlockEpochEvent) {
+	defer bs.wg.Done()
+	defer blockNotifier.Cancel()
+
+	for {
+		select {
+		case blockEpoch, ok := <-blockNotifier.Epochs:
+			if !ok {
+				bs.logger.Warn("block notifier is closed")
+				return
+			}
+			bs.logger.Debugf("Received new best btc block: %d", blockEpoch.Height)
+			bs.handleNewBestBlock(blockEpoch.Hash)
+		case <-bs.quit:
+			return
+		}
+	}
+}
+
+// handleNewBestBlock fetches the new best block, and any ancestors of it that are
+// not connected yet, and sends the blocks the new best chain disconnects and connects
+func (bs *BlockStream) handleNewBestBlock(blockHash *chainhash.Hash) {
+	if bs.indexOf(*blockHash) >= 0 {
+		return
+	}
+
+	// blocks of the new best chain to connect, newest first
+	var connected []*BlockEvent
+	nextHash := *blockHash
+	forkIdx := -1
+	for {
+		event, err := bs.fetchBlock(&nextHash)
+		if err != nil {
+			// the block will be fetched again as an ancestor of the next best block
+			bs.logger.Errorf("failed to get block %s: %v", nextHash, err)
+			return
+		}
+		connected = append(connected, event)
+
+		prevHash := event.Block.Header.PrevBlock
+		if forkIdx = bs.indexOf(prevHash); forkIdx >= 0 {
+			break
+		}
+		if len(bs.chain) == 0 || event.Height <= bs.chain[0].height || len(connected) >= maxReorgDepth {
+			// either the first block, or the fork point is older than the blocks
+			// remembered, in which case the blocks at replaced heights are disconnected
+			if len(bs.chain) > 0 {
+				bs.logger.Warnf("could not find the fork point of block %s among the last %d blocks", blockHash, len(bs.chain))
+			}
+			break
+		}
+		nextHash = prevHash
+	}
+
+	// blocks of the old best chain to disconnect, newest first
+	var disconnected []*BlockEvent
+	keep := forkIdx + 1
+	if forkIdx < 0 {
+		keep = len(bs.chain)
+		oldestHeight := connected[len(connected)-1].Height
+		for keep > 0 && bs.chain[keep-1].height >= oldestHeight {
+			keep--
+		}
+	}
+	for i := len(bs.chain) - 1; i >= keep; i-- {
+		disconnected = append(disconnected, &BlockEvent{
+			EventType: types.BlockDisconnected,
+			Height:    bs.chain[i].height,
+			Hash:      bs.chain[i].hash,
+		})
+	}
+
+	bs.chain = bs.chain[:keep]
+	events := disconnected
+	for i := len(connected) - 1; i >= 0; i-- {
+		bs.chain = append(bs.chain, blockRef{height: connected[i].Height, hash: connected[i].Hash})
+		events = append(events, connected[i])
+	}
+	if len(bs.chain) > maxReorgDepth {
+		bs.chain = bs.chain[len(bs.chain)-maxReorgDepth:]
+	}
+
+	bs.mu.Lock()
+	bs.tip = connected[0]
+	subs := make([]*Subscription, 0, len(bs.subscriptions))
+	for sub := range bs.subscriptions {
+		subs = append(subs, sub)
+	}
+	bs.mu.Unlock()
+
+	for _, event := range events {
+		if event.EventType == types.BlockConnected {
+			bs.metrics.ConnectedBlocksCounter.Inc()
+		} else {
+			bs.logger.Infof("block %s at height %d is disconnected", event.Hash, event.Height)
+			bs.metrics.DisconnectedBlocksCounter.Inc()
+		}
+		for _, sub := range subs {
+			bs.send(sub, event)
+		}
+	}
+}
+
+// fetchBlock gets the full block. The raw block returned by the BTC node is
+// serialized with the witnesses of its txs.
+func (bs *BlockStream) fetchBlock(blockHash *chainhash.Hash) (*BlockEvent, error) {
+	ib, block, err := bs.btcClient.GetBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockEvent{
+		EventType: types.BlockConnected,
+		Height:    ib.Height,
+		Hash:      *blockHash,
+		Block:     block,
+	}, nil
+}
+
+func (bs *BlockStream) indexOf(blockHash chainhash.Hash) int {
+	for i := len(bs.chain) - 1; i >= 0; i-- {
+		if bs.chain[i].hash == blockHash {
+			return i
+		}
+	}
+	return -1
+}
+
+func (bs *BlockStream) send(sub *Subscription, event *BlockEvent) {
+	select {
+	case sub.events <- event:
+		return
+	default:
+	}
+
+	bs.logger.Warnf("buffer of block subscriber %s is full, waiting for it", sub.name)
+	bs.metrics.FullBuffersCounterVec.WithLabelValues(sub.name).Inc()
+	select {
+	case sub.events <- event:
+	case <-sub.cancel:
+	case <-bs.quit:
+	}
+}
+
+// Subscription receives the blocks of a BlockStream
+type Subscription struct {
+	name       string
+	events     chan *BlockEvent
+	cancel     chan struct{}
+	cancelOnce sync.Once
+	stream     *BlockStream
+}
+
+// Events returns the channel of block events, which is closed once the stream stops
+func (s *Subscription) Events() <-chan *BlockEvent {
+	return s.events
+}
+
+// Cancel stops sending blocks to the subscription
+func (s *Subscription) Cancel() {
+	s.cancelOnce.Do(func() {
+		close(s.cancel)
+		s.stream.unsubscribe(s)
+	})
+}
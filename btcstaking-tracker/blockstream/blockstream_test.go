@@ -0,0 +1,187 @@
+package blockstream_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// blockNotifier only notifies the best blocks sent to its channel
+type blockNotifier struct {
+	notifier.ChainNotifier
+	epochs chan *notifier.BlockEpoch
+}
+
+func (n *blockNotifier) RegisterBlockEpochNtfn(*notifier.BlockEpoch) (*notifier.BlockEpochEvent, error) {
+	return &notifier.BlockEpochEvent{Epochs: n.epochs, Cancel: func() {}}, nil
+}
+
+type testBlock struct {
+	height int32
+	block  *wire.MsgBlock
+}
+
+// genChain generates a chain of blocks on top of the given parent
+func genChain(r *rand.Rand, parent chainhash.Hash, parentHeight int32, n int, blocks map[chainhash.Hash]*testBlock) []*notifier.BlockEpoch {
+	epochs := make([]*notifier.BlockEpoch, 0, n)
+	for i := 0; i < n; i++ {
+		block := &wire.MsgBlock{Header: wire.BlockHeader{
+			PrevBlock:  parent,
+			MerkleRoot: chainhash.Hash(datagen.GenRandomByteArray(r, 32)),
+			Nonce:      r.Uint32(),
+		}}
+		blockHash := block.BlockHash()
+		height := parentHeight + int32(i) + 1
+		blocks[blockHash] = &testBlock{height: height, block: block}
+		epochs = append(epochs, &notifier.BlockEpoch{Hash: &blockHash, Height: height, BlockHeader: &block.Header})
+		parent = blockHash
+	}
+	return epochs
+}
+
+func requireEvent(t *testing.T, sub *blockstream.Subscription, eventType types.EventType, epoch *notifier.BlockEpoch) {
+	select {
+	case event := <-sub.Events():
+		require.Equal(t, eventType, event.EventType)
+		require.Equal(t, epoch.Height, event.Height)
+		require.Equal(t, *epoch.Hash, event.Hash)
+		if eventType == types.BlockConnected {
+			require.Equal(t, *epoch.Hash, event.Block.BlockHash())
+		} else {
+			require.Nil(t, event.Block)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for block %s", epoch.Hash)
+	}
+}
+
+func FuzzBlockStreamReorg(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		// a best chain, and a fork replacing some of its blocks
+		blocks := make(map[chainhash.Hash]*testBlock)
+		mainChain := genChain(r, chainhash.Hash(datagen.GenRandomByteArray(r, 32)), 100, r.Intn(20)+2, blocks)
+		forkDepth := r.Intn(len(mainChain)-1) + 1
+		forkPoint := mainChain[len(mainChain)-1-forkDepth]
+		fork := genChain(r, *forkPoint.Hash, forkPoint.Height, forkDepth+r.Intn(3), blocks)
+
+		btcClient := mocks.NewMockBTCClient(ctrl)
+		btcClient.EXPECT().GetBlockByHash(gomock.Any()).DoAndReturn(
+			func(blockHash *chainhash.Hash) (*types.IndexedBlock, *wire.MsgBlock, error) {
+				b := blocks[*blockHash]
+				return types.NewIndexedBlockFromMsgBlock(b.height, b.block), b.block, nil
+			}).AnyTimes()
+		btcNotifier := &blockNotifier{epochs: make(chan *notifier.BlockEpoch)}
+
+		stream := blockstream.New(btcNotifier, btcClient, 5, zap.NewNop(), metrics.NewBTCStakingTrackerMetrics().BlockStreamMetrics)
+		sub := stream.Subscribe("test")
+		require.NoError(t, stream.Start())
+
+		go func() {
+			for _, epoch := range mainChain {
+				btcNotifier.epochs <- epoch
+			}
+			// the notifier may skip blocks, which are then fetched as ancestors
+			if r.Intn(2) == 0 {
+				btcNotifier.epochs <- fork[len(fork)-1]
+				return
+			}
+			for _, epoch := range fork {
+				btcNotifier.epochs <- epoch
+			}
+		}()
+
+		for _, epoch := range mainChain {
+			requireEvent(t, sub, types.BlockConnected, epoch)
+		}
+		for i := len(mainChain) - 1; i > len(mainChain)-1-forkDepth; i-- {
+			requireEvent(t, sub, types.BlockDisconnected, mainChain[i])
+		}
+		for _, epoch := range fork {
+			requireEvent(t, sub, types.BlockConnected, epoch)
+		}
+
+		// a new subscription starts from the best block
+		lateSub := stream.Subscribe("late")
+		requireEvent(t, lateSub, types.BlockConnected, fork[len(fork)-1])
+
+		require.NoError(t, stream.Stop())
+		_, ok := <-sub.Events()
+		require.False(t, ok)
+	})
+}
+
+func TestBlockStreamWaitsForSlowSubscriber(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	bufferSize := 5
+	blocks := make(map[chainhash.Hash]*testBlock)
+	chain := genChain(r, chainhash.Hash(datagen.GenRandomByteArray(r, 32)), 100, bufferSize+3, blocks)
+
+	btcClient := mocks.NewMockBTCClient(ctrl)
+	btcClient.EXPECT().GetBlockByHash(gomock.Any()).DoAndReturn(
+		func(blockHash *chainhash.Hash) (*types.IndexedBlock, *wire.MsgBlock, error) {
+			b := blocks[*blockHash]
+			return types.NewIndexedBlockFromMsgBlock(b.height, b.block), b.block, nil
+		}).AnyTimes()
+	btcNotifier := &blockNotifier{epochs: make(chan *notifier.BlockEpoch)}
+
+	streamMetrics := metrics.NewBTCStakingTrackerMetrics().BlockStreamMetrics
+	stream := blockstream.New(btcNotifier, btcClient, uint32(bufferSize), zap.NewNop(), streamMetrics)
+	slowSub := stream.Subscribe("slow")
+	sub := stream.Subscribe("test")
+	require.NoError(t, stream.Start())
+
+	go func() {
+		for _, epoch := range chain {
+			btcNotifier.epochs <- epoch
+		}
+	}()
+	// the other subscriber is read concurrently, as it is held up with the slow one
+	received := make(chan []*blockstream.BlockEvent)
+	go func() {
+		var events []*blockstream.BlockEvent
+		for range chain {
+			events = append(events, <-sub.Events())
+		}
+		received <- events
+	}()
+
+	// the stream waits for the slow subscriber once its buffer is full
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(streamMetrics.FullBuffersCounterVec.WithLabelValues("slow")) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// no block is lost for either subscriber once the slow one catches up
+	for _, epoch := range chain {
+		requireEvent(t, slowSub, types.BlockConnected, epoch)
+	}
+	events := <-received
+	for i, epoch := range chain {
+		require.Equal(t, *epoch.Hash, events[i].Hash)
+	}
+
+	require.NoError(t, stream.Stop())
+	_, ok := <-slowSub.Events()
+	require.False(t, ok)
+}
@@ -12,6 +12,8 @@ import (
 // since the given startHeight to see if any slashing tx is not submitted to Bitcoin.
 // If the slashing tx under a finality provider with an equivocation evidence is still
 // spendable on Bitcoin, then it will submit it to Bitcoin thus slashing this BTC delegation.
+// Before that, it tracks again the slashing txs persisted before the restart that
+// are not k-deep yet.
 func (bs *BTCSlasher) Bootstrap(startHeight uint64) error {
 	bs.logger.Info("start bootstrapping BTC slasher")
 
@@ -20,6 +22,12 @@ func (bs *BTCSlasher) Bootstrap(startHeight uint64) error {
 		return err
 	}
 
+	// follow up on the slashing txs submitted before the restart, including
+	// those of evidences before the given start height
+	if err := bs.restoreSlashingTxs(); err != nil {
+		return fmt.Errorf("failed to bootstrap BTC slasher: %w", err)
+	}
+
 	// handle all evidences since the given start height, i.e., for each evidence,
 	// extract its SK and try to slash all BTC delegations under it
 	err := bs.handleAllEvidences(startHeight, func(evidences []*ftypes.Evidence) error {
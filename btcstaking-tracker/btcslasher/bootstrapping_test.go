@@ -32,6 +32,7 @@ func FuzzSlasher_Bootstrapping(f *testing.F) {
 		r := rand.New(rand.NewSource(seed))
 		net := &chaincfg.SimNetParams
 		commonCfg := config.DefaultCommonConfig()
+		slashingTxCfg := config.DefaultSlashingTxConfig()
 		ctrl := gomock.NewController(t)
 
 		mockBabylonQuerier := btcslasher.NewMockBabylonQueryClient(ctrl)
@@ -61,7 +62,7 @@ func FuzzSlasher_Bootstrapping(f *testing.F) {
 		logger, err := config.NewRootLogger("auto", "debug")
 		require.NoError(t, err)
 		slashedFPSKChan := make(chan *btcec.PrivateKey, 100)
-		btcSlasher, err := btcslasher.New(logger, mockBTCClient, mockBabylonQuerier, &chaincfg.SimNetParams, commonCfg.RetrySleepTime, commonCfg.MaxRetrySleepTime, slashedFPSKChan, metrics.NewBTCStakingTrackerMetrics().SlasherMetrics)
+		btcSlasher, err := btcslasher.New(logger, mockBTCClient, mockBabylonQuerier, &chaincfg.SimNetParams, commonCfg.RetrySleepTime, commonCfg.MaxRetrySleepTime, &slashingTxCfg, nil, nil, nil, nil, slashedFPSKChan, metrics.NewBTCStakingTrackerMetrics().SlasherMetrics)
 		require.NoError(t, err)
 
 		// slashing address
@@ -4,20 +4,43 @@ import (
 	"fmt"
 
 	bbn "github.com/babylonchain/babylon/types"
+	"github.com/babylonchain/vigilante/btcclient"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 )
 
 // TODO: use a BTC client adapter
 
-// TODO: ensure k-deep?
 func (bs *BTCSlasher) isTxSubmittedToBitcoin(txHash *chainhash.Hash) bool {
-	_, err := bs.BTCClient.GetRawTransaction(txHash)
-	return err == nil
+	return bs.getSubmittedTx(txHash) != nil
+}
+
+// getSubmittedTx returns the tx with the given hash, including its witness, if
+// it is in the mempool or on Bitcoin, or nil otherwise
+func (bs *BTCSlasher) getSubmittedTx(txHash *chainhash.Hash) *wire.MsgTx {
+	tx, err := bs.BTCClient.GetRawTransaction(txHash)
+	if err != nil {
+		return nil
+	}
+	return tx.MsgTx()
 }
 
 // isTaprootOutputSpendable checks if the taproot output of a given tx is still spendable on Bitcoin
 // This function can be used to check the output of a staking tx or an undelegation tx
 func (bs *BTCSlasher) isTaprootOutputSpendable(txBytes []byte, outIdx uint32) (bool, error) {
+	spendable, err := IsTaprootOutputSpendable(bs.BTCClient, txBytes, outIdx)
+	if err != nil {
+		return false, err
+	}
+	if !spendable {
+		bs.logger.Debugf("tx output %d is already unspendable", outIdx)
+	}
+	return spendable, nil
+}
+
+// IsTaprootOutputSpendable checks if the taproot output of a given tx is still
+// spendable on Bitcoin, including by txs in the mempool
+func IsTaprootOutputSpendable(btcClient btcclient.BTCClient, txBytes []byte, outIdx uint32) (bool, error) {
 	stakingMsgTx, err := bbn.NewBTCTxFromBytes(txBytes)
 	if err != nil {
 		return false, fmt.Errorf(
@@ -30,7 +53,7 @@ func (bs *BTCSlasher) isTaprootOutputSpendable(txBytes []byte, outIdx uint32) (b
 	// we make use of GetTxOut, which returns a non-nil UTXO if it's spendable
 	// see https://developer.bitcoin.org/reference/rpc/gettxout.html for details
 	// NOTE: we also consider mempool tx as per the last parameter
-	txOut, err := bs.BTCClient.GetTxOut(&stakingMsgTxHash, outIdx, true)
+	txOut, err := btcClient.GetTxOut(&stakingMsgTxHash, outIdx, true)
 	if err != nil {
 		return false, fmt.Errorf(
 			"failed to get the output of tx %s: %v",
@@ -38,13 +61,6 @@ func (bs *BTCSlasher) isTaprootOutputSpendable(txBytes []byte, outIdx uint32) (b
 			err,
 		)
 	}
-	if txOut == nil {
-		bs.logger.Debugf(
-			"tx %s output is already unspendable",
-			stakingMsgTxHash.String(),
-		)
-		return false, nil
-	}
-	// spendable
-	return true, nil
+	// spendable iff the UTXO exists
+	return txOut != nil, nil
 }
@@ -0,0 +1,238 @@
+package btcslasher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"go.uber.org/zap"
+)
+
+var errInsufficientFunds = errors.New("insufficient funds to pay the fee")
+
+// FeeBumper bumps the fee of a stalled slashing tx with a child tx spending its
+// slashing output, i.e., child pays for parent. The child is signed with the key
+// of the slashing address held by the BTC wallet, and is funded by a UTXO of the
+// wallet if the slashing output alone cannot pay the fee. The child pays all its
+// inputs back to the slashing address.
+type FeeBumper struct {
+	logger    *zap.SugaredLogger
+	wallet    btcclient.BTCWallet
+	estimator chainfee.Estimator
+	cfg       *config.SlashingTxConfig
+}
+
+func NewFeeBumper(
+	wallet btcclient.BTCWallet,
+	estimator chainfee.Estimator,
+	cfg *config.SlashingTxConfig,
+	parentLogger *zap.Logger,
+) *FeeBumper {
+	return &FeeBumper{
+		logger:    parentLogger.With(zap.String("module", "fee_bumper")).Sugar(),
+		wallet:    wallet,
+		estimator: estimator,
+		cfg:       cfg,
+	}
+}
+
+// childInput is an output spent by the child tx, with the key to sign it
+type childInput struct {
+	outPoint wire.OutPoint
+	txOut    *wire.TxOut
+	privKey  *btcec.PrivateKey
+}
+
+// BumpFee submits a child tx of the given slashing tx, so that the package of
+// both pays the estimated fee rate. inputValue is the value of the staking or
+// unbonding output spent by the slashing tx. It returns the hash of the child tx.
+func (fb *FeeBumper) BumpFee(slashingTx *wire.MsgTx, inputValue int64) (*chainhash.Hash, error) {
+	if len(slashingTx.TxOut) == 0 {
+		return nil, fmt.Errorf("slashing tx has no slashing output")
+	}
+	slashingTxHash := slashingTx.TxHash()
+
+	parentFee := inputValue
+	for _, txOut := range slashingTx.TxOut {
+		parentFee -= txOut.Value
+	}
+	parentVSize := mempool.GetTxVirtualSize(btcutil.NewTx(slashingTx))
+	feeRate := fb.feeRate()
+	if parentFee >= int64(feeRate.FeeForVSize(parentVSize)) {
+		return nil, fmt.Errorf("slashing tx %s already pays fee rate %v or higher", slashingTxHash, feeRate)
+	}
+
+	if err := fb.wallet.WalletPassphrase(fb.wallet.GetWalletPass(), fb.wallet.GetWalletLockTime()); err != nil {
+		return nil, fmt.Errorf("failed to unlock the wallet: %w", err)
+	}
+
+	slashingOutput := slashingTx.TxOut[0]
+	privKey, err := fb.privKeyOf(slashingOutput.PkScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the key of the slashing address: %w", err)
+	}
+	inputs := []*childInput{{
+		outPoint: *wire.NewOutPoint(&slashingTxHash, 0),
+		txOut:    slashingOutput,
+		privKey:  privKey,
+	}}
+
+	childTx, err := buildChildTx(inputs, slashingOutput.PkScript, feeRate, parentVSize, parentFee)
+	if errors.Is(err, errInsufficientFunds) {
+		// fund the child with the highest UTXO of the wallet
+		fundingInput, fundErr := fb.pickFundingInput()
+		if fundErr != nil {
+			return nil, fmt.Errorf("failed to fund the child of slashing tx %s: %w", slashingTxHash, fundErr)
+		}
+		inputs = append(inputs, fundingInput)
+		childTx, err = buildChildTx(inputs, slashingOutput.PkScript, feeRate, parentVSize, parentFee)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the child of slashing tx %s: %w", slashingTxHash, err)
+	}
+
+	childTxHash, err := fb.wallet.SendRawTransaction(childTx, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit the child of slashing tx %s: %w", slashingTxHash, err)
+	}
+	fb.logger.Infof("submitted child tx %s bumping slashing tx %s to fee rate %v", childTxHash, slashingTxHash, feeRate)
+
+	return childTxHash, nil
+}
+
+// feeRate returns the fee rate estimated for the configured target, capped by
+// the max fee rate. A slashing tx is urgent, so a failed estimate falls back to
+// the max fee rate.
+func (fb *FeeBumper) feeRate() chainfee.SatPerKVByte {
+	feeRate := fb.cfg.MaxFeeRate
+	estimate, err := fb.estimator.EstimateFeePerKW(fb.cfg.FeeBumpTargetBlocks)
+	if err != nil {
+		fb.logger.Warnf("failed to estimate fee rate, using the max fee rate %v: %v", feeRate, err)
+	} else if estimate.FeePerKVByte() < feeRate {
+		feeRate = estimate.FeePerKVByte()
+	}
+	if minFeeRate := fb.estimator.RelayFeePerKW().FeePerKVByte(); feeRate < minFeeRate {
+		feeRate = minFeeRate
+	}
+	return feeRate
+}
+
+func (fb *FeeBumper) privKeyOf(pkScript []byte) (*btcec.PrivateKey, error) {
+	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript, fb.wallet.GetNetParams())
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) != 1 {
+		return nil, fmt.Errorf("expected a single address in the pk script, got %d", len(addrs))
+	}
+	wif, err := fb.wallet.DumpPrivKey(addrs[0])
+	if err != nil {
+		return nil, err
+	}
+	return wif.PrivKey, nil
+}
+
+func (fb *FeeBumper) pickFundingInput() (*childInput, error) {
+	topUTXO, _, err := fb.wallet.GetHighUTXOAndSum()
+	if err != nil {
+		return nil, err
+	}
+	utxo, err := types.NewUTXO(topUTXO, fb.wallet.GetNetParams())
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert ListUnspentResult to UTXO: %w", err)
+	}
+	wif, err := fb.wallet.DumpPrivKey(utxo.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return &childInput{
+		outPoint: *utxo.GetOutPoint(),
+		txOut:    wire.NewTxOut(int64(utxo.Amount), utxo.ScriptPK),
+		privKey:  wif.PrivKey,
+	}, nil
+}
+
+// buildChildTx builds a signed tx spending the given inputs to the given pk
+// script, which pays the fee for the package of itself and its parent at the
+// given fee rate
+func buildChildTx(
+	inputs []*childInput,
+	pkScript []byte,
+	feeRate chainfee.SatPerKVByte,
+	parentVSize int64,
+	parentFee int64,
+) (*wire.MsgTx, error) {
+	tx := wire.NewMsgTx(wire.TxVersion)
+	var total int64
+	for _, in := range inputs {
+		tx.AddTxIn(wire.NewTxIn(&in.outPoint, nil, nil))
+		total += in.txOut.Value
+	}
+	tx.AddTxOut(wire.NewTxOut(total, pkScript))
+
+	// the size of the signatures may change with the output value, so the fee is
+	// computed again until it covers the size of the signed child tx
+	var childVSize int64
+	for {
+		if err := signChildTx(tx, inputs); err != nil {
+			return nil, err
+		}
+		signedVSize := mempool.GetTxVirtualSize(btcutil.NewTx(tx))
+		if signedVSize <= childVSize {
+			return tx, nil
+		}
+		childVSize = signedVSize
+
+		childFee := int64(feeRate.FeeForVSize(parentVSize+childVSize)) - parentFee
+		tx.TxOut[0].Value = total - childFee
+		if tx.TxOut[0].Value <= 0 || mempool.IsDust(tx.TxOut[0], mempool.DefaultMinRelayTxFee) {
+			return nil, fmt.Errorf("%w: inputs of %d sats, fee of %d sats", errInsufficientFunds, total, childFee)
+		}
+	}
+}
+
+// signChildTx signs the inputs of the child tx, which spend P2PKH, P2WPKH, or
+// BIP86 P2TR outputs
+func signChildTx(tx *wire.MsgTx, inputs []*childInput) error {
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(inputs))
+	for _, in := range inputs {
+		prevOuts[in.outPoint] = in.txOut
+	}
+	sigHashes := txscript.NewTxSigHashes(tx, txscript.NewMultiPrevOutFetcher(prevOuts))
+
+	for i, in := range inputs {
+		pkScript := in.txOut.PkScript
+		switch txscript.GetScriptClass(pkScript) {
+		case txscript.PubKeyHashTy:
+			sig, err := txscript.SignatureScript(tx, i, pkScript, txscript.SigHashAll, in.privKey, true)
+			if err != nil {
+				return err
+			}
+			tx.TxIn[i].SignatureScript = sig
+		case txscript.WitnessV0PubKeyHashTy:
+			wit, err := txscript.WitnessSignature(tx, sigHashes, i, in.txOut.Value, pkScript, txscript.SigHashAll, in.privKey, true)
+			if err != nil {
+				return err
+			}
+			tx.TxIn[i].Witness = wit
+		case txscript.WitnessV1TaprootTy:
+			wit, err := txscript.TaprootWitnessSignature(tx, sigHashes, i, in.txOut.Value, pkScript, txscript.SigHashDefault, in.privKey)
+			if err != nil {
+				return err
+			}
+			tx.TxIn[i].Witness = wit
+		default:
+			return fmt.Errorf("non-supported pk script of input %d", i)
+		}
+	}
+	return nil
+}
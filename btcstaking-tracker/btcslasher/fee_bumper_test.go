@@ -0,0 +1,86 @@
+package btcslasher
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/stretchr/testify/require"
+)
+
+func genRandomChildInput(r *rand.Rand, t *testing.T, value int64) *childInput {
+	sk, _, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(t, err)
+	pkHash := btcutil.Hash160(sk.PubKey().SerializeCompressed())
+
+	var addr btcutil.Address
+	switch r.Intn(3) {
+	case 0:
+		addr, err = btcutil.NewAddressPubKeyHash(pkHash, &chaincfg.SimNetParams)
+	case 1:
+		addr, err = btcutil.NewAddressWitnessPubKeyHash(pkHash, &chaincfg.SimNetParams)
+	default:
+		tapKey := txscript.ComputeTaprootKeyNoScript(sk.PubKey())
+		addr, err = btcutil.NewAddressTaproot(schnorr.SerializePubKey(tapKey), &chaincfg.SimNetParams)
+	}
+	require.NoError(t, err)
+	pkScript, err := txscript.PayToAddrScript(addr)
+	require.NoError(t, err)
+
+	return &childInput{
+		outPoint: *wire.NewOutPoint((*chainhash.Hash)(datagen.GenRandomByteArray(r, chainhash.HashSize)), r.Uint32()),
+		txOut:    wire.NewTxOut(value, pkScript),
+		privKey:  sk,
+	}
+}
+
+func FuzzBuildChildTx(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+
+		feeRate := chainfee.SatPerKVByte(datagen.RandomInt(r, 100000) + 10000)
+		parentVSize := int64(datagen.RandomInt(r, 300) + 100)
+		parentFee := int64(datagen.RandomInt(r, 1000))
+
+		// a slashing output too small to pay the fee, and a funding UTXO
+		slashingInput := genRandomChildInput(r, t, 500)
+		_, err := buildChildTx([]*childInput{slashingInput}, slashingInput.txOut.PkScript, feeRate, parentVSize, parentFee)
+		require.ErrorIs(t, err, errInsufficientFunds)
+
+		inputs := []*childInput{slashingInput, genRandomChildInput(r, t, int64(datagen.RandomInt(r, 1000000)+100000))}
+		childTx, err := buildChildTx(inputs, slashingInput.txOut.PkScript, feeRate, parentVSize, parentFee)
+		require.NoError(t, err)
+
+		// the package pays the fee rate
+		var totalIn int64
+		prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(inputs))
+		for _, in := range inputs {
+			totalIn += in.txOut.Value
+			prevOuts[in.outPoint] = in.txOut
+		}
+		require.Len(t, childTx.TxOut, 1)
+		childFee := totalIn - childTx.TxOut[0].Value
+		childVSize := mempool.GetTxVirtualSize(btcutil.NewTx(childTx))
+		require.GreaterOrEqual(t, parentFee+childFee, int64(feeRate.FeeForVSize(parentVSize+childVSize)))
+
+		// the inputs are signed
+		prevOutFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+		sigHashes := txscript.NewTxSigHashes(childTx, prevOutFetcher)
+		for i, in := range inputs {
+			engine, err := txscript.NewEngine(
+				in.txOut.PkScript, childTx, i, txscript.StandardVerifyFlags, nil, sigHashes, in.txOut.Value, prevOutFetcher,
+			)
+			require.NoError(t, err)
+			require.NoError(t, engine.Execute())
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package btcslasher
+
+import (
+	"context"
+
+	btcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	ftypes "github.com/babylonchain/babylon/x/finality/types"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnscheduler"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+)
+
+// ScheduledBabylonQueryClient sends the queries of a BabylonQueryClient through
+// the Babylon request scheduler shared by the routines of the BTC staking tracker.
+// Slashing depends on all of them, so they are of the highest priority.
+// Subscriptions are long-lived and not scheduled.
+type ScheduledBabylonQueryClient struct {
+	client    BabylonQueryClient
+	scheduler *bbnscheduler.Scheduler
+	// requests not bound to a caller context wait for a slot until the tracker quits
+	quitCtx context.Context
+}
+
+var _ BabylonQueryClient = (*ScheduledBabylonQueryClient)(nil)
+
+func NewScheduledBabylonQueryClient(quitCtx context.Context, client BabylonQueryClient, scheduler *bbnscheduler.Scheduler) *ScheduledBabylonQueryClient {
+	return &ScheduledBabylonQueryClient{
+		client:    client,
+		scheduler: scheduler,
+		quitCtx:   quitCtx,
+	}
+}
+
+func (sc *ScheduledBabylonQueryClient) BTCCheckpointParams() (*btcctypes.QueryParamsResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointBTCCheckpointParams, bbnscheduler.PriorityHigh,
+		sc.client.BTCCheckpointParams)
+}
+
+func (sc *ScheduledBabylonQueryClient) BTCStakingParamsByVersion(version uint32) (*bstypes.QueryParamsByVersionResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointBTCStakingParams, bbnscheduler.PriorityHigh,
+		func() (*bstypes.QueryParamsByVersionResponse, error) {
+			return sc.client.BTCStakingParamsByVersion(version)
+		})
+}
+
+func (sc *ScheduledBabylonQueryClient) FinalityProviderDelegations(fpBTCPKHex string, pagination *query.PageRequest) (*bstypes.QueryFinalityProviderDelegationsResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointFinalityProviderDelegations, bbnscheduler.PriorityHigh,
+		func() (*bstypes.QueryFinalityProviderDelegationsResponse, error) {
+			return sc.client.FinalityProviderDelegations(fpBTCPKHex, pagination)
+		})
+}
+
+func (sc *ScheduledBabylonQueryClient) ListEvidences(startHeight uint64, pagination *query.PageRequest) (*ftypes.QueryListEvidencesResponse, error) {
+	return bbnscheduler.Call(sc.quitCtx, sc.scheduler, bbnscheduler.EndpointListEvidences, bbnscheduler.PriorityHigh,
+		func() (*ftypes.QueryListEvidencesResponse, error) {
+			return sc.client.ListEvidences(startHeight, pagination)
+		})
+}
+
+func (sc *ScheduledBabylonQueryClient) Subscribe(subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	return sc.client.Subscribe(subscriber, query, outCapacity...)
+}
+
+func (sc *ScheduledBabylonQueryClient) UnsubscribeAll(subscriber string) error {
+	return sc.client.UnsubscribeAll(subscriber)
+}
+
+func (sc *ScheduledBabylonQueryClient) IsRunning() bool {
+	return sc.client.IsRunning()
+}
@@ -8,10 +8,14 @@ import (
 
 	bbn "github.com/babylonchain/babylon/types"
 	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/alert"
 	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
+	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	"go.uber.org/zap"
 )
@@ -33,8 +37,10 @@ type BTCSlasher struct {
 	// parameters
 	netParams              *chaincfg.Params
 	btcFinalizationTimeout uint64
+	btcConfirmationDepth   uint64
 	retrySleepTime         time.Duration
 	maxRetrySleepTime      time.Duration
+	slashingTxCfg          *config.SlashingTxConfig
 
 	// channel for finality signature messages, which might include
 	// equivocation evidences
@@ -43,6 +49,23 @@ type BTCSlasher struct {
 	slashedFPSKChan chan *btcec.PrivateKey
 	// channel for receiving the slash result of each BTC delegation
 	slashResultChan chan *SlashResult
+	// channel for stalled slashing txs to be escalated
+	stalledSlashingTxChan chan *trackedSlashingTx
+
+	// blockStream follows BTC blocks to track slashing txs until they are k-deep
+	blockStream *blockstream.BlockStream
+	// feeBumper bumps the fee of stalled slashing txs, nil if disabled
+	feeBumper *FeeBumper
+	// sends alerts on stalled slashing txs
+	alerts *alert.Dispatcher
+	// persists the tracked slashing txs across restarts, nil if disabled
+	slashingTxStore *SlashingTxStore
+
+	// slashing txs submitted to Bitcoin that are not k-deep yet, and the height
+	// of the best BTC block
+	slashingTxsMu sync.Mutex
+	slashingTxs   map[chainhash.Hash]*trackedSlashingTx
+	btcTipHeight  int32
 
 	metrics *metrics.SlasherMetrics
 
@@ -59,22 +82,34 @@ func New(
 	netParams *chaincfg.Params,
 	retrySleepTime time.Duration,
 	maxRetrySleepTime time.Duration,
+	slashingTxCfg *config.SlashingTxConfig,
+	blockStream *blockstream.BlockStream,
+	feeBumper *FeeBumper,
+	alerts *alert.Dispatcher,
+	slashingTxStore *SlashingTxStore,
 	slashedFPSKChan chan *btcec.PrivateKey,
 	metrics *metrics.SlasherMetrics,
 ) (*BTCSlasher, error) {
 	logger := parentLogger.With(zap.String("module", "slasher")).Sugar()
 
 	return &BTCSlasher{
-		logger:            logger,
-		BTCClient:         btcClient,
-		BBNQuerier:        bbnQuerier,
-		netParams:         netParams,
-		retrySleepTime:    retrySleepTime,
-		maxRetrySleepTime: maxRetrySleepTime,
-		slashedFPSKChan:   slashedFPSKChan,
-		slashResultChan:   make(chan *SlashResult, 1000),
-		quit:              make(chan struct{}),
-		metrics:           metrics,
+		logger:                logger,
+		BTCClient:             btcClient,
+		BBNQuerier:            bbnQuerier,
+		netParams:             netParams,
+		retrySleepTime:        retrySleepTime,
+		maxRetrySleepTime:     maxRetrySleepTime,
+		slashingTxCfg:         slashingTxCfg,
+		blockStream:           blockStream,
+		feeBumper:             feeBumper,
+		alerts:                alerts,
+		slashingTxStore:       slashingTxStore,
+		slashingTxs:           make(map[chainhash.Hash]*trackedSlashingTx),
+		slashedFPSKChan:       slashedFPSKChan,
+		slashResultChan:       make(chan *SlashResult, 1000),
+		stalledSlashingTxChan: make(chan *trackedSlashingTx, stalledSlashingTxChanSize),
+		quit:                  make(chan struct{}),
+		metrics:               metrics,
 	}, nil
 }
 
@@ -106,6 +141,7 @@ func (bs *BTCSlasher) LoadParams() error {
 		return err
 	}
 	bs.btcFinalizationTimeout = btccParamsResp.Params.CheckpointFinalizationTimeout
+	bs.btcConfirmationDepth = btccParamsResp.Params.BtcConfirmationDepth
 
 	return nil
 }
@@ -130,9 +166,11 @@ func (bs *BTCSlasher) Start() error {
 		bs.logger.Debugf("slasher routine has started subscribing %s", queryName)
 
 		// start slasher
-		bs.wg.Add(2)
+		bs.wg.Add(4)
 		go bs.equivocationTracker()
 		go bs.slashingEnforcer()
+		go bs.slashingTxMonitor(bs.blockStream.Subscribe(blockSubscriberName))
+		go bs.stalledSlashingTxEscalator()
 
 		bs.logger.Info("the BTC slasher has started")
 	})
@@ -147,6 +185,11 @@ func (bs *BTCSlasher) slashingEnforcer() {
 
 	bs.logger.Info("slashing enforcer has started")
 
+	// slashing txs recorded in metrics. A BTC delegation restaked to several
+	// slashed finality providers yields a result for each of them, all with the
+	// same slashing tx once it is on Bitcoin.
+	recordedSlashingTxs := make(map[chainhash.Hash]struct{})
+
 	// start handling incoming slashing events
 	for {
 		select {
@@ -173,18 +216,20 @@ func (bs *BTCSlasher) slashingEnforcer() {
 				bs.logger.Errorf(
 					"failed to slash BTC delegation with staking tx hash %s under finality provider %s: %v",
 					slashRes.Del.StakingTxHex,
-					slashRes.Del.FpBtcPkList[0].MarshalHex(), // TODO: work with restaking
+					slashRes.FpBtcPk.MarshalHex(),
 					slashRes.Err,
 				)
 			} else {
 				bs.logger.Infof(
 					"successfully slash BTC delegation with staking tx hash %s under finality provider %s",
 					slashRes.Del.StakingTxHex,
-					slashRes.Del.FpBtcPkList[0].MarshalHex(), // TODO: work with restaking
+					slashRes.FpBtcPk.MarshalHex(),
 				)
 
 				// record the metrics of the slashed delegation
-				bs.metrics.RecordSlashedDelegation(slashRes.Del, slashRes.SlashingTxHash.String())
+				_, recorded := recordedSlashingTxs[*slashRes.SlashingTxHash]
+				bs.metrics.RecordSlashedDelegation(slashRes.Del, slashRes.FpBtcPk.MarshalHex(), !recorded)
+				recordedSlashingTxs[*slashRes.SlashingTxHash] = struct{}{}
 			}
 		}
 	}
@@ -14,10 +14,12 @@ import (
 	btcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
 	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/cosmos/cosmos-sdk/types/query"
 	"github.com/golang/mock/gomock"
@@ -26,6 +28,7 @@ import (
 	"github.com/babylonchain/vigilante/btcstaking-tracker/btcslasher"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
+	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
 	"github.com/babylonchain/vigilante/testutil/mocks"
 )
 
@@ -36,6 +39,7 @@ func FuzzSlasher(f *testing.F) {
 		r := rand.New(rand.NewSource(seed))
 		net := &chaincfg.SimNetParams
 		commonCfg := config.DefaultCommonConfig()
+		slashingTxCfg := config.DefaultSlashingTxConfig()
 		ctrl := gomock.NewController(t)
 
 		mockBabylonQuerier := btcslasher.NewMockBabylonQueryClient(ctrl)
@@ -71,7 +75,7 @@ func FuzzSlasher(f *testing.F) {
 		logger, err := config.NewRootLogger("auto", "debug")
 		require.NoError(t, err)
 		slashedFPSKChan := make(chan *btcec.PrivateKey, 100)
-		btcSlasher, err := btcslasher.New(logger, mockBTCClient, mockBabylonQuerier, &chaincfg.SimNetParams, commonCfg.RetrySleepTime, commonCfg.MaxRetrySleepTime, slashedFPSKChan, metrics.NewBTCStakingTrackerMetrics().SlasherMetrics)
+		btcSlasher, err := btcslasher.New(logger, mockBTCClient, mockBabylonQuerier, &chaincfg.SimNetParams, commonCfg.RetrySleepTime, commonCfg.MaxRetrySleepTime, &slashingTxCfg, nil, nil, nil, nil, slashedFPSKChan, metrics.NewBTCStakingTrackerMetrics().SlasherMetrics)
 		require.NoError(t, err)
 		err = btcSlasher.LoadParams()
 		require.NoError(t, err)
@@ -291,6 +295,151 @@ func FuzzSlasher(f *testing.F) {
 	})
 }
 
+// FuzzBuildSlashingTxWithWitnessRestaked tests slashing a BTC delegation restaked
+// to several finality providers, which Babylon lists in a different order than
+// the sorted one of the slashing path script
+func FuzzBuildSlashingTxWithWitnessRestaked(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		net := &chaincfg.SimNetParams
+
+		// covenant committee
+		numCovenants := r.Intn(5) + 1
+		covenantSks := make([]*btcec.PrivateKey, 0, numCovenants)
+		covenantBtcPks := make([]*btcec.PublicKey, 0, numCovenants)
+		for i := 0; i < numCovenants; i++ {
+			covenantSk, covenantPk, err := datagen.GenRandomBTCKeyPair(r)
+			require.NoError(t, err)
+			covenantSks = append(covenantSks, covenantSk)
+			covenantBtcPks = append(covenantBtcPks, covenantPk)
+		}
+		bsParams := &bstypes.Params{
+			CovenantQuorum: 1,
+			CovenantPks:    vdatagen.NewBIP340PKsFromBTCPKs(covenantBtcPks),
+			SlashingRate:   sdkmath.LegacyMustNewDecFromStr("0.1"),
+		}
+		slashingAddr, err := datagen.GenRandomBTCAddress(r, net)
+		require.NoError(t, err)
+
+		// a BTC delegation restaked to several finality providers, whose covenant
+		// adaptor signatures are ordered as the sorted finality providers
+		fpSKs, fpPKs, err := vdatagen.GenRandomFPKeyPairs(r, r.Intn(3)+3)
+		require.NoError(t, err)
+		slashedIdx := r.Intn(len(fpSKs))
+		slashedFPSK := fpSKs[slashedIdx]
+		slashedFPPK := bbn.NewBIP340PubKeyFromBTCPK(slashedFPSK.PubKey())
+		delSK, _, err := datagen.GenRandomBTCKeyPair(r)
+		require.NoError(t, err)
+		btcDel := vdatagen.GenRandomUnbondedBTCDelegation(
+			r,
+			t,
+			net,
+			fpPKs,
+			delSK,
+			covenantSks,
+			covenantBtcPks,
+			bsParams.CovenantQuorum,
+			slashingAddr.String(),
+			bsParams.SlashingRate,
+			1000,
+		)
+
+		// Babylon lists the slashed finality provider neither first nor at its sorted position
+		btcDel.FpBtcPkList = vdatagen.GenUnsortedFPPKList(r, fpPKs, slashedIdx)
+		listedIdx := -1
+		for i, pk := range btcDel.FpBtcPkList {
+			if pk.Equals(slashedFPPK) {
+				listedIdx = i
+			}
+		}
+		require.NotEqual(t, 0, listedIdx)
+		require.NotEqual(t, slashedIdx, listedIdx)
+		btcDelResp := bstypes.NewBTCDelegationResponse(btcDel, bstypes.BTCDelegationStatus_UNBONDED)
+
+		// the slashing txs spend the staking and unbonding outputs
+		slashedOutputs := make(map[wire.OutPoint]*slashedOutput)
+		for _, txHex := range []string{btcDelResp.StakingTxHex, btcDelResp.UndelegationResponse.UnbondingTxHex} {
+			tx, _, err := bbn.NewBTCTxFromHex(txHex)
+			require.NoError(t, err)
+			for idx, txOut := range tx.TxOut {
+				outPoint := wire.OutPoint{Hash: tx.TxHash(), Index: uint32(idx)}
+				slashedOutputs[outPoint] = &slashedOutput{txOut: txOut, fpPKs: btcDelResp.FpBtcPkList}
+			}
+		}
+
+		slashingTx, err := btcslasher.BuildSlashingTxWithWitness(btcDelResp, bsParams, net, slashedFPSK)
+		require.NoError(t, err)
+		unbondingSlashingTx, err := btcslasher.BuildUnbondingSlashingTxWithWitness(btcDelResp, bsParams, net, slashedFPSK)
+		require.NoError(t, err)
+		for _, tx := range []*wire.MsgTx{slashingTx, unbondingSlashingTx} {
+			require.NoError(t, verifySlashingTxWitness(tx, slashedOutputs, bsParams.CovenantPks, slashedFPPK))
+
+			// only the slashed finality provider signs, at its sorted position
+			witness := tx.TxIn[0].Witness
+			for i := range fpPKs {
+				require.Equal(t, i == slashedIdx, len(witness[numCovenants+i]) > 0)
+			}
+		}
+	})
+}
+
+type slashedOutput struct {
+	txOut *wire.TxOut
+	fpPKs []bbn.BIP340PubKey
+}
+
+// verifySlashingTxWitness verifies that each signature in the witness of the
+// slashing tx is by the covenant member or finality provider at its position in
+// the sorted lists of PKs, and that the slashed finality provider signs
+func verifySlashingTxWitness(
+	tx *wire.MsgTx,
+	slashedOutputs map[wire.OutPoint]*slashedOutput,
+	covPKs []bbn.BIP340PubKey,
+	slashedFPPK *bbn.BIP340PubKey,
+) error {
+	output, ok := slashedOutputs[tx.TxIn[0].PreviousOutPoint]
+	if !ok {
+		return fmt.Errorf("slashing tx %s spends an unknown output", tx.TxHash())
+	}
+	witness := tx.TxIn[0].Witness
+	orderedPKs := append(bbn.SortBIP340PKs(covPKs), bbn.SortBIP340PKs(output.fpPKs)...)
+	// the witness ends with the delegator signature, the script and the control block
+	if len(witness) != len(orderedPKs)+3 {
+		return fmt.Errorf("slashing tx %s has %d witness elements, expected %d", tx.TxHash(), len(witness), len(orderedPKs)+3)
+	}
+
+	prevOutFetcher := txscript.NewCannedPrevOutputFetcher(output.txOut.PkScript, output.txOut.Value)
+	sigHash, err := txscript.CalcTapscriptSignaturehash(
+		txscript.NewTxSigHashes(tx, prevOutFetcher),
+		txscript.SigHashDefault,
+		tx,
+		0,
+		prevOutFetcher,
+		txscript.NewBaseTapLeaf(witness[len(witness)-2]),
+	)
+	if err != nil {
+		return err
+	}
+	for i, pk := range orderedPKs {
+		if len(witness[i]) == 0 {
+			if pk.Equals(slashedFPPK) {
+				return fmt.Errorf("slashed finality provider does not sign slashing tx %s", tx.TxHash())
+			}
+			continue
+		}
+		sig, err := schnorr.ParseSignature(witness[i])
+		if err != nil {
+			return err
+		}
+		if !sig.Verify(sigHash, pk.MustToBTCPK()) {
+			return fmt.Errorf("invalid signature by %s at witness position %d of slashing tx %s", pk.MarshalHex(), i, tx.TxHash())
+		}
+	}
+	return nil
+}
+
 func newBTCDelegatorDelegationsResponse(delegations []*bstypes.BTCDelegatorDelegations, status bstypes.BTCDelegationStatus) *bstypes.BTCDelegatorDelegationsResponse {
 	delListResp := make([]*bstypes.BTCDelegationResponse, 0)
 	for _, dels := range delegations {
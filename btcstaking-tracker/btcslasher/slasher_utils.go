@@ -27,8 +27,12 @@ const (
 	defaultPaginationLimit = 100
 )
 
+// SlashResult is the result of slashing a BTC delegation with the secret key of
+// one of the finality providers it is restaked to
 type SlashResult struct {
-	Del            *bstypes.BTCDelegationResponse
+	Del *bstypes.BTCDelegationResponse
+	// FpBtcPk is the PK of the slashed finality provider signing the slashing tx
+	FpBtcPk        *bbn.BIP340PubKey
 	SlashingTxHash *chainhash.Hash
 	Err            error
 }
@@ -70,6 +74,7 @@ func (bs *BTCSlasher) slashBTCDelegation(
 
 	slashRes := &SlashResult{
 		Del:            del,
+		FpBtcPk:        fpBTCPK,
 		SlashingTxHash: txHash,
 		Err:            err,
 	}
@@ -100,8 +105,9 @@ func (bs *BTCSlasher) sendSlashingTx(
 	}
 
 	txHash := slashTx.MustGetTxHash()
-	if bs.isTxSubmittedToBitcoin(txHash) {
+	if submittedTx := bs.getSubmittedTx(txHash); submittedTx != nil {
 		// already submitted to Bitcoin, skip
+		bs.trackSlashingTx(fpBTCPK, del, submittedTx, isUnbondingSlashingTx)
 		return txHash, nil
 	}
 
@@ -185,7 +191,8 @@ func (bs *BTCSlasher) sendSlashingTx(
 		fpBTCPK.MarshalHex(),
 	)
 
-	// TODO: wait for k-deep to ensure slashing tx is included
+	// follow up on the slashing tx until it is k-deep
+	bs.trackSlashingTx(fpBTCPK, del, slashingMsgTxWithWitness, isUnbondingSlashingTx)
 
 	return txHash, nil
 }
@@ -231,8 +238,7 @@ func BuildUnbondingSlashingTxWithWitness(
 	}
 
 	// get the list of covenant signatures encrypted by the given finality provider's PK
-	fpPK := fpSK.PubKey()
-	fpBTCPK := bbn.NewBIP340PubKeyFromBTCPK(fpPK)
+	fpBTCPK := bbn.NewBIP340PubKeyFromBTCPK(fpSK.PubKey())
 	fpIdx, err := findFPIdxInWitness(fpBTCPK, d.FpBtcPkList)
 	if err != nil {
 		return nil, err
@@ -253,10 +259,11 @@ func BuildUnbondingSlashingTxWithWitness(
 		return nil, err
 	}
 
-	// assemble witness for unbonding slashing tx
+	// assemble witness for unbonding slashing tx, where the signature of the
+	// finality provider is at its position among the sorted restaked ones
 	slashingMsgTxWithWitness, err := slashTx.BuildSlashingTxWithWitness(
 		fpSK,
-		d.FpBtcPkList,
+		bbn.SortBIP340PKs(d.FpBtcPkList),
 		unbondingMsgTx,
 		0,
 		delSlashingSig,
@@ -276,7 +283,9 @@ func BuildUnbondingSlashingTxWithWitness(
 }
 
 // findFPIdxInWitness returns the index of the given finality provider
-// among all restaked finality providers
+// among all restaked finality providers, sorted as in the slashing path
+// script. The index selects both the signature of the finality provider in
+// the witness and the covenant adaptor signatures encrypted by its PK.
 func findFPIdxInWitness(fpBTCPK *bbn.BIP340PubKey, fpBtcPkList []bbn.BIP340PubKey) (int, error) {
 	sortedFPBTCPKList := bbn.SortBIP340PKs(fpBtcPkList)
 	for i, pk := range sortedFPBTCPKList {
@@ -328,8 +337,7 @@ func BuildSlashingTxWithWitness(
 
 	// get the list of covenant signatures encrypted by the given finality provider's PK
 	fpBTCPK := bbn.NewBIP340PubKeyFromBTCPK(fpSK.PubKey())
-	sortedFPBTCPKList := bbn.SortBIP340PKs(d.FpBtcPkList)
-	fpIdx, err := findFPIdxInWitness(fpBTCPK, sortedFPBTCPKList)
+	fpIdx, err := findFPIdxInWitness(fpBTCPK, d.FpBtcPkList)
 	if err != nil {
 		return nil, err
 	}
@@ -349,10 +357,11 @@ func BuildSlashingTxWithWitness(
 		return nil, err
 	}
 
-	// assemble witness for slashing tx
+	// assemble witness for slashing tx, where the signature of the finality
+	// provider is at its position among the sorted restaked ones
 	slashingMsgTxWithWitness, err := slashTx.BuildSlashingTxWithWitness(
 		fpSK,
-		sortedFPBTCPKList,
+		bbn.SortBIP340PKs(d.FpBtcPkList),
 		stakingMsgTx,
 		d.StakingOutputIdx,
 		delSigSlash,
@@ -0,0 +1,391 @@
+package btcslasher
+
+import (
+	"fmt"
+
+	bbn "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/alert"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
+	"github.com/babylonchain/vigilante/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const (
+	blockSubscriberName = "btc_slasher"
+	// stalledSlashingTxChanSize is the number of stalled slashing txs waiting
+	// for escalation
+	stalledSlashingTxChanSize = 100
+)
+
+// trackedSlashingTx is a slashing tx submitted to Bitcoin that is not k-deep yet
+type trackedSlashingTx struct {
+	// the slashing tx with witness, to submit it again
+	tx *wire.MsgTx
+	// value of the staking or unbonding output spent by the slashing tx
+	inputValue    int64
+	stakingTxHash chainhash.Hash
+	delBTCPKHex   string
+	fpBTCPKHex    string
+	// height of the best block when the slashing tx was submitted, or when it
+	// was last escalated or rolled back
+	stallStartHeight int32
+	// height of the block including the slashing tx, or 0 if it is not in the best chain
+	inclusionHeight int32
+	// child tx bumping the fee of the slashing tx, if any
+	childTxHash *chainhash.Hash
+	// whether the slashing tx is waiting for, or under, escalation
+	escalating bool
+}
+
+// trackSlashingTx follows up on a slashing tx submitted to Bitcoin until it is
+// k-deep. Slashing is not failed if the tx cannot be tracked.
+func (bs *BTCSlasher) trackSlashingTx(
+	fpBTCPK *bbn.BIP340PubKey,
+	del *bstypes.BTCDelegationResponse,
+	slashingTx *wire.MsgTx,
+	isUnbondingSlashingTx bool,
+) {
+	slashingTxHash := slashingTx.TxHash()
+	stakingMsgTx, _, err := bbn.NewBTCTxFromHex(del.StakingTxHex)
+	if err != nil {
+		bs.logger.Errorf("failed to track slashing tx %s: invalid staking tx: %v", slashingTxHash, err)
+		return
+	}
+	if int(del.StakingOutputIdx) >= len(stakingMsgTx.TxOut) {
+		bs.logger.Errorf("failed to track slashing tx %s: staking tx has no output %d", slashingTxHash, del.StakingOutputIdx)
+		return
+	}
+	inputValue := stakingMsgTx.TxOut[del.StakingOutputIdx].Value
+	if isUnbondingSlashingTx {
+		unbondingMsgTx, _, err := bbn.NewBTCTxFromHex(del.UndelegationResponse.UnbondingTxHex)
+		if err != nil || len(unbondingMsgTx.TxOut) == 0 {
+			bs.logger.Errorf("failed to track slashing tx %s: invalid unbonding tx: %v", slashingTxHash, err)
+			return
+		}
+		inputValue = unbondingMsgTx.TxOut[0].Value
+	}
+
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+
+	if _, ok := bs.slashingTxs[slashingTxHash]; ok {
+		return
+	}
+	tracked := &trackedSlashingTx{
+		tx:               slashingTx,
+		inputValue:       inputValue,
+		stakingTxHash:    stakingMsgTx.TxHash(),
+		delBTCPKHex:      del.BtcPk.MarshalHex(),
+		fpBTCPKHex:       fpBTCPK.MarshalHex(),
+		stallStartHeight: bs.btcTipHeight,
+	}
+	bs.slashingTxs[slashingTxHash] = tracked
+	bs.storeSlashingTx(tracked)
+	bs.metrics.TrackedSlashingTxsGauge.Set(float64(len(bs.slashingTxs)))
+	bs.logger.Debugf("tracking slashing tx %s until it is %d-deep", slashingTxHash, bs.btcConfirmationDepth)
+}
+
+// slashingTxMonitor is a routine that follows the BTC blocks, forgets slashing
+// txs once they are k-deep, and hands slashing txs that stay out of the best
+// chain for the configured number of blocks over to the escalator. Escalation
+// makes RPCs to the BTC node, so it does not hold up the block subscription.
+func (bs *BTCSlasher) slashingTxMonitor(blockSubscription *blockstream.Subscription) {
+	defer bs.wg.Done()
+	defer blockSubscription.Cancel()
+
+	bs.logger.Info("slashing tx monitor has started")
+
+	for {
+		select {
+		case <-bs.quit:
+			bs.logger.Debug("slashing tx monitor loop quit")
+			return
+		case event, ok := <-blockSubscription.Events():
+			if !ok {
+				return
+			}
+			if event.EventType == types.BlockDisconnected {
+				bs.handleDisconnectedBlock(event)
+				continue
+			}
+			for _, stalled := range bs.handleConnectedBlock(event) {
+				bs.enqueueStalledSlashingTx(stalled)
+			}
+		}
+	}
+}
+
+// enqueueStalledSlashingTx hands a stalled slashing tx over to the escalator.
+// If the escalator is too far behind, the slashing tx is escalated once it
+// stalls again.
+func (bs *BTCSlasher) enqueueStalledSlashingTx(tracked *trackedSlashingTx) {
+	select {
+	case bs.stalledSlashingTxChan <- tracked:
+	default:
+		bs.logger.Warnf("too many stalled slashing txs waiting for escalation, postponing the escalation of slashing tx %s", tracked.tx.TxHash())
+		bs.slashingTxsMu.Lock()
+		tracked.escalating = false
+		bs.slashingTxsMu.Unlock()
+	}
+}
+
+// stalledSlashingTxEscalator is a routine that escalates the stalled slashing
+// txs handed over by the slashing tx monitor
+func (bs *BTCSlasher) stalledSlashingTxEscalator() {
+	defer bs.wg.Done()
+
+	for {
+		select {
+		case <-bs.quit:
+			bs.logger.Debug("stalled slashing tx escalator loop quit")
+			return
+		case tracked := <-bs.stalledSlashingTxChan:
+			bs.slashingTxsMu.Lock()
+			// the slashing tx may be included or untracked in the meantime
+			_, ok := bs.slashingTxs[tracked.tx.TxHash()]
+			pending := ok && tracked.inclusionHeight == 0
+			bs.slashingTxsMu.Unlock()
+
+			if pending {
+				bs.escalateStalledSlashingTx(tracked)
+			}
+
+			bs.slashingTxsMu.Lock()
+			tracked.escalating = false
+			bs.slashingTxsMu.Unlock()
+		}
+	}
+}
+
+// handleConnectedBlock records the slashing txs included in the block, forgets
+// the k-deep ones, and returns the stalled ones that are not under escalation yet
+func (bs *BTCSlasher) handleConnectedBlock(event *blockstream.BlockEvent) []*trackedSlashingTx {
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+
+	bs.btcTipHeight = event.Height
+	for _, tx := range event.Block.Transactions {
+		if tracked, ok := bs.slashingTxs[tx.TxHash()]; ok && tracked.inclusionHeight == 0 {
+			bs.logger.Infof("slashing tx %s is included in BTC block %s at height %d", tx.TxHash(), event.Hash, event.Height)
+			tracked.inclusionHeight = event.Height
+		}
+	}
+
+	var stalled []*trackedSlashingTx
+	for txHash, tracked := range bs.slashingTxs {
+		if tracked.inclusionHeight > 0 {
+			if uint64(event.Height-tracked.inclusionHeight+1) >= bs.btcConfirmationDepth {
+				bs.logger.Infof(
+					"slashing tx %s of BTC delegation %s under finality provider %s is %d-deep",
+					txHash, tracked.delBTCPKHex, tracked.fpBTCPKHex, bs.btcConfirmationDepth,
+				)
+				bs.removeSlashingTx(txHash)
+				bs.metrics.ConfirmedSlashingTxsCounter.Inc()
+				bs.resolveStalledSlashingTxAlert(tracked)
+			}
+			continue
+		}
+		// submitted before the first block is known
+		if tracked.stallStartHeight == 0 {
+			tracked.stallStartHeight = event.Height
+		}
+		if !tracked.escalating && event.Height-tracked.stallStartHeight >= int32(bs.slashingTxCfg.StallBlocks) {
+			tracked.stallStartHeight = event.Height
+			tracked.escalating = true
+			stalled = append(stalled, tracked)
+		}
+	}
+	bs.metrics.TrackedSlashingTxsGauge.Set(float64(len(bs.slashingTxs)))
+
+	return stalled
+}
+
+// handleDisconnectedBlock rolls back the inclusion of slashing txs in the block
+func (bs *BTCSlasher) handleDisconnectedBlock(event *blockstream.BlockEvent) {
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+
+	bs.btcTipHeight = event.Height - 1
+	for txHash, tracked := range bs.slashingTxs {
+		if tracked.inclusionHeight >= event.Height {
+			bs.logger.Warnf("slashing tx %s is rolled back from BTC block %s at height %d", txHash, event.Hash, event.Height)
+			tracked.inclusionHeight = 0
+			tracked.stallStartHeight = bs.btcTipHeight
+		}
+	}
+}
+
+// escalateStalledSlashingTx makes sure a stalled slashing tx is known to
+// Bitcoin, and bumps its fee, or raises an alert when that is not possible
+func (bs *BTCSlasher) escalateStalledSlashingTx(tracked *trackedSlashingTx) {
+	txHash := tracked.tx.TxHash()
+	bs.logger.Warnf(
+		"slashing tx %s of BTC delegation %s under finality provider %s is not included in %d BTC blocks",
+		txHash, tracked.delBTCPKHex, tracked.fpBTCPKHex, bs.slashingTxCfg.StallBlocks,
+	)
+	bs.metrics.StalledSlashingTxsCounter.Inc()
+
+	// the slashing tx may be included before it is tracked
+	if inclusionHeight := bs.getInclusionHeight(tracked.tx); inclusionHeight > 0 {
+		bs.slashingTxsMu.Lock()
+		tracked.inclusionHeight = inclusionHeight
+		bs.slashingTxsMu.Unlock()
+		return
+	}
+
+	// the slashing tx may be evicted from the mempool
+	if !bs.isTxSubmittedToBitcoin(&txHash) {
+		if _, err := bs.BTCClient.SendRawTransaction(tracked.tx, true); err != nil {
+			prevOut := tracked.tx.TxIn[0].PreviousOutPoint
+			if txOut, errTxOut := bs.BTCClient.GetTxOut(&prevOut.Hash, prevOut.Index, true); errTxOut == nil && txOut == nil {
+				// the staking or unbonding output is spent by another tx, e.g.,
+				// another slashing tx of the same BTC delegation
+				bs.logger.Warnf("stop tracking slashing tx %s whose input %s is spent: %v", txHash, prevOut, err)
+				bs.untrackSlashingTx(tracked)
+				return
+			}
+			bs.raiseStalledSlashingTxAlert(tracked, fmt.Sprintf("failed to submit it again: %v", err))
+			return
+		}
+		bs.logger.Infof("submitted slashing tx %s again", txHash)
+	}
+
+	if bs.feeBumper == nil {
+		bs.raiseStalledSlashingTxAlert(tracked, "fee bumping is disabled")
+		return
+	}
+	if tracked.childTxHash != nil {
+		bs.raiseStalledSlashingTxAlert(tracked, fmt.Sprintf("its fee is already bumped by child tx %s", tracked.childTxHash))
+		return
+	}
+	childTxHash, err := bs.feeBumper.BumpFee(tracked.tx, tracked.inputValue)
+	if err != nil {
+		bs.metrics.FailedFeeBumpsCounter.Inc()
+		bs.raiseStalledSlashingTxAlert(tracked, fmt.Sprintf("failed to bump its fee: %v", err))
+		return
+	}
+	bs.metrics.FeeBumpedSlashingTxsCounter.Inc()
+
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+	tracked.childTxHash = childTxHash
+	// the slashing tx may be k-deep in the meantime
+	if _, ok := bs.slashingTxs[txHash]; ok {
+		bs.storeSlashingTx(tracked)
+	}
+}
+
+// getInclusionHeight returns the height of the block including the given tx,
+// or 0 if the tx is not in the best chain or the outputs of the tx are spent
+func (bs *BTCSlasher) getInclusionHeight(tx *wire.MsgTx) int32 {
+	txHash := tx.TxHash()
+	for i := range tx.TxOut {
+		txOut, err := bs.BTCClient.GetTxOut(&txHash, uint32(i), false)
+		if err != nil || txOut == nil || txOut.Confirmations <= 0 {
+			continue
+		}
+		bs.slashingTxsMu.Lock()
+		defer bs.slashingTxsMu.Unlock()
+		return bs.btcTipHeight - int32(txOut.Confirmations) + 1
+	}
+	return 0
+}
+
+func (bs *BTCSlasher) untrackSlashingTx(tracked *trackedSlashingTx) {
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+	bs.removeSlashingTx(tracked.tx.TxHash())
+	bs.metrics.TrackedSlashingTxsGauge.Set(float64(len(bs.slashingTxs)))
+	bs.resolveStalledSlashingTxAlert(tracked)
+}
+
+// storeSlashingTx persists the given tracked slashing tx, if persistence is
+// enabled. The caller holds slashingTxsMu, so that the store follows the
+// tracked slashing txs in order.
+func (bs *BTCSlasher) storeSlashingTx(tracked *trackedSlashingTx) {
+	if bs.slashingTxStore == nil {
+		return
+	}
+	if err := bs.slashingTxStore.put(tracked); err != nil {
+		bs.logger.Warnf("failed to persist slashing tx %s: %v", tracked.tx.TxHash(), err)
+	}
+}
+
+// removeSlashingTx stops tracking the slashing tx with the given hash. The
+// caller holds slashingTxsMu.
+func (bs *BTCSlasher) removeSlashingTx(txHash chainhash.Hash) {
+	delete(bs.slashingTxs, txHash)
+	if bs.slashingTxStore == nil {
+		return
+	}
+	if err := bs.slashingTxStore.delete(txHash); err != nil {
+		bs.logger.Warnf("failed to remove persisted slashing tx %s: %v", txHash, err)
+	}
+}
+
+// restoreSlashingTxs tracks again the slashing txs persisted before a restart,
+// and looks up whether they are in the best chain
+func (bs *BTCSlasher) restoreSlashingTxs() error {
+	if bs.slashingTxStore == nil {
+		return nil
+	}
+	trackedTxs, err := bs.slashingTxStore.load()
+	if err != nil {
+		return fmt.Errorf("failed to load persisted slashing txs: %w", err)
+	}
+	if len(trackedTxs) == 0 {
+		return nil
+	}
+	_, height, err := bs.BTCClient.GetBestBlock()
+	if err != nil {
+		return fmt.Errorf("failed to get the best BTC block: %w", err)
+	}
+
+	bs.slashingTxsMu.Lock()
+	bs.btcTipHeight = int32(height)
+	bs.slashingTxsMu.Unlock()
+
+	for _, tracked := range trackedTxs {
+		tracked.stallStartHeight = int32(height)
+		tracked.inclusionHeight = bs.getInclusionHeight(tracked.tx)
+
+		bs.slashingTxsMu.Lock()
+		if _, ok := bs.slashingTxs[tracked.tx.TxHash()]; !ok {
+			bs.slashingTxs[tracked.tx.TxHash()] = tracked
+		}
+		bs.slashingTxsMu.Unlock()
+	}
+
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+	bs.metrics.TrackedSlashingTxsGauge.Set(float64(len(bs.slashingTxs)))
+	bs.logger.Infof("restored %d slashing txs to track until they are %d-deep", len(trackedTxs), bs.btcConfirmationDepth)
+
+	return nil
+}
+
+func (bs *BTCSlasher) raiseStalledSlashingTxAlert(tracked *trackedSlashingTx, reason string) {
+	if bs.alerts == nil {
+		return
+	}
+	bs.alerts.Raise(&alert.Alert{
+		Kind: alert.KindStalledSlashingTx,
+		Message: fmt.Sprintf(
+			"slashing tx %s of BTC delegation %s under finality provider %s is not included in %d BTC blocks, and %s",
+			tracked.tx.TxHash(), tracked.delBTCPKHex, tracked.fpBTCPKHex, bs.slashingTxCfg.StallBlocks, reason,
+		),
+		Evidence: alert.Evidence{
+			StakingTxHash:  tracked.stakingTxHash.String(),
+			SlashingTxHash: tracked.tx.TxHash().String(),
+		},
+	})
+}
+
+func (bs *BTCSlasher) resolveStalledSlashingTxAlert(tracked *trackedSlashingTx) {
+	if bs.alerts == nil {
+		return
+	}
+	bs.alerts.Resolve(alert.Key(alert.KindStalledSlashingTx, 0, tracked.tx.TxHash().String()))
+}
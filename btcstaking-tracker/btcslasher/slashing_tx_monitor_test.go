@@ -0,0 +1,315 @@
+package btcslasher
+
+import (
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/alert"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+	"github.com/babylonchain/vigilante/types"
+)
+
+func newTestAlerts(t *testing.T) *alert.Dispatcher {
+	cfg := config.DefaultAlertConfig()
+	cfg.File = filepath.Join(t.TempDir(), "alerts.jsonl")
+	require.NoError(t, cfg.Validate())
+	return alert.NewDispatcher(&cfg, alert.NewSinksFromConfig(&cfg), zap.NewNop(), metrics.NewMonitorMetrics().AlertMetrics)
+}
+
+// trackTestSlashingTx tracks the given slashing tx as if it was submitted at
+// the current BTC tip
+func trackTestSlashingTx(bs *BTCSlasher, tracked *trackedSlashingTx) {
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+	tracked.stallStartHeight = bs.btcTipHeight
+	bs.slashingTxs[tracked.tx.TxHash()] = tracked
+}
+
+func isTracked(bs *BTCSlasher, tracked *trackedSlashingTx) bool {
+	bs.slashingTxsMu.Lock()
+	defer bs.slashingTxsMu.Unlock()
+	_, ok := bs.slashingTxs[tracked.tx.TxHash()]
+	return ok
+}
+
+func disconnectedBlockEvent(height int32) *blockstream.BlockEvent {
+	return &blockstream.BlockEvent{
+		EventType: types.BlockDisconnected,
+		Height:    height,
+	}
+}
+
+func FuzzHandleConnectedBlock(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		bs := newTestSlasher(t, nil, nil)
+		// deep enough for the pending slashing tx to stall twice meanwhile
+		bs.btcConfirmationDepth = 20
+		depth := int32(bs.btcConfirmationDepth)
+		stallBlocks := int32(bs.slashingTxCfg.StallBlocks)
+
+		// a slashing tx submitted before the first block is known stalls from
+		// the first block on
+		height := int32(r.Intn(1000) + 1)
+		pendingTx, includedTx := genTrackedSlashingTx(r), genTrackedSlashingTx(r)
+		trackTestSlashingTx(bs, pendingTx)
+		require.Empty(t, bs.handleConnectedBlock(connectedBlockEvent(height)))
+		require.Equal(t, height, pendingTx.stallStartHeight)
+
+		// a slashing tx included in a block stops stalling
+		trackTestSlashingTx(bs, includedTx)
+		inclusionHeight := height + 1 + int32(r.Intn(int(stallBlocks)-1))
+		for height++; height < inclusionHeight; height++ {
+			require.Empty(t, bs.handleConnectedBlock(connectedBlockEvent(height)))
+		}
+		require.Empty(t, bs.handleConnectedBlock(connectedBlockEvent(height, includedTx.tx)))
+		require.Equal(t, inclusionHeight, includedTx.inclusionHeight)
+
+		// the pending slashing tx stalls after the configured number of blocks,
+		// and does not stall again while it is under escalation
+		for height++; height < pendingTx.stallStartHeight+stallBlocks; height++ {
+			require.Empty(t, bs.handleConnectedBlock(connectedBlockEvent(height)))
+		}
+		require.Equal(t, []*trackedSlashingTx{pendingTx}, bs.handleConnectedBlock(connectedBlockEvent(height)))
+		require.True(t, pendingTx.escalating)
+		for i := int32(0); i < stallBlocks; i++ {
+			height++
+			require.Empty(t, bs.handleConnectedBlock(connectedBlockEvent(height)))
+		}
+
+		// once escalated, it stalls again at the next block
+		pendingTx.escalating = false
+		height++
+		require.Equal(t, []*trackedSlashingTx{pendingTx}, bs.handleConnectedBlock(connectedBlockEvent(height)))
+
+		// the included slashing tx is forgotten once it is k-deep, and the
+		// pending one is still tracked
+		for height++; height < inclusionHeight+depth-1; height++ {
+			bs.handleConnectedBlock(connectedBlockEvent(height))
+			require.True(t, isTracked(bs, includedTx))
+		}
+		bs.handleConnectedBlock(connectedBlockEvent(height))
+		require.False(t, isTracked(bs, includedTx))
+		require.True(t, isTracked(bs, pendingTx))
+		require.Equal(t, float64(1), testutil.ToFloat64(bs.metrics.ConfirmedSlashingTxsCounter))
+		require.Equal(t, float64(1), testutil.ToFloat64(bs.metrics.TrackedSlashingTxsGauge))
+	})
+}
+
+func FuzzHandleDisconnectedBlock(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		bs := newTestSlasher(t, nil, nil)
+
+		height := int32(r.Intn(1000) + 1)
+		bs.handleConnectedBlock(connectedBlockEvent(height))
+		tracked := genTrackedSlashingTx(r)
+		trackTestSlashingTx(bs, tracked)
+
+		// the slashing tx is included, and buried by a few blocks
+		inclusionHeight := height + 1
+		bs.handleConnectedBlock(connectedBlockEvent(inclusionHeight, tracked.tx))
+		numBuryingBlocks := int32(r.Intn(testBTCConfirmationDepth - 2))
+		for i := int32(1); i <= numBuryingBlocks; i++ {
+			bs.handleConnectedBlock(connectedBlockEvent(inclusionHeight + i))
+		}
+
+		// disconnecting the burying blocks keeps the inclusion
+		for i := numBuryingBlocks; i >= 1; i-- {
+			bs.handleDisconnectedBlock(disconnectedBlockEvent(inclusionHeight + i))
+			require.Equal(t, inclusionHeight, tracked.inclusionHeight)
+		}
+
+		// disconnecting the block including the slashing tx rolls back its
+		// inclusion, and it stalls from the new tip on
+		bs.handleDisconnectedBlock(disconnectedBlockEvent(inclusionHeight))
+		require.Zero(t, tracked.inclusionHeight)
+		require.Equal(t, height, tracked.stallStartHeight)
+		require.Equal(t, height, bs.btcTipHeight)
+		require.True(t, isTracked(bs, tracked))
+
+		// the slashing tx is included again in the new best chain
+		bs.handleConnectedBlock(connectedBlockEvent(inclusionHeight))
+		bs.handleConnectedBlock(connectedBlockEvent(inclusionHeight+1, tracked.tx))
+		require.Equal(t, inclusionHeight+1, tracked.inclusionHeight)
+	})
+}
+
+// newEscalationTestSlasher returns a slasher at the given BTC tip, tracking
+// the given stalled slashing tx that is not included in a block
+func newEscalationTestSlasher(t *testing.T, tipHeight int32, tracked *trackedSlashingTx) (*mocks.MockBTCClient, *BTCSlasher) {
+	mockBTCClient := mocks.NewMockBTCClient(gomock.NewController(t))
+	bs := newTestSlasher(t, mockBTCClient, nil)
+	bs.alerts = newTestAlerts(t)
+	bs.btcTipHeight = tipHeight
+
+	trackTestSlashingTx(bs, tracked)
+	txHash := tracked.tx.TxHash()
+	mockBTCClient.EXPECT().GetTxOut(&txHash, uint32(0), false).Return(nil, nil).AnyTimes()
+	return mockBTCClient, bs
+}
+
+func TestEscalateIncludedSlashingTx(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	mockBTCClient := mocks.NewMockBTCClient(gomock.NewController(t))
+	bs := newTestSlasher(t, mockBTCClient, nil)
+	bs.alerts = newTestAlerts(t)
+	bs.btcTipHeight = 110
+	tracked := genTrackedSlashingTx(r)
+	trackTestSlashingTx(bs, tracked)
+
+	// the slashing tx was included before it was tracked, e.g., by another
+	// slasher, hence it is neither submitted again nor alerted on
+	txHash := tracked.tx.TxHash()
+	mockBTCClient.EXPECT().GetTxOut(&txHash, uint32(0), false).Return(&btcjson.GetTxOutResult{Confirmations: 3}, nil)
+	bs.escalateStalledSlashingTx(tracked)
+	require.Equal(t, int32(108), tracked.inclusionHeight)
+	require.Zero(t, bs.alerts.NumActive())
+}
+
+func TestEscalateEvictedSlashingTx(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	tracked := genTrackedSlashingTx(r)
+	mockBTCClient, bs := newEscalationTestSlasher(t, 110, tracked)
+	txHash := tracked.tx.TxHash()
+	prevOut := tracked.tx.TxIn[0].PreviousOutPoint
+	mockBTCClient.EXPECT().GetRawTransaction(&txHash).Return(nil, errors.New("no such mempool or blockchain transaction")).AnyTimes()
+
+	// the slashing tx evicted from the mempool is submitted again, and an alert
+	// is raised as its fee cannot be bumped
+	mockBTCClient.EXPECT().SendRawTransaction(tracked.tx, true).Return(&txHash, nil)
+	bs.escalateStalledSlashingTx(tracked)
+	require.Equal(t, 1, bs.alerts.NumActive())
+	require.True(t, isTracked(bs, tracked))
+
+	// an alert is raised if the slashing tx cannot be submitted again while its
+	// input is unspent
+	bs.alerts = newTestAlerts(t)
+	mockBTCClient.EXPECT().SendRawTransaction(tracked.tx, true).Return(nil, errors.New("min relay fee not met"))
+	mockBTCClient.EXPECT().GetTxOut(&prevOut.Hash, prevOut.Index, true).Return(&btcjson.GetTxOutResult{}, nil)
+	bs.escalateStalledSlashingTx(tracked)
+	require.Equal(t, 1, bs.alerts.NumActive())
+	require.True(t, isTracked(bs, tracked))
+
+	// the slashing tx is no longer tracked once its input is spent by another
+	// tx, and its alert is resolved
+	mockBTCClient.EXPECT().SendRawTransaction(tracked.tx, true).Return(nil, errors.New("bad-txns-inputs-missingorspent"))
+	mockBTCClient.EXPECT().GetTxOut(&prevOut.Hash, prevOut.Index, true).Return(nil, nil)
+	bs.escalateStalledSlashingTx(tracked)
+	require.False(t, isTracked(bs, tracked))
+	require.Zero(t, bs.alerts.NumActive())
+	require.Equal(t, float64(3), testutil.ToFloat64(bs.metrics.StalledSlashingTxsCounter))
+}
+
+func TestEscalateBumpsSlashingTxFee(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// a slashing tx paying a low fee, whose slashing output is of the wallet
+	tracked := genTrackedSlashingTx(r)
+	slashingInput := genRandomChildInput(r, t, 100000)
+	tracked.tx.TxOut[0] = slashingInput.txOut
+	tracked.inputValue = slashingInput.txOut.Value + 100
+	mockBTCClient, bs := newEscalationTestSlasher(t, 110, tracked)
+	txHash := tracked.tx.TxHash()
+	mockBTCClient.EXPECT().GetRawTransaction(&txHash).Return(btcutil.NewTx(tracked.tx), nil).AnyTimes()
+
+	mockWallet := mocks.NewMockBTCWallet(gomock.NewController(t))
+	mockWallet.EXPECT().GetWalletPass().Return("pass").AnyTimes()
+	mockWallet.EXPECT().GetWalletLockTime().Return(int64(10)).AnyTimes()
+	mockWallet.EXPECT().WalletPassphrase("pass", int64(10)).Return(nil).AnyTimes()
+	mockWallet.EXPECT().GetNetParams().Return(&chaincfg.SimNetParams).AnyTimes()
+	wif, err := btcutil.NewWIF(slashingInput.privKey, &chaincfg.SimNetParams, true)
+	require.NoError(t, err)
+	mockWallet.EXPECT().DumpPrivKey(gomock.Any()).Return(wif, nil).AnyTimes()
+	// 50 sat/vbyte
+	estimator := chainfee.NewStaticEstimator(chainfee.SatPerKWeight(12500), 0)
+	bs.feeBumper = NewFeeBumper(mockWallet, estimator, bs.slashingTxCfg, zap.NewNop())
+
+	// the fee of the slashing tx known to Bitcoin is bumped by a child tx
+	// spending its slashing output
+	childTxHash := genRandomHash(r)
+	mockWallet.EXPECT().SendRawTransaction(gomock.Any(), true).DoAndReturn(func(childTx *wire.MsgTx, _ bool) (*chainhash.Hash, error) {
+		require.Equal(t, txHash, childTx.TxIn[0].PreviousOutPoint.Hash)
+		return childTxHash, nil
+	})
+	bs.escalateStalledSlashingTx(tracked)
+	require.Equal(t, childTxHash, tracked.childTxHash)
+	require.Zero(t, bs.alerts.NumActive())
+	require.Equal(t, float64(1), testutil.ToFloat64(bs.metrics.FeeBumpedSlashingTxsCounter))
+
+	// a slashing tx that stalls although its fee is bumped is alerted on
+	bs.escalateStalledSlashingTx(tracked)
+	require.Equal(t, 1, bs.alerts.NumActive())
+
+	// so is a slashing tx whose fee cannot be bumped
+	bs.alerts = newTestAlerts(t)
+	tracked.childTxHash = nil
+	mockWallet.EXPECT().SendRawTransaction(gomock.Any(), true).Return(nil, errors.New("insufficient fee"))
+	bs.escalateStalledSlashingTx(tracked)
+	require.Nil(t, tracked.childTxHash)
+	require.Equal(t, 1, bs.alerts.NumActive())
+	require.Equal(t, float64(1), testutil.ToFloat64(bs.metrics.FailedFeeBumpsCounter))
+}
+
+func TestStalledSlashingTxEscalator(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	tracked := genTrackedSlashingTx(r)
+	mockBTCClient, bs := newEscalationTestSlasher(t, 110, tracked)
+	txHash := tracked.tx.TxHash()
+	mockBTCClient.EXPECT().GetRawTransaction(&txHash).Return(btcutil.NewTx(tracked.tx), nil).AnyTimes()
+	bs.wg.Add(1)
+	go bs.stalledSlashingTxEscalator()
+	defer func() {
+		close(bs.quit)
+		bs.wg.Wait()
+	}()
+
+	// stalled slashing txs are escalated by the escalator, which lets them
+	// stall again afterwards
+	stalled := bs.handleConnectedBlock(connectedBlockEvent(110 + int32(bs.slashingTxCfg.StallBlocks)))
+	require.Equal(t, []*trackedSlashingTx{tracked}, stalled)
+	bs.enqueueStalledSlashingTx(tracked)
+	require.Eventually(t, func() bool {
+		bs.slashingTxsMu.Lock()
+		defer bs.slashingTxsMu.Unlock()
+		return !tracked.escalating
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, bs.alerts.NumActive())
+	require.Equal(t, float64(1), testutil.ToFloat64(bs.metrics.StalledSlashingTxsCounter))
+}
+
+func TestEnqueueStalledSlashingTxWhenFull(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	bs := newTestSlasher(t, nil, nil)
+
+	// the escalation of a slashing tx is postponed rather than holding up the
+	// blocks when the escalator is too far behind
+	for i := 0; i < stalledSlashingTxChanSize; i++ {
+		bs.stalledSlashingTxChan <- genTrackedSlashingTx(r)
+	}
+	tracked := genTrackedSlashingTx(r)
+	trackTestSlashingTx(bs, tracked)
+	tracked.escalating = true
+	bs.enqueueStalledSlashingTx(tracked)
+	require.False(t, tracked.escalating)
+	require.Len(t, bs.stalledSlashingTxChan, stalledSlashingTxChanSize)
+}
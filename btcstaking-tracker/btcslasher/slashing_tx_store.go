@@ -0,0 +1,142 @@
+package btcslasher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	bolt "go.etcd.io/bbolt"
+)
+
+var slashingTxBucket = []byte("slashing-txs")
+
+// SlashingTxStore persists the slashing txs tracked by the BTC slasher in a
+// bbolt database, so that they are followed up on until they are k-deep across
+// restarts. Only what cannot be re-derived from Bitcoin is stored: the inclusion
+// of a slashing tx is looked up again when it is restored.
+type SlashingTxStore struct {
+	db *bolt.DB
+}
+
+// storedSlashingTx is a tracked slashing tx as persisted in the store
+type storedSlashingTx struct {
+	// the slashing tx with witness, in the Bitcoin wire format
+	Tx            []byte `json:"tx"`
+	InputValue    int64  `json:"input_value"`
+	StakingTxHash string `json:"staking_tx_hash"`
+	DelBTCPKHex   string `json:"del_btc_pk_hex"`
+	FpBTCPKHex    string `json:"fp_btc_pk_hex"`
+	ChildTxHash   string `json:"child_tx_hash,omitempty"`
+}
+
+// NewSlashingTxStore opens, or creates, the slashing tx database at the given path
+func NewSlashingTxStore(path string) (*SlashingTxStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// a timeout avoids blocking forever if another process holds the database
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open slashing tx store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(slashingTxBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialise slashing tx store %s: %w", path, err)
+	}
+	return &SlashingTxStore{db: db}, nil
+}
+
+// put stores the given tracked slashing tx, overwriting any previous version
+func (s *SlashingTxStore) put(tracked *trackedSlashingTx) error {
+	var buf bytes.Buffer
+	if err := tracked.tx.Serialize(&buf); err != nil {
+		return err
+	}
+	stored := &storedSlashingTx{
+		Tx:            buf.Bytes(),
+		InputValue:    tracked.inputValue,
+		StakingTxHash: tracked.stakingTxHash.String(),
+		DelBTCPKHex:   tracked.delBTCPKHex,
+		FpBTCPKHex:    tracked.fpBTCPKHex,
+	}
+	if tracked.childTxHash != nil {
+		stored.ChildTxHash = tracked.childTxHash.String()
+	}
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	txHash := tracked.tx.TxHash()
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(slashingTxBucket).Put(txHash[:], value)
+	})
+}
+
+// delete removes the slashing tx with the given hash
+func (s *SlashingTxStore) delete(txHash chainhash.Hash) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(slashingTxBucket).Delete(txHash[:])
+	})
+}
+
+// load returns all the stored slashing txs. The inclusion and stall heights of
+// the returned slashing txs are unset.
+func (s *SlashingTxStore) load() ([]*trackedSlashingTx, error) {
+	var trackedTxs []*trackedSlashingTx
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(slashingTxBucket).ForEach(func(k, v []byte) error {
+			tracked, err := decodeStoredSlashingTx(v)
+			if err != nil {
+				return fmt.Errorf("invalid slashing tx %x: %w", k, err)
+			}
+			trackedTxs = append(trackedTxs, tracked)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trackedTxs, nil
+}
+
+func decodeStoredSlashingTx(value []byte) (*trackedSlashingTx, error) {
+	var stored storedSlashingTx
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, err
+	}
+	slashingTx := &wire.MsgTx{}
+	if err := slashingTx.Deserialize(bytes.NewReader(stored.Tx)); err != nil {
+		return nil, err
+	}
+	stakingTxHash, err := chainhash.NewHashFromStr(stored.StakingTxHash)
+	if err != nil {
+		return nil, err
+	}
+	tracked := &trackedSlashingTx{
+		tx:            slashingTx,
+		inputValue:    stored.InputValue,
+		stakingTxHash: *stakingTxHash,
+		delBTCPKHex:   stored.DelBTCPKHex,
+		fpBTCPKHex:    stored.FpBTCPKHex,
+	}
+	if stored.ChildTxHash != "" {
+		if tracked.childTxHash, err = chainhash.NewHashFromStr(stored.ChildTxHash); err != nil {
+			return nil, err
+		}
+	}
+	return tracked, nil
+}
+
+// Close closes the slashing tx database
+func (s *SlashingTxStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,169 @@
+package btcslasher
+
+import (
+	"encoding/hex"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+	"github.com/babylonchain/vigilante/types"
+)
+
+const testBTCConfirmationDepth = 6
+
+func newTestSlasher(t *testing.T, btcClient btcclient.BTCClient, store *SlashingTxStore) *BTCSlasher {
+	slashingTxCfg := config.DefaultSlashingTxConfig()
+	bs, err := New(zap.NewNop(), btcClient, nil, &chaincfg.SimNetParams, 1, 0, &slashingTxCfg, nil, nil, nil, store, nil, metrics.NewBTCStakingTrackerMetrics().SlasherMetrics)
+	require.NoError(t, err)
+	bs.btcConfirmationDepth = testBTCConfirmationDepth
+	return bs
+}
+
+func newTestSlashingTxStore(t *testing.T) *SlashingTxStore {
+	store, err := NewSlashingTxStore(filepath.Join(t.TempDir(), "slashing-txs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func genRandomHash(r *rand.Rand) *chainhash.Hash {
+	return (*chainhash.Hash)(datagen.GenRandomByteArray(r, chainhash.HashSize))
+}
+
+// genTrackedSlashingTx generates a tracked slashing tx, with a witness, that is
+// not included in any block
+func genTrackedSlashingTx(r *rand.Rand) *trackedSlashingTx {
+	slashingTx := wire.NewMsgTx(wire.TxVersion)
+	txIn := wire.NewTxIn(wire.NewOutPoint(genRandomHash(r), r.Uint32()), nil, nil)
+	txIn.Witness = wire.TxWitness{datagen.GenRandomByteArray(r, 64), datagen.GenRandomByteArray(r, 34)}
+	slashingTx.AddTxIn(txIn)
+	slashingTx.AddTxOut(wire.NewTxOut(int64(r.Intn(100000)+1000), datagen.GenRandomByteArray(r, 34)))
+	return &trackedSlashingTx{
+		tx:            slashingTx,
+		inputValue:    int64(r.Intn(1000000) + 100000),
+		stakingTxHash: *genRandomHash(r),
+		delBTCPKHex:   hex.EncodeToString(datagen.GenRandomByteArray(r, 32)),
+		fpBTCPKHex:    hex.EncodeToString(datagen.GenRandomByteArray(r, 32)),
+	}
+}
+
+func requireSameSlashingTx(t *testing.T, expected, actual *trackedSlashingTx) {
+	require.Equal(t, expected.tx.TxHash(), actual.tx.TxHash())
+	require.Equal(t, expected.tx.WitnessHash(), actual.tx.WitnessHash())
+	require.Equal(t, expected.inputValue, actual.inputValue)
+	require.Equal(t, expected.stakingTxHash, actual.stakingTxHash)
+	require.Equal(t, expected.delBTCPKHex, actual.delBTCPKHex)
+	require.Equal(t, expected.fpBTCPKHex, actual.fpBTCPKHex)
+	require.Equal(t, expected.childTxHash, actual.childTxHash)
+}
+
+func connectedBlockEvent(height int32, txs ...*wire.MsgTx) *blockstream.BlockEvent {
+	block := &wire.MsgBlock{Transactions: txs}
+	return &blockstream.BlockEvent{
+		EventType: types.BlockConnected,
+		Height:    height,
+		Hash:      block.BlockHash(),
+		Block:     block,
+	}
+}
+
+func FuzzSlashingTxStore(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		path := filepath.Join(t.TempDir(), "slashing-txs.db")
+		store, err := NewSlashingTxStore(path)
+		require.NoError(t, err)
+
+		// slashing txs are stored, updated once their fee is bumped, and removed
+		numTxs := r.Intn(10) + 1
+		expected := make(map[chainhash.Hash]*trackedSlashingTx)
+		for i := 0; i < numTxs; i++ {
+			tracked := genTrackedSlashingTx(r)
+			require.NoError(t, store.put(tracked))
+			if r.Intn(2) == 0 {
+				tracked.childTxHash = genRandomHash(r)
+				require.NoError(t, store.put(tracked))
+			}
+			expected[tracked.tx.TxHash()] = tracked
+		}
+		for txHash := range expected {
+			if r.Intn(3) == 0 {
+				require.NoError(t, store.delete(txHash))
+				delete(expected, txHash)
+			}
+		}
+		require.NoError(t, store.Close())
+
+		// the slashing txs are the same after reopening the store
+		store, err = NewSlashingTxStore(path)
+		require.NoError(t, err)
+		defer store.Close()
+		loaded, err := store.load()
+		require.NoError(t, err)
+		require.Len(t, loaded, len(expected))
+		for _, tracked := range loaded {
+			requireSameSlashingTx(t, expected[tracked.tx.TxHash()], tracked)
+			require.Zero(t, tracked.inclusionHeight)
+			require.Zero(t, tracked.stallStartHeight)
+		}
+	})
+}
+
+func TestRestoreSlashingTxs(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	store := newTestSlashingTxStore(t)
+
+	// a slashing tx in a block, and a bumped one that is not
+	includedTx, pendingTx := genTrackedSlashingTx(r), genTrackedSlashingTx(r)
+	pendingTx.childTxHash = genRandomHash(r)
+	bs := newTestSlasher(t, nil, store)
+	bs.slashingTxsMu.Lock()
+	for _, tracked := range []*trackedSlashingTx{includedTx, pendingTx} {
+		bs.slashingTxs[tracked.tx.TxHash()] = tracked
+		bs.storeSlashingTx(tracked)
+	}
+	bs.slashingTxsMu.Unlock()
+
+	// the slashing txs are tracked again after a restart, and their inclusion
+	// is looked up on Bitcoin
+	mockBTCClient := mocks.NewMockBTCClient(gomock.NewController(t))
+	includedTxHash, pendingTxHash := includedTx.tx.TxHash(), pendingTx.tx.TxHash()
+	mockBTCClient.EXPECT().GetBestBlock().Return(genRandomHash(r), uint64(100), nil)
+	mockBTCClient.EXPECT().GetTxOut(&includedTxHash, uint32(0), false).Return(&btcjson.GetTxOutResult{Confirmations: 2}, nil)
+	mockBTCClient.EXPECT().GetTxOut(&pendingTxHash, uint32(0), false).Return(nil, nil)
+	bs = newTestSlasher(t, mockBTCClient, store)
+	require.NoError(t, bs.restoreSlashingTxs())
+	require.Len(t, bs.slashingTxs, 2)
+	restoredIncludedTx, restoredPendingTx := bs.slashingTxs[includedTxHash], bs.slashingTxs[pendingTxHash]
+	requireSameSlashingTx(t, includedTx, restoredIncludedTx)
+	requireSameSlashingTx(t, pendingTx, restoredPendingTx)
+	require.Equal(t, int32(99), restoredIncludedTx.inclusionHeight)
+	require.Zero(t, restoredPendingTx.inclusionHeight)
+	require.Equal(t, int32(100), restoredPendingTx.stallStartHeight)
+
+	// the included slashing tx is removed from the store once it is k-deep
+	for height := int32(101); height < 99+testBTCConfirmationDepth; height++ {
+		bs.handleConnectedBlock(connectedBlockEvent(height))
+	}
+	loaded, err := store.load()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	requireSameSlashingTx(t, pendingTx, loaded[0])
+	require.NotContains(t, bs.slashingTxs, includedTxHash)
+}
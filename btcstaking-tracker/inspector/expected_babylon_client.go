@@ -0,0 +1,14 @@
+package inspector
+
+import (
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	ftypes "github.com/babylonchain/babylon/x/finality/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
+)
+
+type BabylonQueryClient interface {
+	BTCDelegation(stakingTxHashHex string) (*bstypes.QueryBTCDelegationResponse, error)
+	BTCStakingParamsByVersion(version uint32) (*bstypes.QueryParamsByVersionResponse, error)
+	FinalityProvider(fpBtcPkHex string) (*bstypes.QueryFinalityProviderResponse, error)
+	ListEvidences(startHeight uint64, pagination *query.PageRequest) (*ftypes.QueryListEvidencesResponse, error)
+}
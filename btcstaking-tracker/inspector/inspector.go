@@ -0,0 +1,300 @@
+package inspector
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	bbn "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	ftypes "github.com/babylonchain/babylon/x/finality/types"
+	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/btcslasher"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/unbondingwatcher"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/cosmos/cosmos-sdk/types/query"
+)
+
+const (
+	outputStaking   = "staking"
+	outputUnbonding = "unbonding"
+
+	defaultPaginationLimit = 100
+)
+
+// Inspector runs the checks of the BTC staking tracker against a single BTC
+// delegation, without watching or sending anything
+type Inspector struct {
+	bbnClient BabylonQueryClient
+	btcClient btcclient.BTCClient
+	btcParams *chaincfg.Params
+}
+
+func New(bbnClient BabylonQueryClient, btcClient btcclient.BTCClient, btcParams *chaincfg.Params) *Inspector {
+	return &Inspector{
+		bbnClient: bbnClient,
+		btcClient: btcClient,
+		btcParams: btcParams,
+	}
+}
+
+// Inspect reports the lifecycle of the BTC delegation with the given staking tx
+// on Babylon and on Bitcoin. If spendingTxHash is not nil, the tx is classified
+// as a spend of the staking or unbonding output of the delegation.
+func (i *Inspector) Inspect(stakingTxHash chainhash.Hash, spendingTxHash *chainhash.Hash) (*Report, error) {
+	delResp, err := i.bbnClient.BTCDelegation(stakingTxHash.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BTC delegation %s from Babylon: %w", stakingTxHash, err)
+	}
+	btcDel := delResp.BtcDelegation
+	paramsResp, err := i.bbnClient.BTCStakingParamsByVersion(btcDel.ParamsVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get BTC staking parameters of version %d: %w", btcDel.ParamsVersion, err)
+	}
+	params := &paramsResp.Params
+
+	report := &Report{
+		StakingTxHash: stakingTxHash.String(),
+		StakerPk:      btcDel.BtcPk.MarshalHex(),
+		Status:        btcDel.StatusDesc,
+		Active:        btcDel.Active,
+		StartHeight:   btcDel.StartHeight,
+		EndHeight:     btcDel.EndHeight,
+		ParamsVersion: btcDel.ParamsVersion,
+	}
+
+	stakingTxBytes, err := hex.DecodeString(btcDel.StakingTxHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid staking tx of BTC delegation %s: %w", stakingTxHash, err)
+	}
+
+	if btcDel.UndelegationResponse == nil {
+		// without the unbonding tx, neither the delegation can be built as the
+		// unbonding watcher does nor its spends be classified, so only the staking
+		// tx and the slashing by the BTC slasher are inspected
+		report.UnbondingInfoAbsent = true
+		report.StakingTx, _ = i.inspectTx(stakingTxHash)
+		report.StakingTx.Output = i.inspectOutput(stakingTxBytes, btcDel.StakingOutputIdx)
+		if spendingTxHash != nil {
+			report.Spends = append(report.Spends, SpendReport{
+				SpendingTxHash: spendingTxHash.String(),
+				Type:           unbondingwatcher.SpendTypeUnknown.String(),
+				Reason:         "unbonding info of the delegation is absent on Babylon",
+			})
+		}
+		if report.FinalityProviders, err = i.inspectFinalityProviders(btcDel, params); err != nil {
+			return nil, err
+		}
+		report.Findings = findings(report)
+		return report, nil
+	}
+
+	// build the delegation the same way as the unbonding watcher
+	del, err := unbondingwatcher.DelegationFromResponse(btcDel, params, i.btcParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BTC delegation %s: %w", stakingTxHash, err)
+	}
+	unbondingMsgTx, _, err := bbn.NewBTCTxFromHex(btcDel.UndelegationResponse.UnbondingTxHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unbonding tx of BTC delegation %s: %w", stakingTxHash, err)
+	}
+	td := &unbondingwatcher.TrackedDelegation{
+		StakingTx:               del.StakingTx,
+		StakingOutputIdx:        del.StakingOutputIdx,
+		UnbondingOutput:         del.UnbondingOutput,
+		SlashingTxHash:          del.SlashingTxHash,
+		UnbondingSlashingTxHash: del.UnbondingSlashingTxHash,
+		StakingScripts:          del.StakingScripts,
+		UnbondingScripts:        del.UnbondingScripts,
+		UnbondingTxHash:         unbondingMsgTx.TxHash(),
+	}
+
+	// txs of the delegation on Bitcoin
+	var unbondingOnBTC, slashingOnBTC, unbondingSlashingOnBTC *wire.MsgTx
+	report.StakingTx, _ = i.inspectTx(td.StakingTx.TxHash())
+	report.UnbondingTx, unbondingOnBTC = i.inspectTx(td.UnbondingTxHash)
+	report.SlashingTx, slashingOnBTC = i.inspectTx(td.SlashingTxHash)
+	report.UnbondingSlashingTx, unbondingSlashingOnBTC = i.inspectTx(td.UnbondingSlashingTxHash)
+
+	// outputs of the delegation that can still be slashed
+	report.StakingTx.Output = i.inspectOutput(stakingTxBytes, td.StakingOutputIdx)
+	if unbondingOnBTC != nil {
+		unbondingTxBytes, err := hex.DecodeString(btcDel.UndelegationResponse.UnbondingTxHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unbonding tx of BTC delegation %s: %w", stakingTxHash, err)
+		}
+		// unbonding tx always has only one output
+		report.UnbondingTx.Output = i.inspectOutput(unbondingTxBytes, 0)
+	}
+
+	// classify the known spends of the delegation's outputs, as the unbonding
+	// watcher would upon their confirmation
+	if unbondingOnBTC != nil {
+		report.Spends = append(report.Spends, classify(unbondingOnBTC, td))
+	}
+	if slashingOnBTC != nil {
+		report.Spends = append(report.Spends, classify(slashingOnBTC, td))
+	}
+	if unbondingSlashingOnBTC != nil {
+		report.Spends = append(report.Spends, classify(unbondingSlashingOnBTC, td))
+	}
+	if spendingTxHash != nil {
+		spendingTx, err := i.btcClient.GetRawTransaction(spendingTxHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get spending tx %s from Bitcoin: %w", spendingTxHash, err)
+		}
+		report.Spends = append(report.Spends, classify(spendingTx.MsgTx(), td))
+	}
+
+	// slashing of the delegation as the BTC slasher would do it
+	if report.FinalityProviders, err = i.inspectFinalityProviders(btcDel, params); err != nil {
+		return nil, err
+	}
+
+	report.Findings = findings(report)
+
+	return report, nil
+}
+
+// inspectFinalityProviders reports the finality providers of the delegation and,
+// for the slashed ones, whether the BTC slasher can build the slashing txs
+func (i *Inspector) inspectFinalityProviders(
+	btcDel *bstypes.BTCDelegationResponse,
+	params *bstypes.Params,
+) ([]FinalityProviderReport, error) {
+	var fpReports []FinalityProviderReport
+	evidences := make(map[string]*ftypes.Evidence)
+	evidencesLoaded := false
+	for _, fpBTCPK := range btcDel.FpBtcPkList {
+		fpBTCPKHex := fpBTCPK.MarshalHex()
+		fpReport := FinalityProviderReport{BtcPk: fpBTCPKHex}
+		fpResp, err := i.bbnClient.FinalityProvider(fpBTCPKHex)
+		if err != nil {
+			fpReport.Error = fmt.Sprintf("failed to get finality provider: %v", err)
+			fpReports = append(fpReports, fpReport)
+			continue
+		}
+		fpReport.SlashedBabylonHeight = fpResp.FinalityProvider.SlashedBabylonHeight
+		fpReport.SlashedBtcHeight = fpResp.FinalityProvider.SlashedBtcHeight
+		fpReport.Slashed = fpReport.SlashedBabylonHeight > 0
+		if !fpReport.Slashed {
+			fpReports = append(fpReports, fpReport)
+			continue
+		}
+
+		if !evidencesLoaded {
+			if err := i.loadEvidences(evidences); err != nil {
+				return nil, err
+			}
+			evidencesLoaded = true
+		}
+		evidence, ok := evidences[fpBTCPKHex]
+		if !ok {
+			fpReports = append(fpReports, fpReport)
+			continue
+		}
+		fpReport.EvidenceHeight = evidence.BlockHeight
+		fpBTCSK, err := evidence.ExtractBTCSK()
+		if err != nil {
+			fpReport.Error = fmt.Sprintf("failed to extract secret key from evidence: %v", err)
+			fpReports = append(fpReports, fpReport)
+			continue
+		}
+		fpReport.SlashingTxWitness = buildWitness(btcslasher.BuildSlashingTxWithWitness, btcDel, params, i.btcParams, fpBTCSK)
+		if btcDel.UndelegationResponse != nil {
+			fpReport.UnbondingSlashingTxWitness = buildWitness(btcslasher.BuildUnbondingSlashingTxWithWitness, btcDel, params, i.btcParams, fpBTCSK)
+		}
+		fpReports = append(fpReports, fpReport)
+	}
+
+	return fpReports, nil
+}
+
+// inspectTx returns whether the tx is known to the Bitcoin node. Unless the
+// node indexes all txs, confirmed txs not related to its wallet are not found.
+func (i *Inspector) inspectTx(txHash chainhash.Hash) (TxReport, *wire.MsgTx) {
+	tx, err := i.btcClient.GetRawTransaction(&txHash)
+	if err != nil {
+		return TxReport{Hash: txHash.String()}, nil
+	}
+	return TxReport{Hash: txHash.String(), OnBitcoin: true}, tx.MsgTx()
+}
+
+func (i *Inspector) inspectOutput(txBytes []byte, outIdx uint32) *OutputReport {
+	spendable, err := btcslasher.IsTaprootOutputSpendable(i.btcClient, txBytes, outIdx)
+	if err != nil {
+		return &OutputReport{Index: outIdx, Error: err.Error()}
+	}
+	return &OutputReport{Index: outIdx, Spendable: spendable}
+}
+
+func (i *Inspector) loadEvidences(evidences map[string]*ftypes.Evidence) error {
+	pagination := query.PageRequest{Limit: defaultPaginationLimit}
+	for {
+		resp, err := i.bbnClient.ListEvidences(0, &pagination)
+		if err != nil {
+			return fmt.Errorf("failed to get evidences: %w", err)
+		}
+		for _, evidence := range resp.Evidences {
+			evidences[evidence.FpBtcPk.MarshalHex()] = evidence
+		}
+		if resp.Pagination == nil || resp.Pagination.NextKey == nil {
+			return nil
+		}
+		pagination.Key = resp.Pagination.NextKey
+	}
+}
+
+// classify classifies the tx as a spend of the staking or unbonding output of the delegation
+func classify(tx *wire.MsgTx, td *unbondingwatcher.TrackedDelegation) SpendReport {
+	stakingOutPoint := wire.OutPoint{Hash: td.StakingTx.TxHash(), Index: td.StakingOutputIdx}
+	unbondingOutPoint := wire.OutPoint{Hash: td.UnbondingTxHash, Index: 0}
+
+	spendReport := SpendReport{SpendingTxHash: tx.TxHash().String()}
+	var classification *unbondingwatcher.SpendClassification
+	for _, txIn := range tx.TxIn {
+		if txIn.PreviousOutPoint == stakingOutPoint {
+			spendReport.Output = outputStaking
+			classification = unbondingwatcher.ClassifySpend(tx, td)
+			break
+		}
+		if txIn.PreviousOutPoint == unbondingOutPoint {
+			spendReport.Output = outputUnbonding
+			classification = unbondingwatcher.ClassifyUnbondingSpend(tx, td)
+			break
+		}
+	}
+	if classification == nil {
+		spendReport.Type = unbondingwatcher.SpendTypeUnknown.String()
+		spendReport.Reason = "tx spends neither the staking nor the unbonding output"
+		return spendReport
+	}
+
+	spendReport.Type = classification.Type.String()
+	if classification.Reason != nil {
+		spendReport.Reason = classification.Reason.Error()
+	}
+	return spendReport
+}
+
+type slashingTxBuilder func(
+	d *bstypes.BTCDelegationResponse,
+	bsParams *bstypes.Params,
+	btcNet *chaincfg.Params,
+	fpSK *btcec.PrivateKey,
+) (*wire.MsgTx, error)
+
+func buildWitness(
+	build slashingTxBuilder,
+	d *bstypes.BTCDelegationResponse,
+	bsParams *bstypes.Params,
+	btcNet *chaincfg.Params,
+	fpSK *btcec.PrivateKey,
+) *WitnessReport {
+	if _, err := build(d, bsParams, btcNet, fpSK); err != nil {
+		return &WitnessReport{Error: err.Error()}
+	}
+	return &WitnessReport{Built: true}
+}
@@ -0,0 +1,322 @@
+package inspector_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bbn "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	ftypes "github.com/babylonchain/babylon/x/finality/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/inspector"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/unbondingwatcher"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+)
+
+var testNet = &chaincfg.SimNetParams
+
+// testDelegation is an active BTC delegation to a single finality provider
+type testDelegation struct {
+	resp        *bstypes.BTCDelegationResponse
+	params      *bstypes.Params
+	del         *unbondingwatcher.Delegation
+	delSK       *btcec.PrivateKey
+	fpSK        *btcec.PrivateKey
+	fpBTCPKHex  string
+	stakingTx   *wire.MsgTx
+	unbondingTx *wire.MsgTx
+	slashingTx  *wire.MsgTx
+}
+
+func genTestDelegation(t *testing.T, r *rand.Rand) *testDelegation {
+	covenantSK, covenantPK, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(t, err)
+	params := &bstypes.Params{
+		CovenantQuorum: 1,
+		CovenantPks:    []bbn.BIP340PubKey{*bbn.NewBIP340PubKeyFromBTCPK(covenantPK)},
+		SlashingRate:   sdkmath.LegacyMustNewDecFromStr("0.1"),
+	}
+	fpSK, fpPK, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(t, err)
+	fpBTCPK := bbn.NewBIP340PubKeyFromBTCPK(fpPK)
+	delSK, _, err := datagen.GenRandomBTCKeyPair(r)
+	require.NoError(t, err)
+	slashingAddr, err := datagen.GenRandomBTCAddress(r, testNet)
+	require.NoError(t, err)
+
+	btcDel, err := datagen.GenRandomBTCDelegation(
+		r,
+		t,
+		testNet,
+		[]bbn.BIP340PubKey{*fpBTCPK},
+		delSK,
+		[]*btcec.PrivateKey{covenantSK},
+		[]*btcec.PublicKey{covenantPK},
+		params.CovenantQuorum,
+		slashingAddr.String(),
+		100,
+		1100,
+		datagen.RandomInt(r, 100000)+10000,
+		params.SlashingRate,
+		101,
+	)
+	require.NoError(t, err)
+	resp := bstypes.NewBTCDelegationResponse(btcDel, bstypes.BTCDelegationStatus_ACTIVE)
+	del, err := unbondingwatcher.DelegationFromResponse(resp, params, testNet)
+	require.NoError(t, err)
+
+	stakingTx, _, err := bbn.NewBTCTxFromHex(resp.StakingTxHex)
+	require.NoError(t, err)
+	unbondingTx, _, err := bbn.NewBTCTxFromHex(resp.UndelegationResponse.UnbondingTxHex)
+	require.NoError(t, err)
+	slashingTx, err := bstypes.NewBTCSlashingTxFromHex(resp.SlashingTxHex)
+	require.NoError(t, err)
+	slashingMsgTx, err := slashingTx.ToMsgTx()
+	require.NoError(t, err)
+
+	return &testDelegation{
+		resp:        resp,
+		params:      params,
+		del:         del,
+		delSK:       delSK,
+		fpSK:        fpSK,
+		fpBTCPKHex:  fpBTCPK.MarshalHex(),
+		stakingTx:   stakingTx,
+		unbondingTx: unbondingTx,
+		slashingTx:  slashingMsgTx,
+	}
+}
+
+// withWitness returns the tx whose first input spends through the given script,
+// with dummy signatures
+func (td *testDelegation) withWitness(t *testing.T, r *rand.Rand, tx *wire.MsgTx, script []byte) *wire.MsgTx {
+	sig, err := schnorr.Sign(td.delSK, datagen.GenRandomByteArray(r, 32))
+	require.NoError(t, err)
+	spendingTx := tx.Copy()
+	spendingTx.TxIn[0].Witness = wire.TxWitness{
+		sig.Serialize(),
+		sig.Serialize(),
+		script,
+		datagen.GenRandomByteArray(r, 33),
+	}
+	return spendingTx
+}
+
+// newTestInspector mocks a Bitcoin node knowing the given txs, whose unspent
+// outputs are those of the given txs
+func newTestInspector(
+	t *testing.T,
+	td *testDelegation,
+	onBitcoin []*wire.MsgTx,
+	unspent []*wire.MsgTx,
+) (*inspector.Inspector, *inspector.MockBabylonQueryClient) {
+	ctrl := gomock.NewController(t)
+	mockBabylonClient := inspector.NewMockBabylonQueryClient(ctrl)
+	mockBTCClient := mocks.NewMockBTCClient(ctrl)
+
+	stakingTxHash := td.stakingTx.TxHash()
+	mockBabylonClient.EXPECT().BTCDelegation(stakingTxHash.String()).
+		Return(&bstypes.QueryBTCDelegationResponse{BtcDelegation: td.resp}, nil)
+	mockBabylonClient.EXPECT().BTCStakingParamsByVersion(td.resp.ParamsVersion).
+		Return(&bstypes.QueryParamsByVersionResponse{Params: *td.params}, nil)
+
+	txs := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, tx := range onBitcoin {
+		txs[tx.TxHash()] = tx
+	}
+	mockBTCClient.EXPECT().GetRawTransaction(gomock.Any()).DoAndReturn(
+		func(txHash *chainhash.Hash) (*btcutil.Tx, error) {
+			tx, ok := txs[*txHash]
+			if !ok {
+				return nil, errors.New("no such mempool or blockchain transaction")
+			}
+			return btcutil.NewTx(tx), nil
+		}).AnyTimes()
+	unspentTxs := make(map[chainhash.Hash]bool)
+	for _, tx := range unspent {
+		unspentTxs[tx.TxHash()] = true
+	}
+	mockBTCClient.EXPECT().GetTxOut(gomock.Any(), gomock.Any(), true).DoAndReturn(
+		func(txHash *chainhash.Hash, _ uint32, _ bool) (*btcjson.GetTxOutResult, error) {
+			if !unspentTxs[*txHash] {
+				return nil, nil
+			}
+			return &btcjson.GetTxOutResult{}, nil
+		}).AnyTimes()
+
+	return inspector.New(mockBabylonClient, mockBTCClient, testNet), mockBabylonClient
+}
+
+func notSlashed(fpBTCPKHex string, mockBabylonClient *inspector.MockBabylonQueryClient) {
+	mockBabylonClient.EXPECT().FinalityProvider(fpBTCPKHex).Return(&bstypes.QueryFinalityProviderResponse{
+		FinalityProvider: &bstypes.FinalityProviderResponse{},
+	}, nil)
+}
+
+func TestInspectActiveDelegation(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	td := genTestDelegation(t, r)
+	insp, mockBabylonClient := newTestInspector(t, td, []*wire.MsgTx{td.stakingTx}, []*wire.MsgTx{td.stakingTx})
+	notSlashed(td.fpBTCPKHex, mockBabylonClient)
+
+	report, err := insp.Inspect(td.stakingTx.TxHash(), nil)
+	require.NoError(t, err)
+	require.True(t, report.Active)
+	require.True(t, report.StakingTx.OnBitcoin)
+	require.True(t, report.StakingTx.Output.Spendable)
+	require.False(t, report.UnbondingTx.OnBitcoin)
+	require.Nil(t, report.UnbondingTx.Output)
+	require.False(t, report.SlashingTx.OnBitcoin)
+	require.Empty(t, report.Spends)
+	require.Len(t, report.FinalityProviders, 1)
+	require.False(t, report.FinalityProviders[0].Slashed)
+	require.Empty(t, report.Findings)
+}
+
+func TestInspectUnbondedDelegation(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	td := genTestDelegation(t, r)
+	unbondingTx := td.withWitness(t, r, td.unbondingTx, td.del.StakingScripts.Unbonding)
+	insp, mockBabylonClient := newTestInspector(t, td, []*wire.MsgTx{td.stakingTx, unbondingTx}, []*wire.MsgTx{unbondingTx})
+	notSlashed(td.fpBTCPKHex, mockBabylonClient)
+
+	// the unbonding is not reported to Babylon yet
+	report, err := insp.Inspect(td.stakingTx.TxHash(), nil)
+	require.NoError(t, err)
+	require.False(t, report.StakingTx.Output.Spendable)
+	require.True(t, report.UnbondingTx.OnBitcoin)
+	require.True(t, report.UnbondingTx.Output.Spendable)
+	require.Len(t, report.Spends, 1)
+	require.Equal(t, unbondingTx.TxHash().String(), report.Spends[0].SpendingTxHash)
+	require.Equal(t, "staking", report.Spends[0].Output)
+	require.Equal(t, unbondingwatcher.SpendTypeUnbonding.String(), report.Spends[0].Type)
+	require.Len(t, report.Findings, 1)
+	require.Contains(t, report.Findings[0], "the unbonding watcher should report the unbonding")
+
+	// the unbonding is reported to Babylon
+	td.resp.Active = false
+	insp, mockBabylonClient = newTestInspector(t, td, []*wire.MsgTx{td.stakingTx, unbondingTx}, []*wire.MsgTx{unbondingTx})
+	notSlashed(td.fpBTCPKHex, mockBabylonClient)
+	report, err = insp.Inspect(td.stakingTx.TxHash(), nil)
+	require.NoError(t, err)
+	require.Empty(t, report.Findings)
+}
+
+func TestInspectSlashedDelegation(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	td := genTestDelegation(t, r)
+	evidence, err := datagen.GenRandomEvidence(r, td.fpSK, 100)
+	require.NoError(t, err)
+	slashed := func(mockBabylonClient *inspector.MockBabylonQueryClient) {
+		mockBabylonClient.EXPECT().FinalityProvider(td.fpBTCPKHex).Return(&bstypes.QueryFinalityProviderResponse{
+			FinalityProvider: &bstypes.FinalityProviderResponse{SlashedBabylonHeight: 100, SlashedBtcHeight: 1000},
+		}, nil)
+		mockBabylonClient.EXPECT().ListEvidences(uint64(0), gomock.Any()).Return(&ftypes.QueryListEvidencesResponse{
+			Evidences:  []*ftypes.Evidence{evidence},
+			Pagination: &query.PageResponse{},
+		}, nil)
+	}
+
+	// the finality provider is slashed, but the delegation is not slashed on Bitcoin yet
+	insp, mockBabylonClient := newTestInspector(t, td, []*wire.MsgTx{td.stakingTx}, []*wire.MsgTx{td.stakingTx})
+	slashed(mockBabylonClient)
+	report, err := insp.Inspect(td.stakingTx.TxHash(), nil)
+	require.NoError(t, err)
+	require.Len(t, report.FinalityProviders, 1)
+	fpReport := report.FinalityProviders[0]
+	require.True(t, fpReport.Slashed)
+	require.Equal(t, evidence.BlockHeight, fpReport.EvidenceHeight)
+	require.Empty(t, fpReport.Error)
+	require.True(t, fpReport.SlashingTxWitness.Built)
+	require.True(t, fpReport.UnbondingSlashingTxWitness.Built)
+	require.Len(t, report.Findings, 1)
+	require.Contains(t, report.Findings[0], "the BTC slasher should slash it")
+
+	// the delegation is slashed on Bitcoin
+	slashingTx := td.withWitness(t, r, td.slashingTx, td.del.StakingScripts.Slashing)
+	insp, mockBabylonClient = newTestInspector(t, td, []*wire.MsgTx{td.stakingTx, slashingTx}, nil)
+	slashed(mockBabylonClient)
+	report, err = insp.Inspect(td.stakingTx.TxHash(), nil)
+	require.NoError(t, err)
+	require.True(t, report.SlashingTx.OnBitcoin)
+	require.False(t, report.StakingTx.Output.Spendable)
+	require.Len(t, report.Spends, 1)
+	require.Equal(t, "staking", report.Spends[0].Output)
+	require.Equal(t, unbondingwatcher.SpendTypeSlashing.String(), report.Spends[0].Type)
+	require.Empty(t, report.Findings)
+}
+
+func TestInspectUnknownSpend(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	td := genTestDelegation(t, r)
+
+	// a tx spending the staking output through a script not in the staking output
+	stakingTxHash := td.stakingTx.TxHash()
+	spendingTx := wire.NewMsgTx(2)
+	spendingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&stakingTxHash, td.del.StakingOutputIdx), nil, nil))
+	spendingTx.AddTxOut(wire.NewTxOut(int64(datagen.RandomInt(r, 10000)+1), datagen.GenRandomByteArray(r, 34)))
+	spendingTx = td.withWitness(t, r, spendingTx, datagen.GenRandomByteArray(r, 40))
+	// a tx spending neither output of the delegation
+	unrelatedTx := td.withWitness(t, r, spendingTx, datagen.GenRandomByteArray(r, 40))
+	unrelatedTx.TxIn[0].PreviousOutPoint = *wire.NewOutPoint((*chainhash.Hash)(datagen.GenRandomByteArray(r, 32)), r.Uint32())
+	onBitcoin := []*wire.MsgTx{td.stakingTx, spendingTx, unrelatedTx}
+	insp, mockBabylonClient := newTestInspector(t, td, onBitcoin, nil)
+	notSlashed(td.fpBTCPKHex, mockBabylonClient)
+
+	spendingTxHash := spendingTx.TxHash()
+	report, err := insp.Inspect(stakingTxHash, &spendingTxHash)
+	require.NoError(t, err)
+	require.Len(t, report.Spends, 1)
+	require.Equal(t, spendingTxHash.String(), report.Spends[0].SpendingTxHash)
+	require.Equal(t, "staking", report.Spends[0].Output)
+	require.Equal(t, unbondingwatcher.SpendTypeUnknown.String(), report.Spends[0].Type)
+	require.NotEmpty(t, report.Spends[0].Reason)
+	require.Len(t, report.Findings, 1)
+	require.Contains(t, report.Findings[0], "unknown spend")
+
+	// a tx spending neither output is unknown, but not a finding
+	insp, mockBabylonClient = newTestInspector(t, td, onBitcoin, nil)
+	notSlashed(td.fpBTCPKHex, mockBabylonClient)
+	unrelatedTxHash := unrelatedTx.TxHash()
+	report, err = insp.Inspect(stakingTxHash, &unrelatedTxHash)
+	require.NoError(t, err)
+	require.Len(t, report.Spends, 1)
+	require.Empty(t, report.Spends[0].Output)
+	require.Equal(t, unbondingwatcher.SpendTypeUnknown.String(), report.Spends[0].Type)
+	require.Empty(t, report.Findings)
+}
+
+func TestInspectDelegationWithoutUnbondingInfo(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	td := genTestDelegation(t, r)
+	td.resp.UndelegationResponse = nil
+	insp, mockBabylonClient := newTestInspector(t, td, []*wire.MsgTx{td.stakingTx}, []*wire.MsgTx{td.stakingTx})
+	notSlashed(td.fpBTCPKHex, mockBabylonClient)
+
+	spendingTxHash := chainhash.Hash(datagen.GenRandomByteArray(r, chainhash.HashSize))
+	report, err := insp.Inspect(td.stakingTx.TxHash(), &spendingTxHash)
+	require.NoError(t, err)
+	require.True(t, report.UnbondingInfoAbsent)
+	require.True(t, report.StakingTx.OnBitcoin)
+	require.True(t, report.StakingTx.Output.Spendable)
+	require.Empty(t, report.UnbondingTx.Hash)
+	require.Len(t, report.Spends, 1)
+	require.Equal(t, unbondingwatcher.SpendTypeUnknown.String(), report.Spends[0].Type)
+	require.Len(t, report.FinalityProviders, 1)
+	require.Empty(t, report.Findings)
+	require.Contains(t, report.String(), "unbonding info:     absent on Babylon")
+}
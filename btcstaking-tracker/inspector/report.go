@@ -0,0 +1,226 @@
+package inspector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/unbondingwatcher"
+)
+
+// Report is the lifecycle of a BTC delegation on Babylon and on Bitcoin
+type Report struct {
+	StakingTxHash string `json:"staking_tx_hash"`
+	StakerPk      string `json:"staker_pk"`
+	// status of the delegation on Babylon
+	Status        string `json:"status"`
+	Active        bool   `json:"active"`
+	StartHeight   uint64 `json:"start_height"`
+	EndHeight     uint64 `json:"end_height"`
+	ParamsVersion uint32 `json:"params_version"`
+	// the delegation has no unbonding tx on Babylon, so neither the unbonding
+	// and slashing txs nor the spends of the delegation are inspected
+	UnbondingInfoAbsent bool `json:"unbonding_info_absent"`
+
+	StakingTx           TxReport `json:"staking_tx"`
+	UnbondingTx         TxReport `json:"unbonding_tx"`
+	SlashingTx          TxReport `json:"slashing_tx"`
+	UnbondingSlashingTx TxReport `json:"unbonding_slashing_tx"`
+
+	Spends            []SpendReport            `json:"spends"`
+	FinalityProviders []FinalityProviderReport `json:"finality_providers"`
+	// inconsistencies between Babylon and Bitcoin the tracker should act upon
+	Findings []string `json:"findings"`
+}
+
+type TxReport struct {
+	Hash      string `json:"hash"`
+	OnBitcoin bool   `json:"on_bitcoin"`
+	// only set for the staking output, and the unbonding output once the unbonding
+	// tx is on Bitcoin
+	Output *OutputReport `json:"output,omitempty"`
+}
+
+type OutputReport struct {
+	Index     uint32 `json:"index"`
+	Spendable bool   `json:"spendable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SpendReport is the classification of a tx spending the staking or unbonding output
+type SpendReport struct {
+	SpendingTxHash string `json:"spending_tx_hash"`
+	// staking or unbonding, empty if the tx spends neither
+	Output string `json:"output"`
+	Type   string `json:"type"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type FinalityProviderReport struct {
+	BtcPk                string `json:"btc_pk"`
+	Slashed              bool   `json:"slashed"`
+	SlashedBabylonHeight uint64 `json:"slashed_babylon_height,omitempty"`
+	SlashedBtcHeight     uint64 `json:"slashed_btc_height,omitempty"`
+	// height of the equivocation evidence, only set if the evidence is found
+	EvidenceHeight uint64 `json:"evidence_height,omitempty"`
+	// only set if the secret key of the slashed finality provider is extracted
+	SlashingTxWitness          *WitnessReport `json:"slashing_tx_witness,omitempty"`
+	UnbondingSlashingTxWitness *WitnessReport `json:"unbonding_slashing_tx_witness,omitempty"`
+	Error                      string         `json:"error,omitempty"`
+}
+
+// WitnessReport is the result of assembling the witness of a slashing tx
+type WitnessReport struct {
+	Built bool   `json:"built"`
+	Error string `json:"error,omitempty"`
+}
+
+func (r *Report) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "BTC delegation %s\n", r.StakingTxHash)
+	fmt.Fprintf(&sb, "  staker:          %s\n", r.StakerPk)
+	fmt.Fprintf(&sb, "  status:          %s (active: %t)\n", r.Status, r.Active)
+	fmt.Fprintf(&sb, "  BTC heights:     [%d, %d]\n", r.StartHeight, r.EndHeight)
+	fmt.Fprintf(&sb, "  params version:  %d\n", r.ParamsVersion)
+
+	sb.WriteString("\nTransactions on Bitcoin\n")
+	writeTx(&sb, "staking", r.StakingTx)
+	if r.UnbondingInfoAbsent {
+		sb.WriteString("  unbonding info:     absent on Babylon\n")
+	} else {
+		writeTx(&sb, "unbonding", r.UnbondingTx)
+		writeTx(&sb, "slashing", r.SlashingTx)
+		writeTx(&sb, "unbonding slashing", r.UnbondingSlashingTx)
+	}
+
+	sb.WriteString("\nSpends\n")
+	if len(r.Spends) == 0 {
+		sb.WriteString("  none found\n")
+	}
+	for _, spend := range r.Spends {
+		output := spend.Output
+		if output == "" {
+			output = "no"
+		}
+		fmt.Fprintf(&sb, "  %s spends %s output: %s\n", spend.SpendingTxHash, output, spend.Type)
+		if spend.Reason != "" {
+			fmt.Fprintf(&sb, "    reason: %s\n", spend.Reason)
+		}
+	}
+
+	sb.WriteString("\nFinality providers\n")
+	for _, fp := range r.FinalityProviders {
+		switch {
+		case fp.Error != "" && !fp.Slashed:
+			fmt.Fprintf(&sb, "  %s: %s\n", fp.BtcPk, fp.Error)
+			continue
+		case !fp.Slashed:
+			fmt.Fprintf(&sb, "  %s: not slashed\n", fp.BtcPk)
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s: slashed at Babylon height %d, BTC height %d\n", fp.BtcPk, fp.SlashedBabylonHeight, fp.SlashedBtcHeight)
+		if fp.EvidenceHeight == 0 {
+			sb.WriteString("    no equivocation evidence\n")
+		} else {
+			fmt.Fprintf(&sb, "    equivocation evidence at height %d\n", fp.EvidenceHeight)
+		}
+		if fp.Error != "" {
+			fmt.Fprintf(&sb, "    %s\n", fp.Error)
+		}
+		writeWitness(&sb, "slashing", fp.SlashingTxWitness)
+		writeWitness(&sb, "unbonding slashing", fp.UnbondingSlashingTxWitness)
+	}
+
+	sb.WriteString("\nFindings\n")
+	if len(r.Findings) == 0 {
+		sb.WriteString("  none\n")
+	}
+	for _, finding := range r.Findings {
+		fmt.Fprintf(&sb, "  - %s\n", finding)
+	}
+
+	return sb.String()
+}
+
+func writeTx(sb *strings.Builder, name string, tx TxReport) {
+	onBitcoin := "not found"
+	if tx.OnBitcoin {
+		onBitcoin = "found"
+	}
+	fmt.Fprintf(sb, "  %-19s %s %s\n", name+":", tx.Hash, onBitcoin)
+	if tx.Output == nil {
+		return
+	}
+	switch {
+	case tx.Output.Error != "":
+		fmt.Fprintf(sb, "    output %d: %s\n", tx.Output.Index, tx.Output.Error)
+	case tx.Output.Spendable:
+		fmt.Fprintf(sb, "    output %d: spendable\n", tx.Output.Index)
+	default:
+		fmt.Fprintf(sb, "    output %d: spent\n", tx.Output.Index)
+	}
+}
+
+func writeWitness(sb *strings.Builder, name string, witness *WitnessReport) {
+	if witness == nil {
+		return
+	}
+	if witness.Built {
+		fmt.Fprintf(sb, "    %s tx witness: built\n", name)
+	} else {
+		fmt.Fprintf(sb, "    %s tx witness: %s\n", name, witness.Error)
+	}
+}
+
+// findings lists the inconsistencies between the state of the delegation on
+// Babylon and on Bitcoin, i.e., what the BTC staking tracker is expected to fix
+func findings(r *Report) []string {
+	var found []string
+
+	stakingSpendable := r.StakingTx.Output != nil && r.StakingTx.Output.Spendable
+	unbondingSpendable := r.UnbondingTx.Output != nil && r.UnbondingTx.Output.Spendable
+
+	for _, spend := range r.Spends {
+		switch spend.Type {
+		case unbondingwatcher.SpendTypeUnbonding.String():
+			if r.Active {
+				found = append(found, fmt.Sprintf(
+					"staking output is unbonded by %s, but the delegation is still active on Babylon: the unbonding watcher should report the unbonding",
+					spend.SpendingTxHash))
+			}
+		case unbondingwatcher.SpendTypeUnknown.String():
+			if spend.Output != "" {
+				found = append(found, fmt.Sprintf("unknown spend %s of the %s output: %s", spend.SpendingTxHash, spend.Output, spend.Reason))
+			}
+		}
+	}
+
+	for _, fp := range r.FinalityProviders {
+		if !fp.Slashed {
+			continue
+		}
+		if !stakingSpendable && !unbondingSpendable {
+			continue
+		}
+		if fp.EvidenceHeight == 0 {
+			found = append(found, fmt.Sprintf(
+				"finality provider %s is slashed without an equivocation evidence, while the delegation can still be slashed: the BTC slasher cannot extract its secret key",
+				fp.BtcPk))
+			continue
+		}
+		witness := fp.SlashingTxWitness
+		if !stakingSpendable {
+			witness = fp.UnbondingSlashingTxWitness
+		}
+		if witness == nil || !witness.Built {
+			found = append(found, fmt.Sprintf(
+				"finality provider %s is slashed, but the slashing tx of the delegation cannot be built", fp.BtcPk))
+			continue
+		}
+		found = append(found, fmt.Sprintf(
+			"finality provider %s is slashed, but the delegation is not slashed on Bitcoin: the BTC slasher should slash it",
+			fp.BtcPk))
+	}
+
+	return found
+}
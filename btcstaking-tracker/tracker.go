@@ -1,12 +1,16 @@
 package btcstaking_tracker
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
 	bbnclient "github.com/babylonchain/babylon/client/client"
+	"github.com/babylonchain/vigilante/alert"
 	"github.com/babylonchain/vigilante/btcclient"
 	"github.com/babylonchain/vigilante/btcstaking-tracker/atomicslasher"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnscheduler"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
 	"github.com/babylonchain/vigilante/btcstaking-tracker/btcslasher"
 	uw "github.com/babylonchain/vigilante/btcstaking-tracker/unbondingwatcher"
 	"github.com/babylonchain/vigilante/config"
@@ -23,9 +27,14 @@ type BTCStakingTracker struct {
 
 	btcClient   btcclient.BTCClient // TODO: limit the scope
 	btcNotifier notifier.ChainNotifier
-	// TODO: Ultimately all requests to babylon should go through some kind of semaphore
-	// to avoid spamming babylon with requests
-	bbnClient *bbnclient.Client
+	bbnClient   *bbnclient.Client
+	// bbnScheduler bounds the requests of all routines to Babylon, so that
+	// many tracked BTC delegations do not flood the Babylon node
+	bbnScheduler *bbnscheduler.Scheduler
+	// cancels the requests to Babylon waiting in the scheduler once the tracker quits
+	cancelBbnRequests context.CancelFunc
+	// blockStream fetches each BTC block once and sends it to all routines
+	blockStream *blockstream.BlockStream
 
 	// unbondingWatcher monitors early unbonding transactions on Bitcoin
 	// and reports unbonding BTC delegations back to Babylon
@@ -48,6 +57,9 @@ type BTCStakingTracker struct {
 	// in the BTC slasher consumes the channel.
 	slashedFPSKChan chan *btcec.PrivateKey
 
+	// sends alerts on unexpected spends of staking outputs
+	alerts *alert.Dispatcher
+
 	metrics *metrics.BTCStakingTrackerMetrics
 
 	startOnce sync.Once
@@ -58,7 +70,10 @@ type BTCStakingTracker struct {
 func NewBTCSTakingTracker(
 	btcClient btcclient.BTCClient,
 	btcNotifier notifier.ChainNotifier,
+	hintCache btcclient.HintCache,
 	bbnClient *bbnclient.Client,
+	feeBumper *btcslasher.FeeBumper,
+	slashingTxStore *btcslasher.SlashingTxStore,
 	cfg *config.BTCStakingTrackerConfig,
 	commonCfg *config.CommonConfig,
 	parentLogger *zap.Logger,
@@ -66,20 +81,28 @@ func NewBTCSTakingTracker(
 ) *BTCStakingTracker {
 	logger := parentLogger.With(zap.String("module", "btcstaking-tracker"))
 
+	btcParams, err := netparams.GetBTCParams(cfg.BTCNetParams)
+	if err != nil {
+		parentLogger.Fatal("failed to get BTC parameter", zap.Error(err))
+	}
+
+	alerts := alert.NewDispatcher(&cfg.Alert, alert.NewSinksFromConfig(&cfg.Alert), logger, metrics.AlertMetrics)
+
+	bbnScheduler := bbnscheduler.New(&cfg.BabylonRequests, logger, metrics.BabylonSchedulerMetrics)
+	bbnRequestsCtx, cancelBbnRequests := context.WithCancel(context.Background())
+
+	blockStream := blockstream.New(btcNotifier, btcClient, cfg.BlockStreamBufferSize, logger, metrics.BlockStreamMetrics)
+
 	// watcher routine
-	babylonAdapter := uw.NewBabylonClientAdapter(bbnClient)
-	watcher := uw.NewUnbondingWatcher(btcNotifier, babylonAdapter, cfg, logger, metrics.UnbondingWatcherMetrics)
+	babylonAdapter := uw.NewScheduledBabylonNodeAdapter(bbnRequestsCtx, uw.NewBabylonClientAdapter(bbnClient, btcParams), bbnScheduler)
+	watcher := uw.NewUnbondingWatcher(btcNotifier, blockStream, hintCache, alerts, babylonAdapter, cfg, logger, metrics.UnbondingWatcherMetrics)
 
 	slashedFPSKChan := make(chan *btcec.PrivateKey, 100) // TODO: parameterise buffer size
 
 	// BTC slasher routine
 	// NOTE: To make subscriber in slasher work, the underlying RPC client
 	// has to be kept running with a websocket connection
-	bbnQueryClient := bbnClient.QueryClient
-	btcParams, err := netparams.GetBTCParams(cfg.BTCNetParams)
-	if err != nil {
-		parentLogger.Fatal("failed to get BTC parameter", zap.Error(err))
-	}
+	bbnQueryClient := btcslasher.NewScheduledBabylonQueryClient(bbnRequestsCtx, bbnClient.QueryClient, bbnScheduler)
 	btcSlasher, err := btcslasher.New(
 		logger,
 		btcClient,
@@ -87,6 +110,11 @@ func NewBTCSTakingTracker(
 		btcParams,
 		commonCfg.RetrySleepTime,
 		commonCfg.MaxRetrySleepTime,
+		&cfg.SlashingTxs,
+		blockStream,
+		feeBumper,
+		alerts,
+		slashingTxStore,
 		slashedFPSKChan,
 		metrics.SlasherMetrics,
 	)
@@ -100,25 +128,28 @@ func NewBTCSTakingTracker(
 		logger,
 		commonCfg.RetrySleepTime,
 		commonCfg.MaxRetrySleepTime,
-		btcClient,
-		btcNotifier,
-		bbnClient,
+		blockStream,
+		atomicslasher.NewScheduledBabylonClient(bbnRequestsCtx, bbnClient, bbnScheduler),
 		slashedFPSKChan,
 		metrics.AtomicSlasherMetrics,
 	)
 
 	return &BTCStakingTracker{
-		cfg:              cfg,
-		logger:           logger.Sugar(),
-		btcClient:        btcClient,
-		btcNotifier:      btcNotifier,
-		bbnClient:        bbnClient,
-		btcSlasher:       btcSlasher,
-		atomicSlasher:    atomicSlasher,
-		unbondingWatcher: watcher,
-		slashedFPSKChan:  slashedFPSKChan,
-		metrics:          metrics,
-		quit:             make(chan struct{}),
+		cfg:               cfg,
+		logger:            logger.Sugar(),
+		btcClient:         btcClient,
+		btcNotifier:       btcNotifier,
+		bbnClient:         bbnClient,
+		bbnScheduler:      bbnScheduler,
+		cancelBbnRequests: cancelBbnRequests,
+		blockStream:       blockStream,
+		btcSlasher:        btcSlasher,
+		atomicSlasher:     atomicSlasher,
+		unbondingWatcher:  watcher,
+		slashedFPSKChan:   slashedFPSKChan,
+		alerts:            alerts,
+		metrics:           metrics,
+		quit:              make(chan struct{}),
 	}
 }
 
@@ -138,6 +169,14 @@ func (tracker *BTCStakingTracker) Start() error {
 	tracker.startOnce.Do(func() {
 		tracker.logger.Info("starting BTC staking tracker")
 
+		tracker.alerts.Start()
+
+		// routines subscribe to the block stream as they start, and the unbonding
+		// watcher waits for the best block
+		if err := tracker.blockStream.Start(); err != nil {
+			startErr = err
+			return
+		}
 		if err := tracker.unbondingWatcher.Start(); err != nil {
 			startErr = err
 			return
@@ -162,6 +201,11 @@ func (tracker *BTCStakingTracker) Stop() error {
 	tracker.stopOnce.Do(func() {
 		tracker.logger.Info("stopping BTC staking tracker")
 
+		// fail queued requests to Babylon first, so that routines waiting for
+		// them do not hold up stopping
+		tracker.cancelBbnRequests()
+		tracker.bbnScheduler.Stop()
+
 		if err := tracker.unbondingWatcher.Stop(); err != nil {
 			stopErr = err
 			return
@@ -174,11 +218,17 @@ func (tracker *BTCStakingTracker) Stop() error {
 			stopErr = err
 			return
 		}
+		if err := tracker.blockStream.Stop(); err != nil {
+			stopErr = err
+			return
+		}
 		if err := tracker.bbnClient.Stop(); err != nil {
 			stopErr = err
 			return
 		}
 
+		tracker.alerts.Stop()
+
 		close(tracker.slashedFPSKChan)
 		close(tracker.quit)
 
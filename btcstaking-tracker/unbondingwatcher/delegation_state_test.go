@@ -0,0 +1,154 @@
+package unbondingwatcher
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSpendNotifier fails all spend registrations
+type failingSpendNotifier struct {
+	notifier.ChainNotifier
+}
+
+func (n *failingSpendNotifier) RegisterSpendNtfn(_ *wire.OutPoint, _ []byte, _ uint32) (*notifier.SpendEvent, error) {
+	return nil, errors.New("notifier is down")
+}
+
+func genTestDelegation(r *rand.Rand) *Delegation {
+	stakingTx := wire.NewMsgTx(wire.TxVersion)
+	stakingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, r.Uint32()), nil, nil))
+	stakingTx.AddTxOut(wire.NewTxOut(int64(r.Intn(100000)+1), datagen.GenRandomByteArray(r, 34)))
+	return &Delegation{
+		StakingTx:             stakingTx,
+		StakingOutputIdx:      0,
+		DelegationStartHeight: uint64(r.Intn(1000)),
+		UnbondingOutput:       wire.NewTxOut(int64(r.Intn(100000)+1), datagen.GenRandomByteArray(r, 34)),
+	}
+}
+
+func trackedDelegationsGauge(uw *UnbondingWatcher, state DelegationState) float64 {
+	return testutil.ToFloat64(uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(state.String()))
+}
+
+func TestStartUnbondingRollsBackOnRegistrationError(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	uw := newTestWatcher(&failingSpendNotifier{}, 1)
+	uw.quit = make(chan struct{})
+	uw.tracker = NewTrackedDelegations()
+	uw.delegationStateChan = make(chan *delegationStateChange)
+
+	delegation := genTestDelegation(r)
+	stakingTxHash := delegation.StakingTx.TxHash()
+	_, err := uw.tracker.AddDelegation(delegation.StakingTx, delegation.StakingOutputIdx, delegation.UnbondingOutput,
+		chainhash.Hash{}, chainhash.Hash{}, nil, nil)
+	require.NoError(t, err)
+	uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(DelegationStateActive.String()).Inc()
+
+	// the delegation stays active when its unbonding output cannot be watched
+	uw.startUnbonding(&delegationStateChange{
+		stakingTxHash:   stakingTxHash,
+		state:           DelegationStateUnbonding,
+		unbondingTxHash: chainhash.Hash(datagen.GenRandomByteArray(r, 32)),
+		unbondingHeight: int32(r.Intn(1000)),
+	})
+	del := uw.tracker.GetDelegation(stakingTxHash)
+	require.Equal(t, DelegationStateActive, del.State)
+	require.Equal(t, chainhash.Hash{}, del.UnbondingTxHash)
+	require.Equal(t, float64(1), trackedDelegationsGauge(uw, DelegationStateActive))
+	require.Zero(t, trackedDelegationsGauge(uw, DelegationStateUnbonding))
+
+	// the retry of the unbonding does not outlive the watcher
+	close(uw.quit)
+	uw.wg.Wait()
+}
+
+func TestCheckUnbondedBabylonDelegations(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	mockAdapter := NewMockBabylonNodeAdapter(gomock.NewController(t))
+	uw := newTestWatcher(nil, 1)
+	uw.cfg.NewDelegationsBatchSize = 2
+	uw.quit = make(chan struct{})
+	uw.tracker = NewTrackedDelegations()
+	uw.newDelegationChan = make(chan *newDelegation, 10)
+	uw.babylonNodeAdapter = mockAdapter
+
+	// a full page with an untracked delegation, a full page with an already tracked
+	// one, and a last page of delegations unbonded by their timelock
+	unbonded, tracked := genTestDelegation(r), genTestDelegation(r)
+	_, err := uw.tracker.AddDelegation(tracked.StakingTx, tracked.StakingOutputIdx, tracked.UnbondingOutput,
+		chainhash.Hash{}, chainhash.Hash{}, nil, nil)
+	require.NoError(t, err)
+	gomock.InOrder(
+		mockAdapter.EXPECT().UnbondedBtcDelegations(uint64(0), uint64(2)).Return([]Delegation{*unbonded}, uint64(2), nil),
+		mockAdapter.EXPECT().UnbondedBtcDelegations(uint64(2), uint64(2)).Return([]Delegation{*tracked}, uint64(2), nil),
+		mockAdapter.EXPECT().UnbondedBtcDelegations(uint64(4), uint64(2)).Return(nil, uint64(1), nil),
+	)
+	require.NoError(t, uw.checkUnbondedBabylonDelegations())
+
+	// only the untracked delegation is watched, from the start of the delegation
+	require.Len(t, uw.newDelegationChan, 1)
+	pushed := <-uw.newDelegationChan
+	require.Equal(t, unbonded.StakingTx.TxHash(), pushed.stakingTxHash)
+	require.Equal(t, unbonded.DelegationStartHeight, pushed.delegationStartHeight)
+}
+
+func TestSpendWorkersHandleAllSpends(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	mockAdapter := NewMockBabylonNodeAdapter(gomock.NewController(t))
+	mockAdapter.EXPECT().IsDelegationActive(gomock.Any()).Return(false, nil).AnyTimes()
+	uw := newTestWatcher(nil, 1)
+	uw.cfg.SpendWorkers = 1
+	uw.babylonNodeAdapter = mockAdapter
+	uw.quit = make(chan struct{})
+	uw.delegationStateChan = make(chan *delegationStateChange)
+	uw.watchSpendChan = make(chan *watchedSpend)
+	uw.spendJobChan = make(chan *watchedSpend)
+	uw.wg.Add(2)
+	go uw.pollSpends()
+	go uw.spendWorker()
+	defer func() {
+		close(uw.quit)
+		uw.wg.Wait()
+	}()
+
+	// the staking outputs of all delegations are spent at once
+	numDelegations := r.Intn(10) + 2
+	spentDelegations := make(map[chainhash.Hash]bool)
+	for i := 0; i < numDelegations; i++ {
+		delegation := genTestDelegation(r)
+		spendEvent := notifier.NewSpendEvent(func() {})
+		spendEvent.Spend <- genSpendDetail(r, int32(100+i))
+		sendOrFail(t, uw.watchSpendChan, &watchedSpend{
+			spendEvent: spendEvent,
+			td: &TrackedDelegation{
+				StakingTx:        delegation.StakingTx,
+				StakingOutputIdx: delegation.StakingOutputIdx,
+				UnbondingOutput:  delegation.UnbondingOutput,
+			},
+		})
+		spentDelegations[delegation.StakingTx.TxHash()] = true
+	}
+
+	// a single worker handles all the spends, one after another
+	for i := 0; i < numDelegations; i++ {
+		select {
+		case change := <-uw.delegationStateChan:
+			require.True(t, spentDelegations[change.stakingTxHash])
+			require.Equal(t, DelegationStateUnknownSpend, change.state)
+			delete(spentDelegations, change.stakingTxHash)
+		case <-time.After(3 * spendPollInterval):
+			t.Fatal("timed out waiting for the spends to be handled")
+		}
+	}
+	require.Zero(t, testutil.ToFloat64(uw.metrics.PendingSpendsGauge))
+}
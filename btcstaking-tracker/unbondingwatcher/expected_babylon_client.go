@@ -3,14 +3,20 @@ package unbondingwatcher
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"cosmossdk.io/errors"
+	"github.com/babylonchain/babylon/btcstaking"
 	bbnclient "github.com/babylonchain/babylon/client/client"
 	bbn "github.com/babylonchain/babylon/types"
 	btcstakingtypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnevents"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	"github.com/cosmos/cosmos-sdk/types/query"
 )
 
@@ -19,14 +25,27 @@ var (
 )
 
 type Delegation struct {
-	StakingTx             *wire.MsgTx
-	StakingOutputIdx      uint32
-	DelegationStartHeight uint64
-	UnbondingOutput       *wire.TxOut
+	StakingTx               *wire.MsgTx
+	StakingOutputIdx        uint32
+	DelegationStartHeight   uint64
+	UnbondingOutput         *wire.TxOut
+	SlashingTxHash          chainhash.Hash
+	UnbondingSlashingTxHash chainhash.Hash
+	StakingScripts          *StakingScripts
+	UnbondingScripts        *UnbondingScripts
 }
 
 type BabylonNodeAdapter interface {
+	// Subscriber subscribes to events of delegations becoming active
+	bbnevents.Subscriber
 	ActiveBtcDelegations(offset uint64, limit uint64) ([]Delegation, error)
+	// UnbondedBtcDelegations returns the delegations unbonded on babylon upon the
+	// unbonding tx of their staker, rather than the expiry of their timelock, in the
+	// given page of unbonded delegations, together with the size of the page
+	UnbondedBtcDelegations(offset uint64, limit uint64) ([]Delegation, uint64, error)
+	// ActiveBtcDelegation returns the delegation with the given staking tx hash, or nil
+	// if it is not active
+	ActiveBtcDelegation(stakingTxHash chainhash.Hash) (*Delegation, error)
 	IsDelegationActive(stakingTxHash chainhash.Hash) (bool, error)
 	ReportUnbonding(ctx context.Context, stakingTxHash chainhash.Hash, stakerUnbondingSig *schnorr.Signature) error
 	BtcClientTipHeight() (uint32, error)
@@ -34,16 +53,110 @@ type BabylonNodeAdapter interface {
 
 type BabylonClientAdapter struct {
 	babylonClient *bbnclient.Client
+	btcParams     *chaincfg.Params
+
+	// BTC staking parameters by version, which never change once created
+	paramsMu sync.Mutex
+	params   map[uint32]*btcstakingtypes.Params
 }
 
 var _ BabylonNodeAdapter = (*BabylonClientAdapter)(nil)
 
-func NewBabylonClientAdapter(babylonClient *bbnclient.Client) *BabylonClientAdapter {
+func NewBabylonClientAdapter(babylonClient *bbnclient.Client, btcParams *chaincfg.Params) *BabylonClientAdapter {
 	return &BabylonClientAdapter{
 		babylonClient: babylonClient,
+		btcParams:     btcParams,
+		params:        make(map[uint32]*btcstakingtypes.Params),
 	}
 }
 
+func (bca *BabylonClientAdapter) btcStakingParams(version uint32) (*btcstakingtypes.Params, error) {
+	bca.paramsMu.Lock()
+	defer bca.paramsMu.Unlock()
+
+	if params, ok := bca.params[version]; ok {
+		return params, nil
+	}
+	resp, err := bca.babylonClient.BTCStakingParamsByVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve btc staking params of version %d from babylon: %w", version, err)
+	}
+	bca.params[version] = &resp.Params
+	return &resp.Params, nil
+}
+
+// spendingScripts builds the leaf scripts of the staking and unbonding outputs of the delegation
+func spendingScripts(
+	delegation *btcstakingtypes.BTCDelegationResponse,
+	unbondingOutput *wire.TxOut,
+	params *btcstakingtypes.Params,
+	btcParams *chaincfg.Params,
+) (*StakingScripts, *UnbondingScripts, error) {
+	fpBtcPkList, err := bbn.NewBTCPKsFromBIP340PKs(delegation.FpBtcPkList)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert finality provider pks to BTC pks: %w", err)
+	}
+	covenantBtcPkList, err := bbn.NewBTCPKsFromBIP340PKs(params.CovenantPks)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert covenant pks to BTC pks: %w", err)
+	}
+
+	stakingInfo, err := btcstaking.BuildStakingInfo(
+		delegation.BtcPk.MustToBTCPK(),
+		fpBtcPkList,
+		covenantBtcPkList,
+		params.CovenantQuorum,
+		uint16(delegation.EndHeight-delegation.StartHeight),
+		btcutil.Amount(delegation.TotalSat),
+		btcParams,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create BTC staking info: %w", err)
+	}
+	timeLockPathSpendInfo, err := stakingInfo.TimeLockPathSpendInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	unbondingPathSpendInfo, err := stakingInfo.UnbondingPathSpendInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	slashingPathSpendInfo, err := stakingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unbondingInfo, err := btcstaking.BuildUnbondingInfo(
+		delegation.BtcPk.MustToBTCPK(),
+		fpBtcPkList,
+		covenantBtcPkList,
+		params.CovenantQuorum,
+		uint16(delegation.UnbondingTime),
+		btcutil.Amount(unbondingOutput.Value),
+		btcParams,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create BTC unbonding info: %w", err)
+	}
+	unbondingTimeLockPathSpendInfo, err := unbondingInfo.TimeLockPathSpendInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	unbondingSlashingPathSpendInfo, err := unbondingInfo.SlashingPathSpendInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &StakingScripts{
+		TimeLock:  timeLockPathSpendInfo.GetPkScriptPath(),
+		Unbonding: unbondingPathSpendInfo.GetPkScriptPath(),
+		Slashing:  slashingPathSpendInfo.GetPkScriptPath(),
+	}, &UnbondingScripts{
+		TimeLock: unbondingTimeLockPathSpendInfo.GetPkScriptPath(),
+		Slashing: unbondingSlashingPathSpendInfo.GetPkScriptPath(),
+	}, nil
+}
+
 // TODO: Consider doing quick retries for failed queries.
 func (bca *BabylonClientAdapter) ActiveBtcDelegations(offset uint64, limit uint64) ([]Delegation, error) {
 	resp, err := bca.babylonClient.BTCDelegations(
@@ -61,26 +174,130 @@ func (bca *BabylonClientAdapter) ActiveBtcDelegations(offset uint64, limit uint6
 	delegations := make([]Delegation, len(resp.BtcDelegations))
 
 	for i, delegation := range resp.BtcDelegations {
-		stakingTx, _, err := bbn.NewBTCTxFromHex(delegation.StakingTxHex)
+		del, err := bca.delegationFromResponse(delegation)
 		if err != nil {
 			return nil, err
 		}
+		delegations[i] = *del
+	}
+
+	return delegations, nil
+}
+
+// UnbondedBtcDelegations method for BabylonClientAdapter
+func (bca *BabylonClientAdapter) UnbondedBtcDelegations(offset uint64, limit uint64) ([]Delegation, uint64, error) {
+	resp, err := bca.babylonClient.BTCDelegations(
+		btcstakingtypes.BTCDelegationStatus_UNBONDED,
+		&query.PageRequest{
+			Key:    nil,
+			Offset: offset,
+			Limit:  limit,
+		},
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve delegations from babylon: %w", err)
+	}
 
-		unbondingTx, _, err := bbn.NewBTCTxFromHex(delegation.UndelegationResponse.UnbondingTxHex)
+	delegations := make([]Delegation, 0, len(resp.BtcDelegations))
+
+	for _, delegation := range resp.BtcDelegations {
+		// delegations unbonded by their timelock have no unbonding tx on BTC
+		if delegation.UndelegationResponse == nil || len(delegation.UndelegationResponse.DelegatorUnbondingSigHex) == 0 {
+			continue
+		}
+		del, err := bca.delegationFromResponse(delegation)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
+		delegations = append(delegations, *del)
+	}
 
-		delegations[i] = Delegation{
-			StakingTx:             stakingTx,
-			StakingOutputIdx:      delegation.StakingOutputIdx,
-			DelegationStartHeight: delegation.StartHeight,
-			// unbonding transaction always has only one output
-			UnbondingOutput: unbondingTx.TxOut[0],
-		}
+	return delegations, uint64(len(resp.BtcDelegations)), nil
+}
+
+// ActiveBtcDelegation method for BabylonClientAdapter
+func (bca *BabylonClientAdapter) ActiveBtcDelegation(stakingTxHash chainhash.Hash) (*Delegation, error) {
+	resp, err := bca.babylonClient.BTCDelegation(stakingTxHash.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve delegation from babylon: %w", err)
+	}
+	if !resp.BtcDelegation.Active {
+		return nil, nil
 	}
 
-	return delegations, nil
+	return bca.delegationFromResponse(resp.BtcDelegation)
+}
+
+func (bca *BabylonClientAdapter) delegationFromResponse(delegation *btcstakingtypes.BTCDelegationResponse) (*Delegation, error) {
+	params, err := bca.btcStakingParams(delegation.ParamsVersion)
+	if err != nil {
+		return nil, err
+	}
+	return DelegationFromResponse(delegation, params, bca.btcParams)
+}
+
+// DelegationFromResponse converts a BTC delegation of Babylon, created under the
+// given BTC staking parameters, to the delegation watched on BTC
+func DelegationFromResponse(
+	delegation *btcstakingtypes.BTCDelegationResponse,
+	params *btcstakingtypes.Params,
+	btcParams *chaincfg.Params,
+) (*Delegation, error) {
+	stakingTx, _, err := bbn.NewBTCTxFromHex(delegation.StakingTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingTx, _, err := bbn.NewBTCTxFromHex(delegation.UndelegationResponse.UnbondingTxHex)
+	if err != nil {
+		return nil, err
+	}
+
+	slashingTx, err := btcstakingtypes.NewBTCSlashingTxFromHex(delegation.SlashingTxHex)
+	if err != nil {
+		return nil, err
+	}
+	slashingMsgTx, err := slashingTx.ToMsgTx()
+	if err != nil {
+		return nil, err
+	}
+
+	unbondingSlashingTx, err := btcstakingtypes.NewBTCSlashingTxFromHex(delegation.UndelegationResponse.SlashingTxHex)
+	if err != nil {
+		return nil, err
+	}
+	unbondingSlashingMsgTx, err := unbondingSlashingTx.ToMsgTx()
+	if err != nil {
+		return nil, err
+	}
+
+	// unbonding transaction always has only one output
+	stakingScripts, unbondingScripts, err := spendingScripts(delegation, unbondingTx.TxOut[0], params, btcParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Delegation{
+		StakingTx:             stakingTx,
+		StakingOutputIdx:      delegation.StakingOutputIdx,
+		DelegationStartHeight: delegation.StartHeight,
+		// unbonding transaction always has only one output
+		UnbondingOutput:         unbondingTx.TxOut[0],
+		SlashingTxHash:          slashingMsgTx.TxHash(),
+		UnbondingSlashingTxHash: unbondingSlashingMsgTx.TxHash(),
+		StakingScripts:          stakingScripts,
+		UnbondingScripts:        unbondingScripts,
+	}, nil
+}
+
+// Subscribe method for BabylonClientAdapter
+func (bca *BabylonClientAdapter) Subscribe(subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	return bca.babylonClient.Subscribe(subscriber, query, outCapacity...)
+}
+
+// UnsubscribeAll method for BabylonClientAdapter
+func (bca *BabylonClientAdapter) UnsubscribeAll(subscriber string) error {
+	return bca.babylonClient.UnsubscribeAll(subscriber)
 }
 
 // IsDelegationActive method for BabylonClientAdapter
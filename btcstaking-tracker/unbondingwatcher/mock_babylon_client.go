@@ -10,6 +10,7 @@ import (
 
 	schnorr "github.com/btcsuite/btcd/btcec/v2/schnorr"
 	chainhash "github.com/btcsuite/btcd/chaincfg/chainhash"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -36,6 +37,21 @@ func (m *MockBabylonNodeAdapter) EXPECT() *MockBabylonNodeAdapterMockRecorder {
 	return m.recorder
 }
 
+// ActiveBtcDelegation mocks base method.
+func (m *MockBabylonNodeAdapter) ActiveBtcDelegation(stakingTxHash chainhash.Hash) (*Delegation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveBtcDelegation", stakingTxHash)
+	ret0, _ := ret[0].(*Delegation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveBtcDelegation indicates an expected call of ActiveBtcDelegation.
+func (mr *MockBabylonNodeAdapterMockRecorder) ActiveBtcDelegation(stakingTxHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveBtcDelegation", reflect.TypeOf((*MockBabylonNodeAdapter)(nil).ActiveBtcDelegation), stakingTxHash)
+}
+
 // ActiveBtcDelegations mocks base method.
 func (m *MockBabylonNodeAdapter) ActiveBtcDelegations(offset, limit uint64) ([]Delegation, error) {
 	m.ctrl.T.Helper()
@@ -94,3 +110,53 @@ func (mr *MockBabylonNodeAdapterMockRecorder) ReportUnbonding(ctx, stakingTxHash
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportUnbonding", reflect.TypeOf((*MockBabylonNodeAdapter)(nil).ReportUnbonding), ctx, stakingTxHash, stakerUnbondingSig)
 }
+
+// Subscribe mocks base method.
+func (m *MockBabylonNodeAdapter) Subscribe(subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{subscriber, query}
+	for _, a := range outCapacity {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Subscribe", varargs...)
+	ret0, _ := ret[0].(<-chan coretypes.ResultEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockBabylonNodeAdapterMockRecorder) Subscribe(subscriber, query interface{}, outCapacity ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{subscriber, query}, outCapacity...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockBabylonNodeAdapter)(nil).Subscribe), varargs...)
+}
+
+// UnbondedBtcDelegations mocks base method.
+func (m *MockBabylonNodeAdapter) UnbondedBtcDelegations(offset, limit uint64) ([]Delegation, uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbondedBtcDelegations", offset, limit)
+	ret0, _ := ret[0].([]Delegation)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UnbondedBtcDelegations indicates an expected call of UnbondedBtcDelegations.
+func (mr *MockBabylonNodeAdapterMockRecorder) UnbondedBtcDelegations(offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbondedBtcDelegations", reflect.TypeOf((*MockBabylonNodeAdapter)(nil).UnbondedBtcDelegations), offset, limit)
+}
+
+// UnsubscribeAll mocks base method.
+func (m *MockBabylonNodeAdapter) UnsubscribeAll(subscriber string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnsubscribeAll", subscriber)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnsubscribeAll indicates an expected call of UnsubscribeAll.
+func (mr *MockBabylonNodeAdapterMockRecorder) UnsubscribeAll(subscriber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsubscribeAll", reflect.TypeOf((*MockBabylonNodeAdapter)(nil).UnsubscribeAll), subscriber)
+}
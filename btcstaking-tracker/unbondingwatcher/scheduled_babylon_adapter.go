@@ -0,0 +1,85 @@
+package unbondingwatcher
+
+import (
+	"context"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnscheduler"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+)
+
+// ScheduledBabylonNodeAdapter sends the requests of a BabylonNodeAdapter through
+// the Babylon request scheduler shared by the routines of the BTC staking tracker.
+// Polling delegations is bookkeeping, while checking and reporting unbonding of a
+// delegation takes precedence over it.
+type ScheduledBabylonNodeAdapter struct {
+	adapter   BabylonNodeAdapter
+	scheduler *bbnscheduler.Scheduler
+	// requests not bound to a caller context wait for a slot until the tracker quits
+	quitCtx context.Context
+}
+
+var _ BabylonNodeAdapter = (*ScheduledBabylonNodeAdapter)(nil)
+
+func NewScheduledBabylonNodeAdapter(quitCtx context.Context, adapter BabylonNodeAdapter, scheduler *bbnscheduler.Scheduler) *ScheduledBabylonNodeAdapter {
+	return &ScheduledBabylonNodeAdapter{
+		adapter:   adapter,
+		scheduler: scheduler,
+		quitCtx:   quitCtx,
+	}
+}
+
+func (sa *ScheduledBabylonNodeAdapter) ActiveBtcDelegations(offset uint64, limit uint64) ([]Delegation, error) {
+	return bbnscheduler.Call(sa.quitCtx, sa.scheduler, bbnscheduler.EndpointBTCDelegations, bbnscheduler.PriorityLow,
+		func() ([]Delegation, error) {
+			return sa.adapter.ActiveBtcDelegations(offset, limit)
+		})
+}
+
+func (sa *ScheduledBabylonNodeAdapter) UnbondedBtcDelegations(offset uint64, limit uint64) ([]Delegation, uint64, error) {
+	var pageSize uint64
+	delegations, err := bbnscheduler.Call(sa.quitCtx, sa.scheduler, bbnscheduler.EndpointBTCDelegations, bbnscheduler.PriorityLow,
+		func() ([]Delegation, error) {
+			delegations, n, err := sa.adapter.UnbondedBtcDelegations(offset, limit)
+			pageSize = n
+			return delegations, err
+		})
+	return delegations, pageSize, err
+}
+
+func (sa *ScheduledBabylonNodeAdapter) ActiveBtcDelegation(stakingTxHash chainhash.Hash) (*Delegation, error) {
+	return bbnscheduler.Call(sa.quitCtx, sa.scheduler, bbnscheduler.EndpointBTCDelegation, bbnscheduler.PriorityNormal,
+		func() (*Delegation, error) {
+			return sa.adapter.ActiveBtcDelegation(stakingTxHash)
+		})
+}
+
+func (sa *ScheduledBabylonNodeAdapter) IsDelegationActive(stakingTxHash chainhash.Hash) (bool, error) {
+	return bbnscheduler.Call(sa.quitCtx, sa.scheduler, bbnscheduler.EndpointBTCDelegation, bbnscheduler.PriorityNormal,
+		func() (bool, error) {
+			return sa.adapter.IsDelegationActive(stakingTxHash)
+		})
+}
+
+func (sa *ScheduledBabylonNodeAdapter) ReportUnbonding(ctx context.Context, stakingTxHash chainhash.Hash, stakerUnbondingSig *schnorr.Signature) error {
+	return sa.scheduler.Do(ctx, bbnscheduler.EndpointSendMsg, bbnscheduler.PriorityNormal, func() error {
+		return sa.adapter.ReportUnbonding(ctx, stakingTxHash, stakerUnbondingSig)
+	})
+}
+
+func (sa *ScheduledBabylonNodeAdapter) BtcClientTipHeight() (uint32, error) {
+	return bbnscheduler.Call(sa.quitCtx, sa.scheduler, bbnscheduler.EndpointBTCHeaderChainTip, bbnscheduler.PriorityLow,
+		func() (uint32, error) {
+			return sa.adapter.BtcClientTipHeight()
+		})
+}
+
+// Subscribe is long-lived and not scheduled
+func (sa *ScheduledBabylonNodeAdapter) Subscribe(subscriber, query string, outCapacity ...int) (<-chan coretypes.ResultEvent, error) {
+	return sa.adapter.Subscribe(subscriber, query, outCapacity...)
+}
+
+func (sa *ScheduledBabylonNodeAdapter) UnsubscribeAll(subscriber string) error {
+	return sa.adapter.UnsubscribeAll(subscriber)
+}
@@ -0,0 +1,139 @@
+package unbondingwatcher
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// SpendType is the kind of tx spending a staking or unbonding output
+type SpendType int
+
+const (
+	// SpendTypeUnknown is a spend that matches none of the expected spending paths
+	// of the output, or matches one with an unexpected tx
+	SpendTypeUnknown SpendType = iota
+	// SpendTypeUnbonding is an early unbonding tx spending the unbonding path
+	SpendTypeUnbonding
+	// SpendTypeWithdrawal is a withdrawal spending the timelock path after the staking
+	// or unbonding time
+	SpendTypeWithdrawal
+	// SpendTypeSlashing is the delegation's slashing or unbonding slashing tx
+	// spending the slashing path
+	SpendTypeSlashing
+)
+
+func (t SpendType) String() string {
+	switch t {
+	case SpendTypeUnbonding:
+		return "unbonding"
+	case SpendTypeWithdrawal:
+		return "withdrawal"
+	case SpendTypeSlashing:
+		return "slashing"
+	default:
+		return "unknown"
+	}
+}
+
+// StakingScripts are the leaf scripts of the taproot staking output, one per spending path
+type StakingScripts struct {
+	TimeLock  []byte
+	Unbonding []byte
+	Slashing  []byte
+}
+
+// UnbondingScripts are the leaf scripts of the taproot unbonding output, one per spending path
+type UnbondingScripts struct {
+	TimeLock []byte
+	Slashing []byte
+}
+
+// SpendClassification is the result of classifying a spend of a staking or unbonding output
+type SpendClassification struct {
+	Type SpendType
+	// signature of the staker on the unbonding tx, only set for unbonding spends
+	StakerSignature *schnorr.Signature
+	// why the spend is unknown, only set for unknown spends
+	Reason error
+}
+
+func unknownSpend(format string, args ...interface{}) *SpendClassification {
+	return &SpendClassification{Type: SpendTypeUnknown, Reason: fmt.Errorf(format, args...)}
+}
+
+// revealedScript returns the leaf script revealed by the input of the tx spending the outpoint
+func revealedScript(tx *wire.MsgTx, outPoint wire.OutPoint) ([]byte, error) {
+	for _, txIn := range tx.TxIn {
+		if txIn.PreviousOutPoint != outPoint {
+			continue
+		}
+		// a script path spend ends with the script and the control block. The key
+		// path of staking and unbonding outputs is unspendable, hence never expected.
+		if len(txIn.Witness) < 2 {
+			return nil, fmt.Errorf("input witness has %d elements, which is not a script path spend", len(txIn.Witness))
+		}
+		return txIn.Witness[len(txIn.Witness)-2], nil
+	}
+	return nil, fmt.Errorf("tx %s does not spend %s", tx.TxHash(), outPoint)
+}
+
+// ClassifySpend classifies the tx spending the staking output of the delegation
+// by the leaf script revealed in the witness of the spending input. A spend of the
+// slashing path must be the delegation's slashing tx, and a spend of the unbonding
+// path must pay to the unbonding output of the delegation.
+func ClassifySpend(tx *wire.MsgTx, td *TrackedDelegation) *SpendClassification {
+	script, err := revealedScript(tx, wire.OutPoint{Hash: td.StakingTx.TxHash(), Index: td.StakingOutputIdx})
+	if err != nil {
+		return unknownSpend("%v", err)
+	}
+	if td.StakingScripts == nil {
+		return unknownSpend("staking scripts of the delegation are unknown")
+	}
+
+	switch {
+	case bytes.Equal(script, td.StakingScripts.Unbonding):
+		sig, err := tryParseStakerSignatureFromSpentTx(tx, td)
+		if err != nil {
+			return unknownSpend("spend of the unbonding path is not the unbonding tx: %v", err)
+		}
+		return &SpendClassification{Type: SpendTypeUnbonding, StakerSignature: sig}
+	case bytes.Equal(script, td.StakingScripts.TimeLock):
+		return &SpendClassification{Type: SpendTypeWithdrawal}
+	case bytes.Equal(script, td.StakingScripts.Slashing):
+		if tx.TxHash() != td.SlashingTxHash {
+			return unknownSpend("spend of the slashing path %s is not the slashing tx %s", tx.TxHash(), td.SlashingTxHash)
+		}
+		return &SpendClassification{Type: SpendTypeSlashing}
+	default:
+		return unknownSpend("spend reveals a script that is not in the staking output")
+	}
+}
+
+// ClassifyUnbondingSpend classifies the tx spending the unbonding output of the
+// delegation, which is either a withdrawal after the unbonding time, or the
+// delegation's unbonding slashing tx
+func ClassifyUnbondingSpend(tx *wire.MsgTx, td *TrackedDelegation) *SpendClassification {
+	// unbonding tx always has only one output
+	script, err := revealedScript(tx, wire.OutPoint{Hash: td.UnbondingTxHash, Index: 0})
+	if err != nil {
+		return unknownSpend("%v", err)
+	}
+	if td.UnbondingScripts == nil {
+		return unknownSpend("unbonding scripts of the delegation are unknown")
+	}
+
+	switch {
+	case bytes.Equal(script, td.UnbondingScripts.TimeLock):
+		return &SpendClassification{Type: SpendTypeWithdrawal}
+	case bytes.Equal(script, td.UnbondingScripts.Slashing):
+		if tx.TxHash() != td.UnbondingSlashingTxHash {
+			return unknownSpend("spend of the slashing path %s is not the unbonding slashing tx %s", tx.TxHash(), td.UnbondingSlashingTxHash)
+		}
+		return &SpendClassification{Type: SpendTypeSlashing}
+	default:
+		return unknownSpend("spend reveals a script that is not in the unbonding output")
+	}
+}
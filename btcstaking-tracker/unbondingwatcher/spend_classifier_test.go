@@ -0,0 +1,170 @@
+package unbondingwatcher_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/babylonchain/babylon/btcstaking"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/unbondingwatcher"
+)
+
+func FuzzClassifySpend(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		net := &chaincfg.SimNetParams
+
+		stakerSK, stakerPK, err := datagen.GenRandomBTCKeyPair(r)
+		require.NoError(t, err)
+		_, fpPK, err := datagen.GenRandomBTCKeyPair(r)
+		require.NoError(t, err)
+		covQuorum := uint32(datagen.RandomInt(r, 3) + 1)
+		covenantPKs := make([]*btcec.PublicKey, 0, covQuorum+1)
+		for i := uint32(0); i <= covQuorum; i++ {
+			_, covenantPK, err := datagen.GenRandomBTCKeyPair(r)
+			require.NoError(t, err)
+			covenantPKs = append(covenantPKs, covenantPK)
+		}
+		stakingInfo, err := btcstaking.BuildStakingInfo(
+			stakerPK,
+			[]*btcec.PublicKey{fpPK},
+			covenantPKs,
+			covQuorum,
+			uint16(datagen.RandomInt(r, 1000)+100),
+			btcutil.Amount(datagen.RandomInt(r, 100000)+10000),
+			net,
+		)
+		require.NoError(t, err)
+		unbondingInfo, err := btcstaking.BuildUnbondingInfo(
+			stakerPK,
+			[]*btcec.PublicKey{fpPK},
+			covenantPKs,
+			covQuorum,
+			uint16(datagen.RandomInt(r, 100)+10),
+			btcutil.Amount(datagen.RandomInt(r, 10000)+1000),
+			net,
+		)
+		require.NoError(t, err)
+		timeLockPathSpendInfo, err := stakingInfo.TimeLockPathSpendInfo()
+		require.NoError(t, err)
+		unbondingPathSpendInfo, err := stakingInfo.UnbondingPathSpendInfo()
+		require.NoError(t, err)
+		slashingPathSpendInfo, err := stakingInfo.SlashingPathSpendInfo()
+		require.NoError(t, err)
+		unbondingTimeLockPathSpendInfo, err := unbondingInfo.TimeLockPathSpendInfo()
+		require.NoError(t, err)
+		unbondingSlashingPathSpendInfo, err := unbondingInfo.SlashingPathSpendInfo()
+		require.NoError(t, err)
+
+		randomOutput := func() *wire.TxOut {
+			return wire.NewTxOut(int64(datagen.RandomInt(r, 10000)+1), datagen.GenRandomByteArray(r, 34))
+		}
+		randomOutPoint := func() *wire.OutPoint {
+			return wire.NewOutPoint((*chainhash.Hash)(datagen.GenRandomByteArray(r, 32)), r.Uint32())
+		}
+
+		stakingTx := wire.NewMsgTx(2)
+		stakingTx.AddTxIn(wire.NewTxIn(randomOutPoint(), nil, nil))
+		stakingTx.AddTxOut(stakingInfo.StakingOutput)
+		stakingTxHash := stakingTx.TxHash()
+
+		// spends the output through the given script, with dummy signatures
+		spendOutput := func(outPoint *wire.OutPoint, script []byte, outputs ...*wire.TxOut) *wire.MsgTx {
+			sig, err := schnorr.Sign(stakerSK, datagen.GenRandomByteArray(r, 32))
+			require.NoError(t, err)
+			tx := wire.NewMsgTx(2)
+			tx.AddTxIn(wire.NewTxIn(outPoint, nil, wire.TxWitness{
+				sig.Serialize(),
+				sig.Serialize(),
+				script,
+				datagen.GenRandomByteArray(r, 33),
+			}))
+			for _, output := range outputs {
+				tx.AddTxOut(output)
+			}
+			return tx
+		}
+		spendTx := func(script []byte, outputs ...*wire.TxOut) *wire.MsgTx {
+			return spendOutput(wire.NewOutPoint(&stakingTxHash, 0), script, outputs...)
+		}
+
+		unbondingOutput := unbondingInfo.UnbondingOutput
+		unbondingTx := spendTx(unbondingPathSpendInfo.GetPkScriptPath(), unbondingOutput)
+		unbondingTxHash := unbondingTx.TxHash()
+		spendUnbondingTx := func(script []byte, outputs ...*wire.TxOut) *wire.MsgTx {
+			return spendOutput(wire.NewOutPoint(&unbondingTxHash, 0), script, outputs...)
+		}
+		slashingTx := spendTx(slashingPathSpendInfo.GetPkScriptPath(), randomOutput(), randomOutput())
+		unbondingSlashingTx := spendUnbondingTx(unbondingSlashingPathSpendInfo.GetPkScriptPath(), randomOutput(), randomOutput())
+		td := &unbondingwatcher.TrackedDelegation{
+			StakingTx:               stakingTx,
+			StakingOutputIdx:        0,
+			UnbondingOutput:         unbondingOutput,
+			SlashingTxHash:          slashingTx.TxHash(),
+			UnbondingSlashingTxHash: unbondingSlashingTx.TxHash(),
+			StakingScripts: &unbondingwatcher.StakingScripts{
+				TimeLock:  timeLockPathSpendInfo.GetPkScriptPath(),
+				Unbonding: unbondingPathSpendInfo.GetPkScriptPath(),
+				Slashing:  slashingPathSpendInfo.GetPkScriptPath(),
+			},
+			UnbondingScripts: &unbondingwatcher.UnbondingScripts{
+				TimeLock: unbondingTimeLockPathSpendInfo.GetPkScriptPath(),
+				Slashing: unbondingSlashingPathSpendInfo.GetPkScriptPath(),
+			},
+		}
+
+		// expected spends
+		res := unbondingwatcher.ClassifySpend(unbondingTx, td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnbonding, res.Type)
+		require.NotNil(t, res.StakerSignature)
+		res = unbondingwatcher.ClassifySpend(spendTx(td.StakingScripts.TimeLock, randomOutput()), td)
+		require.Equal(t, unbondingwatcher.SpendTypeWithdrawal, res.Type)
+		res = unbondingwatcher.ClassifySpend(slashingTx, td)
+		require.Equal(t, unbondingwatcher.SpendTypeSlashing, res.Type)
+
+		// unexpected txs through expected paths
+		res = unbondingwatcher.ClassifySpend(spendTx(td.StakingScripts.Unbonding, randomOutput()), td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+		require.Error(t, res.Reason)
+		res = unbondingwatcher.ClassifySpend(spendTx(td.StakingScripts.Slashing, randomOutput()), td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+		require.Error(t, res.Reason)
+
+		// unexpected paths
+		res = unbondingwatcher.ClassifySpend(spendTx(datagen.GenRandomByteArray(r, 40), randomOutput()), td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+		keyPathSpend := spendTx(td.StakingScripts.TimeLock, randomOutput())
+		keyPathSpend.TxIn[0].Witness = keyPathSpend.TxIn[0].Witness[:1]
+		res = unbondingwatcher.ClassifySpend(keyPathSpend, td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+
+		// a tx not spending the staking output
+		otherTx := spendTx(td.StakingScripts.TimeLock, randomOutput())
+		otherTx.TxIn[0].PreviousOutPoint = *randomOutPoint()
+		res = unbondingwatcher.ClassifySpend(otherTx, td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+
+		// spends of the unbonding output
+		td.UnbondingTxHash = unbondingTxHash
+		res = unbondingwatcher.ClassifyUnbondingSpend(spendUnbondingTx(td.UnbondingScripts.TimeLock, randomOutput()), td)
+		require.Equal(t, unbondingwatcher.SpendTypeWithdrawal, res.Type)
+		res = unbondingwatcher.ClassifyUnbondingSpend(unbondingSlashingTx, td)
+		require.Equal(t, unbondingwatcher.SpendTypeSlashing, res.Type)
+		res = unbondingwatcher.ClassifyUnbondingSpend(spendUnbondingTx(td.UnbondingScripts.Slashing, randomOutput()), td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+		require.Error(t, res.Reason)
+		// the staking slashing tx does not spend the unbonding output
+		res = unbondingwatcher.ClassifyUnbondingSpend(slashingTx, td)
+		require.Equal(t, unbondingwatcher.SpendTypeUnknown, res.Type)
+	})
+}
@@ -0,0 +1,211 @@
+package unbondingwatcher
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	notifier "github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+)
+
+// confNotifier hands out the given confirmation events in order, records the
+// confirmation requests, and notifies the best blocks sent to its channel
+type confNotifier struct {
+	notifier.ChainNotifier
+	confEvents chan *notifier.ConfirmationEvent
+	numConfs   chan uint32
+	epochs     chan *notifier.BlockEpoch
+}
+
+func newConfNotifier() *confNotifier {
+	return &confNotifier{
+		confEvents: make(chan *notifier.ConfirmationEvent, 10),
+		numConfs:   make(chan uint32, 10),
+		epochs:     make(chan *notifier.BlockEpoch),
+	}
+}
+
+func (n *confNotifier) RegisterBlockEpochNtfn(*notifier.BlockEpoch) (*notifier.BlockEpochEvent, error) {
+	return &notifier.BlockEpochEvent{Epochs: n.epochs, Cancel: func() {}}, nil
+}
+
+func (n *confNotifier) RegisterConfirmationsNtfn(_ *chainhash.Hash, _ []byte, numConfs, _ uint32, _ ...notifier.NotifierOption) (*notifier.ConfirmationEvent, error) {
+	n.numConfs <- numConfs
+	return <-n.confEvents, nil
+}
+
+func (n *confNotifier) newConfEvent(numConfs uint32) *notifier.ConfirmationEvent {
+	confEvent := notifier.NewConfirmationEvent(numConfs, func() {})
+	n.confEvents <- confEvent
+	return confEvent
+}
+
+func newTestWatcher(btcNotifier notifier.ChainNotifier, depth uint32) *UnbondingWatcher {
+	cfg := config.DefaultBTCStakingTrackerConfig()
+	cfg.SpendConfirmationDepth = depth
+	return &UnbondingWatcher{
+		cfg:         &cfg,
+		logger:      zap.NewNop().Sugar(),
+		btcNotifier: btcNotifier,
+		metrics:     metrics.NewBTCStakingTrackerMetrics().UnbondingWatcherMetrics,
+	}
+}
+
+func genSpendDetail(r *rand.Rand, height int32) *notifier.SpendDetail {
+	pkScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(datagen.GenRandomByteArray(r, 20)).Script()
+	if err != nil {
+		panic(err)
+	}
+	spendingTx := wire.NewMsgTx(wire.TxVersion)
+	spendingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, r.Uint32()), nil, nil))
+	spendingTx.AddTxOut(wire.NewTxOut(int64(r.Intn(100000)+1), pkScript))
+	spendingTxHash := spendingTx.TxHash()
+	return &notifier.SpendDetail{
+		SpentOutPoint:  &spendingTx.TxIn[0].PreviousOutPoint,
+		SpenderTxHash:  &spendingTxHash,
+		SpendingTx:     spendingTx,
+		SpendingHeight: height,
+	}
+}
+
+// waitForConfirmedSpendAsync runs waitForConfirmedSpend and returns the channel of its result
+func waitForConfirmedSpendAsync(ctx context.Context, uw *UnbondingWatcher, spendEvent *notifier.SpendEvent) chan *notifier.SpendDetail {
+	result := make(chan *notifier.SpendDetail, 1)
+	go func() {
+		result <- uw.waitForConfirmedSpend(ctx, spendEvent)
+	}()
+	return result
+}
+
+func requireResult(t *testing.T, result chan *notifier.SpendDetail, expected *notifier.SpendDetail) {
+	select {
+	case spendDetail := <-result:
+		require.Equal(t, expected, spendDetail)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the confirmed spend")
+	}
+}
+
+func requireNumConfs(t *testing.T, n *confNotifier, expected uint32) {
+	select {
+	case numConfs := <-n.numConfs:
+		require.Equal(t, expected, numConfs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the confirmation request")
+	}
+}
+
+func sendOrFail[T any](t *testing.T, ch chan T, v T) {
+	select {
+	case ch <- v:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out sending to the watcher")
+	}
+}
+
+func FuzzWaitForConfirmedSpend(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		depth := uint32(r.Intn(10) + 2)
+		btcNotifier := newConfNotifier()
+		uw := newTestWatcher(btcNotifier, depth)
+		spendEvent := notifier.NewSpendEvent(func() {})
+		result := waitForConfirmedSpendAsync(context.Background(), uw, spendEvent)
+
+		// spends reorged out before they are deep enough are discarded. A reorg
+		// fires both the negative confirmation and the reorg of the spend.
+		numReorgs := r.Intn(3)
+		for i := 0; i < numReorgs; i++ {
+			confEvent := btcNotifier.newConfEvent(depth)
+			sendOrFail(t, spendEvent.Spend, genSpendDetail(r, int32(100+i)))
+			requireNumConfs(t, btcNotifier, depth)
+			confEvent.NegativeConf <- 1
+			sendOrFail(t, spendEvent.Reorg, struct{}{})
+		}
+
+		// the spend confirmed by the configured depth is returned
+		confEvent := btcNotifier.newConfEvent(depth)
+		spendDetail := genSpendDetail(r, 200)
+		sendOrFail(t, spendEvent.Spend, spendDetail)
+		requireNumConfs(t, btcNotifier, depth)
+		confEvent.Confirmed <- &notifier.TxConfirmation{BlockHeight: 200 + depth - 1}
+		requireResult(t, result, spendDetail)
+	})
+}
+
+func TestWaitForConfirmedSpendQuit(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	btcNotifier := newConfNotifier()
+	uw := newTestWatcher(btcNotifier, 3)
+
+	// quit while waiting for a spend
+	ctx, cancel := context.WithCancel(context.Background())
+	result := waitForConfirmedSpendAsync(ctx, uw, notifier.NewSpendEvent(func() {}))
+	cancel()
+	requireResult(t, result, nil)
+
+	// quit while waiting for the confirmation of a spend
+	ctx, cancel = context.WithCancel(context.Background())
+	spendEvent := notifier.NewSpendEvent(func() {})
+	result = waitForConfirmedSpendAsync(ctx, uw, spendEvent)
+	btcNotifier.newConfEvent(3)
+	spendEvent.Spend <- genSpendDetail(r, 100)
+	requireNumConfs(t, btcNotifier, 3)
+	cancel()
+	requireResult(t, result, nil)
+}
+
+func TestWaitForConfirmedSpendInBlock(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	btcNotifier := newConfNotifier()
+	uw := newTestWatcher(btcNotifier, 1)
+
+	// a spend in a block is confirmed without registering for confirmations
+	spendEvent := notifier.NewSpendEvent(func() {})
+	spendDetail := genSpendDetail(r, 100)
+	spendEvent.Spend <- spendDetail
+	require.Equal(t, spendDetail, uw.waitForConfirmedSpend(context.Background(), spendEvent))
+	require.Empty(t, btcNotifier.numConfs)
+}
+
+func TestWaitForConfirmedSpendWithoutStandardOutput(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	depth := uint32(3)
+	btcNotifier := newConfNotifier()
+	uw := newTestWatcher(btcNotifier, depth)
+	spendEvent := notifier.NewSpendEvent(func() {})
+	result := waitForConfirmedSpendAsync(context.Background(), uw, spendEvent)
+
+	// confirmations of a spending tx without a standard output cannot be watched,
+	// so the blocks on top of it are counted instead
+	genNonStandardSpendDetail := func(height int32) *notifier.SpendDetail {
+		spendDetail := genSpendDetail(r, height)
+		spendDetail.SpendingTx.TxOut[0].PkScript = []byte{txscript.OP_TRUE}
+		return spendDetail
+	}
+
+	// a spend reorged out before it is deep enough is discarded
+	sendOrFail(t, spendEvent.Spend, genNonStandardSpendDetail(100))
+	sendOrFail(t, btcNotifier.epochs, &notifier.BlockEpoch{Height: 101})
+	sendOrFail(t, spendEvent.Reorg, struct{}{})
+
+	spendDetail := genNonStandardSpendDetail(200)
+	sendOrFail(t, spendEvent.Spend, spendDetail)
+	for height := int32(200); height < 200+int32(depth); height++ {
+		require.Empty(t, result)
+		sendOrFail(t, btcNotifier.epochs, &notifier.BlockEpoch{Height: height})
+	}
+	requireResult(t, result, spendDetail)
+	require.Empty(t, btcNotifier.numConfs)
+}
@@ -8,10 +8,59 @@ import (
 	"github.com/btcsuite/btcd/wire"
 )
 
+// DelegationState is the state of a tracked delegation on BTC
+type DelegationState int
+
+const (
+	// DelegationStateActive means that the staking output is not spent
+	DelegationStateActive DelegationState = iota
+	// DelegationStateUnbonding means that the staking output is spent by the
+	// unbonding tx, and the unbonding output is not spent
+	DelegationStateUnbonding
+	// DelegationStateWithdrawn means that the staking or unbonding output is
+	// withdrawn by the staker after its timelock
+	DelegationStateWithdrawn
+	// DelegationStateSlashed means that the staking or unbonding output is spent
+	// by the slashing or unbonding slashing tx
+	DelegationStateSlashed
+	// DelegationStateUnknownSpend means that the staking or unbonding output is
+	// spent by a tx of unknown type
+	DelegationStateUnknownSpend
+)
+
+func (s DelegationState) String() string {
+	switch s {
+	case DelegationStateActive:
+		return "active"
+	case DelegationStateUnbonding:
+		return "unbonding"
+	case DelegationStateWithdrawn:
+		return "withdrawn"
+	case DelegationStateSlashed:
+		return "slashed"
+	case DelegationStateUnknownSpend:
+		return "unknown_spend"
+	default:
+		return "invalid"
+	}
+}
+
+// IsTerminal returns whether the delegation has no output left to watch
+func (s DelegationState) IsTerminal() bool {
+	return s != DelegationStateActive && s != DelegationStateUnbonding
+}
+
 type TrackedDelegation struct {
-	StakingTx        *wire.MsgTx
-	StakingOutputIdx uint32
-	UnbondingOutput  *wire.TxOut
+	StakingTx               *wire.MsgTx
+	StakingOutputIdx        uint32
+	UnbondingOutput         *wire.TxOut
+	SlashingTxHash          chainhash.Hash
+	UnbondingSlashingTxHash chainhash.Hash
+	StakingScripts          *StakingScripts
+	UnbondingScripts        *UnbondingScripts
+	// only set once the unbonding tx is on BTC
+	UnbondingTxHash chainhash.Hash
+	State           DelegationState
 }
 
 type TrackedDelegations struct {
@@ -44,11 +93,20 @@ func (dt *TrackedDelegations) AddDelegation(
 	StakingTx *wire.MsgTx,
 	StakingOutputIdx uint32,
 	UnbondingOutput *wire.TxOut,
+	SlashingTxHash chainhash.Hash,
+	UnbondingSlashingTxHash chainhash.Hash,
+	StakingScripts *StakingScripts,
+	UnbondingScripts *UnbondingScripts,
 ) (*TrackedDelegation, error) {
 	delegation := &TrackedDelegation{
-		StakingTx:        StakingTx,
-		StakingOutputIdx: StakingOutputIdx,
-		UnbondingOutput:  UnbondingOutput,
+		StakingTx:               StakingTx,
+		StakingOutputIdx:        StakingOutputIdx,
+		UnbondingOutput:         UnbondingOutput,
+		SlashingTxHash:          SlashingTxHash,
+		UnbondingSlashingTxHash: UnbondingSlashingTxHash,
+		StakingScripts:          StakingScripts,
+		UnbondingScripts:        UnbondingScripts,
+		State:                   DelegationStateActive,
 	}
 
 	stakingTxHash := StakingTx.TxHash()
@@ -70,3 +128,55 @@ func (dt *TrackedDelegations) RemoveDelegation(stakingTxHash chainhash.Hash) {
 
 	delete(dt.mapping, stakingTxHash)
 }
+
+// SetState moves the tracked delegation to the given state, and returns its previous state
+func (dt *TrackedDelegations) SetState(stakingTxHash chainhash.Hash, state DelegationState) (DelegationState, error) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	del, ok := dt.mapping[stakingTxHash]
+	if !ok {
+		return 0, fmt.Errorf("delegation not tracked for staking tx hash %s", stakingTxHash)
+	}
+	prevState := del.State
+	if prevState.IsTerminal() {
+		return prevState, fmt.Errorf("delegation for staking tx hash %s is already in terminal state %s", stakingTxHash, prevState)
+	}
+	del.State = state
+	return prevState, nil
+}
+
+// SetUnbonding moves the tracked delegation to the unbonding state with the given unbonding tx
+func (dt *TrackedDelegations) SetUnbonding(stakingTxHash chainhash.Hash, unbondingTxHash chainhash.Hash) (*TrackedDelegation, error) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	del, ok := dt.mapping[stakingTxHash]
+	if !ok {
+		return nil, fmt.Errorf("delegation not tracked for staking tx hash %s", stakingTxHash)
+	}
+	if del.State != DelegationStateActive {
+		return nil, fmt.Errorf("delegation for staking tx hash %s cannot start unbonding in state %s", stakingTxHash, del.State)
+	}
+	del.State = DelegationStateUnbonding
+	del.UnbondingTxHash = unbondingTxHash
+	return del, nil
+}
+
+// CancelUnbonding moves the tracked delegation back from the unbonding state to
+// the active state, e.g. when its unbonding output cannot be watched
+func (dt *TrackedDelegations) CancelUnbonding(stakingTxHash chainhash.Hash) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	del, ok := dt.mapping[stakingTxHash]
+	if !ok {
+		return fmt.Errorf("delegation not tracked for staking tx hash %s", stakingTxHash)
+	}
+	if del.State != DelegationStateUnbonding {
+		return fmt.Errorf("delegation for staking tx hash %s cannot cancel unbonding in state %s", stakingTxHash, del.State)
+	}
+	del.State = DelegationStateActive
+	del.UnbondingTxHash = chainhash.Hash{}
+	return nil
+}
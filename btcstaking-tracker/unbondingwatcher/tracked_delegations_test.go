@@ -0,0 +1,75 @@
+package unbondingwatcher_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/btcstaking-tracker/unbondingwatcher"
+)
+
+func FuzzTrackedDelegationsLifecycle(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		tracker := unbondingwatcher.NewTrackedDelegations()
+
+		stakingTx := wire.NewMsgTx(2)
+		stakingTx.AddTxOut(wire.NewTxOut(int64(datagen.RandomInt(r, 10000)+1), datagen.GenRandomByteArray(r, 34)))
+		stakingTxHash := stakingTx.TxHash()
+		unbondingTxHash := chainhash.Hash(datagen.GenRandomByteArray(r, 32))
+
+		_, err := tracker.AddDelegation(stakingTx, 0, stakingTx.TxOut[0], chainhash.Hash{}, chainhash.Hash{}, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, unbondingwatcher.DelegationStateActive, tracker.GetDelegation(stakingTxHash).State)
+
+		terminalStates := []unbondingwatcher.DelegationState{
+			unbondingwatcher.DelegationStateWithdrawn,
+			unbondingwatcher.DelegationStateSlashed,
+			unbondingwatcher.DelegationStateUnknownSpend,
+		}
+		terminalState := terminalStates[r.Intn(len(terminalStates))]
+
+		// active -> (unbonding ->) terminal
+		expectedPrevState := unbondingwatcher.DelegationStateActive
+		if r.Intn(2) == 0 {
+			del, err := tracker.SetUnbonding(stakingTxHash, unbondingTxHash)
+			require.NoError(t, err)
+			require.Equal(t, unbondingwatcher.DelegationStateUnbonding, del.State)
+			require.Equal(t, unbondingTxHash, del.UnbondingTxHash)
+			// only active delegations can start unbonding
+			_, err = tracker.SetUnbonding(stakingTxHash, unbondingTxHash)
+			require.Error(t, err)
+			if r.Intn(2) == 0 {
+				// a cancelled unbonding is started again
+				require.NoError(t, tracker.CancelUnbonding(stakingTxHash))
+				del = tracker.GetDelegation(stakingTxHash)
+				require.Equal(t, unbondingwatcher.DelegationStateActive, del.State)
+				require.Equal(t, chainhash.Hash{}, del.UnbondingTxHash)
+				require.Error(t, tracker.CancelUnbonding(stakingTxHash))
+				_, err = tracker.SetUnbonding(stakingTxHash, unbondingTxHash)
+				require.NoError(t, err)
+			}
+			expectedPrevState = unbondingwatcher.DelegationStateUnbonding
+		}
+		prevState, err := tracker.SetState(stakingTxHash, terminalState)
+		require.NoError(t, err)
+		require.Equal(t, expectedPrevState, prevState)
+		require.True(t, tracker.GetDelegation(stakingTxHash).State.IsTerminal())
+
+		// terminal states are final
+		_, err = tracker.SetState(stakingTxHash, unbondingwatcher.DelegationStateActive)
+		require.Error(t, err)
+		_, err = tracker.SetUnbonding(stakingTxHash, unbondingTxHash)
+		require.Error(t, err)
+
+		tracker.RemoveDelegation(stakingTxHash)
+		_, err = tracker.SetState(stakingTxHash, terminalState)
+		require.Error(t, err)
+	})
+}
@@ -10,16 +10,33 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/babylonchain/vigilante/alert"
+	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/bbnevents"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/blockstream"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/types"
 	"github.com/babylonchain/vigilante/utils"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	notifier "github.com/lightningnetwork/lnd/chainntnfs"
 	"go.uber.org/zap"
 )
 
+const (
+	// name of the subscription to delegations becoming active on babylon
+	activatedDelegationsSubscriber = "unbonding-watcher-delegations"
+	// interval before retrying a failed rescan of delegations
+	failedRescanRetryInterval = 30 * time.Second
+	// interval before retrying to watch the unbonding output of a delegation
+	failedUnbondingRetryInterval = 30 * time.Second
+	// interval between polls of the spend events of the watched staking outputs
+	spendPollInterval = 5 * time.Second
+)
+
 var (
 	fixedDelyTypeWithJitter = retry.DelayType(retry.CombineDelay(retry.FixedDelay, retry.RandomDelay))
 	retryForever            = retry.Attempts(0)
@@ -43,15 +60,33 @@ func (uw *UnbondingWatcher) quitContext() (context.Context, func()) {
 }
 
 type newDelegation struct {
-	stakingTxHash         chainhash.Hash
-	stakingTx             *wire.MsgTx
-	stakingOutputIdx      uint32
-	delegationStartHeight uint64
-	unbondingOutput       *wire.TxOut
+	stakingTxHash           chainhash.Hash
+	stakingTx               *wire.MsgTx
+	stakingOutputIdx        uint32
+	delegationStartHeight   uint64
+	unbondingOutput         *wire.TxOut
+	slashingTxHash          chainhash.Hash
+	unbondingSlashingTxHash chainhash.Hash
+	stakingScripts          *StakingScripts
+	unbondingScripts        *UnbondingScripts
 }
 
-type delegationInactive struct {
+// delegationStateChange moves a tracked delegation to a new state upon a spend
+// of its staking or unbonding output
+type delegationStateChange struct {
 	stakingTxHash chainhash.Hash
+	state         DelegationState
+	// only set when moving to the unbonding state
+	unbondingTxHash chainhash.Hash
+	unbondingHeight int32
+}
+
+// watchedSpend is a delegation whose staking output is watched for a spend
+type watchedSpend struct {
+	spendEvent *notifier.SpendEvent
+	td         *TrackedDelegation
+	// set once the staking output is spent
+	spendDetail *notifier.SpendDetail
 }
 
 type UnbondingWatcher struct {
@@ -62,33 +97,47 @@ type UnbondingWatcher struct {
 	cfg         *config.BTCStakingTrackerConfig
 	logger      *zap.SugaredLogger
 	btcNotifier notifier.ChainNotifier
+	blockStream *blockstream.BlockStream
+	hintCache   btcclient.HintCache
+	alerts      *alert.Dispatcher
 	metrics     *metrics.UnbondingWatcherMetrics
-	// TODO: Ultimately all requests to babylon should go through some kind of semaphore
-	// to avoid spamming babylon with requests
+	// requests to babylon are expected to be bounded by the adapter, see ScheduledBabylonNodeAdapter
 	babylonNodeAdapter     BabylonNodeAdapter
 	tracker                *TrackedDelegations
 	newDelegationChan      chan *newDelegation
-	delegetionInactiveChan chan *delegationInactive
+	delegationStateChan    chan *delegationStateChange
 	currentBestBlockHeight atomic.Uint32
+	// staking outputs to watch, polled by a single routine
+	watchSpendChan chan *watchedSpend
+	// spent staking outputs, handled by a bounded number of workers
+	spendJobChan chan *watchedSpend
 }
 
 func NewUnbondingWatcher(
 	btcNotifier notifier.ChainNotifier,
+	blockStream *blockstream.BlockStream,
+	hintCache btcclient.HintCache,
+	alerts *alert.Dispatcher,
 	babylonNodeAdapter BabylonNodeAdapter,
 	cfg *config.BTCStakingTrackerConfig,
 	parentLogger *zap.Logger,
 	metrics *metrics.UnbondingWatcherMetrics,
 ) *UnbondingWatcher {
 	return &UnbondingWatcher{
-		quit:                   make(chan struct{}),
-		cfg:                    cfg,
-		logger:                 parentLogger.With(zap.String("module", "unbonding_watcher")).Sugar(),
-		btcNotifier:            btcNotifier,
-		babylonNodeAdapter:     babylonNodeAdapter,
-		metrics:                metrics,
-		tracker:                NewTrackedDelegations(),
-		newDelegationChan:      make(chan *newDelegation),
-		delegetionInactiveChan: make(chan *delegationInactive),
+		quit:                make(chan struct{}),
+		cfg:                 cfg,
+		logger:              parentLogger.With(zap.String("module", "unbonding_watcher")).Sugar(),
+		btcNotifier:         btcNotifier,
+		blockStream:         blockStream,
+		hintCache:           hintCache,
+		alerts:              alerts,
+		babylonNodeAdapter:  babylonNodeAdapter,
+		metrics:             metrics,
+		tracker:             NewTrackedDelegations(),
+		newDelegationChan:   make(chan *newDelegation),
+		delegationStateChan: make(chan *delegationStateChange),
+		watchSpendChan:      make(chan *watchedSpend),
+		spendJobChan:        make(chan *watchedSpend),
 	}
 }
 
@@ -97,29 +146,38 @@ func (uw *UnbondingWatcher) Start() error {
 	uw.startOnce.Do(func() {
 		uw.logger.Info("starting unbonding watcher")
 
-		blockEventNotifier, err := uw.btcNotifier.RegisterBlockEpochNtfn(nil)
-
-		if err != nil {
-			startErr = err
-			return
-		}
+		blockSubscription := uw.blockStream.Subscribe("unbonding_watcher")
 
-		// we registered for notifications with `nil`  so we should receive best block
-		// immeadiatly
+		// the first block of a subscription is the best block
 		select {
-		case block := <-blockEventNotifier.Epochs:
+		case block, ok := <-blockSubscription.Events():
+			if !ok {
+				startErr = errors.New("block stream stopped before watcher finished start")
+				return
+			}
 			uw.currentBestBlockHeight.Store(uint32(block.Height))
 		case <-uw.quit:
+			blockSubscription.Cancel()
 			startErr = errors.New("watcher quit before finishing start")
 			return
 		}
 
 		uw.logger.Infof("Initial btc best block height is: %d", uw.currentBestBlockHeight.Load())
 
-		uw.wg.Add(3)
-		go uw.handleNewBlocks(blockEventNotifier)
+		uw.wg.Add(4)
+		go uw.handleNewBlocks(blockSubscription)
 		go uw.handleDelegations()
 		go uw.fetchDelegations()
+		go uw.pollSpends()
+		for i := uint32(0); i < uw.cfg.SpendWorkers; i++ {
+			uw.wg.Add(1)
+			go uw.spendWorker()
+		}
+		// there is nothing to prune without a persistent hint cache
+		if uw.cfg.HintCacheFile != "" && uw.cfg.HintCachePruneInterval > 0 {
+			uw.wg.Add(1)
+			go uw.pruneHints()
+		}
 		uw.logger.Info("unbonding watcher started")
 	})
 	return startErr
@@ -136,15 +194,20 @@ func (uw *UnbondingWatcher) Stop() error {
 	return stopErr
 }
 
-func (uw *UnbondingWatcher) handleNewBlocks(blockNotifier *notifier.BlockEpochEvent) {
+func (uw *UnbondingWatcher) handleNewBlocks(blockSubscription *blockstream.Subscription) {
 	defer uw.wg.Done()
-	defer blockNotifier.Cancel()
+	defer blockSubscription.Cancel()
 	for {
 		select {
-		case block, ok := <-blockNotifier.Epochs:
+		case block, ok := <-blockSubscription.Events():
 			if !ok {
 				return
 			}
+			if block.EventType == types.BlockDisconnected {
+				uw.currentBestBlockHeight.Store(uint32(block.Height - 1))
+				uw.logger.Debugf("Btc block %d is disconnected", block.Height)
+				continue
+			}
 			uw.currentBestBlockHeight.Store(uint32(block.Height))
 			uw.logger.Debugf("Received new best btc block: %d", block.Height)
 		case <-uw.quit:
@@ -153,6 +216,27 @@ func (uw *UnbondingWatcher) handleNewBlocks(blockNotifier *notifier.BlockEpochEv
 	}
 }
 
+// pushNewDelegation reports the delegation to the newDelegationChan, unless it is already tracked
+func (uw *UnbondingWatcher) pushNewDelegation(delegation *Delegation) {
+	stakingTxHash := delegation.StakingTx.TxHash()
+
+	// if we already have this delegation, skip it
+	if uw.tracker.GetDelegation(stakingTxHash) != nil {
+		return
+	}
+	utils.PushOrQuit(uw.newDelegationChan, &newDelegation{
+		stakingTxHash:           stakingTxHash,
+		stakingTx:               delegation.StakingTx,
+		stakingOutputIdx:        delegation.StakingOutputIdx,
+		delegationStartHeight:   delegation.DelegationStartHeight,
+		unbondingOutput:         delegation.UnbondingOutput,
+		slashingTxHash:          delegation.SlashingTxHash,
+		unbondingSlashingTxHash: delegation.UnbondingSlashingTxHash,
+		stakingScripts:          delegation.StakingScripts,
+		unbondingScripts:        delegation.UnbondingScripts,
+	}, uw.quit)
+}
+
 // checkBabylonDelegations iterates over all active babylon delegations, and reports not already
 // tracked delegations to the newDelegationChan
 func (uw *UnbondingWatcher) checkBabylonDelegations() error {
@@ -166,19 +250,8 @@ func (uw *UnbondingWatcher) checkBabylonDelegations() error {
 
 		uw.logger.Debugf("fetched %d delegations from babylon", len(delegations))
 
-		for _, delegation := range delegations {
-			stakingTxHash := delegation.StakingTx.TxHash()
-
-			// if we already have this delegation, skip it
-			if uw.tracker.GetDelegation(stakingTxHash) == nil {
-				utils.PushOrQuit(uw.newDelegationChan, &newDelegation{
-					stakingTxHash:         stakingTxHash,
-					stakingTx:             delegation.StakingTx,
-					stakingOutputIdx:      delegation.StakingOutputIdx,
-					delegationStartHeight: delegation.DelegationStartHeight,
-					unbondingOutput:       delegation.UnbondingOutput,
-				}, uw.quit)
-			}
+		for idx := range delegations {
+			uw.pushNewDelegation(&delegations[idx])
 		}
 
 		if len(delegations) < int(uw.cfg.NewDelegationsBatchSize) {
@@ -190,46 +263,188 @@ func (uw *UnbondingWatcher) checkBabylonDelegations() error {
 	}
 }
 
+// checkUnbondedBabylonDelegations iterates over all babylon delegations unbonded by
+// their staker, and reports not already tracked delegations to the newDelegationChan.
+// Their staking output is watched as the one of an active delegation, so that its
+// spend by the unbonding tx is found again and the unbonding output is watched.
+func (uw *UnbondingWatcher) checkUnbondedBabylonDelegations() error {
+	var i = uint64(0)
+	for {
+		delegations, pageSize, err := uw.babylonNodeAdapter.UnbondedBtcDelegations(i, uw.cfg.NewDelegationsBatchSize)
+
+		if err != nil {
+			return fmt.Errorf("error fetching unbonded delegations from babylon: %v", err)
+		}
+
+		uw.logger.Debugf("fetched %d unbonded delegations from babylon", len(delegations))
+
+		for idx := range delegations {
+			uw.pushNewDelegation(&delegations[idx])
+		}
+
+		if pageSize < uw.cfg.NewDelegationsBatchSize {
+			return nil
+		}
+
+		i += uw.cfg.NewDelegationsBatchSize
+	}
+}
+
+// rescanDelegations checks all active babylon delegations, as well as the unbonded
+// ones if includeUnbonded is set, and returns whether it succeeded
+func (uw *UnbondingWatcher) rescanDelegations(includeUnbonded bool) bool {
+	uw.logger.Debug("Quering babylon for new delegations")
+	btcLightClientTipHeight, err := uw.babylonNodeAdapter.BtcClientTipHeight()
+
+	if err != nil {
+		uw.logger.Errorf("error fetching babylon tip height: %v", err)
+		return false
+	}
+
+	currentBtcNodeHeight := uw.currentBestBlockHeight.Load()
+
+	// Our local node is out of sync with the babylon btc light client. If we would
+	// query for delegation we might receive delegations from blocks that we cannot check.
+	// Log this and give node chance to catch up i.e do not check current delegations
+	if currentBtcNodeHeight < btcLightClientTipHeight {
+		uw.logger.Debugf("btc light client tip height is %d, connected node best block height is %d. Waiting for node to catch up", btcLightClientTipHeight, uw.currentBestBlockHeight.Load())
+		return false
+	}
+
+	if err := uw.checkBabylonDelegations(); err != nil {
+		uw.logger.Errorf("error checking babylon delegations: %v", err)
+		return false
+	}
+	if includeUnbonded {
+		if err := uw.checkUnbondedBabylonDelegations(); err != nil {
+			uw.logger.Errorf("error checking unbonded babylon delegations: %v", err)
+			return false
+		}
+	}
+	return true
+}
+
+// handleActivatedDelegation starts tracking a delegation which became active on babylon
+func (uw *UnbondingWatcher) handleActivatedDelegation(stakingTxHash chainhash.Hash) {
+	if uw.tracker.GetDelegation(stakingTxHash) != nil {
+		return
+	}
+
+	delegation, err := uw.babylonNodeAdapter.ActiveBtcDelegation(stakingTxHash)
+	if err != nil {
+		uw.logger.Errorf("error fetching activated delegation %s, leaving it to the next rescan: %v", stakingTxHash, err)
+		return
+	}
+	if delegation == nil {
+		uw.logger.Debugf("activated delegation %s is no longer active", stakingTxHash)
+		return
+	}
+	uw.pushNewDelegation(delegation)
+}
+
+// fetchDelegations discovers the delegations to watch. Delegations are discovered
+// as they become active on babylon, and by rescans of all active delegations at
+// startup, upon resubscribing to babylon events, and then every
+// CheckDelegationsInterval, which catch up on the events missed while disconnected
+// from babylon. The rescan at startup also discovers the delegations which were
+// unbonding on BTC when the watcher stopped, as they are no longer active.
 func (uw *UnbondingWatcher) fetchDelegations() {
 	defer uw.wg.Done()
-	ticker := time.NewTicker(uw.cfg.CheckDelegationsInterval)
-	defer ticker.Stop()
+	quitCtx, cancel := uw.quitContext()
+	defer cancel()
+
+	activated := bbnevents.SubscribeActivatedDelegations(quitCtx, uw.babylonNodeAdapter, activatedDelegationsSubscriber, uw.logger)
+
+	rescanTimer := time.NewTimer(0)
+	defer rescanTimer.Stop()
+	bootstrapped := false
 
 	for {
 		select {
-		case <-ticker.C:
-			uw.logger.Debug("Quering babylon for new delegations")
-			btcLightClientTipHeight, err := uw.babylonNodeAdapter.BtcClientTipHeight()
-
-			if err != nil {
-				uw.logger.Errorf("error fetching babylon tip height: %v", err)
-				continue
+		case <-activated.Resubscribed():
+			// catch up on the delegations activated while the subscription was down
+			if !rescanTimer.Stop() {
+				<-rescanTimer.C
+			}
+			rescanTimer.Reset(0)
+
+		case <-rescanTimer.C:
+			if uw.rescanDelegations(!bootstrapped) {
+				bootstrapped = true
+				rescanTimer.Reset(uw.cfg.CheckDelegationsInterval)
+			} else {
+				// do not wait for the next rescan when the initial one has not happened yet
+				rescanTimer.Reset(min(uw.cfg.CheckDelegationsInterval, failedRescanRetryInterval))
 			}
 
-			currentBtcNodeHeight := uw.currentBestBlockHeight.Load()
+		case stakingTxHash := <-activated.Activated():
+			uw.handleActivatedDelegation(stakingTxHash)
 
-			// Our local node is out of sync with the babylon btc light client. If we would
-			// query for delegation we might receive delegations from blocks that we cannot check.
-			// Log this and give node chance to catch up i.e do not check current delegations
-			if currentBtcNodeHeight < btcLightClientTipHeight {
-				uw.logger.Debugf("btc light client tip height is %d, connected node best block height is %d. Waiting for node to catch up", btcLightClientTipHeight, uw.currentBestBlockHeight.Load())
-				continue
-			}
+		case <-uw.quit:
+			uw.logger.Debug("fetch delegations loop quit")
+			return
+		}
+	}
+}
 
-			err = uw.checkBabylonDelegations()
+// pruneHints periodically prunes the hints left in the hint cache by requests
+// that are no longer registered, e.g. of delegations that stopped being active
+// while the tracker was down, so that the hint cache does not grow forever
+func (uw *UnbondingWatcher) pruneHints() {
+	defer uw.wg.Done()
+	ticker := time.NewTicker(uw.cfg.HintCachePruneInterval)
+	defer ticker.Stop()
 
+	for {
+		select {
+		case <-ticker.C:
+			pruned, err := uw.hintCache.Prune(uw.cfg.HintCachePruneDepth)
 			if err != nil {
-				uw.logger.Errorf("error checking babylon delegations: %v", err)
+				uw.logger.Errorf("error pruning hint cache: %v", err)
 				continue
 			}
-
+			if pruned > 0 {
+				uw.logger.Debugf("pruned %d stale hints from hint cache", pruned)
+				uw.metrics.PrunedHintsCounter.Add(float64(pruned))
+			}
 		case <-uw.quit:
-			uw.logger.Debug("fetch delegations loop quit")
+			uw.logger.Debug("prune hints loop quit")
 			return
 		}
 	}
 }
 
+// purgeSpendHints removes the spend hints of the staking and unbonding outputs
+// of a delegation in a terminal state, which are never registered again
+func (uw *UnbondingWatcher) purgeSpendHints(td *TrackedDelegation) {
+	stakingTxHash := td.StakingTx.TxHash()
+	spendRequest, err := notifier.NewSpendRequest(
+		&wire.OutPoint{Hash: stakingTxHash, Index: td.StakingOutputIdx},
+		td.StakingTx.TxOut[td.StakingOutputIdx].PkScript,
+	)
+	if err != nil {
+		uw.logger.Errorf("error building spend request of staking tx %s: %v", stakingTxHash, err)
+		return
+	}
+	spendRequests := []notifier.SpendRequest{spendRequest}
+
+	if td.UnbondingTxHash != (chainhash.Hash{}) {
+		spendRequest, err := notifier.NewSpendRequest(
+			&wire.OutPoint{Hash: td.UnbondingTxHash, Index: 0},
+			td.UnbondingOutput.PkScript,
+		)
+		if err != nil {
+			uw.logger.Errorf("error building spend request of unbonding tx %s: %v", td.UnbondingTxHash, err)
+		} else {
+			spendRequests = append(spendRequests, spendRequest)
+		}
+	}
+
+	if err := uw.hintCache.PurgeSpendHint(spendRequests...); err != nil {
+		uw.logger.Errorf("error purging spend hints of staking tx %s: %v", stakingTxHash, err)
+	}
+}
+
 func getStakingTxInputIdx(tx *wire.MsgTx, td *TrackedDelegation) (int, error) {
 	stakingTxHash := td.StakingTx.TxHash()
 
@@ -342,47 +557,389 @@ func (uw *UnbondingWatcher) reportUnbondingToBabylon(
 	)
 }
 
-func (uw *UnbondingWatcher) watchForSpend(spendEvent *notifier.SpendEvent, td *TrackedDelegation) {
+// alertUnknownSpend raises an alert on the unknown spend of the staking output,
+// unless the delegation is already known to be inactive on Babylon
+func (uw *UnbondingWatcher) alertUnknownSpend(stakingTxHash, spendingTxHash chainhash.Hash, reason error) {
+	if uw.alerts == nil {
+		return
+	}
+	active, err := uw.babylonNodeAdapter.IsDelegationActive(stakingTxHash)
+	if err != nil {
+		// better a spurious alert than a missed one
+		uw.logger.Errorf("error checking if delegation for staking tx %s is active: %v", stakingTxHash, err)
+	} else if !active {
+		return
+	}
+	uw.alerts.Raise(&alert.Alert{
+		Kind:    alert.KindUnknownStakingSpend,
+		Message: fmt.Sprintf("staking output of active delegation %s is spent by tx %s of unknown type: %v", stakingTxHash, spendingTxHash, reason),
+		Evidence: alert.Evidence{
+			StakingTxHash:  stakingTxHash.String(),
+			SpendingTxHash: spendingTxHash.String(),
+		},
+	})
+}
+
+func (uw *UnbondingWatcher) resolveUnknownSpendAlert(stakingTxHash chainhash.Hash) {
+	if uw.alerts == nil {
+		return
+	}
+	uw.alerts.Resolve(alert.Key(alert.KindUnknownStakingSpend, 0, stakingTxHash.String()))
+}
+
+// waitForConfirmedSpend waits for a spend of the watched output which is confirmed
+// by the configured depth. A spend rolled back by a reorg before that is discarded,
+// and the output is watched again. It returns nil if the watcher quits.
+func (uw *UnbondingWatcher) waitForConfirmedSpend(ctx context.Context, spendEvent *notifier.SpendEvent) *notifier.SpendDetail {
+	for {
+		var spendDetail *notifier.SpendDetail
+		select {
+		case sd, ok := <-spendEvent.Spend:
+			if !ok {
+				return nil
+			}
+			spendDetail = sd
+		case <-ctx.Done():
+			return nil
+		}
+
+		if uw.waitForSpendConfirmation(ctx, spendEvent, spendDetail) {
+			return spendDetail
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		uw.spendReorged(spendDetail)
+	}
+}
+
+func (uw *UnbondingWatcher) spendReorged(spendDetail *notifier.SpendDetail) {
+	uw.metrics.ReorgedSpendsCounter.Inc()
+	uw.logger.Infof("Spending tx %s of %s is reorged out before being %d blocks deep, watching the output again",
+		spendDetail.SpenderTxHash, spendDetail.SpentOutPoint, uw.cfg.SpendConfirmationDepth)
+}
+
+// waitForSpendConfirmation returns whether the spending tx becomes deep enough
+// before it is reorged out or the watcher quits
+func (uw *UnbondingWatcher) waitForSpendConfirmation(
+	ctx context.Context,
+	spendEvent *notifier.SpendEvent,
+	spendDetail *notifier.SpendDetail,
+) bool {
+	// the spending tx is already in a block
+	if uw.cfg.SpendConfirmationDepth <= 1 {
+		return true
+	}
+
+	// confirmations are matched by txid together with any standard output script
+	var pkScript []byte
+	for _, txOut := range spendDetail.SpendingTx.TxOut {
+		if _, err := txscript.ParsePkScript(txOut.PkScript); err == nil {
+			pkScript = txOut.PkScript
+			break
+		}
+	}
+	if pkScript == nil {
+		uw.logger.Debugf("Spending tx %s has no standard output to watch confirmations of, counting the blocks on top of it", spendDetail.SpenderTxHash)
+		return uw.waitForSpendDepth(ctx, spendEvent, spendDetail)
+	}
+
+	confEvent, err := uw.btcNotifier.RegisterConfirmationsNtfn(
+		spendDetail.SpenderTxHash,
+		pkScript,
+		uw.cfg.SpendConfirmationDepth,
+		uint32(spendDetail.SpendingHeight),
+	)
+	if err != nil {
+		uw.logger.Errorf("error registering confirmation ntfn for spending tx %s, counting the blocks on top of it: %v", spendDetail.SpenderTxHash, err)
+		return uw.waitForSpendDepth(ctx, spendEvent, spendDetail)
+	}
+	defer confEvent.Cancel()
+
+	// a reorg of the spending tx fires both the negative confirmation and the
+	// reorg of the spend. Only the latter is waited for, as a reorg signal left
+	// behind would discard the next spend.
+	select {
+	case <-confEvent.Confirmed:
+		return true
+	case <-spendEvent.Reorg:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// waitForSpendDepth returns whether the best chain grows to the configured depth
+// on top of the block of the spending tx before the spend is reorged out or the
+// watcher quits. It is used for spending txs whose confirmations cannot be watched.
+func (uw *UnbondingWatcher) waitForSpendDepth(
+	ctx context.Context,
+	spendEvent *notifier.SpendEvent,
+	spendDetail *notifier.SpendDetail,
+) bool {
+	blockEvent, err := uw.btcNotifier.RegisterBlockEpochNtfn(nil)
+	if err != nil {
+		uw.logger.Errorf("error registering block ntfn for spending tx %s, acting on it right away: %v", spendDetail.SpenderTxHash, err)
+		return true
+	}
+	defer blockEvent.Cancel()
+
+	confirmedHeight := spendDetail.SpendingHeight + int32(uw.cfg.SpendConfirmationDepth) - 1
+	for {
+		select {
+		case epoch, ok := <-blockEvent.Epochs:
+			if !ok {
+				return false
+			}
+			if epoch.Height >= confirmedHeight {
+				return true
+			}
+		case <-spendEvent.Reorg:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// pollSpends hands the delegations whose staking output is spent over to the
+// spend workers. The spend events of all watched staking outputs are polled by
+// this single routine, so that the number of routines does not grow with the
+// number of delegations.
+func (uw *UnbondingWatcher) pollSpends() {
+	defer uw.wg.Done()
+
+	ticker := time.NewTicker(spendPollInterval)
+	defer ticker.Stop()
+
+	watched := make(map[chainhash.Hash]*watchedSpend)
+	// spent staking outputs waiting for a free worker. They are queued here so
+	// that new staking outputs are accepted while all workers are busy.
+	var spent []*watchedSpend
+	for {
+		var jobChan chan *watchedSpend
+		var nextJob *watchedSpend
+		if len(spent) > 0 {
+			jobChan = uw.spendJobChan
+			nextJob = spent[0]
+		}
+
+		select {
+		case ws := <-uw.watchSpendChan:
+			watched[ws.td.StakingTx.TxHash()] = ws
+		case jobChan <- nextJob:
+			spent = spent[1:]
+			uw.metrics.PendingSpendsGauge.Set(float64(len(spent)))
+		case <-ticker.C:
+			for stakingTxHash, ws := range watched {
+				select {
+				case spendDetail, ok := <-ws.spendEvent.Spend:
+					delete(watched, stakingTxHash)
+					if !ok {
+						continue
+					}
+					ws.spendDetail = spendDetail
+					spent = append(spent, ws)
+				default:
+				}
+			}
+			uw.metrics.PendingSpendsGauge.Set(float64(len(spent)))
+		case <-uw.quit:
+			return
+		}
+	}
+}
+
+// spendWorker handles the spends of staking outputs, one at a time
+func (uw *UnbondingWatcher) spendWorker() {
 	defer uw.wg.Done()
 	quitCtx, cancel := uw.quitContext()
 	defer cancel()
 
-	var spendingTx *wire.MsgTx = nil
-	select {
-	case spendDetail := <-spendEvent.Spend:
-		spendingTx = spendDetail.SpendingTx
-	case <-uw.quit:
+	for {
+		select {
+		case ws := <-uw.spendJobChan:
+			uw.handleSpend(quitCtx, ws)
+		case <-uw.quit:
+			return
+		}
+	}
+}
+
+// handleSpend acts on the spend of the staking output of a delegation once it
+// is deep enough. A spend reorged out before that is discarded, and the staking
+// output is watched again.
+func (uw *UnbondingWatcher) handleSpend(quitCtx context.Context, ws *watchedSpend) {
+	spendDetail, td := ws.spendDetail, ws.td
+	if !uw.waitForSpendConfirmation(quitCtx, ws.spendEvent, spendDetail) {
+		if quitCtx.Err() != nil {
+			return
+		}
+		uw.spendReorged(spendDetail)
+		ws.spendDetail = nil
+		utils.PushOrQuit[*watchedSpend](uw.watchSpendChan, ws, uw.quit)
 		return
 	}
 
-	schnorrSignature, err := tryParseStakerSignatureFromSpentTx(spendingTx, td)
+	spendingTx := spendDetail.SpendingTx
+	classification := ClassifySpend(spendingTx, td)
 	delegationId := td.StakingTx.TxHash()
 	spendingTxHash := spendingTx.TxHash()
+	uw.metrics.DetectedSpendsCounterVec.WithLabelValues("staking", classification.Type.String()).Inc()
 
-	if err != nil {
-		uw.metrics.DetectedNonUnbondingTransactionsCounter.Inc()
-		// Error means that this is not unbonding tx. At this point, it means that it is
-		// either withdrawal transaction or slashing transaction spending staking staking output.
-		// As we only care about unbonding transactions, we do not need to take additional actions.
-		// We start polling babylon for delegation to stop being active, and then delete it from tracker.
-		uw.logger.Debugf("Spending tx %s for staking tx %s is not unbonding tx. Info: %v", spendingTxHash, delegationId, err)
-		uw.waitForDelegationToStopBeingActive(quitCtx, delegationId)
-	} else {
+	stateChange := &delegationStateChange{stakingTxHash: delegationId}
+	switch classification.Type {
+	case SpendTypeUnbonding:
 		uw.metrics.DetectedUnbondingTransactionsCounter.Inc()
 		// We found valid unbonding tx. We need to try to report it to babylon.
 		// We stop reporting if delegation is no longer active or we succeed.
 		uw.logger.Debugf("found unbonding tx %s for staking tx %s", spendingTxHash, delegationId)
-		uw.reportUnbondingToBabylon(quitCtx, delegationId, schnorrSignature)
+		uw.reportUnbondingToBabylon(quitCtx, delegationId, classification.StakerSignature)
 		uw.logger.Debugf("unbonding tx %s for staking tx %s reported to babylon", spendingTxHash, delegationId)
+		// from now on, the unbonding output is watched
+		stateChange.state = DelegationStateUnbonding
+		stateChange.unbondingTxHash = spendingTxHash
+		stateChange.unbondingHeight = spendDetail.SpendingHeight
+	case SpendTypeUnknown:
+		uw.metrics.DetectedNonUnbondingTransactionsCounter.Inc()
+		// The staking output is spent in a way the protocol does not expect. Babylon is
+		// not aware of it, so an operator has to look into it while the delegation is active.
+		uw.logger.Warnf("Spending tx %s for staking tx %s is of unknown type: %v", spendingTxHash, delegationId, classification.Reason)
+		uw.alertUnknownSpend(delegationId, spendingTxHash, classification.Reason)
+		uw.waitForDelegationToStopBeingActive(quitCtx, delegationId)
+		if quitCtx.Err() == nil {
+			uw.resolveUnknownSpendAlert(delegationId)
+		}
+		stateChange.state = DelegationStateUnknownSpend
+	default:
+		uw.metrics.DetectedNonUnbondingTransactionsCounter.Inc()
+		// Withdrawal and slashing txs need no action from us. We start polling babylon
+		// for delegation to stop being active, and then delete it from tracker.
+		uw.logger.Debugf("Spending tx %s for staking tx %s is %s tx", spendingTxHash, delegationId, classification.Type)
+		uw.waitForDelegationToStopBeingActive(quitCtx, delegationId)
+		stateChange.state = spendTypeToState(classification.Type)
+	}
+
+	utils.PushOrQuit[*delegationStateChange](uw.delegationStateChan, stateChange, uw.quit)
+}
+
+// watchForUnbondingSpend waits for the unbonding output of the delegation to be
+// spent, which brings the delegation to a terminal state
+func (uw *UnbondingWatcher) watchForUnbondingSpend(spendEvent *notifier.SpendEvent, td *TrackedDelegation) {
+	defer uw.wg.Done()
+	quitCtx, cancel := uw.quitContext()
+	defer cancel()
+
+	spendDetail := uw.waitForConfirmedSpend(quitCtx, spendEvent)
+	if spendDetail == nil {
+		return
 	}
+	spendingTx := spendDetail.SpendingTx
 
-	utils.PushOrQuit[*delegationInactive](
-		uw.delegetionInactiveChan,
-		&delegationInactive{stakingTxHash: delegationId},
+	classification := ClassifyUnbondingSpend(spendingTx, td)
+	delegationId := td.StakingTx.TxHash()
+	spendingTxHash := spendingTx.TxHash()
+	uw.metrics.DetectedSpendsCounterVec.WithLabelValues("unbonding", classification.Type.String()).Inc()
+
+	if classification.Type == SpendTypeUnknown {
+		// the delegation is already unbonded on Babylon, so nothing is at stake anymore
+		uw.logger.Warnf("Spending tx %s of unbonding tx %s for staking tx %s is of unknown type: %v",
+			spendingTxHash, td.UnbondingTxHash, delegationId, classification.Reason)
+	} else {
+		uw.logger.Debugf("Spending tx %s of unbonding tx %s for staking tx %s is %s tx",
+			spendingTxHash, td.UnbondingTxHash, delegationId, classification.Type)
+	}
+
+	utils.PushOrQuit[*delegationStateChange](
+		uw.delegationStateChan,
+		&delegationStateChange{stakingTxHash: delegationId, state: spendTypeToState(classification.Type)},
 		uw.quit,
 	)
 }
 
+// spendTypeToState returns the terminal state of a delegation whose output is spent by the given type of tx
+func spendTypeToState(spendType SpendType) DelegationState {
+	switch spendType {
+	case SpendTypeWithdrawal:
+		return DelegationStateWithdrawn
+	case SpendTypeSlashing:
+		return DelegationStateSlashed
+	default:
+		return DelegationStateUnknownSpend
+	}
+}
+
+// startUnbonding moves the delegation to the unbonding state, and starts watching its unbonding output
+func (uw *UnbondingWatcher) startUnbonding(change *delegationStateChange) {
+	del, err := uw.tracker.SetUnbonding(change.stakingTxHash, change.unbondingTxHash)
+	if err != nil {
+		uw.logger.Errorf("error moving delegation to unbonding state: %v", err)
+		return
+	}
+	uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(DelegationStateActive.String()).Dec()
+	uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(DelegationStateUnbonding.String()).Inc()
+
+	// unbonding tx always has only one output
+	unbondingOutpoint := wire.OutPoint{
+		Hash:  change.unbondingTxHash,
+		Index: 0,
+	}
+	spendEv, err := uw.btcNotifier.RegisterSpendNtfn(
+		&unbondingOutpoint,
+		del.UnbondingOutput.PkScript,
+		uint32(change.unbondingHeight),
+	)
+	if err != nil {
+		uw.logger.Errorf("error registering spend ntfn for unbonding tx %s of staking tx %s, retrying in %v: %v",
+			change.unbondingTxHash, change.stakingTxHash, failedUnbondingRetryInterval, err)
+		uw.cancelUnbonding(change)
+		return
+	}
+
+	uw.wg.Add(1)
+	go uw.watchForUnbondingSpend(spendEv, del)
+}
+
+// cancelUnbonding moves the delegation back to the active state, as its unbonding
+// output is not watched, and starts unbonding again after failedUnbondingRetryInterval
+func (uw *UnbondingWatcher) cancelUnbonding(change *delegationStateChange) {
+	if err := uw.tracker.CancelUnbonding(change.stakingTxHash); err != nil {
+		uw.logger.Errorf("error moving delegation back to active state: %v", err)
+		return
+	}
+	uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(DelegationStateUnbonding.String()).Dec()
+	uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(DelegationStateActive.String()).Inc()
+
+	uw.wg.Add(1)
+	go func() {
+		defer uw.wg.Done()
+		select {
+		case <-time.After(failedUnbondingRetryInterval):
+			utils.PushOrQuit[*delegationStateChange](uw.delegationStateChan, change, uw.quit)
+		case <-uw.quit:
+		}
+	}()
+}
+
+// finishDelegation moves the delegation to a terminal state, and stops tracking it
+func (uw *UnbondingWatcher) finishDelegation(change *delegationStateChange) {
+	del := uw.tracker.GetDelegation(change.stakingTxHash)
+	if del == nil {
+		return
+	}
+	prevState, err := uw.tracker.SetState(change.stakingTxHash, change.state)
+	if err != nil {
+		uw.logger.Errorf("error moving delegation to %s state: %v", change.state, err)
+		return
+	}
+	uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(prevState.String()).Dec()
+	uw.metrics.FinishedDelegationsCounterVec.WithLabelValues(change.state.String()).Inc()
+
+	// remove delegation from tracker, together with its spend hints
+	uw.purgeSpendHints(del)
+	uw.tracker.RemoveDelegation(change.stakingTxHash)
+}
+
 func (uw *UnbondingWatcher) handleDelegations() {
 	defer uw.wg.Done()
 	for {
@@ -394,6 +951,10 @@ func (uw *UnbondingWatcher) handleDelegations() {
 				newDelegation.stakingTx,
 				newDelegation.stakingOutputIdx,
 				newDelegation.unbondingOutput,
+				newDelegation.slashingTxHash,
+				newDelegation.unbondingSlashingTxHash,
+				newDelegation.stakingScripts,
+				newDelegation.unbondingScripts,
 			)
 
 			if err != nil {
@@ -401,7 +962,7 @@ func (uw *UnbondingWatcher) handleDelegations() {
 				continue
 			}
 
-			uw.metrics.NumberOfTrackedActiveDelegations.Inc()
+			uw.metrics.TrackedDelegationsGaugeVec.WithLabelValues(DelegationStateActive.String()).Inc()
 
 			stakingOutpoint := wire.OutPoint{
 				Hash:  newDelegation.stakingTxHash,
@@ -419,14 +980,14 @@ func (uw *UnbondingWatcher) handleDelegations() {
 				continue
 			}
 
-			uw.wg.Add(1)
-			go uw.watchForSpend(spendEv, del)
-		case in := <-uw.delegetionInactiveChan:
-			uw.logger.Debugf("Delegation for staking transaction with hash %s stopped being active", in.stakingTxHash)
-			// remove delegation from tracker
-			uw.tracker.RemoveDelegation(in.stakingTxHash)
-
-			uw.metrics.NumberOfTrackedActiveDelegations.Dec()
+			utils.PushOrQuit[*watchedSpend](uw.watchSpendChan, &watchedSpend{spendEvent: spendEv, td: del}, uw.quit)
+		case change := <-uw.delegationStateChan:
+			uw.logger.Debugf("Delegation for staking transaction with hash %s moved to %s state", change.stakingTxHash, change.state)
+			if change.state == DelegationStateUnbonding {
+				uw.startUnbonding(change)
+			} else {
+				uw.finishDelegation(change)
+			}
 
 		case <-uw.quit:
 			uw.logger.Debug("handle delegations loop quit")
@@ -1,16 +1,24 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 
 	bbnclient "github.com/babylonchain/babylon/client/client"
+	bbnqccfg "github.com/babylonchain/babylon/client/config"
+	bbnqc "github.com/babylonchain/babylon/client/query"
 	"github.com/babylonchain/vigilante/btcclient"
 	bst "github.com/babylonchain/vigilante/btcstaking-tracker"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/btcslasher"
+	"github.com/babylonchain/vigilante/btcstaking-tracker/inspector"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
 	"github.com/babylonchain/vigilante/netparams"
 	"github.com/babylonchain/vigilante/rpcserver"
+	"github.com/babylonchain/vigilante/submitter/relayer"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 func GetBTCStakingTracker() *cobra.Command {
@@ -60,7 +68,8 @@ func GetBTCStakingTracker() *cobra.Command {
 			}
 
 			// create BTC client and connect to BTC server
-			// Note that monitor needs to subscribe to new BTC blocks
+			// Note that the tracker only queries the BTC client, and receives
+			// BTC blocks from the BTC notifier
 			btcClient, err := btcclient.NewWithBlockSubscriber(
 				&cfg.BTC,
 				cfg.Common.RetrySleepTime,
@@ -71,24 +80,77 @@ func GetBTCStakingTracker() *cobra.Command {
 				panic(fmt.Errorf("failed to open BTC client: %w", err))
 			}
 
-			// create BTC notifier
-			// TODO: is it possible to merge BTC client and BTC notifier?
+			// create BTC notifier, whose blocks are fetched once by the block stream
+			// of the tracker and sent to all its routines
 			btcParams, err := netparams.GetBTCParams(cfg.BTC.NetParams)
 			if err != nil {
 				panic(fmt.Errorf("failed to get BTC parameter: %w", err))
 			}
 			btcCfg := btcclient.CfgToBtcNodeBackendConfig(cfg.BTC, "") // we will read certifcates from file
-			btcNotifier, err := btcclient.NewNodeBackend(btcCfg, btcParams, &btcclient.EmptyHintCache{})
+			var hintCache btcclient.HintCache = &btcclient.EmptyHintCache{}
+			if cfg.BTCStakingTracker.HintCacheFile != "" {
+				boltHintCache, err := btcclient.NewBoltHintCache(cfg.BTCStakingTracker.HintCacheFile)
+				if err != nil {
+					panic(fmt.Errorf("failed to open hint cache: %w", err))
+				}
+				hintCache = boltHintCache
+				// the notifier commits hints until it is stopped, hence the hint
+				// cache is closed last
+				addInterruptHandler(func() {
+					rootLogger.Info("Closing hint cache...")
+					if err := boltHintCache.Close(); err != nil {
+						rootLogger.Error("failed to close hint cache", zap.Error(err))
+					}
+					rootLogger.Info("Hint cache closed")
+				})
+			}
+			btcNotifier, err := btcclient.NewNodeBackend(btcCfg, btcParams, hintCache)
 			if err != nil {
 				panic(fmt.Errorf("failed to create btc chain notifier: %w", err))
 			}
 
+			// create the fee bumper of stalled slashing txs, whose wallet holds the
+			// key of the slashing address
+			var feeBumper *btcslasher.FeeBumper
+			if cfg.BTCStakingTracker.SlashingTxs.EnableFeeBumping {
+				btcWallet, err := btcclient.NewWallet(&cfg.BTC, rootLogger)
+				if err != nil {
+					panic(fmt.Errorf("failed to open BTC wallet: %w", err))
+				}
+				feeEstimator, err := relayer.NewFeeEstimator(&cfg.BTC)
+				if err != nil {
+					panic(fmt.Errorf("failed to create fee estimator: %w", err))
+				}
+				feeBumper = btcslasher.NewFeeBumper(btcWallet, feeEstimator, &cfg.BTCStakingTracker.SlashingTxs, rootLogger)
+			}
+
+			// open the store of the slashing txs followed up on until they are k-deep
+			var slashingTxStore *btcslasher.SlashingTxStore
+			if cfg.BTCStakingTracker.SlashingTxs.StoreFile != "" {
+				slashingTxStore, err = btcslasher.NewSlashingTxStore(cfg.BTCStakingTracker.SlashingTxs.StoreFile)
+				if err != nil {
+					panic(fmt.Errorf("failed to open slashing tx store: %w", err))
+				}
+				// the slasher writes to the store until it is stopped, hence the
+				// store is closed after the tracker
+				addInterruptHandler(func() {
+					rootLogger.Info("Closing slashing tx store...")
+					if err := slashingTxStore.Close(); err != nil {
+						rootLogger.Error("failed to close slashing tx store", zap.Error(err))
+					}
+					rootLogger.Info("Slashing tx store closed")
+				})
+			}
+
 			bsMetrics := metrics.NewBTCStakingTrackerMetrics()
 
 			bstracker := bst.NewBTCSTakingTracker(
 				btcClient,
 				btcNotifier,
+				hintCache,
 				bbnClient,
+				feeBumper,
+				slashingTxStore,
 				&cfg.BTCStakingTracker,
 				&cfg.Common,
 				rootLogger,
@@ -138,7 +200,7 @@ func GetBTCStakingTracker() *cobra.Command {
 			})
 			addInterruptHandler(func() {
 				rootLogger.Info("Stopping BTC notifier...")
-				if err := bstracker.Stop(); err != nil {
+				if err := btcNotifier.Stop(); err != nil {
 					panic(fmt.Errorf("failed to stop btc chain notifier: %w", err))
 				}
 				rootLogger.Info("BTC notifier shutdown")
@@ -159,5 +221,96 @@ func GetBTCStakingTracker() *cobra.Command {
 	cmd.Flags().StringVar(&babylonKeyDir, "babylon-key", "", "Directory of the Babylon key")
 	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultConfigFile(), "config file")
 	cmd.Flags().Uint64Var(&startHeight, "start-height", 0, "height that the BTC slasher starts scanning for evidences")
+	cmd.AddCommand(getInspectCmd())
+	return cmd
+}
+
+// getInspectCmd returns the CLI command to inspect the lifecycle of a BTC delegation
+// with the checks of the BTC staking tracker, without starting it
+func getInspectCmd() *cobra.Command {
+	var (
+		cfgFile           string
+		stakingTxHashHex  string
+		spendingTxHashHex string
+		jsonOutput        bool
+	)
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Report the lifecycle of a BTC delegation on Babylon and on Bitcoin",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			stakingTxHash, err := chainhash.NewHashFromStr(stakingTxHashHex)
+			if err != nil {
+				return fmt.Errorf("invalid staking tx hash: %w", err)
+			}
+			var spendingTxHash *chainhash.Hash
+			if spendingTxHashHex != "" {
+				spendingTxHash, err = chainhash.NewHashFromStr(spendingTxHashHex)
+				if err != nil {
+					return fmt.Errorf("invalid spending tx hash: %w", err)
+				}
+			}
+
+			cfg, err := config.New(cfgFile)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			rootLogger, err := cfg.CreateLogger()
+			if err != nil {
+				return fmt.Errorf("failed to create logger: %w", err)
+			}
+
+			queryCfg := &bbnqccfg.BabylonQueryConfig{
+				RPCAddr: cfg.Babylon.RPCAddr,
+				Timeout: cfg.Babylon.Timeout,
+			}
+			if err := queryCfg.Validate(); err != nil {
+				return fmt.Errorf("invalid config for query client: %w", err)
+			}
+			bbnQueryClient, err := bbnqc.New(queryCfg)
+			if err != nil {
+				return fmt.Errorf("failed to create babylon query client: %w", err)
+			}
+			if err := bbnQueryClient.Start(); err != nil {
+				return fmt.Errorf("failed to start babylon query client: %w", err)
+			}
+			defer func() { _ = bbnQueryClient.Stop() }()
+
+			// blocks are never subscribed to, as only RPCs are needed
+			btcClient, err := btcclient.NewWithBlockSubscriber(
+				&cfg.BTC,
+				cfg.Common.RetrySleepTime,
+				cfg.Common.MaxRetrySleepTime,
+				rootLogger,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to open BTC client: %w", err)
+			}
+			defer btcClient.Stop()
+
+			btcParams, err := netparams.GetBTCParams(cfg.BTC.NetParams)
+			if err != nil {
+				return fmt.Errorf("failed to get BTC parameter: %w", err)
+			}
+
+			report, err := inspector.New(bbnQueryClient, btcClient, btcParams).Inspect(*stakingTxHash, spendingTxHash)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), report.String())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultConfigFile(), "config file")
+	cmd.Flags().StringVar(&stakingTxHashHex, "staking-tx", "", "hash of the staking tx of the BTC delegation")
+	cmd.Flags().StringVar(&spendingTxHashHex, "spending-tx", "", "hash of a tx to classify as a spend of the staking or unbonding output")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "print the report as JSON")
+	_ = cmd.MarkFlagRequired("staking-tx")
 	return cmd
 }
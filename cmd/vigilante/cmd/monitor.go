@@ -11,6 +11,9 @@ import (
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
 	"github.com/babylonchain/vigilante/monitor"
+	"github.com/babylonchain/vigilante/monitor/evidence"
+	"github.com/babylonchain/vigilante/monitor/valset"
+	"github.com/babylonchain/vigilante/netparams"
 	"github.com/babylonchain/vigilante/rpcserver"
 	"github.com/babylonchain/vigilante/types"
 )
@@ -23,6 +26,7 @@ const (
 // GetMonitorCmd returns the CLI commands for the monitor
 func GetMonitorCmd() *cobra.Command {
 	var genesisFile string
+	var reset bool
 	var cfgFile = ""
 	// Group monitor queries under a subcommand
 	cmd := &cobra.Command{
@@ -77,6 +81,13 @@ func GetMonitorCmd() *cobra.Command {
 				panic(fmt.Errorf("failed to read genesis file: %w", err))
 			}
 
+			if reset {
+				if err := monitor.RemoveState(&cfg.Monitor); err != nil {
+					panic(fmt.Errorf("failed to remove the monitor state file: %w", err))
+				}
+				rootLogger.Info("Removed the monitor state file, starting over from the genesis epoch")
+			}
+
 			// register monitor metrics
 			monitorMetrics := metrics.NewMonitorMetrics()
 
@@ -119,5 +130,117 @@ func GetMonitorCmd() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&genesisFile, genesisFileNameFlag, GenesisFileNameDefault, "genesis file")
 	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultConfigFile(), "config file")
+	cmd.Flags().BoolVar(&reset, "reset", false, "discard the persisted verification progress and start over from the genesis epoch")
+	cmd.AddCommand(getVerifyEvidenceCmd())
+	cmd.AddCommand(getExportValSetHistoryCmd())
+	return cmd
+}
+
+// getVerifyEvidenceCmd returns the CLI command to verify a fork evidence bundle offline
+func getVerifyEvidenceCmd() *cobra.Command {
+	var btcNetwork string
+	cmd := &cobra.Command{
+		Use:   "verify-evidence <file>",
+		Short: "Verify offline a fork evidence bundle written by the monitor",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			btcParams, err := netparams.GetBTCParams(btcNetwork)
+			if err != nil {
+				return err
+			}
+			bundle, err := evidence.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read evidence bundle: %w", err)
+			}
+			report, err := evidence.Verify(bundle, btcParams.PowLimit)
+			if err != nil {
+				return fmt.Errorf("the evidence is invalid: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "The evidence is valid: Babylon is forked at epoch %d\n", report.Epoch)
+			fmt.Fprintf(out, "  checkpoint on BTC commits to Babylon block:     %s\n", report.BTCCheckpointBlockHash)
+			fmt.Fprintf(out, "  checkpoint on Babylon commits to Babylon block: %s\n", report.BabylonCheckpointBlockHash)
+			for _, block := range report.BTCBlocks {
+				fmt.Fprintf(out, "  checkpoint tx included in BTC block %s\n", block)
+			}
+			fmt.Fprintf(out, "Both checkpoints are signed by the validator set in the bundle, which should be cross-checked against Babylon\n")
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&btcNetwork, "btc-network", types.BtcMainnet.String(), "BTC network of the checkpoint txs, which determines the PoW limit")
+	return cmd
+}
+
+// getExportValSetHistoryCmd returns the CLI command to export the validator sets of a
+// range of epochs from Babylon, for the monitor to verify checkpoints without querying them
+func getExportValSetHistoryCmd() *cobra.Command {
+	var (
+		cfgFile   string
+		fromEpoch uint64
+		toEpoch   uint64
+	)
+	cmd := &cobra.Command{
+		Use:   "export-valset-history <file>",
+		Short: "Export the validator sets of a range of epochs from Babylon to a file used by the monitor",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.New(cfgFile)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			queryCfg := &bbnqccfg.BabylonQueryConfig{
+				RPCAddr: cfg.Babylon.RPCAddr,
+				Timeout: cfg.Babylon.Timeout,
+			}
+			if err := queryCfg.Validate(); err != nil {
+				return fmt.Errorf("invalid config for query client: %w", err)
+			}
+			bbnQueryClient, err := bbnqc.New(queryCfg)
+			if err != nil {
+				return fmt.Errorf("failed to create babylon query client: %w", err)
+			}
+			if err := bbnQueryClient.Start(); err != nil {
+				return fmt.Errorf("failed to start babylon query client: %w", err)
+			}
+			defer func() { _ = bbnQueryClient.Stop() }()
+
+			if toEpoch == 0 {
+				// the last ended epoch
+				res, err := bbnQueryClient.CurrentEpoch()
+				if err != nil {
+					return fmt.Errorf("failed to query the current epoch: %w", err)
+				}
+				if res.CurrentEpoch == 0 {
+					return fmt.Errorf("no epoch has ended on Babylon yet")
+				}
+				toEpoch = res.CurrentEpoch - 1
+			}
+			if fromEpoch == 0 || fromEpoch > toEpoch {
+				return fmt.Errorf("invalid range of epochs %d to %d", fromEpoch, toEpoch)
+			}
+
+			history := &valset.History{}
+			for epoch := fromEpoch; epoch <= toEpoch; epoch++ {
+				res, err := bbnQueryClient.BlsPublicKeyList(epoch, nil)
+				if err != nil {
+					return fmt.Errorf("failed to query the validator set of epoch %d: %w", epoch, err)
+				}
+				if err := history.Append(epoch, valset.FromBlsPublicKeyList(res)); err != nil {
+					return err
+				}
+			}
+			if err := history.WriteFile(args[0]); err != nil {
+				return fmt.Errorf("failed to write validator set history: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported the validator sets of epochs %d to %d, with %d changes, to %s\n",
+				fromEpoch, toEpoch, len(history.Changes), args[0])
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", config.DefaultConfigFile(), "config file")
+	cmd.Flags().Uint64Var(&fromEpoch, "from-epoch", 1, "first epoch to export")
+	cmd.Flags().Uint64Var(&toEpoch, "to-epoch", 0, "last epoch to export, defaults to the last ended epoch")
 	return cmd
 }
@@ -5,11 +5,14 @@ import (
 
 	bbnclient "github.com/babylonchain/babylon/client/client"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 
 	"github.com/babylonchain/vigilante/btcclient"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/netparams"
 	"github.com/babylonchain/vigilante/reporter"
+	"github.com/babylonchain/vigilante/reporter/headerwitness"
 	"github.com/babylonchain/vigilante/rpcserver"
 )
 
@@ -21,24 +24,25 @@ func GetReporterCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "reporter",
 		Short: "Vigilant reporter",
-		Run: func(_ *cobra.Command, _ []string) {
+		RunE: func(_ *cobra.Command, _ []string) error {
 			var (
 				err              error
 				cfg              config.Config
-				btcClient        *btcclient.Client
+				btcClient        btcclient.BTCClient
 				babylonClient    *bbnclient.Client
 				vigilantReporter *reporter.Reporter
+				witness          *headerwitness.Witness
 				server           *rpcserver.Server
 			)
 
 			// get the config from the given file or the default file
 			cfg, err = config.New(cfgFile)
 			if err != nil {
-				panic(fmt.Errorf("failed to load config: %w", err))
+				return fmt.Errorf("failed to load config: %w", err)
 			}
 			rootLogger, err := cfg.CreateLogger()
 			if err != nil {
-				panic(fmt.Errorf("failed to create logger: %w", err))
+				return fmt.Errorf("failed to create logger: %w", err)
 			}
 
 			// apply the flags from CLI
@@ -46,22 +50,27 @@ func GetReporterCmd() *cobra.Command {
 				cfg.Babylon.KeyDirectory = babylonKeyDir
 			}
 
+			// register reporter metrics
+			reporterMetrics := metrics.NewReporterMetrics()
+
 			// create BTC client and connect to BTC server
 			// Note that vigilant reporter needs to subscribe to new BTC blocks
-			btcClient, err = btcclient.NewWithBlockSubscriber(&cfg.BTC, cfg.Common.RetrySleepTime, cfg.Common.MaxRetrySleepTime, rootLogger)
+			if len(cfg.BTC.FailoverNodes) > 0 {
+				// connect to all BTC nodes and fail over between them
+				btcClient, err = btcclient.NewMultiWithBlockSubscriber(&cfg.BTC, cfg.Common.RetrySleepTime, rootLogger, reporterMetrics.BTCBackendMetrics)
+			} else {
+				btcClient, err = btcclient.NewWithBlockSubscriber(&cfg.BTC, cfg.Common.RetrySleepTime, cfg.Common.MaxRetrySleepTime, rootLogger)
+			}
 			if err != nil {
-				panic(fmt.Errorf("failed to open BTC client: %w", err))
+				return fmt.Errorf("failed to open BTC client: %w", err)
 			}
 
 			// create Babylon client. Note that requests from Babylon client are ad hoc
 			babylonClient, err = bbnclient.New(&cfg.Babylon, nil)
 			if err != nil {
-				panic(fmt.Errorf("failed to open Babylon client: %w", err))
+				return fmt.Errorf("failed to open Babylon client: %w", err)
 			}
 
-			// register reporter metrics
-			reporterMetrics := metrics.NewReporterMetrics()
-
 			// create reporter
 			vigilantReporter, err = reporter.New(
 				&cfg.Reporter,
@@ -73,23 +82,82 @@ func GetReporterCmd() *cobra.Command {
 				reporterMetrics,
 			)
 			if err != nil {
-				panic(fmt.Errorf("failed to create vigilante reporter: %w", err))
+				return fmt.Errorf("failed to create vigilante reporter: %w", err)
+			}
+
+			// open the store of the checkpoints that are not accepted by Babylon yet
+			var ckptRetryStore *reporter.CkptRetryStore
+			if cfg.Reporter.CkptRetryQueueFile != "" {
+				ckptRetryStore, err = reporter.NewCkptRetryStore(cfg.Reporter.CkptRetryQueueFile)
+				if err != nil {
+					return fmt.Errorf("failed to open checkpoint retry store: %w", err)
+				}
+				if err := vigilantReporter.SetCkptRetryStore(ckptRetryStore); err != nil {
+					return err
+				}
+				// the reporter writes to the store until it is stopped, hence the
+				// store is closed after the reporter
+				addInterruptHandler(func() {
+					rootLogger.Info("Closing checkpoint retry store...")
+					if err := ckptRetryStore.Close(); err != nil {
+						rootLogger.Error("failed to close checkpoint retry store", zap.Error(err))
+					}
+					rootLogger.Info("Checkpoint retry store closed")
+				})
+			}
+
+			// create header witness that cross-checks the BTC node against independent header sources
+			if cfg.Reporter.HeaderWitness.Enable {
+				// both btcclient.Client and btcclient.MultiClient implement headerwitness.BTCNode
+				node, ok := btcClient.(headerwitness.BTCNode)
+				if !ok {
+					return fmt.Errorf("BTC client does not support the header witness")
+				}
+				btcParams, err := netparams.GetBTCParams(cfg.BTC.NetParams)
+				if err != nil {
+					return fmt.Errorf("failed to get BTC net params: %w", err)
+				}
+				sources, err := headerwitness.NewHeaderSources(&cfg.Reporter.HeaderWitness, btcParams, node, rootLogger)
+				if err != nil {
+					return fmt.Errorf("failed to create header sources of the header witness: %w", err)
+				}
+				witness = headerwitness.New(&cfg.Reporter.HeaderWitness, node, sources, rootLogger, reporterMetrics.HeaderWitnessMetrics)
+				vigilantReporter.SetHeaderWitness(witness)
 			}
 
 			// create RPC server
 			server, err = rpcserver.New(&cfg.GRPC, rootLogger, nil, vigilantReporter, nil, nil)
 			if err != nil {
-				panic(fmt.Errorf("failed to create reporter's RPC server: %w", err))
+				return fmt.Errorf("failed to create reporter's RPC server: %w", err)
 			}
 
+			// start cross-checking the BTC node against the header sources
+			if witness != nil {
+				witness.Start()
+			}
+
+			// start Prometheus metrics server before bootstrapping, so that the
+			// health status of the reporter is exposed while it bootstraps
+			addr := fmt.Sprintf("%s:%d", cfg.Metrics.Host, cfg.Metrics.ServerPort)
+			metrics.Start(addr, reporterMetrics.Registry)
+
 			// start normal-case execution
-			vigilantReporter.Start()
+			if err := vigilantReporter.Start(); err != nil {
+				if witness != nil {
+					witness.Stop()
+				}
+				btcClient.Stop()
+				btcClient.WaitForShutdown()
+				if ckptRetryStore != nil {
+					if err := ckptRetryStore.Close(); err != nil {
+						rootLogger.Error("failed to close checkpoint retry store", zap.Error(err))
+					}
+				}
+				return fmt.Errorf("failed to start the reporter: %w", err)
+			}
 
 			// start RPC server
 			server.Start()
-			// start Prometheus metrics server
-			addr := fmt.Sprintf("%s:%d", cfg.Metrics.Host, cfg.Metrics.ServerPort)
-			metrics.Start(addr, reporterMetrics.Registry)
 
 			// SIGINT handling stuff
 			addInterruptHandler(func() {
@@ -103,6 +171,13 @@ func GetReporterCmd() *cobra.Command {
 				vigilantReporter.Stop()
 				rootLogger.Info("Reporter shutdown")
 			})
+			if witness != nil {
+				addInterruptHandler(func() {
+					rootLogger.Info("Stopping header witness...")
+					witness.Stop()
+					rootLogger.Info("Header witness shutdown")
+				})
+			}
 			addInterruptHandler(func() {
 				rootLogger.Info("Stopping BTC client...")
 				btcClient.Stop()
@@ -112,7 +187,7 @@ func GetReporterCmd() *cobra.Command {
 
 			<-interruptHandlersDone
 			rootLogger.Info("Shutdown complete")
-
+			return nil
 		},
 	}
 	cmd.Flags().StringVar(&babylonKeyDir, "babylon-key-dir", "", "Directory of the Babylon key")
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultAlertSinkTimeout           = 10 * time.Second
+	defaultAlertResendInitialInterval = 10 * time.Minute
+	defaultAlertResendMaxInterval     = 6 * time.Hour
+	defaultAlertExpireAfter           = 7 * 24 * time.Hour
+)
+
+// AlertConfig defines the sinks that the monitor sends alerts to upon detecting
+// safety or liveness violations, and how often unresolved alerts are re-sent
+type AlertConfig struct {
+	// URLs that alerts are POSTed to as JSON
+	WebhookURLs []string `mapstructure:"webhook-urls"`
+	// path of a file that alerts are appended to, one JSON object per line
+	File string `mapstructure:"file"`
+	// path of an executable that is run for each alert, with the alert as JSON on its stdin
+	ExecScript string `mapstructure:"exec-script"`
+	// timeout of sending an alert to a single sink
+	SinkTimeout time.Duration `mapstructure:"sink-timeout"`
+	// interval before an unresolved alert is sent again. The interval doubles after each re-send.
+	ResendInitialInterval time.Duration `mapstructure:"resend-initial-interval"`
+	// cap of the interval between re-sends of an unresolved alert
+	ResendMaxInterval time.Duration `mapstructure:"resend-max-interval"`
+	// an alert that is still unresolved this long after it is first raised is
+	// no longer re-sent, and is raised again if the violation is detected again
+	ExpireAfter time.Duration `mapstructure:"expire-after"`
+}
+
+func (cfg *AlertConfig) Validate() error {
+	for _, u := range cfg.WebhookURLs {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			return fmt.Errorf("invalid webhook URL %s: %w", u, err)
+		}
+	}
+	if cfg.SinkTimeout <= 0 {
+		return errors.New("sink-timeout should be positive")
+	}
+	if cfg.ResendInitialInterval <= 0 {
+		return errors.New("resend-initial-interval should be positive")
+	}
+	if cfg.ResendMaxInterval < cfg.ResendInitialInterval {
+		return errors.New("resend-max-interval should not be less than resend-initial-interval")
+	}
+	if cfg.ExpireAfter <= 0 {
+		return errors.New("expire-after should be positive")
+	}
+	return nil
+}
+
+func DefaultAlertConfig() AlertConfig {
+	return AlertConfig{
+		SinkTimeout:           defaultAlertSinkTimeout,
+		ResendInitialInterval: defaultAlertResendInitialInterval,
+		ResendMaxInterval:     defaultAlertResendMaxInterval,
+		ExpireAfter:           defaultAlertExpireAfter,
+	}
+}
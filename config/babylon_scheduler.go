@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	defaultBabylonMaxConcurrentRequests            = 16
+	defaultBabylonMaxConcurrentRequestsPerEndpoint = 8
+	defaultBabylonRequestsPerSecond                = 50
+	defaultBabylonRequestsBurst                    = 20
+)
+
+// BabylonSchedulerConfig defines how requests of the BTC staking tracker to the
+// Babylon node are bounded, so that many tracked delegations do not flood the node
+type BabylonSchedulerConfig struct {
+	// maximum number of requests in flight at once, across all endpoints
+	MaxConcurrentRequests int `mapstructure:"max-concurrent-requests"`
+	// maximum number of requests in flight at once to a single endpoint
+	MaxConcurrentRequestsPerEndpoint int `mapstructure:"max-concurrent-requests-per-endpoint"`
+	// overrides of max-concurrent-requests-per-endpoint by endpoint name, e.g. btc_delegation
+	EndpointConcurrency map[string]int `mapstructure:"endpoint-concurrency"`
+	// maximum rate of requests per second, 0 for no limit
+	RequestsPerSecond float64 `mapstructure:"requests-per-second"`
+	// number of requests that can be sent at once above the rate
+	Burst int `mapstructure:"burst"`
+}
+
+func (cfg *BabylonSchedulerConfig) Validate() error {
+	if cfg.MaxConcurrentRequests <= 0 {
+		return errors.New("max-concurrent-requests should be positive")
+	}
+	if cfg.MaxConcurrentRequestsPerEndpoint <= 0 {
+		return errors.New("max-concurrent-requests-per-endpoint should be positive")
+	}
+	for endpoint, limit := range cfg.EndpointConcurrency {
+		if limit <= 0 {
+			return fmt.Errorf("endpoint-concurrency of %s should be positive", endpoint)
+		}
+	}
+	if cfg.RequestsPerSecond < 0 {
+		return errors.New("requests-per-second can't be negative")
+	}
+	if cfg.RequestsPerSecond > 0 && cfg.Burst <= 0 {
+		return errors.New("burst should be positive when requests-per-second is set")
+	}
+	return nil
+}
+
+// EndpointLimit returns the maximum number of requests in flight at once to the endpoint
+func (cfg *BabylonSchedulerConfig) EndpointLimit(endpoint string) int {
+	if limit, ok := cfg.EndpointConcurrency[endpoint]; ok {
+		return limit
+	}
+	return cfg.MaxConcurrentRequestsPerEndpoint
+}
+
+func DefaultBabylonSchedulerConfig() BabylonSchedulerConfig {
+	return BabylonSchedulerConfig{
+		MaxConcurrentRequests:            defaultBabylonMaxConcurrentRequests,
+		MaxConcurrentRequestsPerEndpoint: defaultBabylonMaxConcurrentRequestsPerEndpoint,
+		RequestsPerSecond:                defaultBabylonRequestsPerSecond,
+		Burst:                            defaultBabylonRequestsBurst,
+	}
+}
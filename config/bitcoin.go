@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 
@@ -33,6 +34,39 @@ type BTCConfig struct {
 	ZmqSeqEndpoint    string                    `mapstructure:"zmq-seq-endpoint"`
 	ZmqBlockEndpoint  string                    `mapstructure:"zmq-block-endpoint"`
 	ZmqTxEndpoint     string                    `mapstructure:"zmq-tx-endpoint"`
+	// additional BTC nodes to fail over to when the primary node is unavailable or lagging behind
+	FailoverNodes []BTCNodeConfig `mapstructure:"failover-nodes"`
+	// maximum number of blocks the tip of the active BTC node may lag behind the best BTC node before failing over
+	FailoverMaxTipLag uint64 `mapstructure:"failover-max-tip-lag"`
+	// interval between polls of the tips of all BTC nodes
+	FailoverPollInterval time.Duration `mapstructure:"failover-poll-interval"`
+}
+
+// BTCNodeConfig defines the connection to an additional BTC node. As a failover node,
+// the node has to run the same backend as the primary node, and shares all the other
+// fields of BTCConfig with it. The ZMQ endpoints of a bitcoind node are its own, as
+// the ZMQ sockets of the primary node are silent while it is down.
+type BTCNodeConfig struct {
+	// never inherited from the primary node
+	Endpoint string `mapstructure:"endpoint"`
+	// inherited from the primary node if empty
+	Username string `mapstructure:"username"`
+	// inherited from the primary node if empty
+	Password string `mapstructure:"password"`
+	// inherited from the primary node if empty
+	CAFile string `mapstructure:"ca-file"`
+	// inherited from the primary node if unset
+	DisableClientTLS *bool `mapstructure:"no-client-tls"`
+	// never inherited from the primary node, required by the bitcoind backend
+	ZmqSeqEndpoint   string `mapstructure:"zmq-seq-endpoint"`
+	ZmqBlockEndpoint string `mapstructure:"zmq-block-endpoint"`
+	ZmqTxEndpoint    string `mapstructure:"zmq-tx-endpoint"`
+}
+
+// ClientTLSDisabled returns whether TLS is disabled for the node, without
+// inheriting from the primary node. TLS is enabled unless disabled explicitly.
+func (cfg *BTCNodeConfig) ClientTLSDisabled() bool {
+	return cfg.DisableClientTLS != nil && *cfg.DisableClientTLS
 }
 
 func (cfg *BTCConfig) Validate() error {
@@ -91,45 +125,105 @@ func (cfg *BTCConfig) Validate() error {
 		return fmt.Errorf("default-fee should be in the range of [%v, %v]", cfg.TxFeeMin, cfg.TxFeeMax)
 	}
 
+	for i, node := range cfg.FailoverNodes {
+		if node.Endpoint == "" {
+			return fmt.Errorf("endpoint of failover node %d cannot be empty", i)
+		}
+		if node.Endpoint == cfg.Endpoint {
+			return fmt.Errorf("failover node %d has the same endpoint as the primary node", i)
+		}
+		if cfg.BtcBackend == types.Bitcoind {
+			if node.ZmqBlockEndpoint == "" {
+				return fmt.Errorf("zmq block endpoint of failover node %d cannot be empty", i)
+			}
+			if node.ZmqTxEndpoint == "" {
+				return fmt.Errorf("zmq tx endpoint of failover node %d cannot be empty", i)
+			}
+			if node.ZmqSeqEndpoint == "" {
+				return fmt.Errorf("zmq seq endpoint of failover node %d cannot be empty", i)
+			}
+			if node.ZmqSeqEndpoint == cfg.ZmqSeqEndpoint {
+				return fmt.Errorf("failover node %d has the same zmq seq endpoint as the primary node", i)
+			}
+		}
+	}
+
+	if len(cfg.FailoverNodes) > 0 && cfg.FailoverPollInterval <= 0 {
+		return errors.New("failover-poll-interval should be positive")
+	}
+
 	return nil
 }
 
+// NodeConfigs returns the configs of the primary BTC node and all failover BTC nodes,
+// with the primary node first
+func (cfg *BTCConfig) NodeConfigs() []*BTCConfig {
+	cfgs := []*BTCConfig{cfg}
+	for _, node := range cfg.FailoverNodes {
+		nodeCfg := *cfg
+		nodeCfg.FailoverNodes = nil
+		nodeCfg.Endpoint = node.Endpoint
+		nodeCfg.Username = inheritString(node.Username, cfg.Username)
+		nodeCfg.Password = inheritString(node.Password, cfg.Password)
+		nodeCfg.CAFile = inheritString(node.CAFile, cfg.CAFile)
+		if node.DisableClientTLS != nil {
+			nodeCfg.DisableClientTLS = *node.DisableClientTLS
+		}
+		nodeCfg.ZmqSeqEndpoint = node.ZmqSeqEndpoint
+		nodeCfg.ZmqBlockEndpoint = node.ZmqBlockEndpoint
+		nodeCfg.ZmqTxEndpoint = node.ZmqTxEndpoint
+		cfgs = append(cfgs, &nodeCfg)
+	}
+	return cfgs
+}
+
+func inheritString(value, inherited string) string {
+	if value == "" {
+		return inherited
+	}
+	return value
+}
+
 const (
 	// Config for polling jittner in bitcoind client, with polling enabled
-	DefaultTxPollingJitter     = 0.5
-	DefaultRpcBtcNodeHost      = "127.0.01:18556"
-	DefaultBtcNodeRpcUser      = "rpcuser"
-	DefaultBtcNodeRpcPass      = "rpcpass"
-	DefaultBtcNodeEstimateMode = "CONSERVATIVE"
-	DefaultBtcblockCacheSize   = 20 * 1024 * 1024 // 20 MB
-	DefaultZmqSeqEndpoint      = "tcp://127.0.0.1:29000"
-	DefaultZmqBlockEndpoint    = "tcp://127.0.0.1:29001"
-	DefaultZmqTxEndpoint       = "tcp://127.0.0.1:29002"
+	DefaultTxPollingJitter      = 0.5
+	DefaultRpcBtcNodeHost       = "127.0.01:18556"
+	DefaultBtcNodeRpcUser       = "rpcuser"
+	DefaultBtcNodeRpcPass       = "rpcpass"
+	DefaultBtcNodeEstimateMode  = "CONSERVATIVE"
+	DefaultBtcblockCacheSize    = 20 * 1024 * 1024 // 20 MB
+	DefaultZmqSeqEndpoint       = "tcp://127.0.0.1:29000"
+	DefaultZmqBlockEndpoint     = "tcp://127.0.0.1:29001"
+	DefaultZmqTxEndpoint        = "tcp://127.0.0.1:29002"
+	DefaultFailoverMaxTipLag    = 3
+	DefaultFailoverPollInterval = 30 * time.Second
 )
 
 func DefaultBTCConfig() BTCConfig {
 	return BTCConfig{
-		DisableClientTLS:  false,
-		CAFile:            defaultBtcCAFile,
-		Endpoint:          DefaultRpcBtcNodeHost,
-		WalletEndpoint:    "localhost:18554",
-		WalletPassword:    "walletpass",
-		WalletName:        "default",
-		WalletCAFile:      defaultBtcWalletCAFile,
-		WalletLockTime:    10,
-		BtcBackend:        types.Btcd,
-		TxFeeMax:          chainfee.SatPerKVByte(20 * 1000), // 20,000sat/kvb = 20sat/vbyte
-		TxFeeMin:          chainfee.SatPerKVByte(1 * 1000),  // 1,000sat/kvb = 1sat/vbyte
-		DefaultFee:        chainfee.SatPerKVByte(1 * 1000),  // 1,000sat/kvb = 1sat/vbyte
-		EstimateMode:      DefaultBtcNodeEstimateMode,
-		TargetBlockNum:    1,
-		NetParams:         types.BtcSimnet.String(),
-		Username:          DefaultBtcNodeRpcUser,
-		Password:          DefaultBtcNodeRpcPass,
-		ReconnectAttempts: 3,
-		ZmqSeqEndpoint:    DefaultZmqSeqEndpoint,
-		ZmqBlockEndpoint:  DefaultZmqBlockEndpoint,
-		ZmqTxEndpoint:     DefaultZmqTxEndpoint,
+		DisableClientTLS:     false,
+		CAFile:               defaultBtcCAFile,
+		Endpoint:             DefaultRpcBtcNodeHost,
+		WalletEndpoint:       "localhost:18554",
+		WalletPassword:       "walletpass",
+		WalletName:           "default",
+		WalletCAFile:         defaultBtcWalletCAFile,
+		WalletLockTime:       10,
+		BtcBackend:           types.Btcd,
+		TxFeeMax:             chainfee.SatPerKVByte(20 * 1000), // 20,000sat/kvb = 20sat/vbyte
+		TxFeeMin:             chainfee.SatPerKVByte(1 * 1000),  // 1,000sat/kvb = 1sat/vbyte
+		DefaultFee:           chainfee.SatPerKVByte(1 * 1000),  // 1,000sat/kvb = 1sat/vbyte
+		EstimateMode:         DefaultBtcNodeEstimateMode,
+		TargetBlockNum:       1,
+		NetParams:            types.BtcSimnet.String(),
+		Username:             DefaultBtcNodeRpcUser,
+		Password:             DefaultBtcNodeRpcPass,
+		ReconnectAttempts:    3,
+		ZmqSeqEndpoint:       DefaultZmqSeqEndpoint,
+		ZmqBlockEndpoint:     DefaultZmqBlockEndpoint,
+		ZmqTxEndpoint:        DefaultZmqTxEndpoint,
+		FailoverMaxTipLag:    DefaultFailoverMaxTipLag,
+		FailoverPollInterval: DefaultFailoverPollInterval,
 	}
 }
 
@@ -1,3 +1,92 @@
 package config_test
 
-// TODO: tests on Bitcoin config
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// TODO: more tests on Bitcoin config
+
+func TestBTCConfigNodeConfigs(t *testing.T) {
+	cfg := config.DefaultBTCConfig()
+	disableClientTLS := !cfg.DisableClientTLS
+	cfg.FailoverNodes = []config.BTCNodeConfig{
+		{Endpoint: "localhost:18557"},
+		{Endpoint: "localhost:18558", Username: "user2", Password: "pass2"},
+		{Endpoint: "localhost:18559", CAFile: "ca.cert", DisableClientTLS: &disableClientTLS, ZmqSeqEndpoint: "tcp://127.0.0.1:29003"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	nodeCfgs := cfg.NodeConfigs()
+	require.Len(t, nodeCfgs, 4)
+
+	// the primary node comes first
+	require.Equal(t, &cfg, nodeCfgs[0])
+
+	// empty fields are inherited from the primary node
+	require.Equal(t, "localhost:18557", nodeCfgs[1].Endpoint)
+	require.Equal(t, cfg.Username, nodeCfgs[1].Username)
+	require.Equal(t, cfg.Password, nodeCfgs[1].Password)
+	require.Equal(t, cfg.CAFile, nodeCfgs[1].CAFile)
+	require.Equal(t, cfg.DisableClientTLS, nodeCfgs[1].DisableClientTLS)
+	require.Equal(t, cfg.BtcBackend, nodeCfgs[1].BtcBackend)
+	require.Empty(t, nodeCfgs[1].FailoverNodes)
+
+	// set fields override the primary node
+	require.Equal(t, "localhost:18558", nodeCfgs[2].Endpoint)
+	require.Equal(t, "user2", nodeCfgs[2].Username)
+	require.Equal(t, "pass2", nodeCfgs[2].Password)
+	require.Equal(t, cfg.DisableClientTLS, nodeCfgs[2].DisableClientTLS)
+
+	require.Equal(t, "localhost:18559", nodeCfgs[3].Endpoint)
+	require.Equal(t, cfg.Username, nodeCfgs[3].Username)
+	require.Equal(t, "ca.cert", nodeCfgs[3].CAFile)
+	require.Equal(t, disableClientTLS, nodeCfgs[3].DisableClientTLS)
+	require.Equal(t, "tcp://127.0.0.1:29003", nodeCfgs[3].ZmqSeqEndpoint)
+
+	// ZMQ endpoints are never inherited from the primary node
+	require.Empty(t, nodeCfgs[1].ZmqSeqEndpoint)
+	require.Empty(t, nodeCfgs[1].ZmqBlockEndpoint)
+	require.Empty(t, nodeCfgs[1].ZmqTxEndpoint)
+
+	// failover nodes should not duplicate the primary node
+	cfg.FailoverNodes = append(cfg.FailoverNodes, config.BTCNodeConfig{Endpoint: cfg.Endpoint})
+	require.Error(t, cfg.Validate())
+}
+
+func TestBTCConfigBitcoindFailoverZmq(t *testing.T) {
+	cfg := config.DefaultBTCConfig()
+	cfg.BtcBackend = types.Bitcoind
+	node := config.BTCNodeConfig{
+		Endpoint:         "localhost:18557",
+		ZmqSeqEndpoint:   "tcp://127.0.0.1:29003",
+		ZmqBlockEndpoint: "tcp://127.0.0.1:29004",
+		ZmqTxEndpoint:    "tcp://127.0.0.1:29005",
+	}
+	cfg.FailoverNodes = []config.BTCNodeConfig{node}
+	require.NoError(t, cfg.Validate())
+
+	// a bitcoind failover node uses its own ZMQ endpoints
+	nodeCfgs := cfg.NodeConfigs()
+	require.Len(t, nodeCfgs, 2)
+	require.Equal(t, node.ZmqSeqEndpoint, nodeCfgs[1].ZmqSeqEndpoint)
+	require.Equal(t, node.ZmqBlockEndpoint, nodeCfgs[1].ZmqBlockEndpoint)
+	require.Equal(t, node.ZmqTxEndpoint, nodeCfgs[1].ZmqTxEndpoint)
+
+	// each of its ZMQ endpoints is required
+	for _, modify := range []func(n *config.BTCNodeConfig){
+		func(n *config.BTCNodeConfig) { n.ZmqSeqEndpoint = "" },
+		func(n *config.BTCNodeConfig) { n.ZmqBlockEndpoint = "" },
+		func(n *config.BTCNodeConfig) { n.ZmqTxEndpoint = "" },
+		func(n *config.BTCNodeConfig) { n.ZmqSeqEndpoint = cfg.ZmqSeqEndpoint },
+	} {
+		invalidNode := node
+		modify(&invalidNode)
+		cfg.FailoverNodes = []config.BTCNodeConfig{invalidNode}
+		require.Error(t, cfg.Validate())
+	}
+}
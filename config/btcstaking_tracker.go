@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/babylonchain/vigilante/types"
@@ -10,33 +11,70 @@ import (
 
 const maxBatchSize = 10000
 
+var defaultHintCacheFile = filepath.Join(defaultAppDataDir, "bstracker-hints.db")
+
 type BTCStakingTrackerConfig struct {
+	// interval of rescans of all delegations on Babylon. New delegations are discovered
+	// from Babylon events, and rescans catch up on the events missed while disconnected.
 	CheckDelegationsInterval       time.Duration `mapstructure:"check-delegations-interval"`
 	NewDelegationsBatchSize        uint64        `mapstructure:"delegations-batch-size"`
 	CheckDelegationActiveInterval  time.Duration `mapstructure:"check-if-delegation-active-interval"`
 	RetrySubmitUnbondingTxInterval time.Duration `mapstructure:"retry-submit-unbonding-interval"`
 	RetryJitter                    time.Duration `mapstructure:"max-jitter-interval"`
+	// number of blocks a spend of a staking or unbonding output needs to be in before
+	// it is acted upon, e.g. reported as unbonding to Babylon. 1 acts on it as soon as
+	// it is in a block, at the risk of reporting an unbonding tx that is then reorged out.
+	SpendConfirmationDepth uint32 `mapstructure:"spend-confirmation-depth"`
+	// number of workers acting on spends of staking outputs. A worker is busy
+	// with a spend until it is deep enough and reported to Babylon.
+	SpendWorkers uint32 `mapstructure:"spend-workers"`
+	// HintCacheFile is the database persisting the heights up to which spends and
+	// confirmations were scanned, so that restarts do not rescan from the heights
+	// of the delegations. Empty disables persistence.
+	HintCacheFile string `mapstructure:"hint-cache-file"`
+	// hints lagging this many blocks behind the most recent hint are pruned
+	HintCachePruneDepth    uint32        `mapstructure:"hint-cache-prune-depth"`
+	HintCachePruneInterval time.Duration `mapstructure:"hint-cache-prune-interval"`
+	// number of BTC blocks buffered for each routine of the tracker consuming them.
+	// A routine falling further behind holds up the others.
+	BlockStreamBufferSize uint32 `mapstructure:"block-stream-buffer-size"`
+	// sinks of alerts on unknown spends of staking outputs
+	Alert AlertConfig `mapstructure:"alert"`
+	// limits of requests to the Babylon node shared by all routines of the tracker
+	BabylonRequests BabylonSchedulerConfig `mapstructure:"babylon-requests"`
+	// follow-up on submitted slashing txs until they are k-deep
+	SlashingTxs SlashingTxConfig `mapstructure:"slashing-txs"`
 	// the BTC network
 	BTCNetParams string `mapstructure:"btcnetparams"` // should be mainnet|testnet|simnet|signet|regtest
 }
 
 func DefaultBTCStakingTrackerConfig() BTCStakingTrackerConfig {
 	return BTCStakingTrackerConfig{
-		CheckDelegationsInterval: 1 * time.Minute,
+		CheckDelegationsInterval: 1 * time.Hour,
 		NewDelegationsBatchSize:  100,
 		// This can be quite large to avoid wasting resources on checking if delegation is active
 		CheckDelegationActiveInterval: 5 * time.Minute,
 		// This schould be small, as we want to report unbonding tx as soon as possible even if we initialy failed
 		RetrySubmitUnbondingTxInterval: 1 * time.Minute,
 		// pretty large jitter to avoid spamming babylon with requests
-		RetryJitter:  30 * time.Second,
-		BTCNetParams: types.BtcSimnet.String(),
+		RetryJitter:            30 * time.Second,
+		SpendConfirmationDepth: 2,
+		SpendWorkers:           100,
+		HintCacheFile:          defaultHintCacheFile,
+		// about a week of blocks
+		HintCachePruneDepth:    1008,
+		HintCachePruneInterval: 1 * time.Hour,
+		BlockStreamBufferSize:  100,
+		Alert:                  DefaultAlertConfig(),
+		BabylonRequests:        DefaultBabylonSchedulerConfig(),
+		SlashingTxs:            DefaultSlashingTxConfig(),
+		BTCNetParams:           types.BtcSimnet.String(),
 	}
 }
 
 func (cfg *BTCStakingTrackerConfig) Validate() error {
-	if cfg.CheckDelegationsInterval < 0 {
-		return errors.New("check-delegations-interval can't be negative")
+	if cfg.CheckDelegationsInterval <= 0 {
+		return errors.New("check-delegations-interval must be positive")
 	}
 	if cfg.CheckDelegationActiveInterval < 0 {
 		return errors.New("check-if-delegation-active-interval can't be negative")
@@ -50,10 +88,43 @@ func (cfg *BTCStakingTrackerConfig) Validate() error {
 		return errors.New("max-jitter-interval can't be negative")
 	}
 
+	if cfg.SpendConfirmationDepth == 0 {
+		return errors.New("spend-confirmation-depth must be positive")
+	}
+
+	if cfg.SpendWorkers == 0 {
+		return errors.New("spend-workers must be positive")
+	}
+
 	if cfg.NewDelegationsBatchSize > maxBatchSize {
 		return errors.New("delegations-batch-size can't be greater than 10000")
 	}
 
+	if cfg.HintCacheFile != "" {
+		if cfg.HintCachePruneDepth == 0 {
+			return errors.New("hint-cache-prune-depth must be positive")
+		}
+		if cfg.HintCachePruneInterval <= 0 {
+			return errors.New("hint-cache-prune-interval must be positive")
+		}
+	}
+
+	if cfg.BlockStreamBufferSize == 0 {
+		return errors.New("block-stream-buffer-size must be positive")
+	}
+
+	if err := cfg.Alert.Validate(); err != nil {
+		return fmt.Errorf("invalid alert config: %w", err)
+	}
+
+	if err := cfg.BabylonRequests.Validate(); err != nil {
+		return fmt.Errorf("invalid babylon-requests config: %w", err)
+	}
+
+	if err := cfg.SlashingTxs.Validate(); err != nil {
+		return fmt.Errorf("invalid slashing-txs config: %w", err)
+	}
+
 	if _, ok := types.GetValidNetParams()[cfg.BTCNetParams]; !ok {
 		return fmt.Errorf("invalid net params %s", cfg.BTCNetParams)
 	}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultHeaderWitnessCheckInterval  = 1 * time.Minute
+	defaultHeaderWitnessMaxLagBlocks   = 3
+	defaultHeaderWitnessMaxForkDepth   = 1000
+	defaultHeaderWitnessP2PAnchorDepth = 1000
+)
+
+// HeaderWitnessConfig defines the independent BTC header sources the reporter
+// cross-checks the best chain of its BTC node against
+type HeaderWitnessConfig struct {
+	// whether to enable the header witness
+	Enable bool `mapstructure:"enable"`
+	// interval between two cross-checks
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// maximum number of blocks the BTC node may lag behind a witness on the same chain before an alarm is raised
+	MaxLagBlocks uint64 `mapstructure:"max_lag_blocks"`
+	// maximum depth of a fork between the BTC node and a witness that can be compared
+	MaxForkDepth uint64 `mapstructure:"max_fork_depth"`
+	// whether the reporter stops submitting headers to Babylon while an alarm is raised
+	PauseOnAlarm bool `mapstructure:"pause_on_alarm"`
+	// other BTC nodes whose best chains are queried over RPC
+	RPCNodes []BTCNodeConfig `mapstructure:"rpc_nodes"`
+	// path to a file of hex-encoded BTC headers, one per line, ordered by height
+	HeadersFile string `mapstructure:"headers_file"`
+	// height of the first header in the headers file
+	HeadersFileStartHeight int32 `mapstructure:"headers_file_start_height"`
+	// addresses of BTC peers to sync headers from over the P2P network
+	P2PPeers []string `mapstructure:"p2p_peers"`
+	// depth below the BTC node's tip of the header that P2P header sync starts from
+	P2PAnchorDepth uint64 `mapstructure:"p2p_anchor_depth"`
+}
+
+func (cfg *HeaderWitnessConfig) Validate() error {
+	if !cfg.Enable {
+		return nil
+	}
+	if cfg.CheckInterval <= 0 {
+		return errors.New("check_interval should be positive")
+	}
+	if cfg.MaxForkDepth == 0 {
+		return errors.New("max_fork_depth should be positive")
+	}
+	if len(cfg.RPCNodes) == 0 && cfg.HeadersFile == "" && len(cfg.P2PPeers) == 0 {
+		return errors.New("at least one of rpc_nodes, headers_file, and p2p_peers should be set")
+	}
+	for i, node := range cfg.RPCNodes {
+		if node.Endpoint == "" {
+			return fmt.Errorf("endpoint of RPC node %d cannot be empty", i)
+		}
+	}
+	if cfg.HeadersFileStartHeight < 0 {
+		return errors.New("headers_file_start_height can't be negative")
+	}
+	if len(cfg.P2PPeers) > 0 && cfg.P2PAnchorDepth == 0 {
+		return errors.New("p2p_anchor_depth should be positive")
+	}
+	return nil
+}
+
+func DefaultHeaderWitnessConfig() HeaderWitnessConfig {
+	return HeaderWitnessConfig{
+		Enable:         false,
+		CheckInterval:  defaultHeaderWitnessCheckInterval,
+		MaxLagBlocks:   defaultHeaderWitnessMaxLagBlocks,
+		MaxForkDepth:   defaultHeaderWitnessMaxForkDepth,
+		PauseOnAlarm:   false,
+		P2PAnchorDepth: defaultHeaderWitnessP2PAnchorDepth,
+	}
+}
@@ -2,15 +2,26 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 )
 
 const (
-	defaultCheckpointBufferSize         = 100
-	defaultBtcBlockBufferSize           = 100
-	defaultBtcCacheSize                 = 100
-	defaultBtcConfirmationDepth         = 6
-	defaultLivenessCheckIntervalSeconds = 10
-	defaultMaxLiveBtcHeights            = 100
+	defaultCheckpointBufferSize            = 100
+	defaultBtcBlockBufferSize              = 100
+	defaultBtcCacheSize                    = 100
+	defaultBtcConfirmationDepth            = 6
+	defaultLivenessCheckIntervalSeconds    = 10
+	defaultMaxLiveBtcHeights               = 100
+	defaultStuckEpochTimeoutSeconds        = 3600
+	defaultBootstrapPrefetchConcurrency    = 8
+	defaultLightClientAuditIntervalSeconds = 600
+	defaultLightClientAuditDepth           = 100
+	defaultValSetPrefetchEpochs            = 2
+)
+
+var (
+	defaultMonitorStateFile   = filepath.Join(defaultAppDataDir, "monitor-state.json")
+	defaultMonitorEvidenceDir = filepath.Join(defaultAppDataDir, "evidence")
 )
 
 // MonitorConfig defines the Monitor's basic configuration
@@ -25,10 +36,33 @@ type MonitorConfig struct {
 	LivenessCheckIntervalSeconds uint64 `mapstructure:"liveness-check-interval-seconds"`
 	// Max lasting BTC heights that a checkpoint is not reported before an alarm is sent
 	MaxLiveBtcHeights uint64 `mapstructure:"max-live-btc-heights"`
+	// number of concurrent requests to prefetch BTC blocks when bootstrapping
+	BootstrapPrefetchConcurrency uint64 `mapstructure:"bootstrap-prefetch-concurrency"`
 	// the confirmation depth to consider a BTC block as confirmed
 	BtcConfirmationDepth uint64 `mapstructure:"btc-confirmation-depth"`
+	// Max seconds to wait for the checkpoint of the current epoch while checkpoints
+	// of later epochs are found, before a "stuck at epoch" alert is sent
+	StuckEpochTimeoutSeconds uint64 `mapstructure:"stuck-epoch-timeout-seconds"`
 	// whether to enable liveness checker
 	EnableLivenessChecker bool `mapstructure:"enable-liveness-checker"`
+	// whether to periodically audit Babylon's BTC light client against the canonical chain of the BTC node
+	EnableLightClientAudit bool `mapstructure:"enable-light-client-audit"`
+	// Intervals between each audit of Babylon's BTC light client in seconds
+	LightClientAuditIntervalSeconds uint64 `mapstructure:"light-client-audit-interval-seconds"`
+	// number of headers from the tip of Babylon's BTC light client to audit
+	LightClientAuditDepth uint64 `mapstructure:"light-client-audit-depth"`
+	// sinks of alerts on detected safety and liveness violations
+	Alert AlertConfig `mapstructure:"alert"`
+	// file to persist the verification progress, so that the monitor resumes from
+	// the last verified epoch after a restart. Persistence is disabled if empty
+	StateFile string `mapstructure:"state-file"`
+	// directory to write the evidence bundles of detected forks to. Evidence is not written if empty
+	EvidenceDir string `mapstructure:"evidence-dir"`
+	// number of upcoming epochs whose validator sets are fetched in advance
+	ValSetPrefetchEpochs uint64 `mapstructure:"valset-prefetch-epochs"`
+	// file of validator sets exported with `vigilante monitor export-valset-history`. Validator
+	// sets of epochs covered by the file are not queried from Babylon. Not used if empty
+	ValSetHistoryFile string `mapstructure:"valset-history-file"`
 }
 
 func (cfg *MonitorConfig) Validate() error {
@@ -41,17 +75,43 @@ func (cfg *MonitorConfig) Validate() error {
 	if cfg.BtcConfirmationDepth < defaultBtcConfirmationDepth {
 		return fmt.Errorf("btc-confirmation-depth should not be less than %d", defaultBtcConfirmationDepth)
 	}
+	if cfg.BootstrapPrefetchConcurrency == 0 {
+		return fmt.Errorf("bootstrap-prefetch-concurrency should be positive")
+	}
+	if cfg.EnableLightClientAudit {
+		if cfg.LightClientAuditIntervalSeconds == 0 {
+			return fmt.Errorf("light-client-audit-interval-seconds should be positive")
+		}
+		if cfg.LightClientAuditDepth <= cfg.BtcConfirmationDepth {
+			return fmt.Errorf("light-client-audit-depth should be larger than btc-confirmation-depth %d", cfg.BtcConfirmationDepth)
+		}
+	}
+	if cfg.StuckEpochTimeoutSeconds == 0 {
+		return fmt.Errorf("stuck-epoch-timeout-seconds should be positive")
+	}
+	if err := cfg.Alert.Validate(); err != nil {
+		return fmt.Errorf("invalid alert config: %w", err)
+	}
 	return nil
 }
 
 func DefaultMonitorConfig() MonitorConfig {
 	return MonitorConfig{
-		CheckpointBufferSize:         defaultCheckpointBufferSize,
-		BtcBlockBufferSize:           defaultBtcBlockBufferSize,
-		BtcCacheSize:                 defaultBtcCacheSize,
-		LivenessCheckIntervalSeconds: defaultLivenessCheckIntervalSeconds,
-		BtcConfirmationDepth:         defaultBtcConfirmationDepth,
-		MaxLiveBtcHeights:            defaultMaxLiveBtcHeights,
-		EnableLivenessChecker:        true,
+		CheckpointBufferSize:            defaultCheckpointBufferSize,
+		BtcBlockBufferSize:              defaultBtcBlockBufferSize,
+		BtcCacheSize:                    defaultBtcCacheSize,
+		BootstrapPrefetchConcurrency:    defaultBootstrapPrefetchConcurrency,
+		LivenessCheckIntervalSeconds:    defaultLivenessCheckIntervalSeconds,
+		BtcConfirmationDepth:            defaultBtcConfirmationDepth,
+		MaxLiveBtcHeights:               defaultMaxLiveBtcHeights,
+		StuckEpochTimeoutSeconds:        defaultStuckEpochTimeoutSeconds,
+		EnableLivenessChecker:           true,
+		EnableLightClientAudit:          true,
+		LightClientAuditIntervalSeconds: defaultLightClientAuditIntervalSeconds,
+		LightClientAuditDepth:           defaultLightClientAuditDepth,
+		Alert:                           DefaultAlertConfig(),
+		StateFile:                       defaultMonitorStateFile,
+		EvidenceDir:                     defaultMonitorEvidenceDir,
+		ValSetPrefetchEpochs:            defaultValSetPrefetchEpochs,
 	}
 }
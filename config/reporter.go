@@ -1,7 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"time"
 
 	"github.com/babylonchain/vigilante/types"
 )
@@ -9,6 +12,18 @@ import (
 const (
 	minBTCCacheSize = 1000
 	maxHeadersInMsg = 100 // maximum number of headers in a MsgInsertHeaders message
+
+	defaultBootstrapRetryInitialDelay = 5 * time.Second
+	defaultBootstrapRetryMaxDelay     = 5 * time.Minute
+	defaultBootstrapRetryMaxJitter    = 5 * time.Second
+	defaultBootstrapMaxAttempts       = 60
+	defaultBTCSyncPollInterval        = 5 * time.Second
+	defaultGapFillThreshold           = 1000
+)
+
+var (
+	defaultGapFillProgressFile = filepath.Join(defaultAppDataDir, "reporter-gap-fill.json")
+	defaultCkptRetryQueueFile  = filepath.Join(defaultAppDataDir, "reporter-ckpt-retry-queue.db")
 )
 
 // ReporterConfig defines configuration for the reporter.
@@ -16,6 +31,29 @@ type ReporterConfig struct {
 	NetParams       string `mapstructure:"netparams"`          // should be mainnet|testnet|simnet|signet
 	BTCCacheSize    uint64 `mapstructure:"btc_cache_size"`     // size of the BTC cache
 	MaxHeadersInMsg uint32 `mapstructure:"max_headers_in_msg"` // maximum number of headers in a MsgInsertHeaders message
+	// Backoff interval before the first bootstrap retry. The interval doubles after each failed attempt.
+	BootstrapRetryInitialDelay time.Duration `mapstructure:"bootstrap_retry_initial_delay"`
+	// Cap of the backoff interval between bootstrap retries
+	BootstrapRetryMaxDelay time.Duration `mapstructure:"bootstrap_retry_max_delay"`
+	// Maximum random jitter added to each backoff interval between bootstrap retries
+	BootstrapRetryMaxJitter time.Duration `mapstructure:"bootstrap_retry_max_jitter"`
+	// Maximum number of bootstrap attempts before the reporter gives up. Ignored if bootstrap_retry_forever is set
+	BootstrapMaxAttempts uint `mapstructure:"bootstrap_max_attempts"`
+	// whether to keep retrying bootstrapping until it succeeds
+	BootstrapRetryForever bool `mapstructure:"bootstrap_retry_forever"`
+	// Interval between checks of whether BTC has caught up with Babylon's BTC light client
+	BTCSyncPollInterval time.Duration `mapstructure:"btc_sync_poll_interval"`
+	// Minimum number of BTC headers Babylon's BTC light client has to fall behind for the reporter to catch up
+	// by streaming headers from the BTC node in pages, rather than loading all missing blocks into the BTC cache.
+	// 0 disables the gap-fill mode
+	GapFillThreshold uint64 `mapstructure:"gap_fill_threshold"`
+	// File recording the progress of the gap-fill mode, so that an interrupted catch-up resumes where it stopped
+	GapFillProgressFile string `mapstructure:"gap_fill_progress_file"`
+	// Database persisting the matched checkpoints that are not accepted by Babylon yet, so that they are
+	// submitted again after a restart. Empty disables persistence
+	CkptRetryQueueFile string `mapstructure:"ckpt_retry_queue_file"`
+	// independent BTC header sources to cross-check the BTC node against
+	HeaderWitness HeaderWitnessConfig `mapstructure:"header_witness"`
 }
 
 func (cfg *ReporterConfig) Validate() error {
@@ -28,13 +66,44 @@ func (cfg *ReporterConfig) Validate() error {
 	if cfg.MaxHeadersInMsg < maxHeadersInMsg {
 		return fmt.Errorf("max_headers_in_msg has to be at least %d", maxHeadersInMsg)
 	}
+	if cfg.BootstrapRetryInitialDelay < 0 {
+		return errors.New("bootstrap_retry_initial_delay can't be negative")
+	}
+	if cfg.BootstrapRetryMaxDelay < cfg.BootstrapRetryInitialDelay {
+		return errors.New("bootstrap_retry_max_delay can't be less than bootstrap_retry_initial_delay")
+	}
+	if cfg.BootstrapRetryMaxJitter < 0 {
+		return errors.New("bootstrap_retry_max_jitter can't be negative")
+	}
+	if !cfg.BootstrapRetryForever && cfg.BootstrapMaxAttempts == 0 {
+		return errors.New("bootstrap_max_attempts has to be positive unless bootstrap_retry_forever is set")
+	}
+	if cfg.BTCSyncPollInterval <= 0 {
+		return errors.New("btc_sync_poll_interval has to be positive")
+	}
+	if cfg.GapFillThreshold > 0 && cfg.GapFillProgressFile == "" {
+		return errors.New("gap_fill_progress_file cannot be empty when gap_fill_threshold is positive")
+	}
+	if err := cfg.HeaderWitness.Validate(); err != nil {
+		return fmt.Errorf("invalid header_witness: %w", err)
+	}
 	return nil
 }
 
 func DefaultReporterConfig() ReporterConfig {
 	return ReporterConfig{
-		NetParams:       types.BtcSimnet.String(),
-		BTCCacheSize:    minBTCCacheSize,
-		MaxHeadersInMsg: maxHeadersInMsg,
+		NetParams:                  types.BtcSimnet.String(),
+		BTCCacheSize:               minBTCCacheSize,
+		MaxHeadersInMsg:            maxHeadersInMsg,
+		BootstrapRetryInitialDelay: defaultBootstrapRetryInitialDelay,
+		BootstrapRetryMaxDelay:     defaultBootstrapRetryMaxDelay,
+		BootstrapRetryMaxJitter:    defaultBootstrapRetryMaxJitter,
+		BootstrapMaxAttempts:       defaultBootstrapMaxAttempts,
+		BootstrapRetryForever:      false,
+		BTCSyncPollInterval:        defaultBTCSyncPollInterval,
+		GapFillThreshold:           defaultGapFillThreshold,
+		GapFillProgressFile:        defaultGapFillProgressFile,
+		CkptRetryQueueFile:         defaultCkptRetryQueueFile,
+		HeaderWitness:              DefaultHeaderWitnessConfig(),
 	}
 }
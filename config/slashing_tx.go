@@ -0,0 +1,62 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+const (
+	defaultSlashingTxStallBlocks        = 3
+	defaultSlashingTxFeeBumpTargetBlock = 1
+	defaultSlashingTxMaxFeeRate         = chainfee.SatPerKVByte(200 * 1000) // 200sat/vbyte
+)
+
+var defaultSlashingTxStoreFile = filepath.Join(defaultAppDataDir, "bstracker-slashing-txs.db")
+
+// SlashingTxConfig defines how the BTC slasher follows up on the slashing txs it
+// submits until they are k-deep, with k the confirmation depth of Babylon
+type SlashingTxConfig struct {
+	// number of BTC blocks a slashing tx can stay out of the best chain before it
+	// is considered stalled, and either its fee is bumped or an alert is raised
+	StallBlocks uint32 `mapstructure:"stall-blocks"`
+	// whether the fee of stalled slashing txs is bumped with a child tx spending the
+	// slashing output, signed and funded by the BTC wallet configured in the btc
+	// section. The wallet must hold the key of the slashing address.
+	EnableFeeBumping bool `mapstructure:"enable-fee-bumping"`
+	// number of blocks the fee of the slashing tx and its child is estimated to
+	// confirm within
+	FeeBumpTargetBlocks uint32 `mapstructure:"fee-bump-target-blocks"`
+	// maximum fee rate of the slashing tx together with its child, sat/kvb
+	MaxFeeRate chainfee.SatPerKVByte `mapstructure:"max-fee-rate"`
+	// StoreFile is the database persisting the slashing txs that are not k-deep
+	// yet, so that they are followed up on after a restart. Empty disables
+	// persistence.
+	StoreFile string `mapstructure:"store-file"`
+}
+
+func (cfg *SlashingTxConfig) Validate() error {
+	if cfg.StallBlocks == 0 {
+		return errors.New("stall-blocks should be positive")
+	}
+	if cfg.EnableFeeBumping {
+		if cfg.FeeBumpTargetBlocks == 0 {
+			return errors.New("fee-bump-target-blocks should be positive")
+		}
+		if cfg.MaxFeeRate <= 0 {
+			return errors.New("max-fee-rate should be positive")
+		}
+	}
+	return nil
+}
+
+func DefaultSlashingTxConfig() SlashingTxConfig {
+	return SlashingTxConfig{
+		StallBlocks:         defaultSlashingTxStallBlocks,
+		EnableFeeBumping:    false,
+		FeeBumpTargetBlocks: defaultSlashingTxFeeBumpTargetBlock,
+		MaxFeeRate:          defaultSlashingTxMaxFeeRate,
+		StoreFile:           defaultSlashingTxStoreFile,
+	}
+}
@@ -81,7 +81,10 @@ func TestAtomicSlasher(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
@@ -221,7 +224,10 @@ func TestAtomicSlasher_Unbonding(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
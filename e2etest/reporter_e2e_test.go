@@ -131,7 +131,7 @@ func TestReporter_BoostrapUnderFrequentBTCHeaders(t *testing.T) {
 	tm.GenerateAndSubmitsNBlocksFromTip(2)
 
 	// start reporter
-	vigilantReporter.Start()
+	require.NoError(t, vigilantReporter.Start())
 	defer vigilantReporter.Stop()
 
 	// tips should eventually match
@@ -170,7 +170,7 @@ func TestRelayHeadersAndHandleRollbacks(t *testing.T) {
 		reporterMetrics,
 	)
 	require.NoError(t, err)
-	vigilantReporter.Start()
+	require.NoError(t, vigilantReporter.Start())
 	defer vigilantReporter.Stop()
 
 	require.Eventually(t, func() bool {
@@ -224,7 +224,7 @@ func TestHandleReorgAfterRestart(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	vigilantReporter.Start()
+	require.NoError(t, vigilantReporter.Start())
 
 	require.Eventually(t, func() bool {
 		return tm.BabylonBTCChainMatchesBtc(t)
@@ -255,7 +255,7 @@ func TestHandleReorgAfterRestart(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	vigilantReporterNew.Start()
+	require.NoError(t, vigilantReporterNew.Start())
 
 	// Headers should match even though reorg happened
 	require.Eventually(t, func() bool {
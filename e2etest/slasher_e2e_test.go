@@ -76,7 +76,10 @@ func TestSlasher_GracefulShutdown(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
@@ -150,7 +153,10 @@ func TestSlasher_Slasher(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
@@ -247,7 +253,10 @@ func TestSlasher_SlashingUnbonding(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
@@ -361,7 +370,10 @@ func TestSlasher_Bootstrapping(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
@@ -70,6 +70,8 @@ func TestUnbondingWatcher(t *testing.T) {
 	commonCfg := config.DefaultCommonConfig()
 	bstCfg := config.DefaultBTCStakingTrackerConfig()
 	bstCfg.CheckDelegationsInterval = 1 * time.Second
+	// the test mines a single block on top of the unbonding tx
+	bstCfg.SpendConfirmationDepth = 1
 	logger, err := config.NewRootLogger("auto", "debug")
 	require.NoError(t, err)
 
@@ -78,7 +80,10 @@ func TestUnbondingWatcher(t *testing.T) {
 	bsTracker := bst.NewBTCSTakingTracker(
 		tm.BTCClient,
 		backend,
+		&emptyHintCache,
 		tm.BabylonClient,
+		nil,
+		nil,
 		&bstCfg,
 		&commonCfg,
 		logger,
@@ -11,6 +11,9 @@ type BTCStakingTrackerMetrics struct {
 	*UnbondingWatcherMetrics
 	*SlasherMetrics
 	*AtomicSlasherMetrics
+	*AlertMetrics
+	*BabylonSchedulerMetrics
+	*BlockStreamMetrics
 }
 
 func NewBTCStakingTrackerMetrics() *BTCStakingTrackerMetrics {
@@ -18,17 +21,25 @@ func NewBTCStakingTrackerMetrics() *BTCStakingTrackerMetrics {
 	uwMetrics := newUnbondingWatcherMetrics(registry)
 	slasherMetrics := newSlasherMetrics(registry)
 	atomicSlasherMetrics := newAtomicSlasherMetrics(registry)
+	alertMetrics := newAlertMetrics(registry, "btcstaking_tracker")
+	schedulerMetrics := newBabylonSchedulerMetrics(registry)
+	blockStreamMetrics := newBlockStreamMetrics(registry)
 
-	return &BTCStakingTrackerMetrics{registry, uwMetrics, slasherMetrics, atomicSlasherMetrics}
+	return &BTCStakingTrackerMetrics{registry, uwMetrics, slasherMetrics, atomicSlasherMetrics, alertMetrics, schedulerMetrics, blockStreamMetrics}
 }
 
 type UnbondingWatcherMetrics struct {
 	Registry                                *prometheus.Registry
 	ReportedUnbondingTransactionsCounter    prometheus.Counter
 	FailedReportedUnbondingTransactions     prometheus.Counter
-	NumberOfTrackedActiveDelegations        prometheus.Gauge
+	TrackedDelegationsGaugeVec              *prometheus.GaugeVec
+	FinishedDelegationsCounterVec           *prometheus.CounterVec
 	DetectedUnbondingTransactionsCounter    prometheus.Counter
 	DetectedNonUnbondingTransactionsCounter prometheus.Counter
+	DetectedSpendsCounterVec                *prometheus.CounterVec
+	ReorgedSpendsCounter                    prometheus.Counter
+	PendingSpendsGauge                      prometheus.Gauge
+	PrunedHintsCounter                      prometheus.Counter
 }
 
 func newUnbondingWatcherMetrics(registry *prometheus.Registry) *UnbondingWatcherMetrics {
@@ -44,10 +55,26 @@ func newUnbondingWatcherMetrics(registry *prometheus.Registry) *UnbondingWatcher
 			Name: "unbonding_watcher_failed_reported_unbonding_transactions",
 			Help: "The total number times reporting unbonding transactions to Babylon node failed",
 		}),
-		NumberOfTrackedActiveDelegations: registerer.NewGauge(prometheus.GaugeOpts{
-			Name: "unbonding_watcher_tracked_active_delegations",
-			Help: "The number of active delegations tracked by unbonding watcher",
-		}),
+		TrackedDelegationsGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "unbonding_watcher_tracked_delegations",
+				Help: "The number of delegations tracked by unbonding watcher",
+			},
+			[]string{
+				// active or unbonding
+				"state",
+			},
+		),
+		FinishedDelegationsCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "unbonding_watcher_finished_delegations",
+				Help: "The total number of delegations that reached a terminal state and are no longer tracked by unbonding watcher",
+			},
+			[]string{
+				// withdrawn, slashed, or unknown_spend
+				"state",
+			},
+		),
 		DetectedUnbondingTransactionsCounter: registerer.NewCounter(prometheus.CounterOpts{
 			Name: "unbonding_watcher_detected_unbonding_transactions",
 			Help: "The total number of unbonding transactions detected by unbonding watcher",
@@ -56,6 +83,30 @@ func newUnbondingWatcherMetrics(registry *prometheus.Registry) *UnbondingWatcher
 			Name: "unbonding_watcher_detected_non_unbonding_transactions",
 			Help: "The total number of non unbonding (slashing or withdrawal) transactions detected by unbonding watcher",
 		}),
+		DetectedSpendsCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "unbonding_watcher_detected_spends",
+				Help: "The total number of spends of staking and unbonding outputs detected by unbonding watcher",
+			},
+			[]string{
+				// staking or unbonding
+				"output",
+				// unbonding, withdrawal, slashing, or unknown
+				"type",
+			},
+		),
+		ReorgedSpendsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "unbonding_watcher_reorged_spends",
+			Help: "The total number of spends of staking and unbonding outputs reorged out before reaching the confirmation depth",
+		}),
+		PendingSpendsGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "unbonding_watcher_pending_spends",
+			Help: "The number of spends of staking outputs waiting for a free spend worker",
+		}),
+		PrunedHintsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "unbonding_watcher_pruned_hints",
+			Help: "The total number of stale spend and confirmation hints pruned from the hint cache",
+		}),
 	}
 
 	return uwMetrics
@@ -66,6 +117,11 @@ type SlasherMetrics struct {
 	SlashedFinalityProvidersCounter prometheus.Counter
 	SlashedDelegationsCounter       prometheus.Counter
 	SlashedSatsCounter              prometheus.Counter
+	TrackedSlashingTxsGauge         prometheus.Gauge
+	ConfirmedSlashingTxsCounter     prometheus.Counter
+	StalledSlashingTxsCounter       prometheus.Counter
+	FeeBumpedSlashingTxsCounter     prometheus.Counter
+	FailedFeeBumpsCounter           prometheus.Counter
 }
 
 func newSlasherMetrics(registry *prometheus.Registry) *SlasherMetrics {
@@ -84,6 +140,26 @@ func newSlasherMetrics(registry *prometheus.Registry) *SlasherMetrics {
 			Name: "slasher_slashed_sats",
 			Help: "The amount of slashed funds in Satoshi",
 		}),
+		TrackedSlashingTxsGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "slasher_tracked_slashing_txs",
+			Help: "The number of submitted slashing txs that are not k-deep yet",
+		}),
+		ConfirmedSlashingTxsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "slasher_confirmed_slashing_txs",
+			Help: "The number of slashing txs that became k-deep",
+		}),
+		StalledSlashingTxsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "slasher_stalled_slashing_txs",
+			Help: "The number of times a slashing tx stayed out of the best chain for too many blocks",
+		}),
+		FeeBumpedSlashingTxsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "slasher_fee_bumped_slashing_txs",
+			Help: "The number of stalled slashing txs whose fee is bumped with a child tx",
+		}),
+		FailedFeeBumpsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "slasher_failed_fee_bumps",
+			Help: "The number of failed attempts to bump the fee of a stalled slashing tx",
+		}),
 		SlashedDelegationGaugeVec: registerer.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "slasher_new_slashed_delegation",
@@ -92,8 +168,8 @@ func newSlasherMetrics(registry *prometheus.Registry) *SlasherMetrics {
 			[]string{
 				// del_btc_pk is the Bitcoin secp256k1 PK of this BTC delegation in hex string
 				"del_btc_pk",
-				// fp_btc_pk is the Bitcoin secp256k1 PK of the finality provider that
-				// this BTC delegation delegates to, in hex string
+				// fp_btc_pk is the Bitcoin secp256k1 PK of the slashed finality provider
+				// that this BTC delegation is restaked to, in hex string
 				"fp_btc_pk",
 			},
 		),
@@ -102,13 +178,19 @@ func newSlasherMetrics(registry *prometheus.Registry) *SlasherMetrics {
 	return metrics
 }
 
-func (sm *SlasherMetrics) RecordSlashedDelegation(del *types.BTCDelegationResponse, txHashStr string) {
-	// refresh time of the slashed delegation gauge for each (fp, del) pair
-	for _, pk := range del.FpBtcPkList {
-		sm.SlashedDelegationGaugeVec.WithLabelValues(
-			del.BtcPk.MarshalHex(),
-			pk.MarshalHex(),
-		).SetToCurrentTime()
+// RecordSlashedDelegation records the slashing of a BTC delegation by the given
+// finality provider. A delegation restaked to several slashed finality providers
+// is recorded for each of them, but its slashed funds are only counted upon the
+// first record of its slashing tx.
+func (sm *SlasherMetrics) RecordSlashedDelegation(del *types.BTCDelegationResponse, fpBTCPKHex string, isNewSlashingTx bool) {
+	// refresh time of the slashed delegation gauge for the (fp, del) pair
+	sm.SlashedDelegationGaugeVec.WithLabelValues(
+		del.BtcPk.MarshalHex(),
+		fpBTCPKHex,
+	).SetToCurrentTime()
+
+	if !isNewSlashingTx {
+		return
 	}
 
 	// increment slashed Satoshis and slashed delegations
@@ -134,3 +216,78 @@ func newAtomicSlasherMetrics(registry *prometheus.Registry) *AtomicSlasherMetric
 
 	return asMetrics
 }
+
+type BabylonSchedulerMetrics struct {
+	QueuedRequestsGaugeVec    *prometheus.GaugeVec
+	InFlightRequestsGaugeVec  *prometheus.GaugeVec
+	RequestsCounterVec        *prometheus.CounterVec
+	QueueWaitTimeHistogramVec *prometheus.HistogramVec
+}
+
+func newBabylonSchedulerMetrics(registry *prometheus.Registry) *BabylonSchedulerMetrics {
+	registerer := promauto.With(registry)
+
+	return &BabylonSchedulerMetrics{
+		QueuedRequestsGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "babylon_scheduler_queued_requests",
+				Help: "The number of requests to Babylon waiting for a free slot",
+			},
+			[]string{"endpoint", "priority"},
+		),
+		InFlightRequestsGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "babylon_scheduler_in_flight_requests",
+				Help: "The number of requests to Babylon in flight",
+			},
+			[]string{"endpoint"},
+		),
+		RequestsCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "babylon_scheduler_requests",
+				Help: "The total number of requests to Babylon scheduled",
+			},
+			[]string{
+				"endpoint",
+				// success, failure, or cancelled
+				"result",
+			},
+		),
+		QueueWaitTimeHistogramVec: registerer.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "babylon_scheduler_queue_wait_seconds",
+				Help:    "The time requests to Babylon wait for a free slot, including rate limiting",
+				Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+			},
+			[]string{"priority"},
+		),
+	}
+}
+
+type BlockStreamMetrics struct {
+	ConnectedBlocksCounter    prometheus.Counter
+	DisconnectedBlocksCounter prometheus.Counter
+	FullBuffersCounterVec     *prometheus.CounterVec
+}
+
+func newBlockStreamMetrics(registry *prometheus.Registry) *BlockStreamMetrics {
+	registerer := promauto.With(registry)
+
+	return &BlockStreamMetrics{
+		ConnectedBlocksCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "block_stream_connected_blocks",
+			Help: "The total number of BTC blocks connected to the best chain and sent to the routines of the tracker",
+		}),
+		DisconnectedBlocksCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "block_stream_disconnected_blocks",
+			Help: "The total number of BTC blocks disconnected from the best chain by reorgs",
+		}),
+		FullBuffersCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "block_stream_full_buffers",
+				Help: "The total number of times a routine fell behind the BTC blocks by its whole buffer, holding up the others",
+			},
+			[]string{"subscriber"},
+		),
+	}
+}
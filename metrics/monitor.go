@@ -6,12 +6,115 @@ import (
 )
 
 type MonitorMetrics struct {
-	Registry                 *prometheus.Registry
-	ValidEpochsCounter       prometheus.Counter
-	InvalidEpochsCounter     prometheus.Counter
-	ValidBTCHeadersCounter   prometheus.Counter
-	InvalidBTCHeadersCounter prometheus.Counter
-	LivenessAttacksCounter   prometheus.Counter
+	Registry                        *prometheus.Registry
+	ValidEpochsCounter              prometheus.Counter
+	InvalidEpochsCounter            prometheus.Counter
+	ValidBTCHeadersCounter          prometheus.Counter
+	InvalidBTCHeadersCounter        prometheus.Counter
+	LivenessAttacksCounter          prometheus.Counter
+	DeepReorgsCounter               prometheus.Counter
+	BufferedCheckpointsGauge        prometheus.Gauge
+	DroppedCheckpointsCounter       prometheus.Counter
+	EpochGapSecondsGauge            prometheus.Gauge
+	LightClientDivergenceDepthGauge prometheus.Gauge
+	LightClientAuditFailuresCounter prometheus.Counter
+	ValSetChangesCounter            prometheus.Counter
+	ValSetCacheHitsCounter          prometheus.Counter
+	ValSetCacheMissesCounter        prometheus.Counter
+	ValSetEvictionsCounter          prometheus.Counter
+	*AlertMetrics
+	*BtcScannerMetrics
+}
+
+// BtcScannerMetrics are the metrics of the bootstrapping progress of the BTC scanner
+type BtcScannerMetrics struct {
+	BootstrapScannedHeightGauge   prometheus.Gauge
+	BootstrapRemainingBlocksGauge prometheus.Gauge
+	BootstrapETASecondsGauge      prometheus.Gauge
+}
+
+func newBtcScannerMetrics(registry *prometheus.Registry) *BtcScannerMetrics {
+	registerer := promauto.With(registry)
+
+	metrics := &BtcScannerMetrics{
+		BootstrapScannedHeightGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_monitor_bootstrap_scanned_height",
+			Help: "The height of the last confirmed BTC block scanned during bootstrapping",
+		}),
+		BootstrapRemainingBlocksGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_monitor_bootstrap_remaining_blocks",
+			Help: "The number of confirmed BTC blocks remaining to be scanned during bootstrapping",
+		}),
+		BootstrapETASecondsGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_monitor_bootstrap_eta_seconds",
+			Help: "The estimated number of seconds until bootstrapping has scanned all confirmed BTC blocks",
+		}),
+	}
+
+	return metrics
+}
+
+// AlertMetrics are the metrics of the alerts sent by the monitor or the BTC staking tracker
+type AlertMetrics struct {
+	AlertsRaisedCounterVec      *prometheus.CounterVec
+	AlertsSentCounterVec        *prometheus.CounterVec
+	AlertSendFailuresCounterVec *prometheus.CounterVec
+	AlertsExpiredCounterVec     *prometheus.CounterVec
+	ActiveAlertsGauge           prometheus.Gauge
+}
+
+// newAlertMetrics registers the alert metrics, with names starting with the given prefix
+func newAlertMetrics(registry *prometheus.Registry, prefix string) *AlertMetrics {
+	registerer := promauto.With(registry)
+
+	metrics := &AlertMetrics{
+		AlertsRaisedCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prefix + "_alerts_raised",
+				Help: "The total number of distinct alerts raised",
+			},
+			[]string{
+				// the kind of the alert
+				"kind",
+			},
+		),
+		AlertsSentCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prefix + "_alerts_sent",
+				Help: "The total number of alerts sent, including re-sends",
+			},
+			[]string{
+				// the name of the alert sink
+				"sink",
+			},
+		),
+		AlertSendFailuresCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prefix + "_alert_send_failures",
+				Help: "The total number of alerts that failed to be sent",
+			},
+			[]string{
+				// the name of the alert sink
+				"sink",
+			},
+		),
+		AlertsExpiredCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: prefix + "_alerts_expired",
+				Help: "The total number of alerts dropped without being resolved",
+			},
+			[]string{
+				// the kind of the alert
+				"kind",
+			},
+		),
+		ActiveAlertsGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: prefix + "_active_alerts",
+			Help: "The number of alerts that are not resolved yet",
+		}),
+	}
+
+	return metrics
 }
 
 func NewMonitorMetrics() *MonitorMetrics {
@@ -40,6 +143,48 @@ func NewMonitorMetrics() *MonitorMetrics {
 			Name: "vigilante_monitor_liveness_attacks",
 			Help: "The total number of detected liveness attacks",
 		}),
+		DeepReorgsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_deep_reorgs",
+			Help: "The total number of BTC reorgs deeper than the confirmation depth",
+		}),
+		BufferedCheckpointsGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_monitor_buffered_checkpoints",
+			Help: "The number of checkpoints of later epochs buffered until the current epoch is verified",
+		}),
+		DroppedCheckpointsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_dropped_checkpoints",
+			Help: "The total number of checkpoints of later epochs dropped as the buffer of out-of-order checkpoints is full",
+		}),
+		EpochGapSecondsGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_monitor_epoch_gap_seconds",
+			Help: "The number of seconds the monitor has been waiting for the checkpoint of the current epoch while checkpoints of later epochs are buffered",
+		}),
+		LightClientDivergenceDepthGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_monitor_light_client_divergence_depth",
+			Help: "The number of headers from the tip of Babylon's BTC light client that are not on the canonical chain of the BTC node",
+		}),
+		LightClientAuditFailuresCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_light_client_audit_failures",
+			Help: "The total number of audits of Babylon's BTC light client that failed to complete",
+		}),
+		ValSetChangesCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_valset_changes",
+			Help: "The total number of epochs whose validator set differs from that of the previous epoch",
+		}),
+		ValSetCacheHitsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_valset_cache_hits",
+			Help: "The total number of epochs whose validator set is found in the cache",
+		}),
+		ValSetCacheMissesCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_valset_cache_misses",
+			Help: "The total number of epochs whose validator set is not found in the cache",
+		}),
+		ValSetEvictionsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_monitor_valset_evictions",
+			Help: "The total number of cached validator sets evicted as they failed to verify a BLS multi-sig",
+		}),
+		AlertMetrics:      newAlertMetrics(registry, "vigilante_monitor"),
+		BtcScannerMetrics: newBtcScannerMetrics(registry),
 	}
 	return metrics
 }
@@ -17,6 +17,121 @@ type ReporterMetrics struct {
 	SecondsSinceLastCheckpointGauge prometheus.Gauge
 	NewReportedHeaderGaugeVec       *prometheus.GaugeVec
 	NewReportedCheckpointGaugeVec   *prometheus.GaugeVec
+	CheckpointRetryQueueSizeGauge   prometheus.Gauge
+	FailedBootstrapAttemptsCounter  prometheus.Counter
+	HealthStatusGauge               prometheus.Gauge
+	GapFillRemainingHeadersGauge    prometheus.Gauge
+	*BTCBackendMetrics
+	*HeaderWitnessMetrics
+}
+
+// BTCBackendMetrics are the metrics of each BTC node a multi-backend BTC client connects to
+type BTCBackendMetrics struct {
+	BackendHealthyGaugeVec   *prometheus.GaugeVec
+	BackendActiveGaugeVec    *prometheus.GaugeVec
+	BackendTipHeightGaugeVec *prometheus.GaugeVec
+	BackendFailoversCounter  prometheus.Counter
+}
+
+func newBTCBackendMetrics(registry *prometheus.Registry) *BTCBackendMetrics {
+	registerer := promauto.With(registry)
+
+	metrics := &BTCBackendMetrics{
+		BackendHealthyGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "vigilante_btc_backend_healthy",
+				Help: "Whether the BTC node responded to the last tip poll (1) or not (0)",
+			},
+			[]string{
+				// the endpoint of the BTC node
+				"backend",
+			},
+		),
+		BackendActiveGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "vigilante_btc_backend_active",
+				Help: "Whether the BTC node is the one currently in use (1) or not (0)",
+			},
+			[]string{
+				// the endpoint of the BTC node
+				"backend",
+			},
+		),
+		BackendTipHeightGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "vigilante_btc_backend_tip_height",
+				Help: "The height of the tip of the BTC node at the last tip poll",
+			},
+			[]string{
+				// the endpoint of the BTC node
+				"backend",
+			},
+		),
+		BackendFailoversCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_btc_backend_failovers",
+			Help: "The total number of times the BTC client failed over to another BTC node",
+		}),
+	}
+
+	return metrics
+}
+
+// HeaderWitnessMetrics are the metrics of the cross-check between the BTC node
+// and independent BTC header sources
+type HeaderWitnessMetrics struct {
+	WitnessAlarmGaugeVec        *prometheus.GaugeVec
+	WitnessAlarmsCounterVec     *prometheus.CounterVec
+	WitnessCheckFailuresCounter *prometheus.CounterVec
+	WitnessTipHeightGaugeVec    *prometheus.GaugeVec
+}
+
+func newHeaderWitnessMetrics(registry *prometheus.Registry) *HeaderWitnessMetrics {
+	registerer := promauto.With(registry)
+
+	metrics := &HeaderWitnessMetrics{
+		WitnessAlarmGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "vigilante_header_witness_alarm",
+				Help: "Whether the header source reports a better chain than the BTC node (1) or not (0)",
+			},
+			[]string{
+				// the name of the header source
+				"source",
+			},
+		),
+		WitnessAlarmsCounterVec: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "vigilante_header_witness_alarms",
+				Help: "The total number of alarms raised by the header source",
+			},
+			[]string{
+				// the name of the header source
+				"source",
+			},
+		),
+		WitnessCheckFailuresCounter: registerer.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "vigilante_header_witness_check_failures",
+				Help: "The total number of cross-checks against the header source that could not be completed",
+			},
+			[]string{
+				// the name of the header source
+				"source",
+			},
+		),
+		WitnessTipHeightGaugeVec: registerer.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "vigilante_header_witness_tip_height",
+				Help: "The height of the best header of the header source at the last cross-check",
+			},
+			[]string{
+				// the name of the header source
+				"source",
+			},
+		),
+	}
+
+	return metrics
 }
 
 func NewReporterMetrics() *ReporterMetrics {
@@ -49,6 +164,22 @@ func NewReporterMetrics() *ReporterMetrics {
 			Name: "vigilante_reporter_since_last_checkpoint_seconds",
 			Help: "Seconds since the last successful reported BTC checkpoint to Babylon",
 		}),
+		CheckpointRetryQueueSizeGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_reporter_checkpoint_retry_queue_size",
+			Help: "The number of matched BTC checkpoints waiting to be accepted by Babylon",
+		}),
+		FailedBootstrapAttemptsCounter: registerer.NewCounter(prometheus.CounterOpts{
+			Name: "vigilante_reporter_failed_bootstrap_attempts",
+			Help: "The total number of failed bootstrap attempts of the reporter",
+		}),
+		HealthStatusGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_reporter_health_status",
+			Help: "The health status of the reporter (0: starting, 1: OK, 2: retrying bootstrap, 3: bootstrap failed)",
+		}),
+		GapFillRemainingHeadersGauge: registerer.NewGauge(prometheus.GaugeOpts{
+			Name: "vigilante_reporter_gap_fill_remaining_headers",
+			Help: "The number of BTC headers left to submit in the gap-fill mode",
+		}),
 		NewReportedHeaderGaugeVec: registerer.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "vigilante_reporter_new_btc_header",
@@ -75,6 +206,8 @@ func NewReporterMetrics() *ReporterMetrics {
 				"tx2id",
 			},
 		),
+		BTCBackendMetrics:    newBTCBackendMetrics(registry),
+		HeaderWitnessMetrics: newHeaderWitnessMetrics(registry),
 	}
 	return metrics
 }
@@ -0,0 +1,179 @@
+package monitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/vigilante/alert"
+	"github.com/babylonchain/vigilante/monitor/btcscanner"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// newCheckpointEvidence collects the evidence of the checkpoint found on BTC
+func newCheckpointEvidence(ckpt *types.CheckpointRecord) alert.Evidence {
+	evidence := alert.Evidence{
+		CheckpointID:           ckpt.ID(),
+		BTCCheckpointBlockHash: ckpt.RawCheckpoint.BlockHash.String(),
+		FirstSeenBTCHeight:     ckpt.FirstSeenBtcHeight,
+	}
+	for _, tx := range ckpt.Txs {
+		evidence.Txs = append(evidence.Txs, alert.TxEvidence{
+			TxId:        tx.TxId,
+			BlockHash:   tx.BlockHash,
+			BlockHeight: tx.BlockHeight,
+		})
+	}
+	return evidence
+}
+
+// raiseInconsistentCheckpointAlert raises an alert on a valid checkpoint on BTC that
+// commits to a different Babylon block than the checkpoint of the same epoch on Babylon
+func (m *Monitor) raiseInconsistentCheckpointAlert(ckpt *types.CheckpointRecord, verifyErr error, bundleFile string) {
+	if m.alerts == nil {
+		return
+	}
+
+	evidence := newCheckpointEvidence(ckpt)
+	evidence.BundleFile = bundleFile
+	// the Babylon block hash is queried again, as it is only part of the message of the verification error
+	res, err := m.queryRawCheckpointWithRetry(ckpt.EpochNum())
+	if err != nil {
+		m.logger.Errorf("failed to query raw checkpoint at epoch %d for alert evidence: %s", ckpt.EpochNum(), err.Error())
+	} else if bbnCkpt, err := res.RawCheckpoint.Ckpt.ToRawCheckpoint(); err == nil {
+		evidence.BabylonBlockHash = bbnCkpt.BlockHash.String()
+	}
+
+	m.alerts.Raise(&alert.Alert{
+		Kind:     alert.KindInconsistentCheckpoint,
+		Epoch:    ckpt.EpochNum(),
+		Message:  verifyErr.Error(),
+		Evidence: evidence,
+	})
+}
+
+// raiseLivenessAttackAlert raises an alert on a checkpoint that is not reported to Babylon in time
+func (m *Monitor) raiseLivenessAttackAlert(ckpt *types.CheckpointRecord, livenessErr error) {
+	if m.alerts == nil {
+		return
+	}
+
+	m.alerts.Raise(&alert.Alert{
+		Kind:     alert.KindLivenessAttack,
+		Epoch:    ckpt.EpochNum(),
+		Message:  livenessErr.Error(),
+		Evidence: newCheckpointEvidence(ckpt),
+	})
+}
+
+// resolveLivenessAttackAlert stops re-sending the liveness alert on a checkpoint once it passes the liveness check
+func (m *Monitor) resolveLivenessAttackAlert(ckpt *types.CheckpointRecord) {
+	if m.alerts == nil {
+		return
+	}
+
+	m.alerts.Resolve(alert.Key(alert.KindLivenessAttack, ckpt.EpochNum(), ckpt.ID()))
+}
+
+// raiseStuckEpochAlert raises an alert on an epoch whose checkpoint is not found on BTC
+// while checkpoints of later epochs are, so that the later ones cannot be verified
+func (m *Monitor) raiseStuckEpochAlert(epoch uint64, waiting time.Duration, nextBufferedEpoch uint64) {
+	if m.alerts == nil {
+		return
+	}
+
+	m.alerts.Raise(&alert.Alert{
+		Kind:  alert.KindStuckEpoch,
+		Epoch: epoch,
+		Message: fmt.Sprintf("stuck at epoch %d for %v: no valid checkpoint of epoch %d is found on BTC, while %d checkpoints of later epochs from epoch %d are waiting for verification",
+			epoch, waiting.Round(time.Second), epoch, m.numBuffered, nextBufferedEpoch),
+	})
+}
+
+// resolveStuckEpochAlert stops re-sending the stuck alert on an epoch once it is verified
+func (m *Monitor) resolveStuckEpochAlert(epoch uint64) {
+	if m.alerts == nil {
+		return
+	}
+
+	m.alerts.Resolve(alert.Key(alert.KindStuckEpoch, epoch, ""))
+}
+
+// raiseDeepReorgAlert raises an alert on a BTC reorg deeper than the confirmation depth.
+// Each reorg is a different alert, identified by the confirmed tip it replaces
+func (m *Monitor) raiseDeepReorgAlert(reorg *btcscanner.DeepReorg) {
+	if m.alerts == nil {
+		return
+	}
+
+	forkHash := "below the base height"
+	if reorg.ForkHash != nil {
+		forkHash = reorg.ForkHash.String()
+	}
+	a := &alert.Alert{
+		Kind:  alert.KindDeepReorg,
+		Epoch: m.GetCurrentEpoch(),
+		Message: fmt.Sprintf("BTC reorg of %d blocks deeper than the confirmation depth %d: the confirmed tip %s at height %d is replaced from the fork point %s at height %d",
+			reorg.Depth, m.Cfg.BtcConfirmationDepth, reorg.OldTipHash, reorg.OldTipHeight, forkHash, reorg.ForkHeight),
+		Evidence: alert.Evidence{ReorgedTipHash: reorg.OldTipHash.String()},
+	}
+	m.alerts.Raise(a)
+	if m.deepReorgAlerts == nil {
+		m.deepReorgAlerts = make(map[string]int32)
+	}
+	m.deepReorgAlerts[a.Key()] = reorg.OldTipHeight
+}
+
+// resolveDeepReorgAlerts stops re-sending the alerts on deep reorgs once the rescan
+// of the new canonical chain reaches the height of the confirmed tip they replaced,
+// i.e., once the checkpoints in the replaced blocks are found again if still on BTC.
+// The scanner sends the confirmed headers in order, so the header at that height is
+// recognised by its hash.
+func (m *Monitor) resolveDeepReorgAlerts(header *wire.BlockHeader) {
+	if m.alerts == nil || len(m.deepReorgAlerts) == 0 {
+		return
+	}
+
+	headerHash := header.BlockHash()
+	for key, oldTipHeight := range m.deepReorgAlerts {
+		canonicalHeader, err := m.BTCClient.GetBlockHeaderByHeight(uint64(oldTipHeight))
+		if err != nil {
+			// the new canonical chain may not reach the height yet
+			m.logger.Debugf("failed to get the BTC header at height %d to resolve alert %s: %v", oldTipHeight, key, err)
+			continue
+		}
+		if canonicalHeader.BlockHash() == headerHash {
+			m.alerts.Resolve(key)
+			delete(m.deepReorgAlerts, key)
+		}
+	}
+}
+
+// raiseLightClientDivergenceAlert raises an alert on Babylon's BTC light client accepting
+// a branch that the BTC node considers stale. The alert is not tied to any epoch
+func (m *Monitor) raiseLightClientDivergenceAlert(res *LightClientAuditResult) {
+	if m.alerts == nil {
+		return
+	}
+
+	commonHeader := fmt.Sprintf("the highest common header is at height %d", res.CommonHeight)
+	if res.CommonNotFound {
+		commonHeader = fmt.Sprintf("none of the top %d headers is on the canonical BTC chain", res.DivergenceDepth)
+	}
+	m.alerts.Raise(&alert.Alert{
+		Kind: alert.KindLightClientDivergence,
+		Message: fmt.Sprintf("the k-deep header at height %d of Babylon's BTC light client with tip height %d is not on the canonical chain of the BTC node with tip height %d: %s",
+			res.KDeepHeight, res.BabylonTipHeight, res.BtcTipHeight, commonHeader),
+	})
+}
+
+// resolveLightClientDivergenceAlert stops re-sending the divergence alert once Babylon's
+// BTC light client is back on the canonical BTC chain
+func (m *Monitor) resolveLightClientDivergenceAlert() {
+	if m.alerts == nil {
+		return
+	}
+
+	m.alerts.Resolve(alert.Key(alert.KindLightClientDivergence, 0, ""))
+}
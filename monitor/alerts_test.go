@@ -0,0 +1,72 @@
+package monitor
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/alert"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/monitor/btcscanner"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+)
+
+func TestDeepReorgAlerts(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ctl := gomock.NewController(t)
+	mockBtcClient := mocks.NewMockBTCClient(ctl)
+	m := newStateTestMonitor("", testChainID)
+	m.BTCClient = mockBtcClient
+	m.alerts = alert.NewDispatcher(&config.AlertConfig{}, nil, zap.NewNop(), m.metrics.AlertMetrics)
+
+	// the canonical chain of the BTC node, which the rescan catches up with
+	canonical := make(map[uint64]*wire.BlockHeader)
+	mockBtcClient.EXPECT().GetBlockHeaderByHeight(gomock.Any()).DoAndReturn(func(height uint64) (*wire.BlockHeader, error) {
+		if header, ok := canonical[height]; ok {
+			return header, nil
+		}
+		return nil, errors.New("block height out of range")
+	}).AnyTimes()
+
+	genReorg := func(oldTipHeight int32) *btcscanner.DeepReorg {
+		forkHash := chainhash.Hash(datagen.GenRandomByteArray(r, chainhash.HashSize))
+		return &btcscanner.DeepReorg{
+			OldTipHeight: oldTipHeight,
+			OldTipHash:   chainhash.Hash(datagen.GenRandomByteArray(r, chainhash.HashSize)),
+			ForkHeight:   oldTipHeight - 10,
+			ForkHash:     &forkHash,
+			Depth:        10,
+		}
+	}
+
+	// different deep reorgs in the same epoch are different alerts, and each
+	// of them is raised once
+	reorg1 := genReorg(100)
+	reorg2 := genReorg(110)
+	m.handleDeepReorg(reorg1)
+	m.handleDeepReorg(reorg2)
+	m.handleDeepReorg(reorg1)
+	require.Equal(t, 2, m.alerts.NumActive())
+
+	// the alerts stay active while the rescan is below the replaced tips
+	for h := uint64(91); h <= 110; h++ {
+		canonical[h] = datagen.GenRandomBtcdHeader(r)
+	}
+	m.resolveDeepReorgAlerts(canonical[95])
+	require.Equal(t, 2, m.alerts.NumActive())
+
+	// each alert is resolved once the rescan reaches the height of its replaced tip
+	m.resolveDeepReorgAlerts(canonical[100])
+	require.Equal(t, 1, m.alerts.NumActive())
+	m.resolveDeepReorgAlerts(canonical[110])
+	require.Zero(t, m.alerts.NumActive())
+	require.Empty(t, m.deepReorgAlerts)
+}
@@ -55,8 +55,7 @@ func (bs *BtcScanner) handleConnectedBlocks(event *types.BlockEvent) error {
 	blockHash := event.Header.BlockHash()
 	ib, _, err := bs.BtcClient.GetBlockByHash(&blockHash)
 	if err != nil {
-		// failing to request the block, which means a bug
-		panic(err)
+		return fmt.Errorf("%w: cannot get the BTC block %s: %v", ErrBTCRequest, blockHash, err)
 	}
 
 	// get cache tip
@@ -85,9 +84,15 @@ func (bs *BtcScanner) handleConnectedBlocks(event *types.BlockEvent) error {
 		return nil
 	}
 
+	// the bootstrapping handles the reorg once the handling of this block fails
+	if bs.confirmedTipBlock == nil {
+		bs.sendConfirmedBlocksToChan(confirmedBlocks)
+		return nil
+	}
 	confirmedTipHash := bs.confirmedTipBlock.BlockHash()
 	if !confirmedTipHash.IsEqual(&confirmedBlocks[0].Header.PrevBlock) {
-		panic("invalid canonical chain")
+		return fmt.Errorf("%w: BTC block %s at height %d does not extend the confirmed tip %s",
+			ErrDeepReorg, confirmedBlocks[0].BlockHash(), confirmedBlocks[0].Height, confirmedTipHash)
 	}
 
 	bs.sendConfirmedBlocksToChan(confirmedBlocks)
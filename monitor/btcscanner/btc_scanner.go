@@ -1,20 +1,32 @@
 package btcscanner
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/babylonchain/babylon/btctxformatter"
 	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 
 	"github.com/babylonchain/vigilante/btcclient"
 	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
 	"github.com/babylonchain/vigilante/types"
 )
 
+const (
+	bootstrapRetryInitialDelay = 5 * time.Second
+	bootstrapRetryMaxDelay     = 5 * time.Minute
+	deepReorgsBufferSize       = 10
+	// number of scanned blocks between two logs of the bootstrapping progress
+	bootstrapProgressLogInterval = 1000
+)
+
 type BtcScanner struct {
 	logger *zap.SugaredLogger
 
@@ -25,21 +37,28 @@ type BtcScanner struct {
 	BaseHeight uint64
 	// the BTC confirmation depth
 	K uint64
+	// number of concurrent requests to prefetch blocks during bootstrapping
+	prefetchConcurrency uint64
 
 	confirmedTipBlock   *types.IndexedBlock
 	ConfirmedBlocksChan chan *types.IndexedBlock
 
-	// cache of a sequence of checkpoints
-	ckptCache *types.CheckpointCache
+	// cache of a sequence of checkpoints. It is pruned on deep reorgs
+	// while being scanned, so it is guarded by the mutex
+	ckptCache   *types.CheckpointCache
+	ckptCacheMu sync.Mutex
 	// cache of a sequence of unconfirmed blocks
 	UnconfirmedBlockCache *types.BTCCache
 
 	// communicate with the monitor
 	blockHeaderChan chan *wire.BlockHeader
 	checkpointsChan chan *types.CheckpointRecord
+	deepReorgsChan  chan *DeepReorg
 
 	Synced *atomic.Bool
 
+	metrics *metrics.BtcScannerMetrics
+
 	wg      sync.WaitGroup
 	Started *atomic.Bool
 	quit    chan struct{}
@@ -51,6 +70,7 @@ func New(
 	btcClient btcclient.BTCClient,
 	btclightclientBaseHeight uint64,
 	checkpointTag []byte,
+	scannerMetrics *metrics.BtcScannerMetrics,
 ) (*BtcScanner, error) {
 	headersChan := make(chan *wire.BlockHeader, monitorCfg.BtcBlockBufferSize)
 	confirmedBlocksChan := make(chan *types.IndexedBlock, monitorCfg.BtcBlockBufferSize)
@@ -66,17 +86,26 @@ func New(
 		BtcClient:             btcClient,
 		BaseHeight:            btclightclientBaseHeight,
 		K:                     monitorCfg.BtcConfirmationDepth,
+		prefetchConcurrency:   monitorCfg.BootstrapPrefetchConcurrency,
 		ckptCache:             ckptCache,
 		UnconfirmedBlockCache: unconfirmedBlockCache,
 		ConfirmedBlocksChan:   confirmedBlocksChan,
 		blockHeaderChan:       headersChan,
 		checkpointsChan:       ckptsChan,
+		deepReorgsChan:        make(chan *DeepReorg, deepReorgsBufferSize),
 		Synced:                atomic.NewBool(false),
+		metrics:               scannerMetrics,
 		Started:               atomic.NewBool(false),
 		quit:                  make(chan struct{}),
 	}, nil
 }
 
+// SetConfirmedTipBlock sets the last confirmed block that has been scanned,
+// so that the scanning resumes from the next block. It must be called before Start
+func (bs *BtcScanner) SetConfirmedTipBlock(block *types.IndexedBlock) {
+	bs.confirmedTipBlock = block
+}
+
 // Start starts the scanning process from curBTCHeight to tipHeight
 func (bs *BtcScanner) Start() {
 	if bs.Started.Load() {
@@ -120,19 +149,42 @@ func (bs *BtcScanner) Start() {
 	bs.logger.Info("the BTC scanner is stopped")
 }
 
-// Bootstrap syncs with BTC by getting the confirmed blocks and the caching the unconfirmed blocks
+// Bootstrap syncs with BTC by getting the confirmed blocks and the caching the unconfirmed blocks.
+// It retries until the bootstrapping succeeds or the scanner is stopped.
 func (bs *BtcScanner) Bootstrap() {
-	var (
-		firstUnconfirmedHeight uint64
-		confirmedBlock         *types.IndexedBlock
-		err                    error
-	)
-
 	if bs.Synced.Load() {
 		// the scanner is already synced
 		return
 	}
-	defer bs.Synced.Store(true)
+
+	delay := bootstrapRetryInitialDelay
+	for attempt := 1; ; attempt++ {
+		err := bs.bootstrap()
+		if err == nil {
+			bs.Synced.Store(true)
+			return
+		}
+		if errors.Is(err, ErrDeepReorg) {
+			// the confirmed tip is rewound to the fork point, so bootstrap again right away
+			bs.logger.Errorf("bootstrapping is restarted after a deep reorg: %v", err)
+			continue
+		}
+
+		bs.logger.Warnf("failed to bootstrap the BTC scanner: %v. Attempt: %d, next attempt in %v", err, attempt, delay)
+		select {
+		case <-bs.quit:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, bootstrapRetryMaxDelay)
+	}
+}
+
+func (bs *BtcScanner) bootstrap() error {
+	var (
+		firstUnconfirmedHeight uint64
+		confirmedBlock         *types.IndexedBlock
+	)
 
 	if bs.confirmedTipBlock != nil {
 		firstUnconfirmedHeight = uint64(bs.confirmedTipBlock.Height + 1)
@@ -147,36 +199,40 @@ func (bs *BtcScanner) Bootstrap() {
 
 	_, bestHeight, err := bs.BtcClient.GetBestBlock()
 	if err != nil {
-		panic(fmt.Errorf("cannot get the best BTC block"))
+		return fmt.Errorf("%w: cannot get the best BTC block: %v", ErrBTCRequest, err)
 	}
 
 	bestConfirmedHeight := bestHeight - bs.K
-	// process confirmed blocks
-	for i := firstUnconfirmedHeight; i <= bestConfirmedHeight; i++ {
-		ib, _, err := bs.BtcClient.GetBlockByHeight(i)
-		if err != nil {
-			panic(err)
+	// process confirmed blocks, which are prefetched concurrently
+	done := make(chan struct{})
+	defer close(done)
+	progress := newBootstrapProgress(firstUnconfirmedHeight, bestConfirmedHeight)
+	for result := range bs.prefetchBlocks(firstUnconfirmedHeight, bestConfirmedHeight, done) {
+		res := <-result
+		if res.err != nil {
+			return res.err
 		}
 
 		// this is a confirmed block
-		confirmedBlock = ib
+		confirmedBlock = res.block
 
 		// if the scanner was bootstrapped before, the new confirmed canonical chain must connect to the previous one
 		if bs.confirmedTipBlock != nil {
 			confirmedTipHash := bs.confirmedTipBlock.BlockHash()
 			if !confirmedTipHash.IsEqual(&confirmedBlock.Header.PrevBlock) {
-				panic("invalid canonical chain")
+				return bs.handleDeepReorg()
 			}
 		}
 
 		bs.sendConfirmedBlocksToChan([]*types.IndexedBlock{confirmedBlock})
+		bs.reportBootstrapProgress(progress, uint64(confirmedBlock.Height))
 	}
 
 	// add unconfirmed blocks into the cache
 	for i := bestConfirmedHeight + 1; i <= bestHeight; i++ {
 		ib, _, err := bs.BtcClient.GetBlockByHeight(i)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("%w: cannot get the BTC block at height %d: %v", ErrBTCRequest, i, err)
 		}
 
 		// the unconfirmed blocks must follow the canonical chain
@@ -184,7 +240,8 @@ func (bs *BtcScanner) Bootstrap() {
 		if tipCache != nil {
 			tipHash := tipCache.BlockHash()
 			if !tipHash.IsEqual(&ib.Header.PrevBlock) {
-				panic("invalid canonical chain")
+				return fmt.Errorf("%w: BTC block %s at height %d does not extend the cached tip %s",
+					ErrChainChanged, ib.BlockHash(), i, tipHash)
 			}
 		}
 
@@ -192,6 +249,73 @@ func (bs *BtcScanner) Bootstrap() {
 	}
 
 	bs.logger.Infof("bootstrapping is finished at the best confirmed height: %d", bestConfirmedHeight)
+
+	return nil
+}
+
+// handleDeepReorg rewinds the confirmed tip to the last block that is still on the
+// canonical chain, and reports the reorg. Checkpoint segments in the reorged blocks
+// are dropped, and the blocks after the fork point are scanned again.
+func (bs *BtcScanner) handleDeepReorg() error {
+	oldTip := bs.confirmedTipBlock
+	forkBlock, err := bs.findForkPoint(oldTip)
+	if err != nil {
+		return err
+	}
+
+	reorg := &DeepReorg{
+		OldTipHeight: oldTip.Height,
+		OldTipHash:   oldTip.BlockHash(),
+		DetectedAt:   time.Now(),
+	}
+	if forkBlock != nil {
+		forkHash := forkBlock.BlockHash()
+		reorg.ForkHeight = forkBlock.Height
+		reorg.ForkHash = &forkHash
+		reorg.Depth = oldTip.Height - forkBlock.Height
+	} else {
+		reorg.ForkHeight = int32(bs.BaseHeight) - 1
+		reorg.Depth = oldTip.Height - reorg.ForkHeight
+	}
+
+	bs.logger.Errorf("found a BTC reorg of %d confirmed blocks from height %d, which is deeper than the confirmation depth %d",
+		reorg.Depth, reorg.ForkHeight+1, bs.K)
+
+	bs.confirmedTipBlock = forkBlock
+	if bs.ckptCache != nil {
+		bs.ckptCacheMu.Lock()
+		bs.ckptCache.RemoveSegmentsAbove(reorg.ForkHeight)
+		bs.ckptCacheMu.Unlock()
+	}
+	if bs.deepReorgsChan != nil {
+		select {
+		case bs.deepReorgsChan <- reorg:
+		case <-bs.quit:
+		}
+	}
+
+	return fmt.Errorf("%w: %d confirmed blocks from height %d are reorged", ErrDeepReorg, reorg.Depth, reorg.ForkHeight+1)
+}
+
+// findForkPoint walks back from the given block until a block on the canonical chain is found.
+// It returns nil if no such block is found above the base height.
+func (bs *BtcScanner) findForkPoint(block *types.IndexedBlock) (*types.IndexedBlock, error) {
+	for uint64(block.Height) >= bs.BaseHeight {
+		canonicalBlock, _, err := bs.BtcClient.GetBlockByHeight(uint64(block.Height))
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot get the BTC block at height %d: %v", ErrBTCRequest, block.Height, err)
+		}
+		if canonicalBlock.BlockHash() == block.BlockHash() {
+			return block, nil
+		}
+
+		prevHash := block.Header.PrevBlock
+		block, _, err = bs.BtcClient.GetBlockByHash(&prevHash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot get the BTC block %s: %v", ErrBTCRequest, prevHash, err)
+		}
+	}
+	return nil, nil
 }
 
 func (bs *BtcScanner) SetLogger(logger *zap.SugaredLogger) {
@@ -210,44 +334,100 @@ func (bs *BtcScanner) sendConfirmedBlocksToChan(blocks []*types.IndexedBlock) {
 }
 
 func (bs *BtcScanner) tryToExtractCheckpoint(block *types.IndexedBlock) *types.CheckpointRecord {
-	found := bs.tryToExtractCkptSegment(block)
+	bs.ckptCacheMu.Lock()
+	defer bs.ckptCacheMu.Unlock()
+
+	found := bs.tryToExtractCkptSegment(bs.ckptCache, block)
 	if !found {
 		return nil
 	}
 
-	rawCheckpointWithBtcHeight, err := bs.matchAndPop()
+	rawCheckpointWithBtcHeight, err := bs.matchAndPop(bs.ckptCache)
 	if err != nil {
-		// if a raw checkpoint is found, it should be decoded. Otherwise
-		// this means there are bugs in the program, should panic here
-		panic(err)
+		// matched segments are expected to be decoded, so this is a bug
+		// rather than an invalid checkpoint submitted to BTC
+		bs.logger.Errorf("failed to extract checkpoint at BTC block %d: %v", block.Height, err)
+		return nil
 	}
 
 	return rawCheckpointWithBtcHeight
 }
 
-func (bs *BtcScanner) matchAndPop() (*types.CheckpointRecord, error) {
-	bs.ckptCache.Match()
-	ckptSegments := bs.ckptCache.PopEarliestCheckpoint()
+// RefetchCheckpoint extracts the checkpoint carried by the given txs from their
+// BTC blocks again, so that the monitor can recover a checkpoint it has dropped
+func (bs *BtcScanner) RefetchCheckpoint(txs []*types.CheckpointTxRecord) (*types.CheckpointRecord, error) {
+	ckptCache := types.NewCheckpointCache(bs.ckptCache.Tag, bs.ckptCache.Version)
+	fetched := make(map[string]bool)
+	for _, tx := range txs {
+		// both segments may be in the same block
+		if fetched[tx.BlockHash] {
+			continue
+		}
+		fetched[tx.BlockHash] = true
+		blockHash, err := chainhash.NewHashFromStr(tx.BlockHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash of BTC block %s: %w", tx.BlockHash, err)
+		}
+		block, _, err := bs.BtcClient.GetBlockByHash(blockHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get BTC block %s: %w", tx.BlockHash, err)
+		}
+		bs.tryToExtractCkptSegment(ckptCache, block)
+	}
+
+	ckpt, err := bs.matchAndPop(ckptCache)
+	if err != nil {
+		return nil, err
+	}
+	if ckpt == nil {
+		return nil, fmt.Errorf("%w: no checkpoint is found in the %d BTC blocks of its txs", ErrInvalidCheckpoint, len(fetched))
+	}
+	return ckpt, nil
+}
+
+func (bs *BtcScanner) matchAndPop(ckptCache *types.CheckpointCache) (*types.CheckpointRecord, error) {
+	ckptCache.Match()
+	ckptSegments := ckptCache.PopEarliestCheckpoint()
 	if ckptSegments == nil {
 		return nil, nil
 	}
-	connectedBytes, err := btctxformatter.ConnectParts(bs.ckptCache.Version, ckptSegments.Segments[0].Data, ckptSegments.Segments[1].Data)
+	connectedBytes, err := btctxformatter.ConnectParts(ckptCache.Version, ckptSegments.Segments[0].Data, ckptSegments.Segments[1].Data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect two checkpoint parts: %w", err)
+		return nil, fmt.Errorf("%w: failed to connect two checkpoint parts: %v", ErrInvalidCheckpoint, err)
 	}
 	// found a pair, check if it is a valid checkpoint
 	rawCheckpoint, err := ckpttypes.FromBTCCkptBytesToRawCkpt(connectedBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode raw checkpoint bytes: %w", err)
+		return nil, fmt.Errorf("%w: failed to decode raw checkpoint bytes: %v", ErrInvalidCheckpoint, err)
+	}
+
+	var txs []*types.CheckpointTxRecord
+	for _, seg := range ckptSegments.Segments {
+		if seg.TxIdx < 0 || seg.TxIdx >= len(seg.AssocBlock.Txs) || seg.AssocBlock.Txs[seg.TxIdx] == nil {
+			continue
+		}
+		proof, err := seg.AssocBlock.GenSPVProof(seg.TxIdx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to generate SPV proof of checkpoint tx: %v", ErrInvalidCheckpoint, err)
+		}
+		txs = append(txs, &types.CheckpointTxRecord{
+			TxId:        seg.AssocBlock.Txs[seg.TxIdx].Hash().String(),
+			BlockHash:   seg.AssocBlock.BlockHash().String(),
+			BlockHeight: uint64(seg.AssocBlock.Height),
+			Proof:       proof,
+			BlockHeader: seg.AssocBlock.Header,
+		})
 	}
 
 	return &types.CheckpointRecord{
 		RawCheckpoint:      rawCheckpoint,
+		BtcCheckpointBytes: connectedBytes,
 		FirstSeenBtcHeight: uint64(ckptSegments.Segments[0].AssocBlock.Height),
+		Txs:                txs,
 	}, nil
 }
 
-func (bs *BtcScanner) tryToExtractCkptSegment(b *types.IndexedBlock) bool {
+func (bs *BtcScanner) tryToExtractCkptSegment(ckptCache *types.CheckpointCache, b *types.IndexedBlock) bool {
 	found := false
 	for _, tx := range b.Txs {
 		if tx == nil {
@@ -255,9 +435,9 @@ func (bs *BtcScanner) tryToExtractCkptSegment(b *types.IndexedBlock) bool {
 		}
 
 		// cache the segment to ckptCache
-		ckptSeg := types.NewCkptSegment(bs.ckptCache.Tag, bs.ckptCache.Version, b, tx)
+		ckptSeg := types.NewCkptSegment(ckptCache.Tag, ckptCache.Version, b, tx)
 		if ckptSeg != nil {
-			err := bs.ckptCache.AddSegment(ckptSeg)
+			err := ckptCache.AddSegment(ckptSeg)
 			if err != nil {
 				bs.logger.Errorf("Failed to add the ckpt segment in tx %v to the ckptCache: %v", tx.Hash(), err)
 				continue
@@ -272,6 +452,10 @@ func (bs *BtcScanner) GetCheckpointsChan() chan *types.CheckpointRecord {
 	return bs.checkpointsChan
 }
 
+func (bs *BtcScanner) GetDeepReorgsChan() chan *DeepReorg {
+	return bs.deepReorgsChan
+}
+
 func (bs *BtcScanner) Stop() {
 	close(bs.quit)
 }
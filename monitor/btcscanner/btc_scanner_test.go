@@ -10,6 +10,7 @@ import (
 	"go.uber.org/atomic"
 
 	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
 	"github.com/babylonchain/vigilante/monitor/btcscanner"
 	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
 	"github.com/babylonchain/vigilante/testutil/mocks"
@@ -61,3 +62,63 @@ func FuzzBootStrap(f *testing.F) {
 		require.True(t, btcScanner.Synced.Load())
 	})
 }
+
+func FuzzBootstrapDeepReorg(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		cfg := config.DefaultMonitorConfig()
+		k := cfg.BtcConfirmationDepth
+		// make sure that there are confirmed blocks after the previous confirmed tip
+		numBlocks := datagen.RandomIntOtherThan(r, 0, 50) + k + 2
+		chainIndexedBlocks := vdatagen.GetRandomIndexedBlocks(r, numBlocks)
+		baseHeight := chainIndexedBlocks[0].Height
+		bestHeight := chainIndexedBlocks[len(chainIndexedBlocks)-1].Height
+
+		// the previous confirmed tip is on a fork that is reorged out of the canonical chain
+		maxTipIdx := int(numBlocks-k) - 2
+		forkIdx := r.Intn(maxTipIdx)
+		depth := r.Intn(maxTipIdx-forkIdx) + 1
+		forkPoint := chainIndexedBlocks[forkIdx]
+		forkBlocks := vdatagen.GetRandomIndexedBlocksFromHeight(r, uint64(depth), forkPoint.Height, forkPoint.BlockHash())
+
+		ctl := gomock.NewController(t)
+		mockBtcClient := mocks.NewMockBTCClient(ctl)
+		mockBtcClient.EXPECT().GetBestBlock().Return(nil, uint64(bestHeight), nil).AnyTimes()
+		for _, ib := range chainIndexedBlocks {
+			mockBtcClient.EXPECT().GetBlockByHeight(gomock.Eq(uint64(ib.Height))).
+				Return(ib, nil, nil).AnyTimes()
+		}
+		parent := forkPoint
+		for _, ib := range forkBlocks {
+			mockBtcClient.EXPECT().GetBlockByHash(gomock.Eq(&ib.Header.PrevBlock)).
+				Return(parent, nil, nil).AnyTimes()
+			parent = ib
+		}
+
+		logger, err := config.NewRootLogger("auto", "debug")
+		require.NoError(t, err)
+		btcScanner, err := btcscanner.New(&cfg, logger, mockBtcClient, uint64(baseHeight), []byte{1, 2, 3, 4}, metrics.NewMonitorMetrics().BtcScannerMetrics)
+		require.NoError(t, err)
+		btcScanner.SetConfirmedTipBlock(forkBlocks[len(forkBlocks)-1])
+
+		btcScanner.Bootstrap()
+		require.True(t, btcScanner.Synced.Load())
+
+		// the reorg is reported
+		reorg := <-btcScanner.GetDeepReorgsChan()
+		require.Equal(t, int32(depth), reorg.Depth)
+		require.Equal(t, forkPoint.Height, reorg.ForkHeight)
+		require.Equal(t, forkPoint.BlockHash(), *reorg.ForkHash)
+		require.Equal(t, forkBlocks[len(forkBlocks)-1].BlockHash(), reorg.OldTipHash)
+
+		// the canonical chain is scanned again from the fork point
+		confirmedBlocks := chainIndexedBlocks[forkIdx+1 : numBlocks-k]
+		require.Len(t, btcScanner.ConfirmedBlocksChan, len(confirmedBlocks))
+		for _, expected := range confirmedBlocks {
+			b := <-btcScanner.ConfirmedBlocksChan
+			require.Equal(t, expected.BlockHash(), b.BlockHash())
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package btcscanner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	// ErrBTCRequest means that a request to the BTC node failed after the retries of the BTC client
+	ErrBTCRequest = errors.New("failed to request the BTC node")
+	// ErrChainChanged means that the BTC chain changed while being scanned,
+	// e.g., a shallow reorg happened during the bootstrapping
+	ErrChainChanged = errors.New("the BTC chain changed during scanning")
+	// ErrDeepReorg means that blocks deeper than the confirmation depth are reorged,
+	// so that the confirmed chain no longer extends the last confirmed block
+	ErrDeepReorg = errors.New("BTC reorg deeper than the confirmation depth")
+	// ErrInvalidCheckpoint means that matched checkpoint segments cannot be decoded
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint on BTC")
+)
+
+// DeepReorg is a reorg of BTC blocks that had been considered confirmed. It is a
+// safety incident, as checkpoints in the reorged blocks were considered final.
+type DeepReorg struct {
+	// the last confirmed block before the reorg
+	OldTipHeight int32
+	OldTipHash   chainhash.Hash
+	// the last confirmed block that is still on the canonical chain, or
+	// nil if the reorg goes below the base height of the scanner
+	ForkHeight int32
+	ForkHash   *chainhash.Hash
+	// the number of confirmed blocks that are reorged
+	Depth      int32
+	DetectedAt time.Time
+}
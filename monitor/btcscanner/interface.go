@@ -12,4 +12,7 @@ type Scanner interface {
 
 	GetCheckpointsChan() chan *types.CheckpointRecord
 	GetHeadersChan() chan *wire.BlockHeader
+	GetDeepReorgsChan() chan *DeepReorg
+
+	RefetchCheckpoint(txs []*types.CheckpointTxRecord) (*types.CheckpointRecord, error)
 }
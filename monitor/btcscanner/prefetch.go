@@ -0,0 +1,93 @@
+package btcscanner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+// prefetchResult is a prefetched block, or the error of fetching it
+type prefetchResult struct {
+	block *types.IndexedBlock
+	err   error
+}
+
+// prefetchBlocks fetches the blocks in heights [from, to] with concurrent requests,
+// and returns a channel delivering them strictly in height order. At most
+// 2*concurrency blocks are buffered ahead of the consumer. Closing done stops
+// the prefetching, e.g., when the consumer returns early.
+func (bs *BtcScanner) prefetchBlocks(from, to uint64, done <-chan struct{}) <-chan chan prefetchResult {
+	concurrency := max(bs.prefetchConcurrency, 1)
+	// each block is delivered through its own channel, queued in height order
+	ordered := make(chan chan prefetchResult, 2*concurrency)
+
+	go func() {
+		defer close(ordered)
+		sem := make(chan struct{}, concurrency)
+		for height := from; height <= to; height++ {
+			result := make(chan prefetchResult, 1)
+			select {
+			case ordered <- result:
+			case <-done:
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-done:
+				return
+			}
+			go func(height uint64) {
+				defer func() { <-sem }()
+				ib, _, err := bs.BtcClient.GetBlockByHeight(height)
+				if err != nil {
+					err = fmt.Errorf("%w: cannot get the BTC block at height %d: %v", ErrBTCRequest, height, err)
+				}
+				result <- prefetchResult{block: ib, err: err}
+			}(height)
+		}
+	}()
+
+	return ordered
+}
+
+// bootstrapProgress tracks the progress of scanning confirmed blocks during bootstrapping
+type bootstrapProgress struct {
+	startedAt time.Time
+	total     uint64
+	scanned   uint64
+}
+
+func newBootstrapProgress(from, to uint64) *bootstrapProgress {
+	p := &bootstrapProgress{startedAt: time.Now()}
+	if to >= from {
+		p.total = to - from + 1
+	}
+	return p
+}
+
+// eta estimates the remaining time from the average scanning rate so far
+func (p *bootstrapProgress) eta() time.Duration {
+	if p.scanned == 0 {
+		return 0
+	}
+	perBlock := time.Since(p.startedAt) / time.Duration(p.scanned)
+	return perBlock * time.Duration(p.total-p.scanned)
+}
+
+// reportBootstrapProgress records a scanned block in the metrics, and logs the progress periodically
+func (bs *BtcScanner) reportBootstrapProgress(p *bootstrapProgress, height uint64) {
+	p.scanned++
+	remaining := p.total - p.scanned
+	eta := p.eta()
+
+	if bs.metrics != nil {
+		bs.metrics.BootstrapScannedHeightGauge.Set(float64(height))
+		bs.metrics.BootstrapRemainingBlocksGauge.Set(float64(remaining))
+		bs.metrics.BootstrapETASecondsGauge.Set(eta.Seconds())
+	}
+	if p.scanned%bootstrapProgressLogInterval == 0 || remaining == 0 {
+		bs.logger.Infof("bootstrapping scanned BTC block at height %d, %d blocks remaining, ETA %v",
+			height, remaining, eta.Round(time.Second))
+	}
+}
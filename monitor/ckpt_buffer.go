@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+// bufferCheckpoint buffers a checkpoint of a later epoch until the current epoch is verified
+func (m *Monitor) bufferCheckpoint(ckpt *types.CheckpointRecord) {
+	if m.numBuffered >= int(m.Cfg.CheckpointBufferSize) {
+		m.dropCheckpoint(ckpt)
+		return
+	}
+	if m.bufferedCheckpoints == nil {
+		m.bufferedCheckpoints = make(map[uint64][]*types.CheckpointRecord)
+	}
+	if m.numBuffered == 0 {
+		m.epochGapSince = time.Now()
+	}
+
+	m.bufferedCheckpoints[ckpt.EpochNum()] = append(m.bufferedCheckpoints[ckpt.EpochNum()], ckpt)
+	m.numBuffered++
+	m.metrics.BufferedCheckpointsGauge.Set(float64(m.numBuffered))
+
+	m.logger.Infof("buffered checkpoint at epoch %d, as the monitor is still waiting for the checkpoint at epoch %d",
+		ckpt.EpochNum(), m.GetCurrentEpoch())
+}
+
+// dropCheckpoint drops a checkpoint of a later epoch as the buffer is full. The
+// location of its txs is kept, so that it is fetched from BTC again once there is room.
+func (m *Monitor) dropCheckpoint(ckpt *types.CheckpointRecord) {
+	m.metrics.DroppedCheckpointsCounter.Inc()
+	if len(ckpt.Txs) == 0 {
+		m.logger.Errorf("dropping checkpoint at epoch %d, as the buffer of %d out-of-order checkpoints is full, and it cannot be fetched again as the location of its txs is unknown",
+			ckpt.EpochNum(), m.Cfg.CheckpointBufferSize)
+		return
+	}
+	if m.droppedCheckpoints == nil {
+		m.droppedCheckpoints = make(map[uint64][]*types.CheckpointTxRecord)
+	}
+	// a checkpoint of the epoch dropped before is fetched instead
+	if _, ok := m.droppedCheckpoints[ckpt.EpochNum()]; !ok {
+		m.droppedCheckpoints[ckpt.EpochNum()] = ckpt.Txs
+	}
+	m.logger.Warnf("dropping checkpoint at epoch %d, as the buffer of %d out-of-order checkpoints is full. It is fetched from BTC again once there is room",
+		ckpt.EpochNum(), m.Cfg.CheckpointBufferSize)
+}
+
+// refetchDroppedCheckpoints fetches the dropped checkpoints from BTC again, in
+// the order of their epochs, as long as there is room in the buffer
+func (m *Monitor) refetchDroppedCheckpoints() error {
+	epochs := make([]uint64, 0, len(m.droppedCheckpoints))
+	for epoch := range m.droppedCheckpoints {
+		epochs = append(epochs, epoch)
+	}
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+
+	for _, epoch := range epochs {
+		if m.numBuffered >= int(m.Cfg.CheckpointBufferSize) {
+			return nil
+		}
+		txs := m.droppedCheckpoints[epoch]
+		delete(m.droppedCheckpoints, epoch)
+		if epoch < m.GetCurrentEpoch() {
+			// the epoch has been verified with another checkpoint
+			continue
+		}
+
+		ckpt, err := m.BTCScanner.RefetchCheckpoint(txs)
+		if err != nil {
+			// retried once the next checkpoint is handled
+			m.logger.Errorf("failed to fetch the dropped checkpoint at epoch %d from BTC again: %v", epoch, err)
+			m.droppedCheckpoints[epoch] = txs
+			return nil
+		}
+		m.logger.Infof("fetched the dropped checkpoint at epoch %d from BTC again", epoch)
+		if err := m.handleCheckpoint(ckpt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyBufferedCheckpoints verifies the buffered checkpoints of the current epoch,
+// and of each following epoch as long as the verification advances
+func (m *Monitor) verifyBufferedCheckpoints() error {
+	for {
+		epoch := m.GetCurrentEpoch()
+		ckpts, ok := m.bufferedCheckpoints[epoch]
+		if !ok {
+			return nil
+		}
+		delete(m.bufferedCheckpoints, epoch)
+		m.numBuffered -= len(ckpts)
+		m.metrics.BufferedCheckpointsGauge.Set(float64(m.numBuffered))
+
+		for _, ckpt := range ckpts {
+			if err := m.verifyCheckpointAndAdvance(ckpt); err != nil {
+				return err
+			}
+			if m.GetCurrentEpoch() != epoch {
+				// the rest are checkpoints of the verified epoch
+				break
+			}
+		}
+		if m.GetCurrentEpoch() == epoch {
+			// none of the buffered checkpoints of the epoch is valid
+			return nil
+		}
+	}
+}
+
+// onEpochVerified resets the waiting of the verified epoch
+func (m *Monitor) onEpochVerified(epoch uint64) {
+	m.resolveStuckEpochAlert(epoch)
+	// the next epoch is waited for from now on
+	m.epochGapSince = time.Now()
+}
+
+// checkStuckEpoch raises an alert if the monitor has been waiting for the checkpoint of
+// the current epoch for too long, while checkpoints of later epochs are found
+func (m *Monitor) checkStuckEpoch() {
+	if m.numBuffered == 0 {
+		m.metrics.EpochGapSecondsGauge.Set(0)
+		return
+	}
+
+	waiting := time.Since(m.epochGapSince)
+	m.metrics.EpochGapSecondsGauge.Set(waiting.Seconds())
+	if waiting < time.Duration(m.Cfg.StuckEpochTimeoutSeconds)*time.Second {
+		return
+	}
+
+	nextBufferedEpoch := uint64(0)
+	for epoch := range m.bufferedCheckpoints {
+		if nextBufferedEpoch == 0 || epoch < nextBufferedEpoch {
+			nextBufferedEpoch = epoch
+		}
+	}
+	m.raiseStuckEpochAlert(m.GetCurrentEpoch(), waiting, nextBufferedEpoch)
+}
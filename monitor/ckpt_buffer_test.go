@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/monitor/btcscanner"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// refetchScanner fetches the checkpoints again by the first tx of their records
+type refetchScanner struct {
+	btcscanner.Scanner
+	ckpts map[*types.CheckpointTxRecord]*types.CheckpointRecord
+}
+
+func (s *refetchScanner) RefetchCheckpoint(txs []*types.CheckpointTxRecord) (*types.CheckpointRecord, error) {
+	ckpt, ok := s.ckpts[txs[0]]
+	if !ok {
+		return nil, errors.New("no checkpoint is found in the BTC blocks")
+	}
+	return ckpt, nil
+}
+
+func TestBufferCheckpointDropsOnOverflow(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	m := newStateTestMonitor("", testChainID)
+	m.Cfg.CheckpointBufferSize = uint64(r.Intn(10) + 1)
+	_, privKeys := datagen.GenerateValidatorSetWithBLSPrivKeys(4)
+
+	// checkpoints of later epochs are buffered until the buffer is full
+	bufferSize := int(m.Cfg.CheckpointBufferSize)
+	numDropped := r.Intn(5) + 1
+	for i := 0; i < bufferSize+numDropped; i++ {
+		rawCkpt := datagen.GenerateLegitimateRawCheckpoint(r, privKeys)
+		rawCkpt.EpochNum = m.GetCurrentEpoch() + uint64(i) + 1
+		m.bufferCheckpoint(types.NewCheckpointRecord(rawCkpt, uint64(i)))
+	}
+	require.Equal(t, bufferSize, m.numBuffered)
+	require.Len(t, m.bufferedCheckpoints, bufferSize)
+	require.Equal(t, float64(bufferSize), testutil.ToFloat64(m.metrics.BufferedCheckpointsGauge))
+
+	// the checkpoints arriving after are dropped
+	require.Equal(t, float64(numDropped), testutil.ToFloat64(m.metrics.DroppedCheckpointsCounter))
+	for i := bufferSize; i < bufferSize+numDropped; i++ {
+		require.NotContains(t, m.bufferedCheckpoints, m.GetCurrentEpoch()+uint64(i)+1)
+	}
+}
+
+func TestRefetchDroppedCheckpoints(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	m := newStateTestMonitor("", testChainID)
+	m.Cfg.CheckpointBufferSize = uint64(r.Intn(10) + 1)
+	scanner := &refetchScanner{ckpts: make(map[*types.CheckpointTxRecord]*types.CheckpointRecord)}
+	m.BTCScanner = scanner
+	_, privKeys := datagen.GenerateValidatorSetWithBLSPrivKeys(4)
+
+	// the checkpoints of later epochs overflowing the buffer are dropped
+	bufferSize := int(m.Cfg.CheckpointBufferSize)
+	numDropped := r.Intn(5) + 1
+	for i := 0; i < bufferSize+numDropped; i++ {
+		rawCkpt := datagen.GenerateLegitimateRawCheckpoint(r, privKeys)
+		rawCkpt.EpochNum = m.GetCurrentEpoch() + uint64(i) + 1
+		_, tx := genBlockAtHeight(r, uint64(i))
+		ckpt := types.NewCheckpointRecord(rawCkpt, uint64(i))
+		ckpt.Txs = []*types.CheckpointTxRecord{tx}
+		scanner.ckpts[tx] = ckpt
+		m.bufferCheckpoint(ckpt)
+	}
+	require.Len(t, m.droppedCheckpoints, numDropped)
+
+	// nothing is fetched again while the buffer is full
+	require.NoError(t, m.refetchDroppedCheckpoints())
+	require.Len(t, m.droppedCheckpoints, numDropped)
+
+	// once there is room, the dropped checkpoints of the earliest epochs are fetched again
+	numFreed := r.Intn(numDropped) + 1
+	for i := 0; i < numFreed; i++ {
+		epoch := m.GetCurrentEpoch() + uint64(i) + 1
+		m.numBuffered -= len(m.bufferedCheckpoints[epoch])
+		delete(m.bufferedCheckpoints, epoch)
+	}
+	require.NoError(t, m.refetchDroppedCheckpoints())
+	require.Equal(t, bufferSize, m.numBuffered)
+	require.Len(t, m.droppedCheckpoints, numDropped-numFreed)
+	for i := bufferSize; i < bufferSize+numFreed; i++ {
+		require.Contains(t, m.bufferedCheckpoints, m.GetCurrentEpoch()+uint64(i)+1)
+	}
+	for i := bufferSize + numFreed; i < bufferSize+numDropped; i++ {
+		require.Contains(t, m.droppedCheckpoints, m.GetCurrentEpoch()+uint64(i)+1)
+	}
+}
@@ -0,0 +1,128 @@
+// Package evidence assembles self-contained evidence of a Babylon fork, i.e., a
+// checkpoint on BTC that conflicts with the checkpoint of the same epoch on Babylon,
+// and verifies such evidence offline so that third parties can audit it.
+package evidence
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/babylonchain/babylon/btctxformatter"
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+// Bundle is the evidence of a fork. All binary data is hex-encoded.
+type Bundle struct {
+	Epoch uint64 `json:"epoch"`
+	// tag of Babylon checkpoints in the OP_RETURN data of BTC txs
+	CheckpointTag string `json:"checkpoint_tag"`
+	// the validator set of the epoch, in the signing order
+	ValSet ckpttypes.ValidatorWithBlsKeySet `json:"val_set"`
+	// the checkpoint on BTC, as connected from the OP_RETURN data of the two txs
+	BTCCheckpoint string `json:"btc_checkpoint"`
+	// SPV proofs of the two BTC txs carrying the checkpoint
+	BTCProofs []*TxProof `json:"btc_proofs"`
+	// the conflicting checkpoint on Babylon encoded in protobuf, including its BLS multi-sig
+	BabylonCheckpoint string    `json:"babylon_checkpoint"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TxProof is the SPV proof of a BTC tx carrying a segment of the checkpoint
+type TxProof struct {
+	Tx      string `json:"tx"`
+	TxIndex uint32 `json:"tx_index"`
+	// the Merkle branch from the tx to the Merkle root of the block, 32 bytes per node
+	MerkleNodes string `json:"merkle_nodes"`
+	BlockHeight uint64 `json:"block_height"`
+	BlockHash   string `json:"block_hash"`
+	BlockHeader string `json:"block_header"`
+}
+
+// NewBundle assembles the evidence from the checkpoint found on BTC and the
+// conflicting checkpoint on Babylon. The SPV proofs of the BTC checkpoint
+// are only available for checkpoints fresh from the BTC scanner.
+func NewBundle(
+	checkpointTag []byte,
+	valSet ckpttypes.ValidatorWithBlsKeySet,
+	btcCkpt *types.CheckpointRecord,
+	bbnCkpt *ckpttypes.RawCheckpoint,
+) (*Bundle, error) {
+	if len(btcCkpt.Txs) != btctxformatter.NumberOfParts {
+		return nil, fmt.Errorf("expected %d BTC txs carrying the checkpoint, got %d", btctxformatter.NumberOfParts, len(btcCkpt.Txs))
+	}
+
+	bbnCkptBytes, err := bbnCkpt.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Babylon checkpoint: %w", err)
+	}
+
+	bundle := &Bundle{
+		Epoch:             btcCkpt.EpochNum(),
+		CheckpointTag:     hex.EncodeToString(checkpointTag),
+		ValSet:            valSet,
+		BTCCheckpoint:     hex.EncodeToString(btcCkpt.BtcCheckpointBytes),
+		BabylonCheckpoint: hex.EncodeToString(bbnCkptBytes),
+		CreatedAt:         time.Now().UTC(),
+	}
+	for _, tx := range btcCkpt.Txs {
+		if tx.Proof == nil || tx.BlockHeader == nil {
+			return nil, fmt.Errorf("SPV proof of BTC tx %s is not available", tx.TxId)
+		}
+		var headerBuf bytes.Buffer
+		if err := tx.BlockHeader.Serialize(&headerBuf); err != nil {
+			return nil, fmt.Errorf("failed to encode header of BTC block %s: %w", tx.BlockHash, err)
+		}
+		bundle.BTCProofs = append(bundle.BTCProofs, &TxProof{
+			Tx:          hex.EncodeToString(tx.Proof.BtcTransaction),
+			TxIndex:     tx.Proof.BtcTransactionIndex,
+			MerkleNodes: hex.EncodeToString(tx.Proof.MerkleNodes),
+			BlockHeight: tx.BlockHeight,
+			BlockHash:   tx.BlockHash,
+			BlockHeader: hex.EncodeToString(headerBuf.Bytes()),
+		})
+	}
+
+	return bundle, nil
+}
+
+// FileName returns the name of the file the bundle is written to
+func (b *Bundle) FileName() string {
+	return fmt.Sprintf("fork-evidence-epoch-%d-%d.json", b.Epoch, b.CreatedAt.Unix())
+}
+
+// WriteFile writes the bundle to the given directory, and returns the path of the file
+func (b *Bundle) WriteFile(dir string) (string, error) {
+	bz, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, b.FileName())
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, bz, 0600); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmpFile, path)
+}
+
+// ReadFile reads a bundle from the given file
+func ReadFile(path string) (*Bundle, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if err := json.Unmarshal(bz, &b); err != nil {
+		return nil, fmt.Errorf("failed to decode evidence bundle: %w", err)
+	}
+	return &b, nil
+}
@@ -0,0 +1,161 @@
+package evidence_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/babylonchain/babylon/btctxformatter"
+	"github.com/babylonchain/babylon/crypto/bls12381"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/monitor/evidence"
+	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
+	"github.com/babylonchain/vigilante/types"
+)
+
+var (
+	testTag    = []byte{1, 2, 3, 4}
+	testParams = &chaincfg.RegressionNetParams
+)
+
+// genCheckpointBlock mines a regtest block including the two txs carrying the checkpoint,
+// and returns the checkpoint record as found by the BTC scanner
+func genCheckpointBlock(t *testing.T, r *rand.Rand, ckpt *ckpttypes.RawCheckpoint) *types.CheckpointRecord {
+	btcCkpt, err := ckpttypes.FromRawCkptToBTCCkpt(ckpt, datagen.GenRandomByteArray(r, btctxformatter.AddressLength))
+	require.NoError(t, err)
+	firstHalf, secondHalf, err := btctxformatter.EncodeCheckpointData(testTag, btctxformatter.CurrentVersion, btcCkpt)
+	require.NoError(t, err)
+
+	msgTxs := []*wire.MsgTx{vdatagen.GenRandomTx(r)}
+	for _, data := range [][]byte{firstHalf, secondHalf} {
+		tx := vdatagen.GenRandomTx(r)
+		script, err := txscript.NewScriptBuilder().AddOp(txscript.OP_RETURN).AddData(data).Script()
+		require.NoError(t, err)
+		tx.TxOut[0] = wire.NewTxOut(0, script)
+		msgTxs = append(msgTxs, tx)
+		// some unrelated tx after each checkpoint tx
+		msgTxs = append(msgTxs, vdatagen.GenRandomTx(r))
+	}
+
+	utilTxs := make([]*btcutil.Tx, 0, len(msgTxs))
+	for _, tx := range msgTxs {
+		utilTxs = append(utilTxs, btcutil.NewTx(tx))
+	}
+	merkles := blockchain.BuildMerkleTreeStore(utilTxs, false)
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Bits:       testParams.PowLimitBits,
+		},
+		Transactions: msgTxs,
+	}
+	target := blockchain.CompactToBig(block.Header.Bits)
+	for {
+		hash := block.Header.BlockHash()
+		if blockchain.HashToBig(&hash).Cmp(target) <= 0 {
+			break
+		}
+		block.Header.Nonce++
+	}
+
+	ib := types.NewIndexedBlockFromMsgBlock(100, block)
+	record := &types.CheckpointRecord{RawCheckpoint: ckpt, FirstSeenBtcHeight: 100}
+	var segs []*types.CkptSegment
+	for _, tx := range ib.Txs {
+		seg := types.NewCkptSegment(testTag, btctxformatter.CurrentVersion, ib, tx)
+		if seg == nil {
+			continue
+		}
+		segs = append(segs, seg)
+		proof, err := ib.GenSPVProof(seg.TxIdx)
+		require.NoError(t, err)
+		record.Txs = append(record.Txs, &types.CheckpointTxRecord{
+			TxId:        tx.Hash().String(),
+			BlockHash:   ib.BlockHash().String(),
+			BlockHeight: uint64(ib.Height),
+			Proof:       proof,
+			BlockHeader: ib.Header,
+		})
+	}
+	require.Len(t, segs, 2)
+	record.BtcCheckpointBytes, err = btctxformatter.ConnectParts(btctxformatter.CurrentVersion, segs[0].Data, segs[1].Data)
+	require.NoError(t, err)
+
+	return record
+}
+
+// genConflictingCheckpoint signs a checkpoint of the same epoch on a different block
+func genConflictingCheckpoint(t *testing.T, r *rand.Rand, ckpt *ckpttypes.RawCheckpoint, privKeys []bls12381.PrivateKey) *ckpttypes.RawCheckpoint {
+	blockHash := datagen.GenRandomBlockHash(r)
+	msgBytes := append(sdk.Uint64ToBigEndian(ckpt.EpochNum), blockHash.MustMarshal()...)
+	signerNum := len(privKeys)*2/3 + 1
+	multiSig, err := bls12381.AggrSigList(datagen.GenerateBLSSigs(privKeys[:signerNum], msgBytes))
+	require.NoError(t, err)
+	return &ckpttypes.RawCheckpoint{
+		EpochNum:    ckpt.EpochNum,
+		BlockHash:   &blockHash,
+		Bitmap:      ckpt.Bitmap,
+		BlsMultiSig: &multiSig,
+	}
+}
+
+func FuzzForkEvidence(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+
+		// at least 4 validators
+		n := r.Intn(10) + 4
+		valSet, privKeys := datagen.GenerateValidatorSetWithBLSPrivKeys(n)
+		btcRawCkpt := datagen.GenerateLegitimateRawCheckpoint(r, privKeys)
+		btcCkpt := genCheckpointBlock(t, r, btcRawCkpt)
+		bbnCkpt := genConflictingCheckpoint(t, r, btcRawCkpt, privKeys)
+
+		// the bundle survives a round trip to disk and is verified
+		bundle, err := evidence.NewBundle(testTag, *valSet, btcCkpt, bbnCkpt)
+		require.NoError(t, err)
+		path, err := bundle.WriteFile(t.TempDir())
+		require.NoError(t, err)
+		bundle, err = evidence.ReadFile(path)
+		require.NoError(t, err)
+		report, err := evidence.Verify(bundle, testParams.PowLimit)
+		require.NoError(t, err)
+		require.Equal(t, btcRawCkpt.EpochNum, report.Epoch)
+		require.Equal(t, btcRawCkpt.BlockHash.String(), report.BTCCheckpointBlockHash)
+		require.Equal(t, bbnCkpt.BlockHash.String(), report.BabylonCheckpointBlockHash)
+		require.Len(t, report.BTCBlocks, 2)
+
+		// the same checkpoint on Babylon is not a fork
+		consistent, err := evidence.NewBundle(testTag, *valSet, btcCkpt, btcRawCkpt)
+		require.NoError(t, err)
+		_, err = evidence.Verify(consistent, testParams.PowLimit)
+		require.ErrorIs(t, err, evidence.ErrNoFork)
+
+		// a tampered SPV proof is rejected
+		tampered, err := evidence.NewBundle(testTag, *valSet, btcCkpt, bbnCkpt)
+		require.NoError(t, err)
+		tampered.BTCProofs[0].TxIndex++
+		_, err = evidence.Verify(tampered, testParams.PowLimit)
+		require.ErrorIs(t, err, evidence.ErrInvalidProof)
+
+		// the PoW of the block is checked against the network
+		_, err = evidence.Verify(bundle, chaincfg.MainNetParams.PowLimit)
+		require.ErrorIs(t, err, evidence.ErrInvalidProof)
+
+		// checkpoints not signed by the validator set are rejected
+		otherValSet, _ := datagen.GenerateValidatorSetWithBLSPrivKeys(n)
+		forged, err := evidence.NewBundle(testTag, *otherValSet, btcCkpt, bbnCkpt)
+		require.NoError(t, err)
+		_, err = evidence.Verify(forged, testParams.PowLimit)
+		require.ErrorIs(t, err, evidence.ErrInvalidCheckpoint)
+	})
+}
@@ -0,0 +1,146 @@
+package evidence
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/babylonchain/babylon/btctxformatter"
+	babylontypes "github.com/babylonchain/babylon/types"
+	btcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+var (
+	ErrInvalidProof      = errors.New("invalid SPV proof")
+	ErrInvalidCheckpoint = errors.New("invalid checkpoint")
+	ErrNoFork            = errors.New("the checkpoints do not conflict")
+)
+
+// Report summarises a verified bundle
+type Report struct {
+	Epoch uint64
+	// Babylon block hash committed by the checkpoint on BTC
+	BTCCheckpointBlockHash string
+	// Babylon block hash committed by the checkpoint on Babylon
+	BabylonCheckpointBlockHash string
+	// hashes and heights of the BTC blocks including the checkpoint txs
+	BTCBlocks []string
+}
+
+// Verify re-checks the bundle offline. It succeeds only if
+// - both BTC txs are included in blocks with valid PoW under the given limit,
+// - the txs carry the checkpoint of the bundle,
+// - both checkpoints are of the epoch of the bundle, and are signed by more than
+// 2/3 of the voting power of the validator set, and
+// - the checkpoints commit to different Babylon blocks.
+// Note that the validator set is taken from the bundle as is, and needs to be
+// cross-checked against Babylon by the auditor.
+func Verify(b *Bundle, powLimit *big.Int) (*Report, error) {
+	tag, err := hex.DecodeString(b.CheckpointTag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint tag: %w", err)
+	}
+	if len(b.BTCProofs) != btctxformatter.NumberOfParts {
+		return nil, fmt.Errorf("%w: expected %d SPV proofs, got %d", ErrInvalidProof, btctxformatter.NumberOfParts, len(b.BTCProofs))
+	}
+
+	report := &Report{Epoch: b.Epoch}
+
+	// verify SPV proofs and extract the checkpoint segments
+	parts := make([][]byte, btctxformatter.NumberOfParts)
+	for _, proof := range b.BTCProofs {
+		header, parsedProof, err := verifyTxProof(proof, powLimit)
+		if err != nil {
+			return nil, err
+		}
+		report.BTCBlocks = append(report.BTCBlocks, fmt.Sprintf("%s (height %d)", header.BlockHash(), proof.BlockHeight))
+
+		tx := parsedProof.Transaction
+		bbnData, err := btctxformatter.IsBabylonCheckpointData(tag, btctxformatter.CurrentVersion, parsedProof.OpReturnData)
+		if err != nil {
+			return nil, fmt.Errorf("%w: tx %s does not carry a checkpoint segment: %v", ErrInvalidCheckpoint, tx.Hash(), err)
+		}
+		if bbnData.Index >= btctxformatter.NumberOfParts || parts[bbnData.Index] != nil {
+			return nil, fmt.Errorf("%w: unexpected segment %d in tx %s", ErrInvalidCheckpoint, bbnData.Index, tx.Hash())
+		}
+		parts[bbnData.Index] = bbnData.Data
+	}
+
+	// connect the segments to the checkpoint on BTC
+	connected, err := btctxformatter.ConnectParts(btctxformatter.CurrentVersion, parts[0], parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to connect checkpoint segments: %v", ErrInvalidCheckpoint, err)
+	}
+	if hex.EncodeToString(connected) != b.BTCCheckpoint {
+		return nil, fmt.Errorf("%w: the BTC txs carry a different checkpoint than the bundle", ErrInvalidCheckpoint)
+	}
+	btcCkpt, err := ckpttypes.FromBTCCkptBytesToRawCkpt(connected)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode BTC checkpoint: %v", ErrInvalidCheckpoint, err)
+	}
+
+	bbnCkptBytes, err := hex.DecodeString(b.BabylonCheckpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode Babylon checkpoint: %v", ErrInvalidCheckpoint, err)
+	}
+	var bbnCkpt ckpttypes.RawCheckpoint
+	if err := bbnCkpt.Unmarshal(bbnCkptBytes); err != nil {
+		return nil, fmt.Errorf("%w: failed to decode Babylon checkpoint: %v", ErrInvalidCheckpoint, err)
+	}
+
+	// verify both checkpoints against the validator set of the epoch
+	epochInfo := types.NewEpochInfo(b.Epoch, b.ValSet)
+	for _, c := range []struct {
+		name string
+		ckpt *ckpttypes.RawCheckpoint
+	}{{"BTC", btcCkpt}, {"Babylon", &bbnCkpt}} {
+		if c.ckpt.EpochNum != b.Epoch {
+			return nil, fmt.Errorf("%w: %s checkpoint is at epoch %d, expected %d", ErrInvalidCheckpoint, c.name, c.ckpt.EpochNum, b.Epoch)
+		}
+		if err := epochInfo.VerifyMultiSig(c.ckpt); err != nil {
+			return nil, fmt.Errorf("%w: invalid BLS multi-sig of %s checkpoint: %v", ErrInvalidCheckpoint, c.name, err)
+		}
+	}
+	report.BTCCheckpointBlockHash = btcCkpt.BlockHash.String()
+	report.BabylonCheckpointBlockHash = bbnCkpt.BlockHash.String()
+
+	if bbnCkpt.BlockHash.Equal(*btcCkpt.BlockHash) {
+		return nil, fmt.Errorf("%w: both commit to Babylon block %s", ErrNoFork, report.BabylonCheckpointBlockHash)
+	}
+
+	return report, nil
+}
+
+// verifyTxProof verifies the PoW of the block header and the inclusion of the
+// tx in the block as Babylon does, and returns the block header and the parsed proof
+func verifyTxProof(proof *TxProof, powLimit *big.Int) (*wire.BlockHeader, *btcctypes.ParsedProof, error) {
+	headerBytes, err := babylontypes.NewBTCHeaderBytesFromHex(proof.BlockHeader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to decode block header: %v", ErrInvalidProof, err)
+	}
+	header := headerBytes.ToBlockHeader()
+	blockHash := header.BlockHash()
+	if proof.BlockHash != "" && proof.BlockHash != blockHash.String() {
+		return nil, nil, fmt.Errorf("%w: block header hashes to %s, expected %s", ErrInvalidProof, blockHash, proof.BlockHash)
+	}
+	txBytes, err := hex.DecodeString(proof.Tx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to decode tx: %v", ErrInvalidProof, err)
+	}
+	merkleNodes, err := hex.DecodeString(proof.MerkleNodes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: failed to decode Merkle branch: %v", ErrInvalidProof, err)
+	}
+
+	parsedProof, err := btcctypes.ParseProof(txBytes, proof.TxIndex, merkleNodes, &headerBytes, powLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: tx in block %s: %v", ErrInvalidProof, blockHash, err)
+	}
+
+	return header, parsedProof, nil
+}
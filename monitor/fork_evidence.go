@@ -0,0 +1,41 @@
+package monitor
+
+import (
+	"github.com/babylonchain/vigilante/monitor/evidence"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// writeForkEvidence writes the evidence bundle of a valid checkpoint on BTC that
+// conflicts with the checkpoint of the same epoch on Babylon, and returns the
+// path of the bundle, or an empty string if the bundle is not written
+func (m *Monitor) writeForkEvidence(ckpt *types.CheckpointRecord) string {
+	if m.Cfg.EvidenceDir == "" {
+		return ""
+	}
+
+	res, err := m.queryRawCheckpointWithRetry(ckpt.EpochNum())
+	if err != nil {
+		m.logger.Errorf("failed to query raw checkpoint at epoch %d for fork evidence: %s", ckpt.EpochNum(), err.Error())
+		return ""
+	}
+	bbnCkpt, err := res.RawCheckpoint.Ckpt.ToRawCheckpoint()
+	if err != nil {
+		m.logger.Errorf("failed to parse raw checkpoint at epoch %d for fork evidence: %s", ckpt.EpochNum(), err.Error())
+		return ""
+	}
+
+	// the checkpoint is verified against the current epoch before it is found to be conflicting
+	bundle, err := evidence.NewBundle(m.checkpointTag, m.curEpoch.GetValSet(), ckpt, bbnCkpt)
+	if err != nil {
+		m.logger.Errorf("failed to assemble fork evidence at epoch %d: %s", ckpt.EpochNum(), err.Error())
+		return ""
+	}
+	path, err := bundle.WriteFile(m.Cfg.EvidenceDir)
+	if err != nil {
+		m.logger.Errorf("failed to write fork evidence at epoch %d: %s", ckpt.EpochNum(), err.Error())
+		return ""
+	}
+
+	m.logger.Infof("wrote fork evidence at epoch %d to %s", ckpt.EpochNum(), path)
+	return path
+}
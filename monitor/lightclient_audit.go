@@ -0,0 +1,128 @@
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	sdkquerytypes "github.com/cosmos/cosmos-sdk/types/query"
+)
+
+// LightClientAuditResult is the outcome of comparing Babylon's BTC light client
+// with the canonical chain of the BTC node
+type LightClientAuditResult struct {
+	BabylonTipHeight uint64
+	BtcTipHeight     uint64
+	// the highest audited Babylon header that is on the canonical chain of the BTC node
+	CommonHeight uint64
+	// whether no audited header is on the canonical chain, in which case the
+	// divergence depth is a lower bound
+	CommonNotFound bool
+	// number of Babylon headers at heights known by the BTC node, from the tip
+	// of the light client downwards, that are not on the canonical chain
+	DivergenceDepth uint64
+	// the height of the k-deep header of Babylon's BTC light client, and whether it
+	// is not on the canonical chain of the BTC node
+	KDeepHeight    uint64
+	KDeepDiverging bool
+}
+
+func (m *Monitor) runLightClientAudit() {
+	ticker := time.NewTicker(time.Duration(m.Cfg.LightClientAuditIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	m.logger.Infof("BTC light client audit is started, auditing every %d seconds", m.Cfg.LightClientAuditIntervalSeconds)
+
+	for {
+		select {
+		case <-m.quit:
+			m.logger.Info("the BTC light client audit is stopped")
+			m.wg.Done()
+			return
+		case <-ticker.C:
+			res, err := m.AuditLightClient()
+			if err != nil {
+				m.logger.Errorf("failed to audit the BTC light client of Babylon: %s", err.Error())
+				m.metrics.LightClientAuditFailuresCounter.Inc()
+				continue
+			}
+			m.metrics.LightClientDivergenceDepthGauge.Set(float64(res.DivergenceDepth))
+			if res.KDeepDiverging {
+				m.raiseLightClientDivergenceAlert(res)
+				continue
+			}
+			if res.DivergenceDepth > 0 {
+				m.logger.Warnf("the top %d headers of Babylon's BTC light client at tip height %d are not on the canonical BTC chain",
+					res.DivergenceDepth, res.BabylonTipHeight)
+			} else {
+				m.logger.Debugf("BTC light client of Babylon at tip height %d is on the canonical BTC chain", res.BabylonTipHeight)
+			}
+			m.resolveLightClientDivergenceAlert()
+		}
+	}
+}
+
+// AuditLightClient walks Babylon's BTC light client from its tip backwards, and compares
+// each header with the header of the BTC node's canonical chain at the same height.
+// Headers above the tip of the BTC node cannot be compared and are skipped.
+func (m *Monitor) AuditLightClient() (*LightClientAuditResult, error) {
+	mainChainRes, err := m.queryBTCMainChainWithRetry(&sdkquerytypes.PageRequest{
+		Limit:   m.Cfg.LightClientAuditDepth,
+		Reverse: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the main chain of the BTC light client: %w", err)
+	}
+	headers := mainChainRes.Headers
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("the main chain of the BTC light client is empty")
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Height > headers[j].Height
+	})
+
+	_, btcTipHeight, err := m.BTCClient.GetBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the best BTC block: %w", err)
+	}
+
+	res := &LightClientAuditResult{
+		BabylonTipHeight: headers[0].Height,
+		BtcTipHeight:     btcTipHeight,
+		CommonNotFound:   true,
+	}
+	for _, header := range headers {
+		if header.Height > btcTipHeight {
+			continue
+		}
+		hash, err := bbntypes.NewBTCHeaderHashBytesFromHex(header.HashHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hash of BTC header at height %d on Babylon: %w", header.Height, err)
+		}
+		btcHeader, err := m.BTCClient.GetBlockHeaderByHeight(header.Height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the BTC header at height %d: %w", header.Height, err)
+		}
+		if btcHeader.BlockHash() == *hash.ToChainhash() {
+			res.CommonHeight = header.Height
+			res.CommonNotFound = false
+			break
+		}
+		res.DivergenceDepth++
+	}
+
+	if res.BabylonTipHeight < m.Cfg.BtcConfirmationDepth {
+		return res, nil
+	}
+	res.KDeepHeight = res.BabylonTipHeight - m.Cfg.BtcConfirmationDepth
+	if res.KDeepHeight > btcTipHeight {
+		// the BTC node is behind, so the k-deep header cannot be audited yet
+		m.logger.Warnf("the BTC node at height %d is behind the k-deep header of Babylon's BTC light client at height %d",
+			btcTipHeight, res.KDeepHeight)
+		return res, nil
+	}
+	res.KDeepDiverging = res.CommonNotFound || res.CommonHeight < res.KDeepHeight
+
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package monitor_test
+
+import (
+	"math/rand"
+	"testing"
+
+	bbndatagen "github.com/babylonchain/babylon/testutil/datagen"
+	bbntypes "github.com/babylonchain/babylon/types"
+	btclctypes "github.com/babylonchain/babylon/x/btclightclient/types"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/monitor"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+)
+
+func FuzzAuditLightClient(f *testing.F) {
+	bbndatagen.AddRandomSeedsToFuzzer(f, 10)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		ctl := gomock.NewController(t)
+		mockBabylonClient := monitor.NewMockBabylonQueryClient(ctl)
+		mockBtcClient := mocks.NewMockBTCClient(ctl)
+		cfg := &config.MonitorConfig{BtcConfirmationDepth: 6, LightClientAuditDepth: 20}
+		m := &monitor.Monitor{
+			Cfg: cfg,
+			// to disable the retry
+			ComCfg: &config.CommonConfig{
+				RetrySleepTime:    1,
+				MaxRetrySleepTime: 0,
+			},
+			BBNQuerier: mockBabylonClient,
+			BTCClient:  mockBtcClient,
+		}
+		logger, err := config.NewRootLogger("auto", "debug")
+		require.NoError(t, err)
+		m.SetLogger(logger.Sugar())
+
+		// the canonical chain of the BTC node
+		tipHeight := uint64(r.Intn(1000)) + cfg.LightClientAuditDepth
+		canonical := make(map[uint64]*wire.BlockHeader)
+		for h := tipHeight - cfg.LightClientAuditDepth + 1; h <= tipHeight; h++ {
+			canonical[h] = bbndatagen.GenRandomBtcdHeader(r)
+		}
+		mockBtcClient.EXPECT().GetBestBlock().Return(nil, tipHeight, nil).AnyTimes()
+		mockBtcClient.EXPECT().GetBlockHeaderByHeight(gomock.Any()).DoAndReturn(func(height uint64) (*wire.BlockHeader, error) {
+			return canonical[height], nil
+		}).AnyTimes()
+
+		// Babylon's BTC light client, whose top headers diverge from the canonical chain
+		divergence := uint64(r.Int63n(int64(cfg.LightClientAuditDepth + 1)))
+		var headers []*btclctypes.BTCHeaderInfoResponse
+		for h := tipHeight; h > tipHeight-cfg.LightClientAuditDepth; h-- {
+			header := canonical[h]
+			if tipHeight-h < divergence {
+				header = bbndatagen.GenRandomBtcdHeader(r)
+			}
+			hash := header.BlockHash()
+			headers = append(headers, &btclctypes.BTCHeaderInfoResponse{
+				Height:  h,
+				HashHex: bbntypes.NewBTCHeaderHashBytesFromChainhash(&hash).MarshalHex(),
+			})
+		}
+		mockBabylonClient.EXPECT().BTCMainChain(gomock.Any()).Return(
+			&btclctypes.QueryMainChainResponse{Headers: headers}, nil,
+		)
+
+		res, err := m.AuditLightClient()
+		require.NoError(t, err)
+		require.Equal(t, tipHeight, res.BabylonTipHeight)
+		require.Equal(t, divergence, res.DivergenceDepth)
+		require.Equal(t, divergence == cfg.LightClientAuditDepth, res.CommonNotFound)
+		require.Equal(t, tipHeight-cfg.BtcConfirmationDepth, res.KDeepHeight)
+		require.Equal(t, divergence > cfg.BtcConfirmationDepth, res.KDeepDiverging)
+	})
+}
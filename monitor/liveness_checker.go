@@ -25,14 +25,21 @@ func (m *Monitor) runLivenessChecker() {
 			m.logger.Debugf("next liveness check is in %d seconds", m.Cfg.LivenessCheckIntervalSeconds)
 			checkpoints := m.checkpointChecklist.GetAll()
 			for _, c := range checkpoints {
-				err := m.CheckLiveness(c)
-				if err != nil {
+				status := m.checkLiveness(c)
+				if err := status.Err; err != nil {
 					m.logger.Errorf("the checkpoint at epoch %d is detected being censored: %s", c.EpochNum(), err.Error())
 					m.metrics.LivenessAttacksCounter.Inc()
+					if errors.Is(err, types.ErrLivenessAttack) {
+						m.raiseLivenessAttackAlert(c, err)
+					}
+					m.recordLiveness(status)
 					continue
 				}
 				m.logger.Debugf("the checkpoint at epoch %d has passed the liveness check", c.EpochNum())
 				m.checkpointChecklist.Remove(c.ID())
+				m.resolveLivenessAttackAlert(c)
+				status.Pending = false
+				m.recordLiveness(status)
 			}
 		}
 	}
@@ -48,50 +55,63 @@ func (m *Monitor) runLivenessChecker() {
 //  5. if H3 - min(H1, H2) > max_live_btc_heights (if the checkpoint is reported), or
 //     H4 - min(H1, H2) > max_live_btc_heights (if the checkpoint is not reported), return error
 func (m *Monitor) CheckLiveness(cr *types.CheckpointRecord) error {
-	var (
-		btcHeightEpochEnded   uint64 // the BTC light client height when the epoch ends (obtained from Babylon)
-		btcHeightFirstSeen    uint64 // the BTC height at which the unique checkpoint first appears (obtained from BTC)
-		btcHeightCkptReported uint64 // the tip height of BTC light client when the checkpoint is reported (obtained from Babylon)
-		currentBtcTipHeight   uint64 // the current tip height of BTC light client (obtained from Babylon)
-		gap                   int    // the gap between two BTC heights
-		err                   error
-	)
+	return m.checkLiveness(cr).Err
+}
+
+// checkLiveness runs the liveness check of CheckLiveness, and returns the heights
+// it is based on together with the verdict
+func (m *Monitor) checkLiveness(cr *types.CheckpointRecord) *CheckpointLiveness {
+	status := newCheckpointLiveness(cr, m.Cfg.MaxLiveBtcHeights)
+	status.CheckedAt = time.Now()
+	fail := func(err error) *CheckpointLiveness {
+		status.Verdict = LivenessError
+		status.Err = err
+		return status
+	}
+
 	epoch := cr.EpochNum()
 	endedEpochRes, err := m.queryEndedEpochBTCHeightWithRetry(cr.EpochNum())
 	if err != nil {
-		return fmt.Errorf("the checkpoint at epoch %d is submitted on BTC the epoch is not ended on Babylon: %w", epoch, err)
+		return fail(fmt.Errorf("the checkpoint at epoch %d is submitted on BTC the epoch is not ended on Babylon: %w", epoch, err))
 	}
-	btcHeightEpochEnded = endedEpochRes.BtcLightClientHeight
-	m.logger.Debugf("the epoch %d is ended at BTC height %d", cr.EpochNum(), btcHeightEpochEnded)
+	// the BTC light client height when the epoch ends (obtained from Babylon)
+	status.EpochEndedBtcHeight = endedEpochRes.BtcLightClientHeight
+	m.logger.Debugf("the epoch %d is ended at BTC height %d", cr.EpochNum(), status.EpochEndedBtcHeight)
 
-	btcHeightFirstSeen = cr.FirstSeenBtcHeight
-	minHeight := minBTCHeight(btcHeightEpochEnded, btcHeightFirstSeen)
+	// the BTC height at which the unique checkpoint first appears (obtained from BTC)
+	minHeight := minBTCHeight(status.EpochEndedBtcHeight, status.FirstSeenBtcHeight)
 
 	reportedRes, err := m.queryReportedCheckpointBTCHeightWithRetry(cr.ID())
 	if err != nil {
 		if !errors.Is(err, monitortypes.ErrCheckpointNotReported) {
-			return fmt.Errorf("failed to query checkpoint of epoch %d reported BTC height: %w", epoch, err)
+			return fail(fmt.Errorf("failed to query checkpoint of epoch %d reported BTC height: %w", epoch, err))
 		}
 		m.logger.Debugf("the checkpoint of epoch %d has not been reported: %s", epoch, err.Error())
 		chainTipRes, err := m.queryBTCHeaderChainTipWithRetry()
 		if err != nil {
-			return fmt.Errorf("failed to query the current tip height of BTC light client: %w", err)
+			return fail(fmt.Errorf("failed to query the current tip height of BTC light client: %w", err))
 		}
-		currentBtcTipHeight = chainTipRes.Header.Height
-		m.logger.Debugf("the current tip height of BTC light client is %d", currentBtcTipHeight)
-		gap = int(currentBtcTipHeight) - int(minHeight)
+		// the current tip height of BTC light client (obtained from Babylon)
+		status.BtcLightClientTipHeight = chainTipRes.Header.Height
+		m.logger.Debugf("the current tip height of BTC light client is %d", status.BtcLightClientTipHeight)
+		status.Gap = int(status.BtcLightClientTipHeight) - int(minHeight)
 	} else {
-		btcHeightCkptReported = reportedRes.BtcLightClientHeight
-		gap = int(btcHeightCkptReported) - int(minHeight)
+		// the tip height of BTC light client when the checkpoint is reported (obtained from Babylon)
+		status.Reported = true
+		status.ReportedBtcHeight = reportedRes.BtcLightClientHeight
+		status.Gap = int(status.ReportedBtcHeight) - int(minHeight)
 	}
 
-	if gap < 0 {
-		return fmt.Errorf("the gap %d between two BTC heights should not be negative", gap)
+	if status.Gap < 0 {
+		return fail(fmt.Errorf("the gap %d between two BTC heights should not be negative", status.Gap))
 	}
 
-	if gap > int(m.Cfg.MaxLiveBtcHeights) {
-		return fmt.Errorf("%w: the gap BTC height is %d, larger than the threshold %d", types.ErrLivenessAttack, gap, m.Cfg.MaxLiveBtcHeights)
+	if status.Gap > int(m.Cfg.MaxLiveBtcHeights) {
+		status.Verdict = LivenessCensored
+		status.Err = fmt.Errorf("%w: the gap BTC height is %d, larger than the threshold %d", types.ErrLivenessAttack, status.Gap, m.Cfg.MaxLiveBtcHeights)
+		return status
 	}
 
-	return nil
+	status.Verdict = LivenessLive
+	return status
 }
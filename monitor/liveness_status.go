@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+// maxLivenessStatuses is the max number of liveness check results kept in memory.
+// Results of checkpoints that passed the check are dropped first, oldest epoch first
+const maxLivenessStatuses = 1000
+
+var ErrLivenessNotFound = errors.New("no liveness check result of the checkpoint is found")
+
+// LivenessVerdict is the verdict of a liveness check
+type LivenessVerdict int
+
+const (
+	// the checkpoint has not been checked yet
+	LivenessUnchecked LivenessVerdict = iota
+	// the checkpoint is reported, or may still be reported, within MaxLiveBtcHeights
+	LivenessLive
+	// the checkpoint is not reported within MaxLiveBtcHeights
+	LivenessCensored
+	// the check failed before reaching a verdict
+	LivenessError
+)
+
+// CheckpointLiveness is the result of the last liveness check of a checkpoint
+type CheckpointLiveness struct {
+	Epoch        uint64
+	CheckpointID string
+	// whether the checkpoint is still tracked by the liveness checker
+	Pending bool
+	// the BTC height at which the checkpoint first appears
+	FirstSeenBtcHeight uint64
+	// the BTC light client height when the epoch ends
+	EpochEndedBtcHeight uint64
+	// the BTC light client height when the checkpoint is reported
+	Reported          bool
+	ReportedBtcHeight uint64
+	// the tip height of the BTC light client, if the checkpoint is not reported
+	BtcLightClientTipHeight uint64
+	// the gap in BTC heights compared against MaxLiveBtcHeights
+	Gap               int
+	MaxLiveBtcHeights uint64
+	Verdict           LivenessVerdict
+	Err               error
+	CheckedAt         time.Time
+}
+
+func newCheckpointLiveness(cr *types.CheckpointRecord, maxLiveBtcHeights uint64) *CheckpointLiveness {
+	return &CheckpointLiveness{
+		Epoch:              cr.EpochNum(),
+		CheckpointID:       cr.ID(),
+		Pending:            true,
+		FirstSeenBtcHeight: cr.FirstSeenBtcHeight,
+		MaxLiveBtcHeights:  maxLiveBtcHeights,
+	}
+}
+
+// recordLiveness keeps the result of the last liveness check of the checkpoint
+func (m *Monitor) recordLiveness(status *CheckpointLiveness) {
+	m.livenessMu.Lock()
+	defer m.livenessMu.Unlock()
+
+	if m.livenessStatuses == nil {
+		m.livenessStatuses = make(map[string]*CheckpointLiveness)
+	}
+	m.livenessStatuses[status.CheckpointID] = status
+	if len(m.livenessStatuses) <= maxLivenessStatuses {
+		return
+	}
+
+	// evict passed checkpoints first, and the oldest epochs among them
+	statuses := make([]*CheckpointLiveness, 0, len(m.livenessStatuses))
+	for _, s := range m.livenessStatuses {
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Pending != statuses[j].Pending {
+			return !statuses[i].Pending
+		}
+		return statuses[i].Epoch < statuses[j].Epoch
+	})
+	for _, s := range statuses[:len(statuses)-maxLivenessStatuses] {
+		delete(m.livenessStatuses, s.CheckpointID)
+	}
+}
+
+// ListPendingCheckpoints returns the liveness of the checkpoints tracked by the
+// liveness checker, sorted by epoch. Checkpoints that are not checked yet have
+// the LivenessUnchecked verdict
+func (m *Monitor) ListPendingCheckpoints() []CheckpointLiveness {
+	m.livenessMu.RLock()
+	defer m.livenessMu.RUnlock()
+
+	var res []CheckpointLiveness
+	for _, cr := range m.checkpointChecklist.GetAll() {
+		if status, ok := m.livenessStatuses[cr.ID()]; ok {
+			pending := *status
+			pending.Pending = true
+			res = append(res, pending)
+			continue
+		}
+		res = append(res, *newCheckpointLiveness(cr, m.Cfg.MaxLiveBtcHeights))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Epoch < res[j].Epoch
+	})
+
+	return res
+}
+
+// GetCheckpointLiveness returns the liveness of the checkpoint of the given epoch.
+// A checkpoint still tracked by the liveness checker takes precedence over one that
+// passed the check, e.g., a conflicting checkpoint of the same epoch
+func (m *Monitor) GetCheckpointLiveness(epoch uint64) (CheckpointLiveness, error) {
+	for _, status := range m.ListPendingCheckpoints() {
+		if status.Epoch == epoch {
+			return status, nil
+		}
+	}
+
+	m.livenessMu.RLock()
+	defer m.livenessMu.RUnlock()
+
+	var latest *CheckpointLiveness
+	for _, status := range m.livenessStatuses {
+		if status.Epoch == epoch && (latest == nil || status.CheckedAt.After(latest.CheckedAt)) {
+			latest = status
+		}
+	}
+	if latest == nil {
+		return CheckpointLiveness{}, ErrLivenessNotFound
+	}
+
+	return *latest, nil
+}
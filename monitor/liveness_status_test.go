@@ -0,0 +1,88 @@
+package monitor
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+func TestRecordLivenessEviction(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	m := newStateTestMonitor("", testChainID)
+
+	// checkpoints still tracked by the liveness checker are of the oldest epochs
+	numPending := r.Intn(10) + 1
+	numEvicted := r.Intn(10) + 1
+	numStatuses := maxLivenessStatuses + numEvicted
+	for i := 0; i < numStatuses; i++ {
+		m.recordLiveness(&CheckpointLiveness{
+			Epoch:        uint64(i + 1),
+			CheckpointID: fmt.Sprintf("ckpt-%d", i+1),
+			Pending:      i < numPending,
+			Verdict:      LivenessLive,
+			CheckedAt:    time.Now(),
+		})
+	}
+	require.Len(t, m.livenessStatuses, maxLivenessStatuses)
+
+	// the passed checkpoints of the oldest epochs are evicted first
+	for i := 0; i < numStatuses; i++ {
+		_, kept := m.livenessStatuses[fmt.Sprintf("ckpt-%d", i+1)]
+		evicted := i >= numPending && i < numPending+numEvicted
+		require.Equal(t, !evicted, kept, "epoch %d", i+1)
+	}
+	_, err := m.GetCheckpointLiveness(uint64(numPending + 1))
+	require.ErrorIs(t, err, ErrLivenessNotFound)
+	status, err := m.GetCheckpointLiveness(uint64(numStatuses))
+	require.NoError(t, err)
+	require.Equal(t, LivenessLive, status.Verdict)
+}
+
+func TestListPendingCheckpoints(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	m := newStateTestMonitor("", testChainID)
+	m.Cfg.MaxLiveBtcHeights = uint64(r.Intn(100) + 1)
+	_, privKeys := datagen.GenerateValidatorSetWithBLSPrivKeys(4)
+
+	// checkpoints of shuffled epochs, some of which are checked already
+	numCkpts := r.Intn(10) + 1
+	checked := make(map[uint64]bool)
+	for _, i := range r.Perm(numCkpts) {
+		rawCkpt := datagen.GenerateLegitimateRawCheckpoint(r, privKeys)
+		rawCkpt.EpochNum = uint64(i + 1)
+		ckpt := types.NewCheckpointRecord(rawCkpt, uint64(r.Intn(1000)))
+		m.checkpointChecklist.Add(ckpt)
+		if r.Intn(2) == 0 {
+			checked[rawCkpt.EpochNum] = true
+			m.recordLiveness(&CheckpointLiveness{
+				Epoch:              rawCkpt.EpochNum,
+				CheckpointID:       ckpt.ID(),
+				FirstSeenBtcHeight: ckpt.FirstSeenBtcHeight,
+				Verdict:            LivenessLive,
+				CheckedAt:          time.Now(),
+			})
+		}
+	}
+	// a checkpoint that passed the check is not pending
+	m.recordLiveness(&CheckpointLiveness{Epoch: uint64(numCkpts + 1), CheckpointID: "passed", Verdict: LivenessLive})
+
+	pending := m.ListPendingCheckpoints()
+	require.Len(t, pending, numCkpts)
+	for i, status := range pending {
+		require.Equal(t, uint64(i+1), status.Epoch)
+		require.True(t, status.Pending)
+		if checked[status.Epoch] {
+			require.Equal(t, LivenessLive, status.Verdict)
+			require.False(t, status.CheckedAt.IsZero())
+		} else {
+			require.Equal(t, LivenessUnchecked, status.Verdict)
+			require.Equal(t, m.Cfg.MaxLiveBtcHeights, status.MaxLiveBtcHeights)
+		}
+	}
+}
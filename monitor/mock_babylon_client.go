@@ -54,6 +54,21 @@ func (mr *MockBabylonQueryClientMockRecorder) BTCHeaderChainTip() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BTCHeaderChainTip", reflect.TypeOf((*MockBabylonQueryClient)(nil).BTCHeaderChainTip))
 }
 
+// BTCMainChain mocks base method.
+func (m *MockBabylonQueryClient) BTCMainChain(pagination *query.PageRequest) (*types.QueryMainChainResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BTCMainChain", pagination)
+	ret0, _ := ret[0].(*types.QueryMainChainResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BTCMainChain indicates an expected call of BTCMainChain.
+func (mr *MockBabylonQueryClientMockRecorder) BTCMainChain(pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BTCMainChain", reflect.TypeOf((*MockBabylonQueryClient)(nil).BTCMainChain), pagination)
+}
+
 // BlsPublicKeyList mocks base method.
 func (m *MockBabylonQueryClient) BlsPublicKeyList(epochNumber uint64, pagination *query.PageRequest) (*types0.QueryBlsPublicKeyListResponse, error) {
 	m.ctrl.T.Helper()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/wire"
 	"github.com/pkg/errors"
@@ -15,10 +16,12 @@ import (
 	checkpointingtypes "github.com/babylonchain/babylon/x/checkpointing/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
+	"github.com/babylonchain/vigilante/alert"
 	"github.com/babylonchain/vigilante/btcclient"
 	"github.com/babylonchain/vigilante/config"
 	"github.com/babylonchain/vigilante/metrics"
 	"github.com/babylonchain/vigilante/monitor/btcscanner"
+	"github.com/babylonchain/vigilante/monitor/valset"
 	"github.com/babylonchain/vigilante/types"
 )
 
@@ -31,12 +34,49 @@ type Monitor struct {
 	BTCScanner btcscanner.Scanner
 	// BBNQuerier queries epoch info from Babylon
 	BBNQuerier BabylonQueryClient
+	// BTCClient queries the canonical chain of the BTC node
+	BTCClient btcclient.BTCClient
 
 	// curEpoch contains information of the current epoch for verification
 	curEpoch *types.EpochInfo
 
+	// the Babylon chain whose checkpoints are verified
+	chainID string
+	// tag of Babylon checkpoints on BTC
+	checkpointTag []byte
+
+	// validator sets of the current and upcoming epochs, and the optional
+	// history of validator sets exported from Babylon
+	valSets       *valset.Cache
+	valSetHistory *valset.History
+	prefetching   *atomic.Bool
+
+	// checkpoints of later epochs waiting for the current epoch to be verified,
+	// and since when the current epoch has been waited for while they are buffered.
+	// They are only accessed from the main loop
+	bufferedCheckpoints map[uint64][]*types.CheckpointRecord
+	numBuffered         int
+	epochGapSince       time.Time
+	// the txs of the checkpoints dropped as the buffer was full, by epoch, so
+	// that the checkpoints are fetched from BTC again once there is room
+	droppedCheckpoints map[uint64][]*types.CheckpointTxRecord
+
 	// tracks checkpoint records that have not been reported back to Babylon
 	checkpointChecklist *types.CheckpointsBookkeeper
+	// results of the last liveness check of each checkpoint, by checkpoint ID
+	livenessStatuses map[string]*CheckpointLiveness
+	livenessMu       sync.RWMutex
+
+	// sends alerts on detected safety and liveness violations
+	alerts *alert.Dispatcher
+	// keys of the active deep reorg alerts, and the height of the confirmed tip
+	// replaced by each reorg, which the rescan has to reach to resolve the alert.
+	// They are only accessed from the main loop
+	deepReorgAlerts map[string]int32
+
+	// the BTC block from which the scanning resumes after a restart
+	resumeBtcHeight uint64
+	resumeBtcHash   string
 
 	metrics *metrics.MonitorMetrics
 
@@ -66,6 +106,7 @@ func New(
 		btcClient,
 		genesisInfo.GetBaseBTCHeight(),
 		checkpointTagBytes,
+		monitorMetrics.BtcScannerMetrics,
 	)
 	if err != nil {
 		panic(fmt.Errorf("failed to create BTC scanner: %w", err))
@@ -78,18 +119,41 @@ func New(
 		sortedGenesisValSet,
 	)
 
-	return &Monitor{
+	m := &Monitor{
 		BBNQuerier:          bbnQueryClient,
 		BTCScanner:          btcScanner,
+		BTCClient:           btcClient,
 		Cfg:                 cfg,
 		ComCfg:              comCfg,
 		logger:              logger.Sugar(),
 		curEpoch:            genesisEpoch,
+		chainID:             genesisInfo.GetChainID(),
+		checkpointTag:       checkpointTagBytes,
+		valSets:             valset.NewCache(),
+		prefetching:         atomic.NewBool(false),
+		bufferedCheckpoints: make(map[uint64][]*types.CheckpointRecord),
 		checkpointChecklist: types.NewCheckpointsBookkeeper(),
+		alerts:              alert.NewDispatcher(&cfg.Alert, alert.NewSinksFromConfig(&cfg.Alert), parentLogger, monitorMetrics.AlertMetrics),
 		metrics:             monitorMetrics,
 		quit:                make(chan struct{}),
 		started:             atomic.NewBool(false),
-	}, nil
+	}
+
+	if cfg.ValSetHistoryFile != "" {
+		m.valSetHistory, err = valset.ReadHistoryFile(cfg.ValSetHistoryFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read validator set history file %s: %w", cfg.ValSetHistoryFile, err)
+		}
+		m.logger.Infof("loaded validator sets of epochs %d to %d from %s",
+			m.valSetHistory.FromEpoch, m.valSetHistory.ToEpoch, cfg.ValSetHistoryFile)
+	}
+
+	// resume from the persisted verification progress, if any
+	if err := m.restoreState(btcScanner, btcClient); err != nil {
+		return nil, err
+	}
+
+	return m, nil
 }
 
 func (m *Monitor) SetLogger(logger *zap.SugaredLogger) {
@@ -111,6 +175,10 @@ func (m *Monitor) Start() {
 		m.logger.Fatalf("failed to start Babylon querier: %v", err)
 	}
 
+	if m.alerts != nil {
+		m.alerts.Start()
+	}
+
 	// starting BTC scanner
 	m.wg.Add(1)
 	go m.runBTCScanner()
@@ -121,11 +189,20 @@ func (m *Monitor) Start() {
 		go m.runLivenessChecker()
 	}
 
+	if m.Cfg.EnableLightClientAudit {
+		// starting BTC light client audit
+		m.wg.Add(1)
+		go m.runLightClientAudit()
+	}
+
 	for m.started.Load() {
 		select {
 		case <-m.quit:
 			m.logger.Info("the monitor is stopping")
 			m.started.Store(false)
+			if err := m.saveState(); err != nil {
+				m.logger.Errorf("failed to save the verification progress: %v", err)
+			}
 		case header := <-m.BTCScanner.GetHeadersChan():
 			err := m.handleNewConfirmedHeader(header)
 			if err != nil {
@@ -133,6 +210,11 @@ func (m *Monitor) Start() {
 				m.metrics.InvalidBTCHeadersCounter.Inc()
 			}
 			m.metrics.ValidBTCHeadersCounter.Inc()
+			m.resolveDeepReorgAlerts(header)
+			// checked on every confirmed BTC block, which arrive regularly
+			m.checkStuckEpoch()
+		case reorg := <-m.BTCScanner.GetDeepReorgsChan():
+			m.handleDeepReorg(reorg)
 		case ckpt := <-m.BTCScanner.GetCheckpointsChan():
 			err := m.handleNewConfirmedCheckpoint(ckpt)
 			if err != nil {
@@ -156,7 +238,38 @@ func (m *Monitor) handleNewConfirmedHeader(header *wire.BlockHeader) error {
 	return m.checkHeaderConsistency(header)
 }
 
+// handleDeepReorg reports a BTC reorg deeper than the confirmation depth. The scanner
+// has already rewound to the fork point and scans the new canonical chain again
+func (m *Monitor) handleDeepReorg(reorg *btcscanner.DeepReorg) {
+	m.logger.Errorf("BTC reorg of %d confirmed blocks from height %d at epoch %d, checkpoints in the reorged blocks may no longer be on BTC",
+		reorg.Depth, reorg.ForkHeight+1, m.GetCurrentEpoch())
+	m.metrics.DeepReorgsCounter.Inc()
+	m.raiseDeepReorgAlert(reorg)
+}
+
 func (m *Monitor) handleNewConfirmedCheckpoint(ckpt *types.CheckpointRecord) error {
+	if err := m.handleCheckpoint(ckpt); err != nil {
+		return err
+	}
+	// verifying checkpoints may have made room for the dropped ones
+	return m.refetchDroppedCheckpoints()
+}
+
+func (m *Monitor) handleCheckpoint(ckpt *types.CheckpointRecord) error {
+	// checkpoints of later epochs cannot be verified before the current epoch
+	// is verified, so they are buffered rather than discarded
+	if ckpt.EpochNum() > m.GetCurrentEpoch() {
+		m.bufferCheckpoint(ckpt)
+		return nil
+	}
+	if err := m.verifyCheckpointAndAdvance(ckpt); err != nil {
+		return err
+	}
+	return m.verifyBufferedCheckpoints()
+}
+
+// verifyCheckpointAndAdvance verifies the checkpoint, and moves to the next epoch if it passes the verification
+func (m *Monitor) verifyCheckpointAndAdvance(ckpt *types.CheckpointRecord) error {
 	err := m.VerifyCheckpoint(ckpt.RawCheckpoint)
 	if err != nil {
 		if sdkerrors.IsOf(err, types.ErrInconsistentBlockHash) {
@@ -165,6 +278,8 @@ func (m *Monitor) handleNewConfirmedCheckpoint(ckpt *types.CheckpointRecord) err
 			if m.Cfg.EnableLivenessChecker {
 				m.addCheckpointToCheckList(ckpt)
 			}
+			bundleFile := m.writeForkEvidence(ckpt)
+			m.raiseInconsistentCheckpointAlert(ckpt, err, bundleFile)
 			// stop verification if a valid BTC checkpoint on an inconsistent BlockHash is found
 			// this means the ledger is on a fork
 			return fmt.Errorf("verification failed at epoch %v: %w", m.GetCurrentEpoch(), err)
@@ -186,6 +301,12 @@ func (m *Monitor) handleNewConfirmedCheckpoint(ckpt *types.CheckpointRecord) err
 		return fmt.Errorf("failed to update information of epoch %d: %w", nextEpochNum, err)
 	}
 
+	m.onEpochVerified(nextEpochNum - 1)
+	m.setResumeBlock(ckpt)
+	if err := m.saveState(); err != nil {
+		m.logger.Errorf("failed to save the verification progress at epoch %d: %v", nextEpochNum, err)
+	}
+
 	return nil
 }
 
@@ -201,7 +322,7 @@ func (m *Monitor) VerifyCheckpoint(btcCkpt *checkpointingtypes.RawCheckpoint) er
 			btcCkpt.EpochNum, m.GetCurrentEpoch()))
 	}
 	// verify BLS sig of the BTC checkpoint
-	err := m.curEpoch.VerifyMultiSig(btcCkpt)
+	err := m.verifyMultiSig(btcCkpt)
 	if err != nil {
 		return fmt.Errorf("invalid BLS sig of BTC checkpoint at epoch %d: %w", m.GetCurrentEpoch(), err)
 	}
@@ -215,7 +336,7 @@ func (m *Monitor) VerifyCheckpoint(btcCkpt *checkpointingtypes.RawCheckpoint) er
 		return fmt.Errorf("failed to parse raw checkpoint %v: %w", res.RawCheckpoint.Ckpt, err)
 	}
 	// verify BLS sig of the raw checkpoint from Babylon
-	err = m.curEpoch.VerifyMultiSig(ckpt)
+	err = m.verifyMultiSig(ckpt)
 	if err != nil {
 		return fmt.Errorf("invalid BLS sig of Babylon raw checkpoint at epoch %d: %w", m.GetCurrentEpoch(), err)
 	}
@@ -229,15 +350,17 @@ func (m *Monitor) VerifyCheckpoint(btcCkpt *checkpointingtypes.RawCheckpoint) er
 
 func (m *Monitor) addCheckpointToCheckList(ckpt *types.CheckpointRecord) {
 	record := types.NewCheckpointRecord(ckpt.RawCheckpoint, ckpt.FirstSeenBtcHeight)
+	record.Txs = ckpt.Txs
 	m.checkpointChecklist.Add(record)
 }
 
 func (m *Monitor) UpdateEpochInfo(epoch uint64) error {
-	ei, err := m.QueryInfoForNextEpoch(epoch)
+	ei, err := m.getEpochInfo(epoch)
 	if err != nil {
 		return fmt.Errorf("failed to query information of the epoch %d: %w", epoch, err)
 	}
 	m.curEpoch = ei
+	m.prefetchValSets(epoch)
 
 	return nil
 }
@@ -277,6 +400,9 @@ func GetSortedValSet(valSet checkpointingtypes.ValidatorWithBlsKeySet) checkpoin
 func (m *Monitor) Stop() {
 	close(m.quit)
 	m.BTCScanner.Stop()
+	if m.alerts != nil {
+		m.alerts.Stop()
+	}
 	// in e2e the test manager will share access to BBN querier and shut down
 	// it earlier than monitor, so we need to check if it's running here
 	if m.BBNQuerier.IsRunning() {
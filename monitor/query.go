@@ -9,8 +9,10 @@ import (
 	epochingtypes "github.com/babylonchain/babylon/x/epoching/types"
 	monitortypes "github.com/babylonchain/babylon/x/monitor/types"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	sdkquerytypes "github.com/cosmos/cosmos-sdk/types/query"
 	"go.uber.org/zap"
 
+	"github.com/babylonchain/vigilante/monitor/valset"
 	"github.com/babylonchain/vigilante/types"
 )
 
@@ -21,17 +23,7 @@ func (m *Monitor) QueryInfoForNextEpoch(epoch uint64) (*types.EpochInfo, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query BLS key set for epoch %v: %w", epoch, err)
 	}
-	valSet := make([]*ckpttypes.ValidatorWithBlsKey, len(res.ValidatorWithBlsKeys))
-	for i, key := range res.ValidatorWithBlsKeys {
-		val := &ckpttypes.ValidatorWithBlsKey{
-			ValidatorAddress: key.ValidatorAddress,
-			BlsPubKey:        key.BlsPubKey,
-			VotingPower:      key.VotingPower,
-		}
-		valSet[i] = val
-	}
-
-	ei := types.NewEpochInfo(epoch, ckpttypes.ValidatorWithBlsKeySet{ValSet: valSet})
+	ei := types.NewEpochInfo(epoch, valset.FromBlsPublicKeyList(res))
 
 	return ei, nil
 }
@@ -184,6 +176,27 @@ func (m *Monitor) queryBTCHeaderChainTipWithRetry() (*btclctypes.QueryTipRespons
 	return &btcHeaderChainTipRes, nil
 }
 
+func (m *Monitor) queryBTCMainChainWithRetry(pagination *sdkquerytypes.PageRequest) (*btclctypes.QueryMainChainResponse, error) {
+	var btcMainChainRes btclctypes.QueryMainChainResponse
+
+	if err := retry.Do(m.ComCfg.RetrySleepTime, m.ComCfg.MaxRetrySleepTime, func() error {
+		res, err := m.BBNQuerier.BTCMainChain(pagination)
+		if err != nil {
+			return err
+		}
+
+		btcMainChainRes = *res
+		return nil
+	}); err != nil {
+		m.logger.Debug(
+			"failed to query the BTC main chain", zap.Error(err))
+
+		return nil, err
+	}
+
+	return &btcMainChainRes, nil
+}
+
 func (m *Monitor) queryContainsBTCBlockWithRetry(blockHash *chainhash.Hash) (*btclctypes.QueryContainsBytesResponse, error) {
 	var containsBTCBlockRes btclctypes.QueryContainsBytesResponse
 
@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	checkpointingtypes "github.com/babylonchain/babylon/x/checkpointing/types"
+
+	"github.com/babylonchain/vigilante/btcclient"
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/monitor/btcscanner"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// monitorState is the verification progress persisted across restarts
+type monitorState struct {
+	// the Babylon chain the progress is of
+	ChainID string `json:"chain_id"`
+	// the epoch to be verified next, together with its validator set
+	Epoch  uint64                                    `json:"epoch"`
+	ValSet checkpointingtypes.ValidatorWithBlsKeySet `json:"val_set"`
+	// the BTC block from which the scanning resumes, i.e., the first block carrying
+	// the last verified checkpoint. Re-scanning the verified checkpoint is harmless,
+	// while starting any later may miss segments of the next checkpoint
+	ResumeBtcHeight uint64 `json:"resume_btc_height,omitempty"`
+	ResumeBtcHash   string `json:"resume_btc_hash,omitempty"`
+	// checkpoints that have not been reported back to Babylon yet
+	Checkpoints []*checkpointState `json:"checkpoints,omitempty"`
+}
+
+type checkpointState struct {
+	// the raw checkpoint encoded in protobuf
+	RawCheckpoint      []byte                      `json:"raw_checkpoint"`
+	FirstSeenBtcHeight uint64                      `json:"first_seen_btc_height"`
+	Txs                []*types.CheckpointTxRecord `json:"txs,omitempty"`
+}
+
+// RemoveState removes the persisted verification progress, so that the monitor
+// starts over from the genesis epoch
+func RemoveState(cfg *config.MonitorConfig) error {
+	if cfg.StateFile == "" {
+		return nil
+	}
+	if err := os.Remove(cfg.StateFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// setResumeBlock records the BTC block from which the scanning resumes after a restart
+func (m *Monitor) setResumeBlock(ckpt *types.CheckpointRecord) {
+	m.resumeBtcHeight = ckpt.FirstSeenBtcHeight
+	m.resumeBtcHash = ""
+	for _, tx := range ckpt.Txs {
+		if m.resumeBtcHash == "" || tx.BlockHeight < m.resumeBtcHeight {
+			m.resumeBtcHeight = tx.BlockHeight
+			m.resumeBtcHash = tx.BlockHash
+		}
+	}
+}
+
+// saveState atomically overwrites the state file with the current verification progress.
+// It is only called from the main loop of the monitor, which is the only writer of curEpoch
+func (m *Monitor) saveState() error {
+	if m.Cfg.StateFile == "" {
+		return nil
+	}
+
+	state := &monitorState{
+		ChainID:         m.chainID,
+		Epoch:           m.curEpoch.GetEpochNumber(),
+		ValSet:          m.curEpoch.GetValSet(),
+		ResumeBtcHeight: m.resumeBtcHeight,
+		ResumeBtcHash:   m.resumeBtcHash,
+	}
+	for _, ckpt := range m.checkpointChecklist.GetAll() {
+		rawCkptBytes, err := ckpt.RawCheckpoint.Marshal()
+		if err != nil {
+			return fmt.Errorf("failed to encode checkpoint at epoch %d: %w", ckpt.EpochNum(), err)
+		}
+		state.Checkpoints = append(state.Checkpoints, &checkpointState{
+			RawCheckpoint:      rawCkptBytes,
+			FirstSeenBtcHeight: ckpt.FirstSeenBtcHeight,
+			Txs:                ckpt.Txs,
+		})
+	}
+
+	bz, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.Cfg.StateFile), 0700); err != nil {
+		return err
+	}
+	tmpFile := m.Cfg.StateFile + ".tmp"
+	if err := os.WriteFile(tmpFile, bz, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, m.Cfg.StateFile)
+}
+
+func loadState(stateFile string) (*monitorState, error) {
+	bz, err := os.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state monitorState
+	if err := json.Unmarshal(bz, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// restoreState resumes the verification progress from the state file, if any
+func (m *Monitor) restoreState(btcScanner *btcscanner.BtcScanner, btcClient btcclient.BTCClient) error {
+	if m.Cfg.StateFile == "" {
+		return nil
+	}
+	state, err := loadState(m.Cfg.StateFile)
+	if err != nil {
+		return fmt.Errorf("failed to load state file %s: %w", m.Cfg.StateFile, err)
+	}
+	if state == nil {
+		m.logger.Infof("no state file found at %s, starting from epoch %d", m.Cfg.StateFile, m.GetCurrentEpoch())
+		return nil
+	}
+	if state.ChainID != m.chainID {
+		return fmt.Errorf("state file %s is of Babylon chain %q, but the monitor verifies Babylon chain %q; restart with --reset",
+			m.Cfg.StateFile, state.ChainID, m.chainID)
+	}
+
+	m.curEpoch = types.NewEpochInfo(state.Epoch, m.cacheValSet(state.Epoch, state.ValSet))
+
+	for _, ckptState := range state.Checkpoints {
+		var rawCkpt checkpointingtypes.RawCheckpoint
+		if err := rawCkpt.Unmarshal(ckptState.RawCheckpoint); err != nil {
+			return fmt.Errorf("failed to decode checkpoint in state file: %w", err)
+		}
+		record := types.NewCheckpointRecord(&rawCkpt, ckptState.FirstSeenBtcHeight)
+		record.Txs = ckptState.Txs
+		m.checkpointChecklist.Add(record)
+	}
+
+	m.resumeBtcHeight = state.ResumeBtcHeight
+	m.resumeBtcHash = state.ResumeBtcHash
+	if m.resumeBtcHeight > btcScanner.BaseHeight {
+		if m.resumeBtcHash != "" {
+			resumeBlock, _, err := btcClient.GetBlockByHeight(m.resumeBtcHeight)
+			if err != nil {
+				return fmt.Errorf("failed to get BTC block at height %d: %w", m.resumeBtcHeight, err)
+			}
+			if resumeBlock.BlockHash().String() != m.resumeBtcHash {
+				return fmt.Errorf("BTC block at height %d is %s, but the state file expects %s; the checkpoint may have been reorged out, restart with --reset",
+					m.resumeBtcHeight, resumeBlock.BlockHash(), m.resumeBtcHash)
+			}
+		}
+		// the scanner starts from the block after the confirmed tip
+		confirmedTip, _, err := btcClient.GetBlockByHeight(m.resumeBtcHeight - 1)
+		if err != nil {
+			return fmt.Errorf("failed to get BTC block at height %d: %w", m.resumeBtcHeight-1, err)
+		}
+		btcScanner.SetConfirmedTipBlock(confirmedTip)
+	}
+
+	m.logger.Infof("resumed from state file %s at epoch %d, scanning BTC from height %d with %d unreported checkpoints",
+		m.Cfg.StateFile, state.Epoch, max(m.resumeBtcHeight, btcScanner.BaseHeight), len(state.Checkpoints))
+
+	return nil
+}
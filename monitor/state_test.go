@@ -0,0 +1,131 @@
+package monitor
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/monitor/btcscanner"
+	"github.com/babylonchain/vigilante/monitor/valset"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+	"github.com/babylonchain/vigilante/types"
+)
+
+const testChainID = "bbn-test-1"
+
+func newStateTestMonitor(stateFile, chainID string) *Monitor {
+	genesisValSet, _ := datagen.GenerateValidatorSetWithBLSPrivKeys(4)
+	return &Monitor{
+		Cfg:                 &config.MonitorConfig{StateFile: stateFile},
+		logger:              zap.NewNop().Sugar(),
+		chainID:             chainID,
+		curEpoch:            types.NewEpochInfo(1, *genesisValSet),
+		valSets:             valset.NewCache(),
+		checkpointChecklist: types.NewCheckpointsBookkeeper(),
+		metrics:             metrics.NewMonitorMetrics(),
+	}
+}
+
+// genBlockAtHeight generates a BTC block at the given height, and the record of a
+// checkpoint tx in it
+func genBlockAtHeight(r *rand.Rand, height uint64) (*types.IndexedBlock, *types.CheckpointTxRecord) {
+	header := &wire.BlockHeader{
+		PrevBlock: chainhash.Hash(datagen.GenRandomByteArray(r, chainhash.HashSize)),
+		Nonce:     r.Uint32(),
+	}
+	block := types.NewIndexedBlock(int32(height), header, nil)
+	return block, &types.CheckpointTxRecord{
+		TxId:        chainhash.Hash(datagen.GenRandomByteArray(r, chainhash.HashSize)).String(),
+		BlockHash:   header.BlockHash().String(),
+		BlockHeight: height,
+	}
+}
+
+func FuzzSaveAndRestoreState(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		stateFile := filepath.Join(t.TempDir(), "monitor-state.json")
+		baseHeight := uint64(r.Intn(1000))
+
+		// the monitor verified a checkpoint, and has unreported checkpoints
+		m := newStateTestMonitor(stateFile, testChainID)
+		epoch := datagen.RandomInt(r, 100) + 2
+		valSet, privKeys := datagen.GenerateValidatorSetWithBLSPrivKeys(r.Intn(10) + 4)
+		m.curEpoch = types.NewEpochInfo(epoch, *valSet)
+		resumeHeight := baseHeight + datagen.RandomInt(r, 100) + 1
+		resumeBlock, resumeTx := genBlockAtHeight(r, resumeHeight)
+		_, laterTx := genBlockAtHeight(r, resumeHeight+datagen.RandomInt(r, 10)+1)
+		verifiedCkpt := types.NewCheckpointRecord(datagen.GenerateLegitimateRawCheckpoint(r, privKeys), laterTx.BlockHeight)
+		verifiedCkpt.Txs = []*types.CheckpointTxRecord{laterTx, resumeTx}
+		m.setResumeBlock(verifiedCkpt)
+		numCkpts := r.Intn(3)
+		for i := 0; i < numCkpts; i++ {
+			_, txRecord := genBlockAtHeight(r, resumeHeight+datagen.RandomInt(r, 100))
+			ckpt := types.NewCheckpointRecord(datagen.GenerateLegitimateRawCheckpoint(r, privKeys), txRecord.BlockHeight)
+			ckpt.Txs = []*types.CheckpointTxRecord{txRecord}
+			m.checkpointChecklist.Add(ckpt)
+		}
+		require.NoError(t, m.saveState())
+
+		// the restarted monitor resumes from the block of the verified checkpoint
+		mockBTCClient := mocks.NewMockBTCClient(gomock.NewController(t))
+		confirmedTip, _ := genBlockAtHeight(r, resumeHeight-1)
+		mockBTCClient.EXPECT().GetBlockByHeight(resumeHeight).Return(resumeBlock, nil, nil)
+		mockBTCClient.EXPECT().GetBlockByHeight(resumeHeight-1).Return(confirmedTip, nil, nil)
+		restarted := newStateTestMonitor(stateFile, testChainID)
+		require.NoError(t, restarted.restoreState(&btcscanner.BtcScanner{BaseHeight: baseHeight}, mockBTCClient))
+
+		require.Equal(t, epoch, restarted.GetCurrentEpoch())
+		require.Equal(t, *valSet, restarted.curEpoch.GetValSet())
+		require.Equal(t, resumeHeight, restarted.resumeBtcHeight)
+		require.Equal(t, resumeTx.BlockHash, restarted.resumeBtcHash)
+		expectedCkpts := make(map[string]*types.CheckpointRecord)
+		for _, ckpt := range m.checkpointChecklist.GetAll() {
+			expectedCkpts[ckpt.ID()] = ckpt
+		}
+		restoredCkpts := restarted.checkpointChecklist.GetAll()
+		require.Len(t, restoredCkpts, len(expectedCkpts))
+		for _, restored := range restoredCkpts {
+			expected, ok := expectedCkpts[restored.ID()]
+			require.True(t, ok)
+			require.Equal(t, expected.FirstSeenBtcHeight, restored.FirstSeenBtcHeight)
+			require.Equal(t, expected.Txs, restored.Txs)
+		}
+	})
+}
+
+func TestRestoreStateChecksChainID(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	stateFile := filepath.Join(t.TempDir(), "monitor-state.json")
+	m := newStateTestMonitor(stateFile, testChainID)
+	m.curEpoch = types.NewEpochInfo(datagen.RandomInt(r, 100)+2, m.curEpoch.GetValSet())
+	require.NoError(t, m.saveState())
+
+	// the progress of another chain is not resumed
+	other := newStateTestMonitor(stateFile, "bbn-other-1")
+	err := other.restoreState(&btcscanner.BtcScanner{}, nil)
+	require.ErrorContains(t, err, "--reset")
+	require.Equal(t, uint64(1), other.GetCurrentEpoch())
+
+	// the progress of the same chain is resumed
+	restarted := newStateTestMonitor(stateFile, testChainID)
+	require.NoError(t, restarted.restoreState(&btcscanner.BtcScanner{}, nil))
+	require.Equal(t, m.GetCurrentEpoch(), restarted.GetCurrentEpoch())
+}
+
+func TestRestoreStateWithoutStateFile(t *testing.T) {
+	m := newStateTestMonitor(filepath.Join(t.TempDir(), "monitor-state.json"), testChainID)
+	require.NoError(t, m.restoreState(&btcscanner.BtcScanner{}, nil))
+	require.Equal(t, uint64(1), m.GetCurrentEpoch())
+}
@@ -0,0 +1,130 @@
+// Package valset keeps the BLS validator sets of Babylon epochs, both in memory
+// for the monitor and in history files exported for offline verification.
+package valset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+)
+
+// Hash returns the hash of the validator set, which changes if any validator,
+// BLS key, voting power, or the signing order changes
+func Hash(valSet *ckpttypes.ValidatorWithBlsKeySet) (string, error) {
+	bz, err := valSet.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode validator set: %w", err)
+	}
+	h := sha256.Sum256(bz)
+	return hex.EncodeToString(h[:]), nil
+}
+
+// FromBlsPublicKeyList returns the validator set in the response of Babylon's
+// BLS public key list query, in the signing order
+func FromBlsPublicKeyList(res *ckpttypes.QueryBlsPublicKeyListResponse) ckpttypes.ValidatorWithBlsKeySet {
+	valSet := make([]*ckpttypes.ValidatorWithBlsKey, len(res.ValidatorWithBlsKeys))
+	for i, key := range res.ValidatorWithBlsKeys {
+		valSet[i] = &ckpttypes.ValidatorWithBlsKey{
+			ValidatorAddress: key.ValidatorAddress,
+			BlsPubKey:        key.BlsPubKey,
+			VotingPower:      key.VotingPower,
+		}
+	}
+	return ckpttypes.ValidatorWithBlsKeySet{ValSet: valSet}
+}
+
+type entry struct {
+	hash   string
+	valSet ckpttypes.ValidatorWithBlsKeySet
+}
+
+// Cache keeps the validator sets of epochs. Consecutive epochs with the same
+// validator set share a single copy of it.
+type Cache struct {
+	mu      sync.RWMutex
+	entries map[uint64]*entry
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: make(map[uint64]*entry)}
+}
+
+// Get returns the validator set of the epoch, if cached
+func (c *Cache) Get(epoch uint64) (ckpttypes.ValidatorWithBlsKeySet, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.entries[epoch]
+	if !ok {
+		return ckpttypes.ValidatorWithBlsKeySet{}, false
+	}
+	return e.valSet, true
+}
+
+// Has returns whether the validator set of the epoch is cached
+func (c *Cache) Has(epoch uint64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.entries[epoch]
+	return ok
+}
+
+// Put caches the validator set of the epoch, and returns the cached validator set
+// together with whether it changed from the previous epoch. If the previous epoch
+// is not cached, the change is unknown and reported as no change.
+func (c *Cache) Put(epoch uint64, valSet ckpttypes.ValidatorWithBlsKeySet) (ckpttypes.ValidatorWithBlsKeySet, bool, error) {
+	hash, err := Hash(&valSet)
+	if err != nil {
+		return valSet, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	if epoch > 0 {
+		if prev, ok := c.entries[epoch-1]; ok {
+			if prev.hash == hash {
+				// share the validator set of the previous epoch rather than keeping another copy
+				valSet = prev.valSet
+			} else {
+				changed = true
+			}
+		}
+	}
+	c.entries[epoch] = &entry{hash: hash, valSet: valSet}
+
+	return valSet, changed, nil
+}
+
+// Evict removes the validator set of the epoch
+func (c *Cache) Evict(epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, epoch)
+}
+
+// PruneBelow removes the validator sets of all epochs lower than the given one
+func (c *Cache) PruneBelow(epoch uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := range c.entries {
+		if e < epoch {
+			delete(c.entries, e)
+		}
+	}
+}
+
+// Len returns the number of cached epochs
+func (c *Cache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.entries)
+}
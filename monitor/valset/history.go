@@ -0,0 +1,119 @@
+package valset
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+)
+
+// History is the validator sets of a range of epochs exported from Babylon, so that
+// BLS multi-sigs of checkpoints can be verified without querying Babylon for them.
+// Only the epochs at which the validator set changes are recorded.
+type History struct {
+	FromEpoch uint64 `json:"from_epoch"`
+	ToEpoch   uint64 `json:"to_epoch"`
+	// in ascending epoch order, the first one being at FromEpoch
+	Changes []*Change `json:"changes"`
+}
+
+// Change is the validator set that applies from its epoch until the next change
+type Change struct {
+	Epoch  uint64                           `json:"epoch"`
+	Hash   string                           `json:"hash"`
+	ValSet ckpttypes.ValidatorWithBlsKeySet `json:"val_set"`
+}
+
+// Append adds the validator set of the epoch right after the last epoch of the history
+func (h *History) Append(epoch uint64, valSet ckpttypes.ValidatorWithBlsKeySet) error {
+	if len(h.Changes) > 0 && epoch != h.ToEpoch+1 {
+		return fmt.Errorf("expected the validator set of epoch %d, got epoch %d", h.ToEpoch+1, epoch)
+	}
+	hash, err := Hash(&valSet)
+	if err != nil {
+		return err
+	}
+
+	if len(h.Changes) == 0 {
+		h.FromEpoch = epoch
+	}
+	h.ToEpoch = epoch
+	if len(h.Changes) > 0 && h.Changes[len(h.Changes)-1].Hash == hash {
+		return nil
+	}
+	h.Changes = append(h.Changes, &Change{Epoch: epoch, Hash: hash, ValSet: valSet})
+	return nil
+}
+
+// Lookup returns the validator set of the epoch, if the epoch is covered by the history
+func (h *History) Lookup(epoch uint64) (ckpttypes.ValidatorWithBlsKeySet, bool) {
+	if len(h.Changes) == 0 || epoch < h.FromEpoch || epoch > h.ToEpoch {
+		return ckpttypes.ValidatorWithBlsKeySet{}, false
+	}
+	// the last change at or before the epoch
+	i := sort.Search(len(h.Changes), func(i int) bool {
+		return h.Changes[i].Epoch > epoch
+	})
+	return h.Changes[i-1].ValSet, true
+}
+
+// Validate checks that the changes are ordered, cover the range of epochs, and match their hashes
+func (h *History) Validate() error {
+	if len(h.Changes) == 0 {
+		return fmt.Errorf("the history has no validator set")
+	}
+	if h.Changes[0].Epoch != h.FromEpoch || h.FromEpoch > h.ToEpoch {
+		return fmt.Errorf("the history of epochs %d to %d starts with a validator set of epoch %d", h.FromEpoch, h.ToEpoch, h.Changes[0].Epoch)
+	}
+	for i, c := range h.Changes {
+		if i > 0 && c.Epoch <= h.Changes[i-1].Epoch {
+			return fmt.Errorf("the validator set of epoch %d is not in ascending epoch order", c.Epoch)
+		}
+		if c.Epoch > h.ToEpoch {
+			return fmt.Errorf("the validator set of epoch %d is beyond the last epoch %d", c.Epoch, h.ToEpoch)
+		}
+		hash, err := Hash(&c.ValSet)
+		if err != nil {
+			return err
+		}
+		if hash != c.Hash {
+			return fmt.Errorf("the validator set of epoch %d has hash %s, expected %s", c.Epoch, hash, c.Hash)
+		}
+	}
+	return nil
+}
+
+// WriteFile atomically writes the history to the given file
+func (h *History) WriteFile(path string) error {
+	bz, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, bz, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
+
+// ReadHistoryFile reads and validates a history from the given file
+func ReadHistoryFile(path string) (*History, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var h History
+	if err := json.Unmarshal(bz, &h); err != nil {
+		return nil, fmt.Errorf("failed to decode validator set history: %w", err)
+	}
+	if err := h.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid validator set history: %w", err)
+	}
+	return &h, nil
+}
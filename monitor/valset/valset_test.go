@@ -0,0 +1,101 @@
+package valset_test
+
+import (
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/monitor/valset"
+)
+
+func FuzzValSetHistory(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+
+		// validator sets of consecutive epochs, changing at random epochs
+		fromEpoch := uint64(r.Intn(100)) + 1
+		numEpochs := r.Intn(50) + 1
+		valSets := make([]ckpttypes.ValidatorWithBlsKeySet, numEpochs)
+		numChanges := 0
+		for i := range valSets {
+			if i == 0 || r.Intn(5) == 0 {
+				valSet, _ := datagen.GenerateValidatorSetWithBLSPrivKeys(r.Intn(10) + 1)
+				valSets[i] = *valSet
+				numChanges++
+				continue
+			}
+			valSets[i] = valSets[i-1]
+		}
+
+		history := &valset.History{}
+		cache := valset.NewCache()
+		for i, valSet := range valSets {
+			epoch := fromEpoch + uint64(i)
+			require.NoError(t, history.Append(epoch, valSet))
+
+			_, changed, err := cache.Put(epoch, valSet)
+			require.NoError(t, err)
+			// the first epoch has no previous one to compare with
+			require.Equal(t, i > 0 && !sameValSet(t, valSets[i-1], valSet), changed)
+		}
+		require.Len(t, history.Changes, countChanges(t, valSets))
+		require.LessOrEqual(t, len(history.Changes), numChanges)
+
+		// only the next epoch can be appended
+		require.Error(t, history.Append(history.ToEpoch+2, valSets[0]))
+
+		// the history survives a round trip to disk
+		path := filepath.Join(t.TempDir(), "valsets.json")
+		require.NoError(t, history.WriteFile(path))
+		history, err := valset.ReadHistoryFile(path)
+		require.NoError(t, err)
+
+		for i, valSet := range valSets {
+			epoch := fromEpoch + uint64(i)
+			fromHistory, ok := history.Lookup(epoch)
+			require.True(t, ok)
+			require.True(t, sameValSet(t, valSet, fromHistory))
+			fromCache, ok := cache.Get(epoch)
+			require.True(t, ok)
+			require.True(t, sameValSet(t, valSet, fromCache))
+		}
+		_, ok := history.Lookup(fromEpoch - 1)
+		require.False(t, ok)
+		_, ok = history.Lookup(history.ToEpoch + 1)
+		require.False(t, ok)
+
+		// a tampered validator set is rejected
+		history.Changes[0].ValSet.ValSet[0].VotingPower++
+		require.NoError(t, history.WriteFile(path))
+		_, err = valset.ReadHistoryFile(path)
+		require.Error(t, err)
+
+		// pruning keeps later epochs
+		cache.PruneBelow(history.ToEpoch)
+		require.Equal(t, 1, cache.Len())
+		require.True(t, cache.Has(history.ToEpoch))
+	})
+}
+
+func sameValSet(t *testing.T, a, b ckpttypes.ValidatorWithBlsKeySet) bool {
+	hashA, err := valset.Hash(&a)
+	require.NoError(t, err)
+	hashB, err := valset.Hash(&b)
+	require.NoError(t, err)
+	return hashA == hashB
+}
+
+func countChanges(t *testing.T, valSets []ckpttypes.ValidatorWithBlsKeySet) int {
+	n := 1
+	for i := 1; i < len(valSets); i++ {
+		if !sameValSet(t, valSets[i-1], valSets[i]) {
+			n++
+		}
+	}
+	return n
+}
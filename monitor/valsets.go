@@ -0,0 +1,146 @@
+package monitor
+
+import (
+	"errors"
+	"fmt"
+
+	checkpointingtypes "github.com/babylonchain/babylon/x/checkpointing/types"
+
+	"github.com/babylonchain/vigilante/monitor/valset"
+	"github.com/babylonchain/vigilante/types"
+)
+
+var errEmptyValSet = errors.New("empty validator set")
+
+// getEpochInfo returns the information of the epoch from, in order, the validator
+// set cache, the validator set history file, and Babylon
+func (m *Monitor) getEpochInfo(epoch uint64) (*types.EpochInfo, error) {
+	// the cache is only set up by New
+	if m.valSets == nil {
+		return m.QueryInfoForNextEpoch(epoch)
+	}
+
+	// the previous epoch is kept to detect changes
+	if epoch > 0 {
+		defer m.valSets.PruneBelow(epoch - 1)
+	}
+
+	if valSet, ok := m.valSets.Get(epoch); ok {
+		m.metrics.ValSetCacheHitsCounter.Inc()
+		return types.NewEpochInfo(epoch, valSet), nil
+	}
+	m.metrics.ValSetCacheMissesCounter.Inc()
+
+	if m.valSetHistory != nil {
+		if valSet, ok := m.valSetHistory.Lookup(epoch); ok && len(valSet.ValSet) > 0 {
+			m.logger.Debugf("using the validator set of epoch %d from the history file", epoch)
+			return types.NewEpochInfo(epoch, m.cacheValSet(epoch, valSet)), nil
+		}
+	}
+
+	return m.queryAndCacheEpochInfo(epoch)
+}
+
+// queryAndCacheEpochInfo queries the validator set of the epoch from Babylon,
+// and caches it unless it is empty
+func (m *Monitor) queryAndCacheEpochInfo(epoch uint64) (*types.EpochInfo, error) {
+	ei, err := m.QueryInfoForNextEpoch(epoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(ei.GetValSet().ValSet) == 0 {
+		return nil, fmt.Errorf("%w of epoch %d from Babylon", errEmptyValSet, epoch)
+	}
+	return types.NewEpochInfo(epoch, m.cacheValSet(epoch, ei.GetValSet())), nil
+}
+
+// verifyMultiSig verifies the BLS multi-sig of the checkpoint with the validator
+// set of the current epoch. A cached validator set failing the verification
+// might be wrong, e.g., prefetched or read from a history file, so it is evicted
+// and the verification is retried once with the validator set from Babylon.
+func (m *Monitor) verifyMultiSig(ckpt *checkpointingtypes.RawCheckpoint) error {
+	err := m.curEpoch.VerifyMultiSig(ckpt)
+	if err == nil || m.valSets == nil {
+		return err
+	}
+
+	epoch := m.GetCurrentEpoch()
+	m.logger.Warnf("evicting the cached validator set of epoch %d, which failed to verify a BLS multi-sig: %v", epoch, err)
+	m.valSets.Evict(epoch)
+	m.metrics.ValSetEvictionsCounter.Inc()
+	ei, queryErr := m.queryAndCacheEpochInfo(epoch)
+	if queryErr != nil {
+		m.logger.Errorf("failed to query the validator set of epoch %d again: %v", epoch, queryErr)
+		return err
+	}
+	m.curEpoch = ei
+	return m.curEpoch.VerifyMultiSig(ckpt)
+}
+
+// cacheValSet caches the validator set of the epoch, and returns the cached
+// validator set, which is shared with the previous epoch if unchanged
+func (m *Monitor) cacheValSet(epoch uint64, valSet checkpointingtypes.ValidatorWithBlsKeySet) checkpointingtypes.ValidatorWithBlsKeySet {
+	cached, changed, err := m.valSets.Put(epoch, valSet)
+	if err != nil {
+		m.logger.Errorf("failed to cache the validator set of epoch %d: %v", epoch, err)
+		return valSet
+	}
+	if changed {
+		m.logger.Infof("the validator set changed at epoch %d, with %d validators", epoch, len(cached.ValSet))
+		m.metrics.ValSetChangesCounter.Inc()
+	}
+	return cached
+}
+
+// prefetchValSets fetches the validator sets of the epochs following the given one in
+// the background, so that verification can proceed while Babylon is briefly unavailable.
+// Only epochs that have started on Babylon are fetched, as Babylon cannot tell the
+// validator set of a later epoch yet
+func (m *Monitor) prefetchValSets(epoch uint64) {
+	if m.valSets == nil || m.Cfg.ValSetPrefetchEpochs == 0 || !m.prefetching.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer m.prefetching.Store(false)
+
+		// no retry, as prefetching is best effort
+		epochRes, err := m.BBNQuerier.CurrentEpoch()
+		if err != nil {
+			m.logger.Debugf("skipped prefetching validator sets after epoch %d: %v", epoch, err)
+			return
+		}
+		lastEpoch := min(epoch+m.Cfg.ValSetPrefetchEpochs, epochRes.CurrentEpoch)
+
+		for e := epoch + 1; e <= lastEpoch; e++ {
+			select {
+			case <-m.quit:
+				return
+			default:
+			}
+			if m.valSets.Has(e) {
+				continue
+			}
+			var (
+				valSet checkpointingtypes.ValidatorWithBlsKeySet
+				ok     bool
+			)
+			if m.valSetHistory != nil {
+				valSet, ok = m.valSetHistory.Lookup(e)
+			}
+			if !ok {
+				res, err := m.BBNQuerier.BlsPublicKeyList(e, nil)
+				if err != nil {
+					m.logger.Debugf("stopped prefetching validator sets at epoch %d: %v", e, err)
+					return
+				}
+				valSet = valset.FromBlsPublicKeyList(res)
+			}
+			if len(valSet.ValSet) == 0 {
+				m.logger.Warnf("stopped prefetching validator sets at epoch %d: %v", e, errEmptyValSet)
+				return
+			}
+			m.cacheValSet(e, valSet)
+		}
+	}()
+}
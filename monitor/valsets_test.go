@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+	epochingtypes "github.com/babylonchain/babylon/x/epoching/types"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/monitor/valset"
+	"github.com/babylonchain/vigilante/types"
+)
+
+func newValSetTestMonitor(t *testing.T, prefetchEpochs uint64) (*MockBabylonQueryClient, *Monitor) {
+	mockBabylonClient := NewMockBabylonQueryClient(gomock.NewController(t))
+	m := &Monitor{
+		Cfg: &config.MonitorConfig{ValSetPrefetchEpochs: prefetchEpochs},
+		// to disable the retry
+		ComCfg: &config.CommonConfig{
+			RetrySleepTime:    1,
+			MaxRetrySleepTime: 0,
+		},
+		logger:      zap.NewNop().Sugar(),
+		BBNQuerier:  mockBabylonClient,
+		valSets:     valset.NewCache(),
+		prefetching: atomic.NewBool(false),
+		metrics:     metrics.NewMonitorMetrics(),
+		quit:        make(chan struct{}),
+	}
+	t.Cleanup(func() { close(m.quit) })
+	return mockBabylonClient, m
+}
+
+func blsPublicKeyList(valSet *ckpttypes.ValidatorWithBlsKeySet) *ckpttypes.QueryBlsPublicKeyListResponse {
+	return &ckpttypes.QueryBlsPublicKeyListResponse{ValidatorWithBlsKeys: valSet.ValSet}
+}
+
+func waitForPrefetching(t *testing.T, m *Monitor) {
+	require.Eventually(t, func() bool {
+		return !m.prefetching.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func FuzzGetEpochInfo(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		mockBabylonClient, m := newValSetTestMonitor(t, 0)
+
+		// each epoch is queried from Babylon once, including epoch 0
+		epoch := uint64(r.Intn(3))
+		valSet, _ := datagen.GenerateValidatorSetWithBLSPrivKeys(r.Intn(10) + 1)
+		mockBabylonClient.EXPECT().BlsPublicKeyList(epoch, gomock.Nil()).Return(blsPublicKeyList(valSet), nil).Times(1)
+		mockBabylonClient.EXPECT().BlsPublicKeyList(epoch+1, gomock.Nil()).Return(blsPublicKeyList(valSet), nil).Times(1)
+
+		for i := 0; i < 2; i++ {
+			ei, err := m.getEpochInfo(epoch)
+			require.NoError(t, err)
+			require.True(t, ei.Equal(types.NewEpochInfo(epoch, *valSet)))
+		}
+		require.Equal(t, float64(1), testutil.ToFloat64(m.metrics.ValSetCacheHitsCounter))
+
+		// the previous epoch is kept, sharing the unchanged validator set
+		_, err := m.getEpochInfo(epoch + 1)
+		require.NoError(t, err)
+		require.Equal(t, 2, m.valSets.Len())
+		require.Zero(t, testutil.ToFloat64(m.metrics.ValSetChangesCounter))
+
+		// an empty validator set is rejected rather than cached
+		mockBabylonClient.EXPECT().BlsPublicKeyList(epoch+2, gomock.Nil()).Return(&ckpttypes.QueryBlsPublicKeyListResponse{}, nil)
+		_, err = m.getEpochInfo(epoch + 2)
+		require.ErrorIs(t, err, errEmptyValSet)
+		require.False(t, m.valSets.Has(epoch+2))
+	})
+}
+
+func FuzzPrefetchValSets(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		prefetchEpochs := uint64(r.Intn(5) + 1)
+		mockBabylonClient, m := newValSetTestMonitor(t, prefetchEpochs)
+
+		// only the epochs up to the current one of Babylon are fetched, and
+		// the prefetching stops at an empty validator set
+		epoch := uint64(r.Intn(100) + 1)
+		currentEpoch := epoch + uint64(r.Intn(int(prefetchEpochs)+2))
+		lastEpoch := min(epoch+prefetchEpochs, currentEpoch)
+		emptyEpoch := lastEpoch + 1
+		if lastEpoch > epoch && r.Intn(2) == 0 {
+			emptyEpoch = epoch + 1 + uint64(r.Intn(int(lastEpoch-epoch)))
+		}
+		mockBabylonClient.EXPECT().CurrentEpoch().Return(&epochingtypes.QueryCurrentEpochResponse{CurrentEpoch: currentEpoch}, nil)
+		for e := epoch + 1; e <= lastEpoch && e <= emptyEpoch; e++ {
+			res := &ckpttypes.QueryBlsPublicKeyListResponse{}
+			if e != emptyEpoch {
+				valSet, _ := datagen.GenerateValidatorSetWithBLSPrivKeys(r.Intn(10) + 1)
+				res = blsPublicKeyList(valSet)
+			}
+			mockBabylonClient.EXPECT().BlsPublicKeyList(e, gomock.Nil()).Return(res, nil).Times(1)
+		}
+
+		m.prefetchValSets(epoch)
+		waitForPrefetching(t, m)
+		for e := epoch + 1; e <= epoch+prefetchEpochs+1; e++ {
+			require.Equal(t, e <= lastEpoch && e < emptyEpoch, m.valSets.Has(e), "epoch %d", e)
+		}
+
+		// prefetched validator sets are used without querying Babylon again
+		for e := epoch + 1; e <= lastEpoch && e < emptyEpoch; e++ {
+			_, err := m.getEpochInfo(e)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestVerifyMultiSigEvictsWrongValSet(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	mockBabylonClient, m := newValSetTestMonitor(t, 0)
+
+	n := r.Intn(10) + 4
+	valSet, privKeys := datagen.GenerateValidatorSetWithBLSPrivKeys(n)
+	wrongValSet, _ := datagen.GenerateValidatorSetWithBLSPrivKeys(n)
+	ckpt := datagen.GenerateLegitimateRawCheckpoint(r, privKeys)
+	epoch := ckpt.EpochNum
+
+	// a wrong validator set is cached, e.g., from a wrong history file
+	m.cacheValSet(epoch, *wrongValSet)
+	ei, err := m.getEpochInfo(epoch)
+	require.NoError(t, err)
+	m.curEpoch = ei
+
+	// the wrong validator set is replaced by the one of Babylon, which verifies
+	// the checkpoint
+	mockBabylonClient.EXPECT().BlsPublicKeyList(epoch, gomock.Nil()).Return(blsPublicKeyList(valSet), nil).Times(2)
+	require.NoError(t, m.verifyMultiSig(ckpt))
+	require.Equal(t, float64(1), testutil.ToFloat64(m.metrics.ValSetEvictionsCounter))
+	cached, ok := m.valSets.Get(epoch)
+	require.True(t, ok)
+	require.True(t, types.NewEpochInfo(epoch, cached).Equal(types.NewEpochInfo(epoch, *valSet)))
+	require.True(t, m.curEpoch.Equal(types.NewEpochInfo(epoch, *valSet)))
+
+	// an invalid multi-sig still fails with the validator set of Babylon
+	sig := datagen.GenRandomBlsMultiSig(r)
+	ckpt.BlsMultiSig = &sig
+	require.ErrorIs(t, m.verifyMultiSig(ckpt), types.ErrInvalidMultiSig)
+	require.Equal(t, float64(2), testutil.ToFloat64(m.metrics.ValSetEvictionsCounter))
+}
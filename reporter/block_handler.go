@@ -1,17 +1,33 @@
 package reporter
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/babylonchain/vigilante/types"
 )
 
+const (
+	// interval between checks of the checkpoint retry queue. The backoff of each
+	// checkpoint in the queue is decided by the queue itself
+	ckptRetryPollInterval = 10 * time.Second
+	// interval between checks of whether the headers skipped while the header
+	// witness raised an alarm can be submitted
+	skippedHeadersPollInterval = 10 * time.Second
+)
+
 // blockEventHandler handles connected and disconnected blocks from the BTC client.
 func (r *Reporter) blockEventHandler() {
 	defer r.wg.Done()
 	quit := r.quitChan()
 
+	ticker := time.NewTicker(skippedHeadersPollInterval)
+	defer ticker.Stop()
+
 	for {
+		var errorRequiringBootstrap error
+
 		select {
 		case event, open := <-r.btcClient.BlockEventChan():
 			if !open {
@@ -19,22 +35,56 @@ func (r *Reporter) blockEventHandler() {
 				return // channel closed
 			}
 
-			var errorRequiringBootstrap error
 			if event.EventType == types.BlockConnected {
 				errorRequiringBootstrap = r.handleConnectedBlocks(event)
 			} else if event.EventType == types.BlockDisconnected {
 				errorRequiringBootstrap = r.handleDisconnectedBlocks(event)
 			}
 
-			if errorRequiringBootstrap != nil {
-				r.logger.Warnf("Due to error in event processing: %v, bootstrap process need to be restarted", errorRequiringBootstrap)
-				r.bootstrapWithRetries(true)
+		case <-ticker.C:
+			// submit the skipped headers as soon as the alarm is cleared, rather
+			// than waiting for the next BTC block
+			if r.headersSkipped && !r.headerSubmissionPaused() {
+				errorRequiringBootstrap = r.submitHeaders(r.babylonClient.MustGetAddr(), nil)
 			}
 
 		case <-quit:
 			// We have been asked to stop
 			return
 		}
+
+		if errorRequiringBootstrap != nil {
+			r.logger.Warnf("Due to error in event processing: %v, bootstrap process need to be restarted", errorRequiringBootstrap)
+			if err := r.bootstrapWithRetries(true); err != nil {
+				// the BTC cache is stale, hence the block events are no longer
+				// handled until the reporter is restarted
+				r.logger.Errorf("Failed to re-bootstrap the vigilant reporter, with health status %v, stop handling block events: %v", r.Health(), err)
+				return
+			}
+		}
+	}
+}
+
+// checkpointRetryHandler periodically resubmits the checkpoints in the retry queue whose
+// backoff has expired, so that they do not have to wait for the next BTC block.
+func (r *Reporter) checkpointRetryHandler() {
+	defer r.wg.Done()
+	quit := r.quitChan()
+
+	ticker := time.NewTicker(ckptRetryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if r.ckptRetryQueue.size() == 0 {
+				continue
+			}
+			r.submitQueuedCheckpoints(r.babylonClient.MustGetAddr())
+		case <-quit:
+			// We have been asked to stop
+			return
+		}
 	}
 }
 
@@ -131,9 +181,8 @@ func (r *Reporter) handleConnectedBlocks(event *types.BlockEvent) error {
 
 	// extracts and submits headers for each blocks in ibs
 	signer := r.babylonClient.MustGetAddr()
-	_, err = r.ProcessHeaders(signer, headersToProcess)
-	if err != nil {
-		r.logger.Warnf("Failed to submit header: %v", err)
+	if err := r.submitHeaders(signer, headersToProcess); err != nil {
+		return err
 	}
 
 	// extracts and submits checkpoints for each blocks in ibs
@@ -144,6 +193,35 @@ func (r *Reporter) handleConnectedBlocks(event *types.BlockEvent) error {
 	return nil
 }
 
+// submitHeaders submits the headers of the given blocks. While the header
+// witness raises an alarm, headers are skipped rather than submitted, without
+// holding up checkpoints. Once the alarm is cleared, the skipped headers are
+// submitted from the BTC cache along with the given ones. It returns an error
+// requiring bootstrap if the skipped headers cannot be submitted, e.g., as
+// some of them are no longer in the BTC cache.
+func (r *Reporter) submitHeaders(signer string, ibs []*types.IndexedBlock) error {
+	if r.headersSkipped {
+		ibs = r.btcCache.GetAllBlocks()
+	}
+
+	_, err := r.ProcessHeaders(signer, ibs)
+	switch {
+	case errors.Is(err, ErrHeaderWitnessAlarm):
+		if !r.headersSkipped {
+			r.logger.Warnf("Skipping header submission until the alarm is cleared: %v", err)
+		}
+		r.headersSkipped = true
+	case err != nil && r.headersSkipped:
+		return fmt.Errorf("failed to submit the headers skipped during the header witness alarm: %w", err)
+	case err != nil:
+		r.logger.Warnf("Failed to submit header: %v", err)
+	case r.headersSkipped:
+		r.logger.Infof("The header witness alarm is cleared, submitted the skipped headers")
+		r.headersSkipped = false
+	}
+	return nil
+}
+
 // handleDisconnectedBlocks handles disconnected blocks from the BTC client.
 func (r *Reporter) handleDisconnectedBlocks(event *types.BlockEvent) error {
 	// get cache tip
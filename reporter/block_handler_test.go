@@ -0,0 +1,138 @@
+package reporter
+
+import (
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+// testWitness is a header witness whose alarm is raised by the test
+type testWitness struct {
+	alarmed atomic.Bool
+}
+
+func (w *testWitness) Alarmed() bool {
+	return w.alarmed.Load()
+}
+
+func connectBlock(r *Reporter, ib *types.IndexedBlock) error {
+	return r.handleConnectedBlocks(types.NewBlockEvent(types.BlockConnected, ib.Height, ib.Header))
+}
+
+// newAlarmedReporter bootstraps a reporter whose header witness raises an alarm,
+// on a BTC chain of numBlocks blocks whose first numOnBabylon blocks are on Babylon
+func newAlarmedReporter(t *testing.T, r *rand.Rand, numBlocks, numOnBabylon int) (*testChain, *MockBabylonClient, *testWitness, *Reporter) {
+	chain := newTestChain(r, numBlocks, numOnBabylon)
+	cfg := newTestReporterConfig()
+	cfg.HeaderWitness.PauseOnAlarm = true
+	btcClient, babylonClient, reporter := newTestReporter(t, cfg)
+	chain.mockClients(btcClient, babylonClient)
+	witness := &testWitness{}
+	witness.alarmed.Store(true)
+	reporter.SetHeaderWitness(witness)
+
+	// bootstrapping succeeds without submitting headers
+	require.NoError(t, reporter.bootstrapWithRetries(false))
+	require.Equal(t, HealthOK, reporter.Health())
+	require.Zero(t, chain.numInsertedHeaders())
+	return chain, babylonClient, witness, reporter
+}
+
+func TestHeaderWitnessAlarmPausesOnlyHeaders(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	chain, babylonClient, witness, reporter := newAlarmedReporter(t, r, 40, 30)
+
+	// the checkpoints of new blocks are submitted while headers are not
+	babylonClient.EXPECT().InsertBTCSpvProof(gomock.Any(), gomock.Any()).Return(&pv.RelayerTxResponse{Code: 0}, nil).Times(1)
+	require.NoError(t, connectBlock(reporter, chain.mine(r, 2)))
+	for _, ib := range chain.extend(r, 3) {
+		require.NoError(t, connectBlock(reporter, ib))
+	}
+	require.Zero(t, chain.numInsertedHeaders())
+	require.Zero(t, reporter.NumPendingCheckpoints())
+
+	// nothing is submitted until the alarm is cleared
+	require.NoError(t, reporter.submitHeaders("", nil))
+	require.Zero(t, chain.numInsertedHeaders())
+
+	// once the alarm is cleared, the skipped headers are submitted without
+	// waiting for a new block
+	witness.alarmed.Store(false)
+	require.NoError(t, reporter.submitHeaders("", nil))
+	require.Equal(t, 40-30+4, chain.numInsertedHeaders())
+	require.Equal(t, chain.tip().BlockHash(), chain.lightClientTip().BlockHash())
+
+	// and new headers are submitted as usual
+	require.NoError(t, connectBlock(reporter, chain.extend(r, 1)[0]))
+	require.Equal(t, 40-30+5, chain.numInsertedHeaders())
+	require.Equal(t, chain.tip().BlockHash(), chain.lightClientTip().BlockHash())
+}
+
+func TestSkippedHeadersBeyondCacheRequireBootstrap(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	chain, _, witness, reporter := newAlarmedReporter(t, r, 40, 30)
+
+	// the alarm lasts until the first skipped headers leave the BTC cache
+	for _, ib := range chain.extend(r, testBTCConfirmationDepth+testCheckpointFinalizationTimeout) {
+		require.NoError(t, connectBlock(reporter, ib))
+	}
+	require.Zero(t, chain.numInsertedHeaders())
+
+	// the skipped headers can only be submitted by bootstrapping again
+	witness.alarmed.Store(false)
+	require.Error(t, connectBlock(reporter, chain.extend(r, 1)[0]))
+	require.NoError(t, reporter.bootstrapWithRetries(true))
+	require.Equal(t, chain.tip().BlockHash(), chain.lightClientTip().BlockHash())
+	require.False(t, reporter.headersSkipped)
+}
+
+func TestBlockHandlerStopsWhenReBootstrapFails(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	chain := newTestChain(r, 50, 20)
+	btcClient, babylonClient, reporter := newTestReporter(t, newTestReporterConfig())
+	require.Equal(t, HealthStarting, reporter.Health())
+
+	// the BTC node becomes unreachable once the reporter is started
+	var unreachable atomic.Bool
+	btcClient.EXPECT().GetBestBlock().DoAndReturn(func() (*chainhash.Hash, uint64, error) {
+		if unreachable.Load() {
+			return nil, 0, errors.New("BTC node is unreachable")
+		}
+		tip := chain.tip()
+		hash := tip.BlockHash()
+		return &hash, uint64(tip.Height), nil
+	}).AnyTimes()
+	chain.mockClients(btcClient, babylonClient)
+	blockEventChan := make(chan *types.BlockEvent)
+	btcClient.EXPECT().BlockEventChan().Return(blockEventChan).AnyTimes()
+	require.NoError(t, reporter.Start())
+	defer func() {
+		reporter.Stop()
+		reporter.WaitForShutdown()
+	}()
+	require.Equal(t, HealthOK, reporter.Health())
+
+	// a block conflicting with the cache requires bootstrapping again, which fails
+	unreachable.Store(true)
+	blockEventChan <- types.NewBlockEvent(types.BlockConnected, chain.tip().Height, datagen.GenRandomBtcdHeader(r))
+	require.Eventually(t, func() bool {
+		return reporter.Health() == HealthBootstrapFailed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the block events are no longer handled on the stale cache
+	select {
+	case blockEventChan <- types.NewBlockEvent(types.BlockConnected, chain.tip().Height, datagen.GenRandomBtcdHeader(r)):
+		t.Fatal("block event is handled after re-bootstrapping failed")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -13,10 +13,7 @@ import (
 )
 
 var (
-	bootstrapAttempts      = uint(60)
-	bootstrapAttemptsAtt   = retry.Attempts(bootstrapAttempts)
-	bootstrapRetryInterval = retry.Delay(30 * time.Second)
-	bootstrapDelayType     = retry.DelayType(retry.FixedDelay)
+	bootstrapDelayType     = retry.DelayType(retry.CombineDelay(retry.BackOffDelay, retry.RandomDelay))
 	bootstrapErrReportType = retry.LastErrorOnly(true)
 )
 
@@ -75,6 +72,11 @@ func (r *Reporter) bootstrap(skipBlockSubscription bool) error {
 		return err
 	}
 
+	// if BBN header chain falls far behind, catch up by streaming headers before loading blocks into cache
+	if err := r.gapFill(); err != nil {
+		return err
+	}
+
 	// initialize cache with the latest blocks
 	if err := r.initBTCCache(); err != nil {
 		return err
@@ -106,8 +108,13 @@ func (r *Reporter) bootstrap(skipBlockSubscription bool) error {
 	// Note: As we are retrieving blocks from btc cache from block just after confirmed block which
 	// we already checked for consistency, we can be sure that even if rest of the block headers is different than in Babylon
 	// due to reorg, our fork will be better than the one in Babylon.
+	r.headersSkipped = false
 	_, err = r.ProcessHeaders(signer, ibs)
-	if err != nil {
+	if errors.Is(err, ErrHeaderWitnessAlarm) {
+		// keep bootstrapping to process checkpoints, the headers are submitted once the alarm is cleared
+		r.logger.Warnf("Skipping header submission until the alarm is cleared: %v", err)
+		r.headersSkipped = true
+	} else if err != nil {
 		// this can happen when there are two contentious vigilantes or if our btc node is behind.
 		r.logger.Errorf("Failed to submit headers: %v", err)
 		// returning error as it is up to the caller to decide what do next
@@ -153,29 +160,51 @@ func (r *Reporter) reporterQuitCtx() (context.Context, func()) {
 	return ctx, cancel
 }
 
-func (r *Reporter) bootstrapWithRetries(skipBlockSubscription bool) {
+// bootstrapWithRetries keeps bootstrapping the reporter with exponential backoff according to
+// the retry policy in the config. Each failed attempt raises the reporter's health status rather
+// than terminating the process, so that the orchestration layer can decide what to do.
+func (r *Reporter) bootstrapWithRetries(skipBlockSubscription bool) error {
 	// if we are exiting, we need to cancel this process
 	ctx, cancel := r.reporterQuitCtx()
 	defer cancel()
+
+	// 0 attempts means retrying until bootstrapping succeeds
+	maxAttempts := r.Cfg.BootstrapMaxAttempts
+	maxAttemptsStr := fmt.Sprintf("%d", maxAttempts)
+	if r.Cfg.BootstrapRetryForever {
+		maxAttempts = 0
+		maxAttemptsStr = "unlimited"
+	}
+
 	if err := retry.Do(func() error {
 		return r.bootstrap(skipBlockSubscription)
 	},
 		retry.Context(ctx),
-		bootstrapAttemptsAtt,
-		bootstrapRetryInterval,
+		retry.Attempts(maxAttempts),
+		retry.Delay(r.Cfg.BootstrapRetryInitialDelay),
+		retry.MaxDelay(r.Cfg.BootstrapRetryMaxDelay),
+		retry.MaxJitter(r.Cfg.BootstrapRetryMaxJitter),
 		bootstrapDelayType,
 		bootstrapErrReportType, retry.OnRetry(func(n uint, err error) {
-			r.logger.Warnf("Failed to bootstap reporter: %v. Attempt: %d, Max attempts: %d", err, n+1, bootstrapAttempts)
+			r.setHealth(HealthBootstrapRetrying)
+			r.metrics.FailedBootstrapAttemptsCounter.Inc()
+			r.logger.Warnf("Failed to bootstap reporter: %v. Attempt: %d, Max attempts: %s", err, n+1, maxAttemptsStr)
 		})); err != nil {
 
 		if errors.Is(err, context.Canceled) {
 			// context was cancelled we do not need to anything more, app is quiting
-			return
+			return err
 		}
 
-		// we failed to bootstrap multiple time, we should panic as something unexpected is happening.
-		r.logger.Fatalf("Failed to bootstrap reporter: %v after %d attempts", err, bootstrapAttempts)
+		// we failed to bootstrap multiple time, something unexpected is happening.
+		// Leave it to the orchestration layer to decide whether to restart the reporter.
+		r.setHealth(HealthBootstrapFailed)
+		r.logger.Errorf("Failed to bootstrap reporter: %v after %s attempts", err, maxAttemptsStr)
+		return err
 	}
+
+	r.setHealth(HealthOK)
+	return nil
 }
 
 // initBTCCache fetches the blocks since T-k-w in the BTC canonical chain
@@ -270,7 +299,8 @@ func (r *Reporter) waitUntilBTCSync() error {
 
 		// periodically check if BTC catches up with BBN.
 		// When BTC catches up, break and continue the bootstrapping process
-		ticker := time.NewTicker(5 * time.Second) // TODO: parameterise the polling interval
+		ticker := time.NewTicker(r.Cfg.BTCSyncPollInterval)
+		defer ticker.Stop()
 		for range ticker.C {
 			_, btcLatestBlockHeight, err = r.btcClient.GetBestBlock()
 			if err != nil {
@@ -1,5 +1,325 @@
 package reporter
 
-// TODO: unit tests on Bootstrap by using mocks
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
 
-// TODO: integration tests on Bootstrap
+	"github.com/babylonchain/babylon/btctxformatter"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bbntypes "github.com/babylonchain/babylon/types"
+	btclctypes "github.com/babylonchain/babylon/x/btclightclient/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+	"github.com/babylonchain/vigilante/types"
+)
+
+const (
+	testBTCConfirmationDepth          = 6
+	testCheckpointFinalizationTimeout = 10
+)
+
+// the checkpoint tag of the Babylon txs generated by vdatagen
+var testCheckpointTag = []byte{1, 2, 3, 4}
+
+// testChain is a BTC chain and Babylon's BTC light client following it, which
+// back the mocked BTC and Babylon clients of a reporter
+type testChain struct {
+	sync.Mutex
+	// the best chain of the BTC node, starting from the base header of the light client
+	blocks []*types.IndexedBlock
+	// the heights of the headers of the light client
+	lcHeights map[chainhash.Hash]uint64
+	lcTip     *types.IndexedBlock
+	// the number of headers inserted into the light client
+	numInserted int
+}
+
+// newTestChain generates a BTC chain of numBlocks blocks, whose first
+// numOnBabylon blocks are on Babylon's BTC light client
+func newTestChain(r *rand.Rand, numBlocks, numOnBabylon int) *testChain {
+	genesis, _ := vdatagen.GenRandomBlock(r, 0, nil)
+	blocks := []*types.IndexedBlock{types.NewIndexedBlockFromMsgBlock(int32(r.Intn(1000)+1), genesis)}
+	c := &testChain{blocks: blocks, lcHeights: map[chainhash.Hash]uint64{}}
+	c.extend(r, numBlocks-1)
+	for _, ib := range c.blocks[:numOnBabylon] {
+		c.lcHeights[ib.BlockHash()] = uint64(ib.Height)
+		c.lcTip = ib
+	}
+	return c
+}
+
+// extend mines n blocks on top of the BTC chain, and returns them
+func (c *testChain) extend(r *rand.Rand, n int) []*types.IndexedBlock {
+	ibs := make([]*types.IndexedBlock, 0, n)
+	for i := 0; i < n; i++ {
+		ibs = append(ibs, c.mine(r, 0))
+	}
+	return ibs
+}
+
+// mine mines a block with the given number of Babylon txs on top of the BTC
+// chain, where 2 Babylon txs carry both segments of a checkpoint
+func (c *testChain) mine(r *rand.Rand, numBabylonTxs int) *types.IndexedBlock {
+	c.Lock()
+	defer c.Unlock()
+	tip := c.blocks[len(c.blocks)-1]
+	prevHash := tip.BlockHash()
+	block, _ := vdatagen.GenRandomBlock(r, numBabylonTxs, &prevHash)
+	ib := types.NewIndexedBlockFromMsgBlock(tip.Height+1, block)
+	c.blocks = append(c.blocks, ib)
+	return ib
+}
+
+func (c *testChain) tip() *types.IndexedBlock {
+	c.Lock()
+	defer c.Unlock()
+	return c.blocks[len(c.blocks)-1]
+}
+
+func (c *testChain) lightClientTip() *types.IndexedBlock {
+	c.Lock()
+	defer c.Unlock()
+	return c.lcTip
+}
+
+func (c *testChain) numInsertedHeaders() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.numInserted
+}
+
+func (c *testChain) blockAt(height uint64) (*types.IndexedBlock, error) {
+	c.Lock()
+	defer c.Unlock()
+	base := uint64(c.blocks[0].Height)
+	if height < base || height-base >= uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("no BTC block at height %d", height)
+	}
+	return c.blocks[height-base], nil
+}
+
+// insertHeaders inserts the headers into the light client as Babylon does,
+// i.e., each header has to extend a header of the light client
+func (c *testChain) insertHeaders(msg *btclctypes.MsgInsertHeaders) error {
+	c.Lock()
+	defer c.Unlock()
+	for _, headerBytes := range msg.Headers {
+		header := headerBytes.ToBlockHeader()
+		prevHeight, ok := c.lcHeights[header.PrevBlock]
+		if !ok {
+			return fmt.Errorf("header %s does not extend the light client", header.BlockHash())
+		}
+		if _, ok := c.lcHeights[header.BlockHash()]; ok {
+			return fmt.Errorf("header %s is already on the light client", header.BlockHash())
+		}
+		c.lcHeights[header.BlockHash()] = prevHeight + 1
+		if prevHeight+1 > uint64(c.lcTip.Height) {
+			c.lcTip = types.NewIndexedBlock(int32(prevHeight+1), header, nil)
+		}
+		c.numInserted++
+	}
+	return nil
+}
+
+func headerInfo(ib *types.IndexedBlock) *btclctypes.BTCHeaderInfoResponse {
+	hash := ib.BlockHash()
+	return &btclctypes.BTCHeaderInfoResponse{
+		Height:  uint64(ib.Height),
+		HashHex: bbntypes.NewBTCHeaderHashBytesFromChainhash(&hash).MarshalHex(),
+	}
+}
+
+// mockClients backs the mocked clients with the test chain. Expectations set
+// before take precedence.
+func (c *testChain) mockClients(btcClient *mocks.MockBTCClient, babylonClient *MockBabylonClient) {
+	btcClient.EXPECT().GetBestBlock().DoAndReturn(func() (*chainhash.Hash, uint64, error) {
+		tip := c.tip()
+		hash := tip.BlockHash()
+		return &hash, uint64(tip.Height), nil
+	}).AnyTimes()
+	btcClient.EXPECT().GetBlockHeaderByHeight(gomock.Any()).DoAndReturn(func(height uint64) (*wire.BlockHeader, error) {
+		ib, err := c.blockAt(height)
+		if err != nil {
+			return nil, err
+		}
+		return ib.Header, nil
+	}).AnyTimes()
+	btcClient.EXPECT().GetBlockByHash(gomock.Any()).DoAndReturn(func(hash *chainhash.Hash) (*types.IndexedBlock, *wire.MsgBlock, error) {
+		c.Lock()
+		defer c.Unlock()
+		for _, ib := range c.blocks {
+			if ib.BlockHash() == *hash {
+				return ib, ib.MsgBlock(), nil
+			}
+		}
+		return nil, nil, fmt.Errorf("no BTC block %s", hash)
+	}).AnyTimes()
+	btcClient.EXPECT().FindTailBlocksByHeight(gomock.Any()).DoAndReturn(func(height uint64) ([]*types.IndexedBlock, error) {
+		if _, err := c.blockAt(height); err != nil {
+			return nil, err
+		}
+		c.Lock()
+		defer c.Unlock()
+		return append([]*types.IndexedBlock{}, c.blocks[height-uint64(c.blocks[0].Height):]...), nil
+	}).AnyTimes()
+	btcClient.EXPECT().MustSubscribeBlocks().AnyTimes()
+
+	babylonClient.EXPECT().MustGetAddr().Return("").AnyTimes()
+	babylonClient.EXPECT().BTCBaseHeader().DoAndReturn(func() (*btclctypes.QueryBaseHeaderResponse, error) {
+		c.Lock()
+		defer c.Unlock()
+		return &btclctypes.QueryBaseHeaderResponse{Header: headerInfo(c.blocks[0])}, nil
+	}).AnyTimes()
+	babylonClient.EXPECT().BTCHeaderChainTip().DoAndReturn(func() (*btclctypes.QueryTipResponse, error) {
+		return &btclctypes.QueryTipResponse{Header: headerInfo(c.lightClientTip())}, nil
+	}).AnyTimes()
+	babylonClient.EXPECT().ContainsBTCBlock(gomock.Any()).DoAndReturn(func(hash *chainhash.Hash) (*btclctypes.QueryContainsBytesResponse, error) {
+		c.Lock()
+		defer c.Unlock()
+		_, ok := c.lcHeights[*hash]
+		return &btclctypes.QueryContainsBytesResponse{Contains: ok}, nil
+	}).AnyTimes()
+	babylonClient.EXPECT().InsertHeaders(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, msg *btclctypes.MsgInsertHeaders) (*pv.RelayerTxResponse, error) {
+		if err := c.insertHeaders(msg); err != nil {
+			return nil, err
+		}
+		return &pv.RelayerTxResponse{Code: 0}, nil
+	}).AnyTimes()
+}
+
+func newTestReporterConfig() *config.ReporterConfig {
+	cfg := config.DefaultReporterConfig()
+	cfg.BootstrapRetryInitialDelay = time.Millisecond
+	cfg.BootstrapRetryMaxDelay = 10 * time.Millisecond
+	cfg.BootstrapRetryMaxJitter = time.Millisecond
+	cfg.BootstrapMaxAttempts = 3
+	cfg.GapFillThreshold = 0
+	return &cfg
+}
+
+func newTestReporter(t *testing.T, cfg *config.ReporterConfig) (*mocks.MockBTCClient, *MockBabylonClient, *Reporter) {
+	ctrl := gomock.NewController(t)
+	btcClient := mocks.NewMockBTCClient(ctrl)
+	babylonClient := NewMockBabylonClient(ctrl)
+	return btcClient, babylonClient, &Reporter{
+		Cfg:                           cfg,
+		logger:                        zap.NewNop().Sugar(),
+		btcClient:                     btcClient,
+		babylonClient:                 babylonClient,
+		retrySleepTime:                time.Millisecond,
+		maxRetrySleepTime:             10 * time.Millisecond,
+		CheckpointCache:               types.NewCheckpointCache(testCheckpointTag, btctxformatter.CurrentVersion),
+		ckptRetryQueue:                newCkptRetryQueue(time.Millisecond, 10*time.Millisecond),
+		reorgList:                     newReorgList(),
+		btcConfirmationDepth:          testBTCConfirmationDepth,
+		checkpointFinalizationTimeout: testCheckpointFinalizationTimeout,
+		metrics:                       metrics.NewReporterMetrics(),
+		quit:                          make(chan struct{}),
+	}
+}
+
+// FuzzBootstrap tests that bootstrapping catches up the light client with the
+// BTC chain, without submitting the headers the light client already has
+func FuzzBootstrap(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		numBlocks := r.Intn(200) + 1
+		numOnBabylon := r.Intn(numBlocks) + 1
+		chain := newTestChain(r, numBlocks, numOnBabylon)
+		btcClient, babylonClient, reporter := newTestReporter(t, newTestReporterConfig())
+		chain.mockClients(btcClient, babylonClient)
+
+		require.NoError(t, reporter.bootstrapWithRetries(false))
+		require.Equal(t, HealthOK, reporter.Health())
+		require.Equal(t, chain.tip().BlockHash(), chain.lightClientTip().BlockHash())
+		require.Equal(t, numBlocks-numOnBabylon, chain.numInsertedHeaders())
+		require.Equal(t, chain.tip().BlockHash(), reporter.btcCache.Tip().BlockHash())
+	})
+}
+
+func TestStartFailsWhenBootstrapFails(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	chain := newTestChain(r, 50, 20)
+	cfg := newTestReporterConfig()
+	btcClient, babylonClient, reporter := newTestReporter(t, cfg)
+
+	// the BTC node is unreachable during all attempts of the first start
+	errUnreachable := errors.New("BTC node is unreachable")
+	btcClient.EXPECT().GetBestBlock().Return(nil, uint64(0), errUnreachable).Times(int(cfg.BootstrapMaxAttempts))
+	chain.mockClients(btcClient, babylonClient)
+
+	// the reporter gives up without starting the handlers, which would read
+	// the block events
+	err := reporter.Start()
+	require.ErrorIs(t, err, errUnreachable)
+	require.Equal(t, HealthBootstrapFailed, reporter.Health())
+	require.Equal(t, float64(HealthBootstrapFailed), testutil.ToFloat64(reporter.metrics.HealthStatusGauge))
+	require.Equal(t, float64(cfg.BootstrapMaxAttempts), testutil.ToFloat64(reporter.metrics.FailedBootstrapAttemptsCounter))
+	require.Zero(t, chain.numInsertedHeaders())
+
+	// the reporter can be started again once the BTC node is back
+	blockEventChan := make(chan *types.BlockEvent)
+	btcClient.EXPECT().BlockEventChan().Return(blockEventChan).AnyTimes()
+	require.NoError(t, reporter.Start())
+	defer func() {
+		reporter.Stop()
+		reporter.WaitForShutdown()
+	}()
+	require.Equal(t, HealthOK, reporter.Health())
+	require.Equal(t, float64(HealthOK), testutil.ToFloat64(reporter.metrics.HealthStatusGauge))
+	require.Equal(t, chain.tip().BlockHash(), chain.lightClientTip().BlockHash())
+}
+
+func TestBootstrapHealthTransitions(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	chain := newTestChain(r, 50, 20)
+	cfg := newTestReporterConfig()
+	cfg.BootstrapMaxAttempts = 5
+	btcClient, babylonClient, reporter := newTestReporter(t, cfg)
+
+	// record the health status at the start of each attempt, the first
+	// attempts failing
+	numFailures := int(cfg.BootstrapMaxAttempts) - 1
+	var healthAtAttempts []HealthStatus
+	btcClient.EXPECT().GetBestBlock().DoAndReturn(func() (*chainhash.Hash, uint64, error) {
+		healthAtAttempts = append(healthAtAttempts, reporter.Health())
+		return nil, 0, errors.New("BTC node is unreachable")
+	}).Times(numFailures)
+	btcClient.EXPECT().GetBestBlock().DoAndReturn(func() (*chainhash.Hash, uint64, error) {
+		healthAtAttempts = append(healthAtAttempts, reporter.Health())
+		tip := chain.tip()
+		hash := tip.BlockHash()
+		return &hash, uint64(tip.Height), nil
+	})
+	chain.mockClients(btcClient, babylonClient)
+
+	require.Equal(t, HealthStarting, reporter.Health())
+	require.NoError(t, reporter.bootstrapWithRetries(false))
+
+	// the reporter is starting during the first attempt, retrying after the
+	// first failed attempt, and OK once an attempt succeeds
+	require.Len(t, healthAtAttempts, numFailures+1)
+	require.Equal(t, HealthStarting, healthAtAttempts[0])
+	for _, health := range healthAtAttempts[1:] {
+		require.Equal(t, HealthBootstrapRetrying, health)
+	}
+	require.Equal(t, HealthOK, reporter.Health())
+	require.Equal(t, float64(HealthOK), testutil.ToFloat64(reporter.metrics.HealthStatusGauge))
+	require.Equal(t, float64(numFailures), testutil.ToFloat64(reporter.metrics.FailedBootstrapAttemptsCounter))
+}
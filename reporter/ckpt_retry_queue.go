@@ -0,0 +1,165 @@
+package reporter
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+type queuedCkpt struct {
+	ckpt        *types.Ckpt
+	attempts    uint
+	nextAttempt time.Time
+	lastErr     error
+}
+
+// key identifies a checkpoint by the pair of BTC txs carrying its segments,
+// so that the same checkpoint found again (e.g., after re-bootstrapping)
+// is not queued twice
+func (q *queuedCkpt) key() string {
+	return ckptKey(q.ckpt)
+}
+
+func ckptKey(ckpt *types.Ckpt) string {
+	var key string
+	for _, seg := range ckpt.Segments {
+		key += seg.AssocBlock.Txs[seg.TxIdx].Hash().String()
+	}
+	return key
+}
+
+// ckptRetryQueue keeps matched checkpoints until Babylon either accepts them,
+// reports them as duplicated, or rejects them with a permanent error.
+// Unlike the checkpoint cache, it survives re-bootstrapping of the reporter, and
+// restarts if it is backed by a store.
+// NOTE: This is not generic data structure, and must be used with conjunction with
+// reporter
+type ckptRetryQueue struct {
+	sync.Mutex
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	entries    map[string]*queuedCkpt
+	// store persists the queued checkpoints until they are done, if not nil
+	store *CkptRetryStore
+}
+
+func newCkptRetryQueue(minBackoff, maxBackoff time.Duration) *ckptRetryQueue {
+	return &ckptRetryQueue{
+		minBackoff: minBackoff,
+		maxBackoff: maxBackoff,
+		entries:    map[string]*queuedCkpt{},
+	}
+}
+
+// restore backs the queue with the given store, and adds the checkpoints
+// persisted in it to the queue. It returns the number of restored checkpoints.
+func (q *ckptRetryQueue) restore(store *CkptRetryStore) (int, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	entries, err := store.load()
+	if err != nil {
+		return 0, err
+	}
+	q.store = store
+	for _, entry := range entries {
+		q.entries[entry.key()] = entry
+	}
+	return len(entries), nil
+}
+
+// push adds a newly matched checkpoint to the queue. The checkpoint is
+// immediately due for submission. Pushing a checkpoint that is already queued
+// is a no-op, and returns false. The checkpoint is queued even if it fails to
+// be persisted.
+func (q *ckptRetryQueue) push(ckpt *types.Ckpt) (bool, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	key := ckptKey(ckpt)
+	if _, ok := q.entries[key]; ok {
+		return false, nil
+	}
+	entry := &queuedCkpt{ckpt: ckpt}
+	q.entries[key] = entry
+	if q.store != nil {
+		return true, q.store.put(entry)
+	}
+	return true, nil
+}
+
+// popReady removes and returns all checkpoints whose next attempt is due at
+// the given time, ordered by epoch number as Babylon requires checkpoints
+// to be submitted in order. The checkpoints stay in the store until they are
+// either rescheduled or done.
+func (q *ckptRetryQueue) popReady(now time.Time) []*queuedCkpt {
+	q.Lock()
+	defer q.Unlock()
+
+	var ready []*queuedCkpt
+	for key, entry := range q.entries {
+		if entry.nextAttempt.After(now) {
+			continue
+		}
+		ready = append(ready, entry)
+		delete(q.entries, key)
+	}
+
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].ckpt.Epoch < ready[j].ckpt.Epoch
+	})
+
+	return ready
+}
+
+// reschedule puts back a checkpoint whose submission failed with a transient
+// error. The delay before the next attempt doubles with every failed attempt,
+// starting from minBackoff and capped at maxBackoff.
+func (q *ckptRetryQueue) reschedule(entry *queuedCkpt, now time.Time, err error) error {
+	q.Lock()
+	defer q.Unlock()
+
+	entry.attempts++
+	entry.lastErr = err
+	entry.nextAttempt = now.Add(q.backoff(entry.attempts))
+	q.entries[entry.key()] = entry
+	if q.store != nil {
+		return q.store.putSchedule(entry)
+	}
+	return nil
+}
+
+// done removes a popped checkpoint that Babylon either accepted, reported as
+// duplicated, or rejected with a permanent error from the store
+func (q *ckptRetryQueue) done(entry *queuedCkpt) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.store != nil {
+		return q.store.delete(entry.key())
+	}
+	return nil
+}
+
+func (q *ckptRetryQueue) backoff(attempts uint) time.Duration {
+	delay := q.minBackoff
+	for i := uint(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= q.maxBackoff {
+			return q.maxBackoff
+		}
+	}
+	if delay > q.maxBackoff {
+		return q.maxBackoff
+	}
+	return delay
+}
+
+func (q *ckptRetryQueue) size() int {
+	q.Lock()
+	defer q.Unlock()
+
+	return len(q.entries)
+}
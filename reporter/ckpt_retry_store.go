@@ -0,0 +1,201 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/babylonchain/babylon/btctxformatter"
+	"github.com/btcsuite/btcd/wire"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+var (
+	ckptRetryBucket = []byte("ckpt-retry-queue")
+	// the schedule of each checkpoint is kept apart from its segments, so
+	// that rescheduling a checkpoint does not write its blocks again
+	ckptRetryScheduleBucket = []byte("ckpt-retry-schedule")
+)
+
+// CkptRetryStore persists the checkpoints in the retry queue of the reporter in
+// a bbolt database, so that a checkpoint matched before a restart is submitted
+// after it, even if its BTC blocks are no longer scanned by bootstrapping
+type CkptRetryStore struct {
+	db *bolt.DB
+}
+
+// storedCkptSegment is a checkpoint segment as persisted in the store
+type storedCkptSegment struct {
+	Data  []byte `json:"data"`
+	Index uint8  `json:"index"`
+	TxIdx int    `json:"tx_idx"`
+	// the block containing the segment, in the Bitcoin wire format, needed to
+	// generate the Merkle proof of the tx carrying the segment
+	Block       []byte `json:"block"`
+	BlockHeight int32  `json:"block_height"`
+}
+
+// storedCkpt is a queued checkpoint as persisted in the store
+type storedCkpt struct {
+	Epoch    uint64               `json:"epoch"`
+	Segments []*storedCkptSegment `json:"segments"`
+}
+
+// storedCkptSchedule is the submission schedule of a queued checkpoint as
+// persisted in the store
+type storedCkptSchedule struct {
+	Attempts    uint      `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// NewCkptRetryStore opens, or creates, the checkpoint retry queue database at the given path
+func NewCkptRetryStore(path string) (*CkptRetryStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// a timeout avoids blocking forever if another process holds the database
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint retry store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(ckptRetryBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(ckptRetryScheduleBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialise checkpoint retry store %s: %w", path, err)
+	}
+	return &CkptRetryStore{db: db}, nil
+}
+
+// put stores the given queued checkpoint along with its schedule, overwriting
+// any previous version
+func (s *CkptRetryStore) put(entry *queuedCkpt) error {
+	stored := &storedCkpt{Epoch: entry.ckpt.Epoch}
+	for _, seg := range entry.ckpt.Segments {
+		var buf bytes.Buffer
+		if err := seg.AssocBlock.MsgBlock().Serialize(&buf); err != nil {
+			return err
+		}
+		stored.Segments = append(stored.Segments, &storedCkptSegment{
+			Data:        seg.Data,
+			Index:       seg.Index,
+			TxIdx:       seg.TxIdx,
+			Block:       buf.Bytes(),
+			BlockHeight: seg.AssocBlock.Height,
+		})
+	}
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	schedule, err := encodeCkptSchedule(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(ckptRetryBucket).Put([]byte(entry.key()), value); err != nil {
+			return err
+		}
+		return tx.Bucket(ckptRetryScheduleBucket).Put([]byte(entry.key()), schedule)
+	})
+}
+
+// putSchedule stores the schedule of the given queued checkpoint, whose
+// segments are already stored
+func (s *CkptRetryStore) putSchedule(entry *queuedCkpt) error {
+	schedule, err := encodeCkptSchedule(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(ckptRetryScheduleBucket).Put([]byte(entry.key()), schedule)
+	})
+}
+
+func encodeCkptSchedule(entry *queuedCkpt) ([]byte, error) {
+	return json.Marshal(&storedCkptSchedule{
+		Attempts:    entry.attempts,
+		NextAttempt: entry.nextAttempt,
+	})
+}
+
+// delete removes the checkpoint with the given key
+func (s *CkptRetryStore) delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(ckptRetryBucket).Delete([]byte(key)); err != nil {
+			return err
+		}
+		return tx.Bucket(ckptRetryScheduleBucket).Delete([]byte(key))
+	})
+}
+
+// load returns all the stored checkpoints. The returned checkpoints are due for
+// submission at their stored next attempt, or immediately if they have never
+// been rescheduled.
+func (s *CkptRetryStore) load() ([]*queuedCkpt, error) {
+	var entries []*queuedCkpt
+	err := s.db.View(func(tx *bolt.Tx) error {
+		schedules := tx.Bucket(ckptRetryScheduleBucket)
+		return tx.Bucket(ckptRetryBucket).ForEach(func(k, v []byte) error {
+			entry, err := decodeStoredCkpt(v)
+			if err != nil {
+				return fmt.Errorf("invalid checkpoint %x: %w", k, err)
+			}
+			if schedule := schedules.Get(k); schedule != nil {
+				var stored storedCkptSchedule
+				if err := json.Unmarshal(schedule, &stored); err != nil {
+					return fmt.Errorf("invalid schedule of checkpoint %x: %w", k, err)
+				}
+				entry.attempts = stored.Attempts
+				entry.nextAttempt = stored.NextAttempt
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func decodeStoredCkpt(value []byte) (*queuedCkpt, error) {
+	var stored storedCkpt
+	if err := json.Unmarshal(value, &stored); err != nil {
+		return nil, err
+	}
+	if len(stored.Segments) != btctxformatter.NumberOfParts {
+		return nil, fmt.Errorf("incorrect number of segments: want %d, got %d", btctxformatter.NumberOfParts, len(stored.Segments))
+	}
+	ckpt := &types.Ckpt{Epoch: stored.Epoch}
+	for _, seg := range stored.Segments {
+		block := &wire.MsgBlock{}
+		if err := block.Deserialize(bytes.NewReader(seg.Block)); err != nil {
+			return nil, err
+		}
+		if seg.TxIdx < 0 || seg.TxIdx >= len(block.Transactions) {
+			return nil, fmt.Errorf("tx index %d is out of the %d txs of block %v", seg.TxIdx, len(block.Transactions), block.BlockHash())
+		}
+		ckpt.Segments = append(ckpt.Segments, &types.CkptSegment{
+			BabylonData: &btctxformatter.BabylonData{Data: seg.Data, Index: seg.Index},
+			TxIdx:       seg.TxIdx,
+			AssocBlock:  types.NewIndexedBlockFromMsgBlock(seg.BlockHeight, block),
+		})
+	}
+	return &queuedCkpt{ckpt: ckpt}, nil
+}
+
+// Close closes the checkpoint retry queue database
+func (s *CkptRetryStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,126 @@
+package reporter_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	btcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
+	pv "github.com/cosmos/relayer/v2/relayer/provider"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/reporter"
+	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
+	"github.com/babylonchain/vigilante/testutil/mocks"
+	"github.com/babylonchain/vigilante/types"
+)
+
+// testCkptRetryBackoff is the delay before submitting a checkpoint again
+// after a transient error
+const testCkptRetryBackoff = 200 * time.Millisecond
+
+// newMockReporterWithStore creates a reporter whose retry queue is persisted in
+// the store at the given path
+func newMockReporterWithStore(t *testing.T, path string) (*reporter.MockBabylonClient, *reporter.Reporter, *reporter.CkptRetryStore) {
+	cfg := config.DefaultConfig()
+	logger, err := cfg.CreateLogger()
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	mockBabylonClient := reporter.NewMockBabylonClient(ctrl)
+	mockBabylonClient.EXPECT().GetConfig().Return(&cfg.Babylon).AnyTimes()
+	mockBabylonClient.EXPECT().BTCCheckpointParams().Return(
+		&btcctypes.QueryParamsResponse{Params: btcctypes.DefaultParams()}, nil).AnyTimes()
+	mockReporter, err := reporter.New(
+		&cfg.Reporter,
+		logger,
+		mocks.NewMockBTCClient(ctrl),
+		mockBabylonClient,
+		testCkptRetryBackoff,
+		testCkptRetryBackoff,
+		metrics.NewReporterMetrics(),
+	)
+	require.NoError(t, err)
+
+	store, err := reporter.NewCkptRetryStore(path)
+	require.NoError(t, err)
+	require.NoError(t, mockReporter.SetCkptRetryStore(store))
+	return mockBabylonClient, mockReporter, store
+}
+
+// FuzzCkptRetryStore fuzz tests the persistence of the retry queue across restarts
+// - Data: a number of random blocks, with or without Babylon txs
+// - Tested property: checkpoints failing with a transient error are submitted again
+// after a restart, with the same proofs and not before their backoff expires,
+// until Babylon accepts them
+func FuzzCkptRetryStore(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		path := filepath.Join(t.TempDir(), "reporter-ckpt-retry-queue.db")
+
+		numBlocks := datagen.RandomInt(r, 100)
+		blocks, _, rawCkpts := vdatagen.GenRandomBlockchainWithBabylonTx(r, numBlocks, 0.3, 0.4)
+		ibs := []*types.IndexedBlock{}
+		numCkpts := 0
+		for i, block := range blocks {
+			ibs = append(ibs, types.NewIndexedBlockFromMsgBlock(r.Int31(), block))
+			if rawCkpts[i] != nil {
+				numCkpts++
+			}
+		}
+
+		// Babylon is unreachable, so the matched checkpoints stay in the retry queue
+		var failedMsgs []*btcctypes.MsgInsertBTCSpvProof
+		mockBabylonClient, mockReporter, store := newMockReporterWithStore(t, path)
+		mockBabylonClient.EXPECT().InsertBTCSpvProof(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, msg *btcctypes.MsgInsertBTCSpvProof) (*pv.RelayerTxResponse, error) {
+				failedMsgs = append(failedMsgs, msg)
+				return nil, errors.New("connection refused")
+			}).Times(numCkpts)
+		failedAt := time.Now()
+		_, numMatchedCkpts, err := mockReporter.ProcessCheckpoints("", ibs)
+		require.NoError(t, err)
+		require.Equal(t, numCkpts, numMatchedCkpts)
+		require.Equal(t, numCkpts, mockReporter.NumPendingCheckpoints())
+		require.NoError(t, store.Close())
+
+		// the restarted reporter submits the same checkpoints without scanning their blocks again
+		// once their backoff persisted before the restart expires
+		var submittedMsgs []*btcctypes.MsgInsertBTCSpvProof
+		var submittedAt []time.Time
+		mockBabylonClient, mockReporter, store = newMockReporterWithStore(t, path)
+		require.Equal(t, numCkpts, mockReporter.NumPendingCheckpoints())
+		mockBabylonClient.EXPECT().InsertBTCSpvProof(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, msg *btcctypes.MsgInsertBTCSpvProof) (*pv.RelayerTxResponse, error) {
+				submittedMsgs = append(submittedMsgs, msg)
+				submittedAt = append(submittedAt, time.Now())
+				return &pv.RelayerTxResponse{Code: 0}, nil
+			}).Times(numCkpts)
+		deadline := time.Now().Add(10 * testCkptRetryBackoff)
+		for mockReporter.NumPendingCheckpoints() > 0 {
+			require.True(t, time.Now().Before(deadline), "the restored checkpoints are not submitted")
+			_, _, err = mockReporter.ProcessCheckpoints("", nil)
+			require.NoError(t, err)
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.ElementsMatch(t, failedMsgs, submittedMsgs)
+		for _, at := range submittedAt {
+			require.False(t, at.Before(failedAt.Add(testCkptRetryBackoff)))
+		}
+		require.NoError(t, store.Close())
+
+		// the accepted checkpoints are not restored again
+		_, mockReporter, store = newMockReporterWithStore(t, path)
+		require.Zero(t, mockReporter.NumPendingCheckpoints())
+		require.NoError(t, store.Close())
+	})
+}
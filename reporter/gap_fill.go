@@ -0,0 +1,220 @@
+package reporter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bbntypes "github.com/babylonchain/babylon/types"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/babylonchain/vigilante/types"
+)
+
+// gapFillProgress is the last BTC header submitted by the gap-fill mode
+type gapFillProgress struct {
+	Height uint64 `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// gapFill catches up Babylon's BTC light client with the BTC node if it falls behind by
+// more than gap_fill_threshold headers. Rather than loading all missing blocks into the
+// BTC cache, it pages headers from the BTC node and submits them in chunks of
+// max_headers_in_msg headers, so that memory usage is bounded by a single chunk.
+// Catch-up stops k+w blocks below the BTC tip, leaving the rest to the normal bootstrap
+// that also submits checkpoints. Checkpoints in the skipped blocks are not submitted.
+// Progress is recorded after each chunk, so an interrupted catch-up resumes where it stopped.
+func (r *Reporter) gapFill() error {
+	if r.Cfg.GapFillThreshold == 0 {
+		return nil
+	}
+
+	_, btcTipHeight, err := r.btcClient.GetBestBlock()
+	if err != nil {
+		return fmt.Errorf("failed to get the BTC tip: %w", err)
+	}
+	tipRes, err := r.babylonClient.BTCHeaderChainTip()
+	if err != nil {
+		return fmt.Errorf("failed to get the tip of Babylon's BTC light client: %w", err)
+	}
+	bbnTipHeight := tipRes.Header.Height
+
+	if btcTipHeight <= bbnTipHeight+r.Cfg.GapFillThreshold || btcTipHeight < r.btcConfirmationDepth+r.checkpointFinalizationTimeout {
+		return nil
+	}
+	targetHeight := btcTipHeight - r.btcConfirmationDepth - r.checkpointFinalizationTimeout
+
+	startHeight, err := r.gapFillStartHeight()
+	if err != nil {
+		return err
+	}
+	if startHeight > targetHeight {
+		return nil
+	}
+
+	r.logger.Infof("Babylon's BTC light client (height %d) falls far behind BTC (height %d), streaming headers from height %d to %d",
+		bbnTipHeight, btcTipHeight, startHeight, targetHeight)
+
+	signer := r.babylonClient.MustGetAddr()
+	var prevHash *chainhash.Hash
+	for pageStart := startHeight; pageStart <= targetHeight; pageStart += uint64(r.Cfg.MaxHeadersInMsg) {
+		if r.ShuttingDown() {
+			return errors.New("reporter is shutting down")
+		}
+		if err := r.waitUntilAlarmCleared(); err != nil {
+			return err
+		}
+
+		pageEnd := min(pageStart+uint64(r.Cfg.MaxHeadersInMsg)-1, targetHeight)
+		page := make([]*types.IndexedBlock, 0, pageEnd-pageStart+1)
+		for height := pageStart; height <= pageEnd; height++ {
+			header, err := r.btcClient.GetBlockHeaderByHeight(height)
+			if err != nil {
+				return fmt.Errorf("failed to get BTC header at height %d: %w", height, err)
+			}
+			// the BTC node might reorg while paging
+			if prevHash != nil && !header.PrevBlock.IsEqual(prevHash) {
+				return fmt.Errorf("BTC header at height %d does not extend the previous header, BTC might have reorged", height)
+			}
+			hash := header.BlockHash()
+			prevHash = &hash
+			page = append(page, types.NewIndexedBlock(int32(height), header, nil))
+		}
+
+		// headers might already be on Babylon, e.g., below a fork of its BTC light
+		// client, or submitted by another reporter meanwhile
+		headerMsgs, err := r.getHeaderMsgsToSubmit(signer, page)
+		if err != nil {
+			return fmt.Errorf("failed to find headers to submit: %w", err)
+		}
+		for _, msg := range headerMsgs {
+			if err := r.submitHeaderMsgs(msg); err != nil {
+				return err
+			}
+		}
+
+		if err := r.saveGapFillProgress(&gapFillProgress{Height: pageEnd, Hash: prevHash.String()}); err != nil {
+			r.logger.Warnf("Failed to save gap-fill progress at height %d: %v", pageEnd, err)
+		}
+		r.metrics.GapFillRemainingHeadersGauge.Set(float64(targetHeight - pageEnd))
+	}
+
+	if err := os.Remove(r.Cfg.GapFillProgressFile); err != nil && !os.IsNotExist(err) {
+		r.logger.Warnf("Failed to remove gap-fill progress file: %v", err)
+	}
+	r.logger.Infof("Successfully caught up Babylon's BTC light client with BTC up to height %d", targetHeight)
+	return nil
+}
+
+// waitUntilAlarmCleared pauses the gap-fill mode while the header witness raises
+// an alarm. Unlike in the normal mode, there are no blocks in the BTC cache yet
+// whose checkpoints could be processed in the meantime.
+func (r *Reporter) waitUntilAlarmCleared() error {
+	if !r.headerSubmissionPaused() {
+		return nil
+	}
+	r.logger.Warnf("Gap-fill mode is paused until the header witness alarm is cleared")
+
+	ticker := time.NewTicker(r.Cfg.BTCSyncPollInterval)
+	defer ticker.Stop()
+	quit := r.quitChan()
+	for r.headerSubmissionPaused() {
+		select {
+		case <-ticker.C:
+		case <-quit:
+			return errors.New("reporter is shutting down")
+		}
+	}
+	r.logger.Infof("The header witness alarm is cleared, resuming gap-fill mode")
+	return nil
+}
+
+// gapFillStartHeight returns the height of the first header to submit in the gap-fill mode.
+// It is right after the highest of the following headers that are both on Babylon's BTC
+// light client and the BTC node's best chain
+// - the last header recorded in the gap-fill progress file
+// - the tip of Babylon's BTC light client
+// - the k-deep header of Babylon's BTC light client, or its base header
+func (r *Reporter) gapFillStartHeight() (uint64, error) {
+	tipRes, err := r.babylonClient.BTCHeaderChainTip()
+	if err != nil {
+		return 0, err
+	}
+	baseRes, err := r.babylonClient.BTCBaseHeader()
+	if err != nil {
+		return 0, err
+	}
+
+	var lastHeight uint64
+	if tipRes.Header.Height >= baseRes.Header.Height+r.btcConfirmationDepth {
+		lastHeight = tipRes.Header.Height - r.btcConfirmationDepth
+	} else {
+		lastHeight = baseRes.Header.Height
+	}
+
+	tipHash, err := bbntypes.NewBTCHeaderHashBytesFromHex(tipRes.Header.HashHex)
+	if err != nil {
+		return 0, err
+	}
+	if tipRes.Header.Height > lastHeight && r.isOnBothChains(tipRes.Header.Height, tipHash.ToChainhash()) {
+		lastHeight = tipRes.Header.Height
+	}
+
+	progress, err := r.loadGapFillProgress()
+	if err != nil {
+		r.logger.Warnf("Failed to load gap-fill progress, ignoring it: %v", err)
+	} else if progress != nil && progress.Height > lastHeight {
+		hash, err := chainhash.NewHashFromStr(progress.Hash)
+		if err == nil && r.isOnBothChains(progress.Height, hash) {
+			r.logger.Infof("Resuming gap-fill from height %d", progress.Height+1)
+			lastHeight = progress.Height
+		}
+	}
+
+	return lastHeight + 1, nil
+}
+
+// isOnBothChains returns whether the header with the given height and hash is both
+// on Babylon's BTC light client and on the BTC node's best chain
+func (r *Reporter) isOnBothChains(height uint64, hash *chainhash.Hash) bool {
+	header, err := r.btcClient.GetBlockHeaderByHeight(height)
+	if err != nil || header.BlockHash() != *hash {
+		return false
+	}
+	res, err := r.babylonClient.ContainsBTCBlock(hash)
+	return err == nil && res.Contains
+}
+
+func (r *Reporter) loadGapFillProgress() (*gapFillProgress, error) {
+	bz, err := os.ReadFile(r.Cfg.GapFillProgressFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var progress gapFillProgress
+	if err := json.Unmarshal(bz, &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// saveGapFillProgress atomically overwrites the gap-fill progress file
+func (r *Reporter) saveGapFillProgress(progress *gapFillProgress) error {
+	bz, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.Cfg.GapFillProgressFile), 0700); err != nil {
+		return err
+	}
+	tmpFile := r.Cfg.GapFillProgressFile + ".tmp"
+	if err := os.WriteFile(tmpFile, bz, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, r.Cfg.GapFillProgressFile)
+}
@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/babylonchain/babylon/testutil/datagen"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	vdatagen "github.com/babylonchain/vigilante/testutil/datagen"
+	"github.com/babylonchain/vigilante/types"
+)
+
+const testGapFillThreshold = 50
+
+func newGapFillTestReporter(t *testing.T, chain *testChain) *Reporter {
+	cfg := newTestReporterConfig()
+	cfg.GapFillThreshold = testGapFillThreshold
+	cfg.GapFillProgressFile = filepath.Join(t.TempDir(), "reporter-gap-fill.json")
+	btcClient, babylonClient, reporter := newTestReporter(t, cfg)
+	chain.mockClients(btcClient, babylonClient)
+	return reporter
+}
+
+// gapFillTarget returns the block up to which the gap-fill mode catches up
+func gapFillTarget(t *testing.T, chain *testChain) *types.IndexedBlock {
+	target, err := chain.blockAt(uint64(chain.tip().Height) - testBTCConfirmationDepth - testCheckpointFinalizationTimeout)
+	require.NoError(t, err)
+	return target
+}
+
+func FuzzGapFill(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		numBlocks := r.Intn(300) + 200
+		numOnBabylon := r.Intn(numBlocks-testGapFillThreshold-testBTCConfirmationDepth-testCheckpointFinalizationTimeout) + 1
+		chain := newTestChain(r, numBlocks, numOnBabylon)
+		reporter := newGapFillTestReporter(t, chain)
+
+		// the light client catches up with the BTC chain up to the target, one
+		// header at a time
+		lcTipHeight := chain.lightClientTip().Height
+		require.NoError(t, reporter.gapFill())
+		target := gapFillTarget(t, chain)
+		require.Equal(t, target.BlockHash(), chain.lightClientTip().BlockHash())
+		require.Equal(t, int(target.Height-lcTipHeight), chain.numInsertedHeaders())
+		require.Zero(t, testutil.ToFloat64(reporter.metrics.GapFillRemainingHeadersGauge))
+		_, err := os.Stat(reporter.Cfg.GapFillProgressFile)
+		require.True(t, os.IsNotExist(err))
+
+		// the light client no longer falls far behind
+		require.NoError(t, reporter.gapFill())
+		require.Equal(t, int(target.Height-lcTipHeight), chain.numInsertedHeaders())
+	})
+}
+
+func TestGapFillSkipsKnownHeaders(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	chain := newTestChain(r, 300, 100)
+
+	// the light client is on a fork shorter than k, so the gap-fill mode starts
+	// below the fork point, from headers the light client already has
+	forkPoint := chain.lightClientTip()
+	prevHash := forkPoint.BlockHash()
+	for i := 0; i < testBTCConfirmationDepth-1; i++ {
+		block, _ := vdatagen.GenRandomBlock(r, 0, &prevHash)
+		prevHash = block.BlockHash()
+		chain.lcTip = types.NewIndexedBlockFromMsgBlock(chain.lcTip.Height+1, block)
+		chain.lcHeights[prevHash] = uint64(chain.lcTip.Height)
+	}
+	reporter := newGapFillTestReporter(t, chain)
+	startHeight, err := reporter.gapFillStartHeight()
+	require.NoError(t, err)
+	require.LessOrEqual(t, startHeight, uint64(forkPoint.Height))
+
+	// only the headers after the fork point are submitted
+	require.NoError(t, reporter.gapFill())
+	target := gapFillTarget(t, chain)
+	require.Equal(t, target.BlockHash(), chain.lightClientTip().BlockHash())
+	require.Equal(t, int(target.Height-forkPoint.Height), chain.numInsertedHeaders())
+}
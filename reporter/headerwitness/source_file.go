@@ -0,0 +1,138 @@
+package headerwitness
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var _ HeaderSource = &FileSource{}
+
+// FileSource is a header source backed by a file of hex-encoded BTC headers,
+// one per line and ordered by height, e.g., exported from a trusted machine.
+// The file is reloaded whenever its modification time changes.
+type FileSource struct {
+	sync.Mutex
+	path        string
+	startHeight int32
+
+	modTime time.Time
+	headers map[chainhash.Hash]*wire.BlockHeader
+	tipHash *chainhash.Hash
+	tipH    int32
+}
+
+func NewFileSource(path string, startHeight int32) (*FileSource, error) {
+	s := &FileSource{
+		path:        path,
+		startHeight: startHeight,
+	}
+	if err := s.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSource) Name() string {
+	return "file:" + s.path
+}
+
+func (s *FileSource) BestTip() (*chainhash.Hash, int32, error) {
+	if err := s.reloadIfChanged(); err != nil {
+		return nil, 0, err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.tipHash == nil {
+		return nil, 0, fmt.Errorf("headers file %s is empty", s.path)
+	}
+	return s.tipHash, s.tipH, nil
+}
+
+func (s *FileSource) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	s.Lock()
+	defer s.Unlock()
+	header, ok := s.headers[*blockHash]
+	if !ok {
+		return nil, fmt.Errorf("header %s is not in headers file %s", blockHash, s.path)
+	}
+	return header, nil
+}
+
+func (s *FileSource) Stop() {}
+
+func (s *FileSource) reloadIfChanged() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat headers file %s: %w", s.path, err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if s.headers != nil && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	headers, tipHash, tipHeight, err := readHeadersFile(s.path, s.startHeight)
+	if err != nil {
+		return err
+	}
+	s.modTime = info.ModTime()
+	s.headers = headers
+	s.tipHash = tipHash
+	s.tipH = tipHeight
+	return nil
+}
+
+// readHeadersFile reads the headers of the file and checks that they form a chain
+func readHeadersFile(path string, startHeight int32) (map[chainhash.Hash]*wire.BlockHeader, *chainhash.Hash, int32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to open headers file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var (
+		headers   = make(map[chainhash.Hash]*wire.BlockHeader)
+		tipHash   *chainhash.Hash
+		tipHeight = startHeight - 1
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		headerBytes, err := hex.DecodeString(line)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("invalid header at height %d in headers file %s: %w", tipHeight+1, path, err)
+		}
+		header := &wire.BlockHeader{}
+		if err := header.Deserialize(bytes.NewReader(headerBytes)); err != nil {
+			return nil, nil, 0, fmt.Errorf("invalid header at height %d in headers file %s: %w", tipHeight+1, path, err)
+		}
+		if tipHash != nil && !header.PrevBlock.IsEqual(tipHash) {
+			return nil, nil, 0, fmt.Errorf("header at height %d in headers file %s does not extend the previous header", tipHeight+1, path)
+		}
+		hash := header.BlockHash()
+		headers[hash] = header
+		tipHash = &hash
+		tipHeight++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to read headers file %s: %w", path, err)
+	}
+	if tipHash == nil {
+		return nil, nil, 0, fmt.Errorf("headers file %s has no headers", path)
+	}
+	return headers, tipHash, tipHeight, nil
+}
@@ -0,0 +1,250 @@
+package headerwitness
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/peer"
+	"github.com/btcsuite/btcd/wire"
+	"go.uber.org/zap"
+)
+
+const (
+	p2pUserAgentName    = "vigilante-header-witness"
+	p2pUserAgentVersion = "0.1.0"
+	p2pDialTimeout      = 30 * time.Second
+	p2pReconnectDelay   = 30 * time.Second
+)
+
+var _ HeaderSource = &P2PSource{}
+
+// p2pHeader is a header received from the peer, together with its height and
+// the cumulative work of its chain above the anchor
+type p2pHeader struct {
+	header *wire.BlockHeader
+	height int32
+	work   *big.Int
+}
+
+// P2PSource is a header source that syncs headers directly from a BTC peer over
+// the P2P network. Header sync starts from an anchor header that is anchor depth
+// blocks below the tip of the BTC node, and the best chain is chosen by work.
+type P2PSource struct {
+	sync.Mutex
+	addr   string
+	params *chaincfg.Params
+	logger *zap.SugaredLogger
+
+	headers map[chainhash.Hash]*p2pHeader
+	anchor  *chainhash.Hash
+	tip     *chainhash.Hash
+	peer    *peer.Peer
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+func NewP2PSource(
+	addr string,
+	params *chaincfg.Params,
+	node BTCNode,
+	anchorDepth uint64,
+	parentLogger *zap.Logger,
+) (*P2PSource, error) {
+	_, nodeTipHeight, err := node.GetBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the tip of the BTC node: %w", err)
+	}
+	var anchorHeight int64
+	if nodeTipHeight > anchorDepth {
+		anchorHeight = int64(nodeTipHeight - anchorDepth)
+	}
+	anchorHash, err := node.GetBlockHash(anchorHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the hash of the anchor header at height %d: %w", anchorHeight, err)
+	}
+	anchorHeader, err := node.GetBlockHeader(anchorHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the anchor header %s: %w", anchorHash, err)
+	}
+
+	s := &P2PSource{
+		addr:   addr,
+		params: params,
+		logger: parentLogger.With(zap.String("module", "header_witness"), zap.String("peer", addr)).Sugar(),
+		headers: map[chainhash.Hash]*p2pHeader{
+			*anchorHash: {header: anchorHeader, height: int32(anchorHeight), work: big.NewInt(0)},
+		},
+		anchor: anchorHash,
+		tip:    anchorHash,
+		quit:   make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.connLoop()
+
+	return s, nil
+}
+
+func (s *P2PSource) Name() string {
+	return "p2p:" + s.addr
+}
+
+func (s *P2PSource) BestTip() (*chainhash.Hash, int32, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.peer == nil || !s.peer.Connected() {
+		return nil, 0, fmt.Errorf("not connected to peer %s", s.addr)
+	}
+	return s.tip, s.headers[*s.tip].height, nil
+}
+
+func (s *P2PSource) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	s.Lock()
+	defer s.Unlock()
+	h, ok := s.headers[*blockHash]
+	if !ok {
+		return nil, fmt.Errorf("header %s has not been received from peer %s", blockHash, s.addr)
+	}
+	return h.header, nil
+}
+
+func (s *P2PSource) Stop() {
+	select {
+	case <-s.quit:
+		return
+	default:
+		close(s.quit)
+	}
+	s.wg.Wait()
+}
+
+// connLoop keeps a connection to the peer and reconnects after the peer disconnects
+func (s *P2PSource) connLoop() {
+	defer s.wg.Done()
+
+	for {
+		if err := s.connectAndServe(); err != nil {
+			s.logger.Warnf("Connection to the BTC peer failed: %v", err)
+		}
+
+		select {
+		case <-time.After(p2pReconnectDelay):
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+func (s *P2PSource) connectAndServe() error {
+	p, err := peer.NewOutboundPeer(&peer.Config{
+		UserAgentName:    p2pUserAgentName,
+		UserAgentVersion: p2pUserAgentVersion,
+		ChainParams:      s.params,
+		TrickleInterval:  time.Second * 10,
+		Listeners: peer.MessageListeners{
+			OnVerAck:  s.onVerAck,
+			OnHeaders: s.onHeaders,
+			OnInv:     s.onInv,
+		},
+	}, s.addr)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", s.addr, p2pDialTimeout)
+	if err != nil {
+		return err
+	}
+	p.AssociateConnection(conn)
+
+	s.Lock()
+	s.peer = p
+	s.Unlock()
+
+	disconnected := make(chan struct{})
+	go func() {
+		p.WaitForDisconnect()
+		close(disconnected)
+	}()
+
+	select {
+	case <-disconnected:
+		return fmt.Errorf("peer disconnected")
+	case <-s.quit:
+		p.Disconnect()
+		p.WaitForDisconnect()
+		return nil
+	}
+}
+
+func (s *P2PSource) onVerAck(p *peer.Peer, _ *wire.MsgVerAck) {
+	s.logger.Infof("Connected to the BTC peer, syncing headers")
+	s.requestHeaders(p)
+}
+
+func (s *P2PSource) onInv(p *peer.Peer, msg *wire.MsgInv) {
+	for _, inv := range msg.InvList {
+		if inv.Type == wire.InvTypeBlock {
+			s.requestHeaders(p)
+			return
+		}
+	}
+}
+
+func (s *P2PSource) onHeaders(p *peer.Peer, msg *wire.MsgHeaders) {
+	s.Lock()
+	var numAccepted int
+	for _, header := range msg.Headers {
+		hash := header.BlockHash()
+		if _, ok := s.headers[hash]; ok {
+			continue
+		}
+		parent, ok := s.headers[header.PrevBlock]
+		if !ok {
+			s.logger.Debugf("Ignoring header %s from the BTC peer that does not connect to known headers", hash)
+			continue
+		}
+		if err := checkPoW(header); err != nil {
+			s.logger.Warnf("Ignoring header %s from the BTC peer: %v", hash, err)
+			continue
+		}
+
+		h := &p2pHeader{
+			header: header,
+			height: parent.height + 1,
+			work:   new(big.Int).Add(parent.work, blockchain.CalcWork(header.Bits)),
+		}
+		s.headers[hash] = h
+		if h.work.Cmp(s.headers[*s.tip].work) > 0 {
+			s.tip = &hash
+		}
+		numAccepted++
+	}
+	s.Unlock()
+
+	// the peer has more headers to send
+	if numAccepted > 0 && len(msg.Headers) == wire.MaxBlockHeadersPerMsg {
+		s.requestHeaders(p)
+	}
+}
+
+// requestHeaders requests the headers after the current tip from the peer
+func (s *P2PSource) requestHeaders(p *peer.Peer) {
+	s.Lock()
+	msg := wire.NewMsgGetHeaders()
+	// the error is only returned when the locator is full
+	_ = msg.AddBlockLocatorHash(s.tip)
+	if !s.tip.IsEqual(s.anchor) {
+		_ = msg.AddBlockLocatorHash(s.anchor)
+	}
+	s.Unlock()
+
+	p.QueueMessage(msg, nil)
+}
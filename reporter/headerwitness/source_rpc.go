@@ -0,0 +1,72 @@
+package headerwitness
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/rpcclient"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/babylonchain/vigilante/config"
+)
+
+var _ HeaderSource = &RPCSource{}
+
+// RPCSource is a header source backed by another BTC node queried over JSON-RPC.
+// HTTP POST mode is used so that both btcd and bitcoind are supported.
+type RPCSource struct {
+	name   string
+	client *rpcclient.Client
+}
+
+func NewRPCSource(cfg *config.BTCNodeConfig) (*RPCSource, error) {
+	connCfg := &rpcclient.ConnConfig{
+		Host:         cfg.Endpoint,
+		HTTPPostMode: true,
+		User:         cfg.Username,
+		Pass:         cfg.Password,
+		DisableTLS:   cfg.ClientTLSDisabled(),
+	}
+	if !cfg.ClientTLSDisabled() && cfg.CAFile != "" {
+		certs, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file of BTC node %s: %w", cfg.Endpoint, err)
+		}
+		connCfg.Certificates = certs
+	}
+
+	client, err := rpcclient.New(connCfg, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create RPC client to BTC node %s: %w", cfg.Endpoint, err)
+	}
+
+	return &RPCSource{
+		name:   "rpc:" + cfg.Endpoint,
+		client: client,
+	}, nil
+}
+
+func (s *RPCSource) Name() string {
+	return s.name
+}
+
+func (s *RPCSource) BestTip() (*chainhash.Hash, int32, error) {
+	hash, err := s.client.GetBestBlockHash()
+	if err != nil {
+		return nil, 0, err
+	}
+	header, err := s.client.GetBlockHeaderVerbose(hash)
+	if err != nil {
+		return nil, 0, err
+	}
+	return hash, header.Height, nil
+}
+
+func (s *RPCSource) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	return s.client.GetBlockHeader(blockHash)
+}
+
+func (s *RPCSource) Stop() {
+	s.client.Shutdown()
+}
@@ -0,0 +1,344 @@
+// Package headerwitness cross-checks the best chain of the BTC node used by the
+// reporter against independent BTC header sources, so that an eclipsed or
+// otherwise compromised BTC node cannot feed a minority chain to Babylon unnoticed.
+package headerwitness
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+)
+
+var (
+	// ErrInvalidPoW is returned when a header of a header source does not satisfy its own difficulty target
+	ErrInvalidPoW = errors.New("header does not satisfy its proof-of-work target")
+	// ErrForkTooDeep is returned when no common ancestor of the BTC node and a header source is found within max fork depth
+	ErrForkTooDeep = errors.New("no common ancestor found within max fork depth")
+)
+
+// BTCNode is the BTC node whose best chain is cross-checked.
+// Both btcclient.Client and btcclient.MultiClient implement it.
+type BTCNode interface {
+	GetBestBlock() (*chainhash.Hash, uint64, error)
+	GetBlockHash(height int64) (*chainhash.Hash, error)
+	GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error)
+}
+
+// HeaderSource is an independent source of BTC headers
+type HeaderSource interface {
+	// Name identifies the header source in logs and metrics
+	Name() string
+	// BestTip returns the hash and height of the best header known to the header source
+	BestTip() (*chainhash.Hash, int32, error)
+	// GetBlockHeader returns the header with the given hash
+	GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error)
+	// Stop releases the resources of the header source
+	Stop()
+}
+
+// CheckResult is the outcome of cross-checking the BTC node against a header source
+type CheckResult struct {
+	Source           string
+	NodeTipHeight    int32
+	WitnessTipHeight int32
+	// ForkHeight is the height of the last common header of the BTC node and the header source
+	ForkHeight int32
+	// NodeWork and WitnessWork are the work of the two chains above the fork height
+	NodeWork    *big.Int
+	WitnessWork *big.Int
+	Alarm       bool
+	Reason      string
+}
+
+// Witness periodically cross-checks the BTC node against all header sources
+type Witness struct {
+	cfg     *config.HeaderWitnessConfig
+	logger  *zap.SugaredLogger
+	node    BTCNode
+	sources []HeaderSource
+	metrics *metrics.HeaderWitnessMetrics
+
+	mu      sync.Mutex
+	alarmed map[string]bool
+
+	wg      sync.WaitGroup
+	started bool
+	quit    chan struct{}
+}
+
+func New(
+	cfg *config.HeaderWitnessConfig,
+	node BTCNode,
+	sources []HeaderSource,
+	parentLogger *zap.Logger,
+	metrics *metrics.HeaderWitnessMetrics,
+) *Witness {
+	return &Witness{
+		cfg:     cfg,
+		logger:  parentLogger.With(zap.String("module", "header_witness")).Sugar(),
+		node:    node,
+		sources: sources,
+		metrics: metrics,
+		alarmed: make(map[string]bool),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start starts the periodic cross-checks
+func (w *Witness) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.started = true
+
+	w.wg.Add(1)
+	go w.checkLoop()
+
+	w.logger.Infof("Successfully started the header witness with %d header sources", len(w.sources))
+}
+
+// Stop stops the cross-checks and all header sources
+func (w *Witness) Stop() {
+	select {
+	case <-w.quit:
+		return
+	default:
+		close(w.quit)
+	}
+	w.wg.Wait()
+	for _, src := range w.sources {
+		src.Stop()
+	}
+}
+
+// Alarmed returns whether any header source reports a better chain than the BTC node
+func (w *Witness) Alarmed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, alarmed := range w.alarmed {
+		if alarmed {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Witness) checkLoop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		w.CheckAll()
+
+		select {
+		case <-ticker.C:
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// CheckAll cross-checks the BTC node against all header sources and updates the alarm status.
+// A header source that cannot be checked keeps its previous alarm status.
+func (w *Witness) CheckAll() {
+	for _, src := range w.sources {
+		res, err := w.Check(src)
+		if err != nil {
+			w.logger.Warnf("Failed to cross-check the BTC node against header source %s: %v", src.Name(), err)
+			w.metrics.WitnessCheckFailuresCounter.WithLabelValues(src.Name()).Inc()
+			continue
+		}
+		w.setAlarm(res)
+	}
+}
+
+func (w *Witness) setAlarm(res *CheckResult) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wasAlarmed := w.alarmed[res.Source]
+	w.alarmed[res.Source] = res.Alarm
+	w.metrics.WitnessTipHeightGaugeVec.WithLabelValues(res.Source).Set(float64(res.WitnessTipHeight))
+
+	if res.Alarm {
+		w.metrics.WitnessAlarmGaugeVec.WithLabelValues(res.Source).Set(1)
+		if !wasAlarmed {
+			w.metrics.WitnessAlarmsCounterVec.WithLabelValues(res.Source).Inc()
+		}
+		w.logger.Errorf("Header source %s disagrees with the BTC node: %s (node tip height: %d, witness tip height: %d, fork height: %d)",
+			res.Source, res.Reason, res.NodeTipHeight, res.WitnessTipHeight, res.ForkHeight)
+		return
+	}
+
+	w.metrics.WitnessAlarmGaugeVec.WithLabelValues(res.Source).Set(0)
+	if wasAlarmed {
+		w.logger.Infof("Header source %s agrees with the BTC node again (node tip height: %d, witness tip height: %d)",
+			res.Source, res.NodeTipHeight, res.WitnessTipHeight)
+	}
+}
+
+// Check cross-checks the best chain of the BTC node against the best chain of the header source.
+// It finds the last common header of the two chains by walking back the chain of the header source,
+// and raises an alarm if
+// - the BTC node is on the chain of the header source but lags behind by more than max lag blocks, or
+// - the two chains fork and the chain of the header source has more work.
+// A fork deeper than max fork depth always raises an alarm.
+func (w *Witness) Check(src HeaderSource) (*CheckResult, error) {
+	_, nodeTipHeightU64, err := w.node.GetBestBlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the tip of the BTC node: %w", err)
+	}
+	nodeTipHeight := int32(nodeTipHeightU64)
+
+	witnessTipHash, witnessTipHeight, err := src.BestTip()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the tip of the header source: %w", err)
+	}
+
+	res := &CheckResult{
+		Source:           src.Name(),
+		NodeTipHeight:    nodeTipHeight,
+		WitnessTipHeight: witnessTipHeight,
+		NodeWork:         big.NewInt(0),
+		WitnessWork:      big.NewInt(0),
+	}
+
+	// walk back the chain of the header source until a header on the best chain of the BTC node
+	lowest := min(nodeTipHeight, witnessTipHeight) - int32(w.cfg.MaxForkDepth)
+	hash, height := witnessTipHash, witnessTipHeight
+	for {
+		if height <= nodeTipHeight {
+			nodeHash, err := w.node.GetBlockHash(int64(height))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the hash of the BTC node's block at height %d: %w", height, err)
+			}
+			if nodeHash.IsEqual(hash) {
+				break
+			}
+		}
+		if height <= lowest || height <= 0 {
+			res.ForkHeight = height
+			res.Alarm = true
+			res.Reason = ErrForkTooDeep.Error()
+			return res, nil
+		}
+
+		header, err := src.GetBlockHeader(hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get header %s from the header source: %w", hash, err)
+		}
+		if err := checkPoW(header); err != nil {
+			return nil, fmt.Errorf("header %s from the header source: %w", hash, err)
+		}
+		res.WitnessWork.Add(res.WitnessWork, blockchain.CalcWork(header.Bits))
+
+		hash = &header.PrevBlock
+		height--
+	}
+	res.ForkHeight = height
+
+	// the BTC node is on the chain of the header source
+	if res.ForkHeight == nodeTipHeight {
+		lag := witnessTipHeight - nodeTipHeight
+		if lag > int32(w.cfg.MaxLagBlocks) {
+			res.Alarm = true
+			res.Reason = fmt.Sprintf("the BTC node lags %d blocks behind", lag)
+		}
+		return res, nil
+	}
+
+	// the header source is on the chain of the BTC node
+	if res.ForkHeight == witnessTipHeight {
+		return res, nil
+	}
+
+	// the two chains fork, compare their work above the fork height
+	for h := res.ForkHeight + 1; h <= nodeTipHeight; h++ {
+		nodeHash, err := w.node.GetBlockHash(int64(h))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the hash of the BTC node's block at height %d: %w", h, err)
+		}
+		header, err := w.node.GetBlockHeader(nodeHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the BTC node's header %s: %w", nodeHash, err)
+		}
+		res.NodeWork.Add(res.NodeWork, blockchain.CalcWork(header.Bits))
+	}
+	if res.WitnessWork.Cmp(res.NodeWork) > 0 {
+		res.Alarm = true
+		res.Reason = fmt.Sprintf("the header source has a fork with more work (%s > %s)", res.WitnessWork, res.NodeWork)
+	}
+	return res, nil
+}
+
+// checkPoW checks whether the hash of the header satisfies the difficulty target of the header.
+// It does not check the target against the difficulty adjustment rules.
+func checkPoW(header *wire.BlockHeader) error {
+	target := blockchain.CompactToBig(header.Bits)
+	if target.Sign() <= 0 {
+		return ErrInvalidPoW
+	}
+	hash := header.BlockHash()
+	if blockchain.HashToBig(&hash).Cmp(target) > 0 {
+		return ErrInvalidPoW
+	}
+	return nil
+}
+
+// NewHeaderSources creates all header sources defined in the config. Header sources
+// that were created are stopped if any of them fails to be created.
+func NewHeaderSources(
+	cfg *config.HeaderWitnessConfig,
+	params *chaincfg.Params,
+	node BTCNode,
+	parentLogger *zap.Logger,
+) ([]HeaderSource, error) {
+	var sources []HeaderSource
+	stopAll := func() {
+		for _, src := range sources {
+			src.Stop()
+		}
+	}
+
+	for i := range cfg.RPCNodes {
+		src, err := NewRPCSource(&cfg.RPCNodes[i])
+		if err != nil {
+			stopAll()
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	if cfg.HeadersFile != "" {
+		src, err := NewFileSource(cfg.HeadersFile, cfg.HeadersFileStartHeight)
+		if err != nil {
+			stopAll()
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	for _, addr := range cfg.P2PPeers {
+		src, err := NewP2PSource(addr, params, node, cfg.P2PAnchorDepth, parentLogger)
+		if err != nil {
+			stopAll()
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+
+	return sources, nil
+}
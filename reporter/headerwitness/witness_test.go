@@ -0,0 +1,190 @@
+package headerwitness_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/babylonchain/vigilante/config"
+	"github.com/babylonchain/vigilante/metrics"
+	"github.com/babylonchain/vigilante/reporter/headerwitness"
+)
+
+// headerChain is an in-memory chain of headers that serves both as BTC node and as header source
+type headerChain struct {
+	name    string
+	headers []*wire.BlockHeader
+	byHash  map[chainhash.Hash]*wire.BlockHeader
+}
+
+func newHeaderChain(name string, headers []*wire.BlockHeader) *headerChain {
+	c := &headerChain{name: name, headers: headers, byHash: make(map[chainhash.Hash]*wire.BlockHeader)}
+	for _, h := range headers {
+		c.byHash[h.BlockHash()] = h
+	}
+	return c
+}
+
+func (c *headerChain) Name() string { return c.name }
+
+func (c *headerChain) Stop() {}
+
+func (c *headerChain) tip() (*chainhash.Hash, int32) {
+	hash := c.headers[len(c.headers)-1].BlockHash()
+	return &hash, int32(len(c.headers) - 1)
+}
+
+func (c *headerChain) BestTip() (*chainhash.Hash, int32, error) {
+	hash, height := c.tip()
+	return hash, height, nil
+}
+
+func (c *headerChain) GetBestBlock() (*chainhash.Hash, uint64, error) {
+	hash, height := c.tip()
+	return hash, uint64(height), nil
+}
+
+func (c *headerChain) GetBlockHash(height int64) (*chainhash.Hash, error) {
+	if height < 0 || height >= int64(len(c.headers)) {
+		return nil, fmt.Errorf("no block at height %d", height)
+	}
+	hash := c.headers[height].BlockHash()
+	return &hash, nil
+}
+
+func (c *headerChain) GetBlockHeader(blockHash *chainhash.Hash) (*wire.BlockHeader, error) {
+	h, ok := c.byHash[*blockHash]
+	if !ok {
+		return nil, fmt.Errorf("unknown header %s", blockHash)
+	}
+	return h, nil
+}
+
+// extendChain mines n headers with the regtest difficulty on top of the given headers
+func extendChain(r *rand.Rand, headers []*wire.BlockHeader, n int) []*wire.BlockHeader {
+	chain := append([]*wire.BlockHeader{}, headers...)
+	for i := 0; i < n; i++ {
+		prev := chain[len(chain)-1]
+		header := &wire.BlockHeader{
+			Version:   1,
+			PrevBlock: prev.BlockHash(),
+			Timestamp: prev.Timestamp.Add(10 * time.Minute),
+			Bits:      chaincfg.RegressionNetParams.PowLimitBits,
+			Nonce:     r.Uint32(),
+		}
+		r.Read(header.MerkleRoot[:])
+		for !satisfiesPoW(header) {
+			header.Nonce++
+		}
+		chain = append(chain, header)
+	}
+	return chain
+}
+
+func satisfiesPoW(header *wire.BlockHeader) bool {
+	hash := header.BlockHash()
+	return blockchain.HashToBig(&hash).Cmp(blockchain.CompactToBig(header.Bits)) <= 0
+}
+
+func newTestWitness(node headerwitness.BTCNode, sources ...headerwitness.HeaderSource) *headerwitness.Witness {
+	cfg := config.DefaultHeaderWitnessConfig()
+	cfg.MaxLagBlocks = 3
+	cfg.MaxForkDepth = 10
+	return headerwitness.New(&cfg, node, sources, zap.NewNop(), metrics.NewReporterMetrics().HeaderWitnessMetrics)
+}
+
+func TestWitnessCheck(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	genesis := chaincfg.RegressionNetParams.GenesisBlock.Header
+	base := extendChain(r, []*wire.BlockHeader{&genesis}, 20)
+
+	t.Run("same chain", func(t *testing.T) {
+		node := newHeaderChain("node", base)
+		src := newHeaderChain("src", base)
+		res, err := newTestWitness(node, src).Check(src)
+		require.NoError(t, err)
+		require.False(t, res.Alarm)
+		require.Equal(t, int32(20), res.ForkHeight)
+	})
+
+	t.Run("header source lags behind", func(t *testing.T) {
+		node := newHeaderChain("node", base)
+		src := newHeaderChain("src", base[:15])
+		res, err := newTestWitness(node, src).Check(src)
+		require.NoError(t, err)
+		require.False(t, res.Alarm)
+		require.Equal(t, int32(14), res.ForkHeight)
+	})
+
+	t.Run("BTC node lags behind within max lag", func(t *testing.T) {
+		node := newHeaderChain("node", base[:18])
+		src := newHeaderChain("src", base)
+		res, err := newTestWitness(node, src).Check(src)
+		require.NoError(t, err)
+		require.False(t, res.Alarm)
+	})
+
+	t.Run("BTC node lags behind beyond max lag", func(t *testing.T) {
+		node := newHeaderChain("node", base[:10])
+		src := newHeaderChain("src", base)
+		w := newTestWitness(node, src)
+		res, err := w.Check(src)
+		require.NoError(t, err)
+		require.True(t, res.Alarm)
+		require.Equal(t, int32(9), res.ForkHeight)
+
+		w.CheckAll()
+		require.True(t, w.Alarmed())
+	})
+
+	t.Run("fork with more work", func(t *testing.T) {
+		node := newHeaderChain("node", extendChain(r, base[:16], 2))
+		src := newHeaderChain("src", extendChain(r, base[:16], 4))
+		res, err := newTestWitness(node, src).Check(src)
+		require.NoError(t, err)
+		require.True(t, res.Alarm)
+		require.Equal(t, int32(15), res.ForkHeight)
+		require.Equal(t, 1, res.WitnessWork.Cmp(res.NodeWork))
+	})
+
+	t.Run("fork with less work", func(t *testing.T) {
+		node := newHeaderChain("node", extendChain(r, base[:16], 4))
+		src := newHeaderChain("src", extendChain(r, base[:16], 2))
+		w := newTestWitness(node, src)
+		res, err := w.Check(src)
+		require.NoError(t, err)
+		require.False(t, res.Alarm)
+
+		w.CheckAll()
+		require.False(t, w.Alarmed())
+	})
+
+	t.Run("fork deeper than max fork depth", func(t *testing.T) {
+		node := newHeaderChain("node", extendChain(r, base[:2], 15))
+		src := newHeaderChain("src", extendChain(r, base[:2], 15))
+		res, err := newTestWitness(node, src).Check(src)
+		require.NoError(t, err)
+		require.True(t, res.Alarm)
+	})
+
+	t.Run("header with invalid PoW", func(t *testing.T) {
+		forged := extendChain(r, base[:16], 5)
+		tip := *forged[len(forged)-1]
+		for satisfiesPoW(&tip) {
+			tip.Nonce++
+		}
+		forged[len(forged)-1] = &tip
+		node := newHeaderChain("node", base)
+		src := newHeaderChain("src", forged)
+		_, err := newTestWitness(node, src).Check(src)
+		require.ErrorIs(t, err, headerwitness.ErrInvalidPoW)
+	})
+}
@@ -0,0 +1,64 @@
+package reporter
+
+import "errors"
+
+// ErrHeaderWitnessAlarm is returned when headers are not submitted to Babylon
+// because an independent header source disagrees with the BTC node
+var ErrHeaderWitnessAlarm = errors.New("header submission is paused as the header witness raised an alarm")
+
+// HeaderWitness reports whether independent BTC header sources disagree with
+// the best chain of the BTC node used by the reporter
+type HeaderWitness interface {
+	Alarmed() bool
+}
+
+// HealthStatus is the health status of the reporter. It is exposed so that
+// the orchestration layer can decide how to react when the reporter cannot
+// bootstrap, rather than the reporter terminating the process by itself.
+type HealthStatus int32
+
+const (
+	// HealthStarting means that the reporter has not bootstrapped yet
+	HealthStarting HealthStatus = iota
+	// HealthOK means that the reporter is bootstrapped and relays BTC headers and checkpoints
+	HealthOK
+	// HealthBootstrapRetrying means that the last bootstrap attempt failed and the reporter keeps retrying
+	HealthBootstrapRetrying
+	// HealthBootstrapFailed means that the reporter has given up bootstrapping after the maximum number of attempts
+	HealthBootstrapFailed
+)
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthStarting:
+		return "STARTING"
+	case HealthOK:
+		return "OK"
+	case HealthBootstrapRetrying:
+		return "BOOTSTRAP_RETRYING"
+	case HealthBootstrapFailed:
+		return "BOOTSTRAP_FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Health returns the current health status of the reporter
+func (r *Reporter) Health() HealthStatus {
+	return HealthStatus(r.health.Load())
+}
+
+func (r *Reporter) setHealth(status HealthStatus) {
+	r.health.Store(int32(status))
+	r.metrics.HealthStatusGauge.Set(float64(status))
+}
+
+// SetHeaderWitness sets the header witness that is consulted before submitting
+// headers to Babylon if pause_on_alarm is enabled
+func (r *Reporter) SetHeaderWitness(w HeaderWitness) {
+	r.headerWitness = w
+}
+
+func (r *Reporter) headerSubmissionPaused() bool {
+	return r.Cfg.HeaderWitness.PauseOnAlarm && r.headerWitness != nil && r.headerWitness.Alarmed()
+}
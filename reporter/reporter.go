@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/babylonchain/babylon/btctxformatter"
@@ -29,11 +30,16 @@ type Reporter struct {
 
 	// Internal states of the reporter
 	CheckpointCache               *types.CheckpointCache
+	ckptRetryQueue                *ckptRetryQueue
+	ckptSubmitMu                  sync.Mutex
 	btcCache                      *types.BTCCache
 	reorgList                     *reorgList
 	btcConfirmationDepth          uint64
 	checkpointFinalizationTimeout uint64
 	metrics                       *metrics.ReporterMetrics
+	headerWitness                 HeaderWitness
+	headersSkipped                bool // whether headers were skipped while the header witness raised an alarm
+	health                        atomic.Int32
 	wg                            sync.WaitGroup
 	started                       bool
 	quit                          chan struct{}
@@ -82,6 +88,7 @@ func New(
 		btcClient:                     btcClient,
 		babylonClient:                 babylonClient,
 		CheckpointCache:               ckptCache,
+		ckptRetryQueue:                newCkptRetryQueue(retrySleepTime, maxRetrySleepTime),
 		reorgList:                     newReorgList(),
 		btcConfirmationDepth:          k,
 		checkpointFinalizationTimeout: w,
@@ -90,8 +97,10 @@ func New(
 	}, nil
 }
 
-// Start starts the goroutines necessary to manage a vigilante.
-func (r *Reporter) Start() {
+// Start bootstraps the reporter and starts the goroutines necessary to manage a
+// vigilante. It returns an error without starting them if bootstrapping fails, in
+// which case the health status tells whether it gave up or was stopped.
+func (r *Reporter) Start() error {
 	r.quitMu.Lock()
 	select {
 	case <-r.quit:
@@ -102,21 +111,50 @@ func (r *Reporter) Start() {
 		// Ignore when the vigilante is still running.
 		if r.started {
 			r.quitMu.Unlock()
-			return
+			return nil
 		}
 		r.started = true
 	}
 	r.quitMu.Unlock()
 
-	r.bootstrapWithRetries(false)
+	if err := r.bootstrapWithRetries(false); err != nil {
+		// allow the reporter to be started again
+		r.quitMu.Lock()
+		r.started = false
+		r.quitMu.Unlock()
+		return fmt.Errorf("failed to bootstrap the vigilant reporter, with health status %v: %w", r.Health(), err)
+	}
 
-	r.wg.Add(1)
+	r.wg.Add(2)
 	go r.blockEventHandler()
+	go r.checkpointRetryHandler()
 
 	// start record time-related metrics
 	r.metrics.RecordMetrics()
 
 	r.logger.Infof("Successfully started the vigilant reporter")
+	return nil
+}
+
+// NumPendingCheckpoints returns the number of matched checkpoints that are
+// waiting in the retry queue to be accepted by Babylon
+func (r *Reporter) NumPendingCheckpoints() int {
+	return r.ckptRetryQueue.size()
+}
+
+// SetCkptRetryStore persists the retry queue in the given store, and restores
+// the checkpoints that were waiting in it before a restart. It has to be called
+// before the reporter is started.
+func (r *Reporter) SetCkptRetryStore(store *CkptRetryStore) error {
+	n, err := r.ckptRetryQueue.restore(store)
+	if err != nil {
+		return fmt.Errorf("failed to restore the checkpoint retry queue: %w", err)
+	}
+	if n > 0 {
+		r.logger.Infof("Restored %d checkpoints waiting to be submitted to Babylon", n)
+	}
+	r.metrics.CheckpointRetryQueueSizeGauge.Set(float64(r.ckptRetryQueue.size()))
+	return nil
 }
 
 // quitChan atomically reads the quit channel.
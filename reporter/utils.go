@@ -2,11 +2,13 @@ package reporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
-	pv "github.com/cosmos/relayer/v2/relayer/provider"
-
+	errorsmod "cosmossdk.io/errors"
 	sdkmath "cosmossdk.io/math"
 	"github.com/babylonchain/babylon/types/retry"
 	btcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
@@ -14,6 +16,19 @@ import (
 	"github.com/babylonchain/vigilante/types"
 )
 
+var (
+	// errors from Babylon showing that the checkpoint is already known, thus no need to submit it again
+	ckptSubmittedErrs = []*errorsmod.Error{
+		btcctypes.ErrDuplicatedSubmission,
+	}
+	// errors from Babylon showing that the checkpoint will never be accepted, thus no need to retry
+	ckptPermanentErrs = []*errorsmod.Error{
+		btcctypes.ErrInvalidCheckpointProof,
+		btcctypes.ErrEpochAlreadyFinalized,
+		btcctypes.ErrProvidedHeaderFromDifferentForks,
+	}
+)
+
 func chunkBy[T any](items []T, chunkSize int) (chunks [][]T) {
 	for chunkSize < len(items) {
 		items, chunks = items[chunkSize:], append(chunks, items[0:chunkSize:chunkSize])
@@ -96,6 +111,10 @@ func (r *Reporter) submitHeaderMsgs(msg *btclctypes.MsgInsertHeaders) error {
 // ProcessHeaders extracts and reports headers from a list of blocks
 // It returns the number of headers that need to be reported (after deduplication)
 func (r *Reporter) ProcessHeaders(signer string, ibs []*types.IndexedBlock) (int, error) {
+	if r.headerSubmissionPaused() {
+		return 0, ErrHeaderWitnessAlarm
+	}
+
 	// get a list of MsgInsertHeader msgs with headers to be submitted
 	headerMsgsToSubmit, err := r.getHeaderMsgsToSubmit(signer, ibs)
 	if err != nil {
@@ -146,13 +165,6 @@ func (r *Reporter) extractCheckpoints(ib *types.IndexedBlock) int {
 }
 
 func (r *Reporter) matchAndSubmitCheckpoints(signer string) (int, error) {
-	var (
-		res                  *pv.RelayerTxResponse
-		proofs               []*btcctypes.BTCSpvProof
-		msgInsertBTCSpvProof *btcctypes.MsgInsertBTCSpvProof
-		err                  error
-	)
-
 	// get matched ckpt parts from the ckptCache
 	// Note that Match() has ensured the checkpoints are always ordered by epoch number
 	r.CheckpointCache.Match()
@@ -160,11 +172,14 @@ func (r *Reporter) matchAndSubmitCheckpoints(signer string) (int, error) {
 
 	if numMatchedCkpts == 0 {
 		r.logger.Debug("Found no matched pair of checkpoint segments in this match attempt")
-		return numMatchedCkpts, nil
 	}
 
-	// for each matched checkpoint, wrap to MsgInsertBTCSpvProof and send to Babylon
-	// Note that this is a while loop that keeps popping checkpoints in the cache
+	// move each matched checkpoint to the retry queue, which keeps it until
+	// Babylon accepts it, so that a failed submission does not lose it
+	// Note that this is a while loop that keeps popping checkpoints in the cache.
+	// The checkpoints are pushed while no submission is in flight, otherwise a
+	// checkpoint popped for submission could be pushed and sent again.
+	r.ckptSubmitMu.Lock()
 	for {
 		// pop the earliest checkpoint
 		// if popping a nil checkpoint, then all checkpoints are popped, break the for loop
@@ -174,34 +189,85 @@ func (r *Reporter) matchAndSubmitCheckpoints(signer string) (int, error) {
 		}
 
 		r.logger.Info("Found a matched pair of checkpoint segments!")
+		pushed, err := r.ckptRetryQueue.push(ckpt)
+		if err != nil {
+			r.logger.Errorf("Failed to persist the checkpoint of epoch %d in the retry queue: %v", ckpt.Epoch, err)
+		}
+		if !pushed {
+			r.logger.Debugf("The checkpoint of epoch %d is already in the retry queue", ckpt.Epoch)
+		}
+	}
+	r.ckptSubmitMu.Unlock()
 
-		// fetch the first checkpoint in cache and construct spv proof
-		proofs = ckpt.MustGenSPVProofs()
+	// wrap each due checkpoint in the queue to MsgInsertBTCSpvProof and send to Babylon
+	r.submitQueuedCheckpoints(signer)
 
-		// wrap to MsgInsertBTCSpvProof
-		msgInsertBTCSpvProof = types.MustNewMsgInsertBTCSpvProof(signer, proofs)
+	return numMatchedCkpts, nil
+}
+
+// submitQueuedCheckpoints submits all checkpoints in the retry queue whose next attempt is due.
+// Checkpoints that fail with a transient error are put back to the queue with a larger backoff,
+// while those accepted, reported as duplicated, or permanently rejected by Babylon are dropped,
+// including from the store of the retry queue.
+func (r *Reporter) submitQueuedCheckpoints(signer string) {
+	r.ckptSubmitMu.Lock()
+	defer r.ckptSubmitMu.Unlock()
+
+	for _, entry := range r.ckptRetryQueue.popReady(time.Now()) {
+		ckpt := entry.ckpt
+
+		// construct spv proofs and wrap to MsgInsertBTCSpvProof
+		proofs := ckpt.MustGenSPVProofs()
+		msgInsertBTCSpvProof := types.MustNewMsgInsertBTCSpvProof(signer, proofs)
 
 		// submit the checkpoint to Babylon
-		res, err = r.babylonClient.InsertBTCSpvProof(context.Background(), msgInsertBTCSpvProof)
+		res, err := r.babylonClient.InsertBTCSpvProof(context.Background(), msgInsertBTCSpvProof)
 		if err != nil {
-			r.logger.Errorf("Failed to submit MsgInsertBTCSpvProof with error %v", err)
-			r.metrics.FailedCheckpointsCounter.Inc()
-			continue
+			switch {
+			case isOneOfErrors(err, ckptSubmittedErrs):
+				r.logger.Infof("The checkpoint of epoch %d has already been submitted to Babylon: %v", ckpt.Epoch, err)
+			case isOneOfErrors(err, ckptPermanentErrs):
+				r.logger.Errorf("Babylon rejected the checkpoint of epoch %d, dropping it: %v", ckpt.Epoch, err)
+				r.metrics.FailedCheckpointsCounter.Inc()
+			default:
+				if storeErr := r.ckptRetryQueue.reschedule(entry, time.Now(), err); storeErr != nil {
+					r.logger.Errorf("Failed to persist the checkpoint of epoch %d in the retry queue: %v", ckpt.Epoch, storeErr)
+				}
+				r.logger.Errorf("Failed to submit MsgInsertBTCSpvProof of epoch %d with error %v. Attempt: %d, next attempt at %v",
+					ckpt.Epoch, err, entry.attempts, entry.nextAttempt)
+				r.metrics.FailedCheckpointsCounter.Inc()
+				continue
+			}
+		} else {
+			r.logger.Infof("Successfully submitted MsgInsertBTCSpvProof with response %d", res.Code)
+			r.metrics.SuccessfulCheckpointsCounter.Inc()
+			r.metrics.SecondsSinceLastCheckpointGauge.Set(0)
+			tx1Block := ckpt.Segments[0].AssocBlock
+			tx2Block := ckpt.Segments[1].AssocBlock
+			r.metrics.NewReportedCheckpointGaugeVec.WithLabelValues(
+				strconv.Itoa(int(ckpt.Epoch)),
+				strconv.Itoa(int(tx1Block.Height)),
+				tx1Block.Txs[ckpt.Segments[0].TxIdx].Hash().String(),
+				tx2Block.Txs[ckpt.Segments[1].TxIdx].Hash().String(),
+			).SetToCurrentTime()
+		}
+		if err := r.ckptRetryQueue.done(entry); err != nil {
+			r.logger.Errorf("Failed to remove the checkpoint of epoch %d from the persisted retry queue: %v", ckpt.Epoch, err)
 		}
-		r.logger.Infof("Successfully submitted MsgInsertBTCSpvProof with response %d", res.Code)
-		r.metrics.SuccessfulCheckpointsCounter.Inc()
-		r.metrics.SecondsSinceLastCheckpointGauge.Set(0)
-		tx1Block := ckpt.Segments[0].AssocBlock
-		tx2Block := ckpt.Segments[1].AssocBlock
-		r.metrics.NewReportedCheckpointGaugeVec.WithLabelValues(
-			strconv.Itoa(int(ckpt.Epoch)),
-			strconv.Itoa(int(tx1Block.Height)),
-			tx1Block.Txs[ckpt.Segments[0].TxIdx].Hash().String(),
-			tx2Block.Txs[ckpt.Segments[1].TxIdx].Hash().String(),
-		).SetToCurrentTime()
 	}
 
-	return numMatchedCkpts, nil
+	r.metrics.CheckpointRetryQueueSizeGauge.Set(float64(r.ckptRetryQueue.size()))
+}
+
+// isOneOfErrors returns whether the error returned by Babylon is one of the given errors.
+// Errors of Babylon txs are relayed as plain strings, so their messages are compared as well.
+func isOneOfErrors(err error, errs []*errorsmod.Error) bool {
+	for _, e := range errs {
+		if errors.Is(err, e) || strings.Contains(err.Error(), e.Error()) {
+			return true
+		}
+	}
+	return false
 }
 
 // ProcessCheckpoints tries to extract checkpoint segments from a list of blocks, find matched checkpoint segments, and report matched checkpoints
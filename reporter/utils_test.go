@@ -1,6 +1,7 @@
 package reporter_test
 
 import (
+	"errors"
 	"math/rand"
 	"testing"
 
@@ -119,3 +120,55 @@ func FuzzProcessCheckpoints(f *testing.F) {
 		require.NoError(t, err)
 	})
 }
+
+// FuzzProcessCheckpointsWithFailedSubmissions fuzz tests ProcessCheckpoints() when Babylon rejects the checkpoints
+// - Data: a number of random blocks, with or without Babylon txs
+// - Tested property: checkpoints failing with a transient error stay in the retry queue,
+// while checkpoints reported as duplicated by Babylon are dropped from the retry queue
+func FuzzProcessCheckpointsWithFailedSubmissions(f *testing.F) {
+	datagen.AddRandomSeedsToFuzzer(f, 100)
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		r := rand.New(rand.NewSource(seed))
+
+		_, mockBabylonClient, mockReporter := newMockReporter(t, ctrl)
+		transient := datagen.OneInN(r, 2)
+		if transient {
+			mockBabylonClient.EXPECT().InsertBTCSpvProof(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused")).AnyTimes()
+		} else {
+			mockBabylonClient.EXPECT().InsertBTCSpvProof(gomock.Any(), gomock.Any()).Return(nil, btcctypes.ErrDuplicatedSubmission).AnyTimes()
+		}
+
+		// generate a random number of blocks, with or without Babylon txs
+		numBlocks := datagen.RandomInt(r, 100)
+		blocks, numCkptSegsExpected, rawCkpts := vdatagen.GenRandomBlockchainWithBabylonTx(r, numBlocks, 0.3, 0.4)
+		ibs := []*types.IndexedBlock{}
+		numMatchedCkptsExpected := 0
+		for i, block := range blocks {
+			ibs = append(ibs, types.NewIndexedBlockFromMsgBlock(r.Int31(), block))
+			if rawCkpts[i] != nil {
+				numMatchedCkptsExpected++
+			}
+		}
+
+		numCkptSegs, numMatchedCkpts, err := mockReporter.ProcessCheckpoints("", ibs)
+		require.Equal(t, numCkptSegsExpected, numCkptSegs)
+		require.Equal(t, numMatchedCkptsExpected, numMatchedCkpts)
+		require.NoError(t, err)
+
+		if transient {
+			require.Equal(t, numMatchedCkptsExpected, mockReporter.NumPendingCheckpoints())
+		} else {
+			require.Zero(t, mockReporter.NumPendingCheckpoints())
+		}
+
+		// processing the same blocks again does not queue the same checkpoints twice
+		_, _, err = mockReporter.ProcessCheckpoints("", ibs)
+		require.NoError(t, err)
+		if transient {
+			require.Equal(t, numMatchedCkptsExpected, mockReporter.NumPendingCheckpoints())
+		}
+	})
+}
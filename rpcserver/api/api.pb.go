@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.0
+// 	protoc-gen-go v1.33.0
 // 	protoc        v3.21.2
 // source: api.proto
 
@@ -24,6 +24,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type LivenessVerdict int32
+
+const (
+	// the checkpoint has not been checked yet
+	LivenessVerdict_LIVENESS_VERDICT_UNCHECKED LivenessVerdict = 0
+	// the checkpoint is reported, or may still be reported, within max-live-btc-heights
+	LivenessVerdict_LIVENESS_VERDICT_LIVE LivenessVerdict = 1
+	// the checkpoint is not reported within max-live-btc-heights
+	LivenessVerdict_LIVENESS_VERDICT_CENSORED LivenessVerdict = 2
+	// the check failed before reaching a verdict
+	LivenessVerdict_LIVENESS_VERDICT_ERROR LivenessVerdict = 3
+)
+
+// Enum value maps for LivenessVerdict.
+var (
+	LivenessVerdict_name = map[int32]string{
+		0: "LIVENESS_VERDICT_UNCHECKED",
+		1: "LIVENESS_VERDICT_LIVE",
+		2: "LIVENESS_VERDICT_CENSORED",
+		3: "LIVENESS_VERDICT_ERROR",
+	}
+	LivenessVerdict_value = map[string]int32{
+		"LIVENESS_VERDICT_UNCHECKED": 0,
+		"LIVENESS_VERDICT_LIVE":      1,
+		"LIVENESS_VERDICT_CENSORED":  2,
+		"LIVENESS_VERDICT_ERROR":     3,
+	}
+)
+
+func (x LivenessVerdict) Enum() *LivenessVerdict {
+	p := new(LivenessVerdict)
+	*p = x
+	return p
+}
+
+func (x LivenessVerdict) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LivenessVerdict) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_enumTypes[0].Descriptor()
+}
+
+func (LivenessVerdict) Type() protoreflect.EnumType {
+	return &file_api_proto_enumTypes[0]
+}
+
+func (x LivenessVerdict) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LivenessVerdict.Descriptor instead.
+func (LivenessVerdict) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{0}
+}
+
 type VersionRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -149,6 +205,337 @@ func (x *VersionResponse) GetBuildMetadata() string {
 	return ""
 }
 
+// CheckpointLiveness is the result of the last liveness check of a checkpoint.
+// All heights are BTC heights
+type CheckpointLiveness struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Epoch        uint64 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	CheckpointId string `protobuf:"bytes,2,opt,name=checkpoint_id,json=checkpointId,proto3" json:"checkpoint_id,omitempty"`
+	// whether the checkpoint is still tracked by the liveness checker
+	Pending bool `protobuf:"varint,3,opt,name=pending,proto3" json:"pending,omitempty"`
+	// the height at which the checkpoint first appears on BTC
+	FirstSeenBtcHeight uint64 `protobuf:"varint,4,opt,name=first_seen_btc_height,json=firstSeenBtcHeight,proto3" json:"first_seen_btc_height,omitempty"`
+	// the height of Babylon's BTC light client when the epoch ended
+	EpochEndedBtcHeight uint64 `protobuf:"varint,5,opt,name=epoch_ended_btc_height,json=epochEndedBtcHeight,proto3" json:"epoch_ended_btc_height,omitempty"`
+	Reported            bool   `protobuf:"varint,6,opt,name=reported,proto3" json:"reported,omitempty"`
+	// the height of Babylon's BTC light client when the checkpoint was reported
+	ReportedBtcHeight uint64 `protobuf:"varint,7,opt,name=reported_btc_height,json=reportedBtcHeight,proto3" json:"reported_btc_height,omitempty"`
+	// the tip height of Babylon's BTC light client, if the checkpoint is not reported
+	BtcLightClientTipHeight uint64 `protobuf:"varint,8,opt,name=btc_light_client_tip_height,json=btcLightClientTipHeight,proto3" json:"btc_light_client_tip_height,omitempty"`
+	// the number of BTC heights the checkpoint took, or has taken so far, to be reported
+	Gap               int64           `protobuf:"varint,9,opt,name=gap,proto3" json:"gap,omitempty"`
+	MaxLiveBtcHeights uint64          `protobuf:"varint,10,opt,name=max_live_btc_heights,json=maxLiveBtcHeights,proto3" json:"max_live_btc_heights,omitempty"`
+	Verdict           LivenessVerdict `protobuf:"varint,11,opt,name=verdict,proto3,enum=rpc.LivenessVerdict" json:"verdict,omitempty"`
+	Error             string          `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	// unix time of the last check in seconds, zero if not checked yet
+	CheckedAt int64 `protobuf:"varint,13,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+}
+
+func (x *CheckpointLiveness) Reset() {
+	*x = CheckpointLiveness{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckpointLiveness) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckpointLiveness) ProtoMessage() {}
+
+func (x *CheckpointLiveness) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckpointLiveness.ProtoReflect.Descriptor instead.
+func (*CheckpointLiveness) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CheckpointLiveness) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetCheckpointId() string {
+	if x != nil {
+		return x.CheckpointId
+	}
+	return ""
+}
+
+func (x *CheckpointLiveness) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+func (x *CheckpointLiveness) GetFirstSeenBtcHeight() uint64 {
+	if x != nil {
+		return x.FirstSeenBtcHeight
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetEpochEndedBtcHeight() uint64 {
+	if x != nil {
+		return x.EpochEndedBtcHeight
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetReported() bool {
+	if x != nil {
+		return x.Reported
+	}
+	return false
+}
+
+func (x *CheckpointLiveness) GetReportedBtcHeight() uint64 {
+	if x != nil {
+		return x.ReportedBtcHeight
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetBtcLightClientTipHeight() uint64 {
+	if x != nil {
+		return x.BtcLightClientTipHeight
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetGap() int64 {
+	if x != nil {
+		return x.Gap
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetMaxLiveBtcHeights() uint64 {
+	if x != nil {
+		return x.MaxLiveBtcHeights
+	}
+	return 0
+}
+
+func (x *CheckpointLiveness) GetVerdict() LivenessVerdict {
+	if x != nil {
+		return x.Verdict
+	}
+	return LivenessVerdict_LIVENESS_VERDICT_UNCHECKED
+}
+
+func (x *CheckpointLiveness) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *CheckpointLiveness) GetCheckedAt() int64 {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return 0
+}
+
+type ListPendingCheckpointsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListPendingCheckpointsRequest) Reset() {
+	*x = ListPendingCheckpointsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPendingCheckpointsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingCheckpointsRequest) ProtoMessage() {}
+
+func (x *ListPendingCheckpointsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingCheckpointsRequest.ProtoReflect.Descriptor instead.
+func (*ListPendingCheckpointsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{3}
+}
+
+type ListPendingCheckpointsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Checkpoints []*CheckpointLiveness `protobuf:"bytes,1,rep,name=checkpoints,proto3" json:"checkpoints,omitempty"`
+}
+
+func (x *ListPendingCheckpointsResponse) Reset() {
+	*x = ListPendingCheckpointsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPendingCheckpointsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingCheckpointsResponse) ProtoMessage() {}
+
+func (x *ListPendingCheckpointsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingCheckpointsResponse.ProtoReflect.Descriptor instead.
+func (*ListPendingCheckpointsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPendingCheckpointsResponse) GetCheckpoints() []*CheckpointLiveness {
+	if x != nil {
+		return x.Checkpoints
+	}
+	return nil
+}
+
+type GetCheckpointLivenessRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Epoch uint64 `protobuf:"varint,1,opt,name=epoch,proto3" json:"epoch,omitempty"`
+}
+
+func (x *GetCheckpointLivenessRequest) Reset() {
+	*x = GetCheckpointLivenessRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCheckpointLivenessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCheckpointLivenessRequest) ProtoMessage() {}
+
+func (x *GetCheckpointLivenessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCheckpointLivenessRequest.ProtoReflect.Descriptor instead.
+func (*GetCheckpointLivenessRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetCheckpointLivenessRequest) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+type GetCheckpointLivenessResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Checkpoint *CheckpointLiveness `protobuf:"bytes,1,opt,name=checkpoint,proto3" json:"checkpoint,omitempty"`
+}
+
+func (x *GetCheckpointLivenessResponse) Reset() {
+	*x = GetCheckpointLivenessResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCheckpointLivenessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCheckpointLivenessResponse) ProtoMessage() {}
+
+func (x *GetCheckpointLivenessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCheckpointLivenessResponse.ProtoReflect.Descriptor instead.
+func (*GetCheckpointLivenessResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetCheckpointLivenessResponse) GetCheckpoint() *CheckpointLiveness {
+	if x != nil {
+		return x.Checkpoint
+	}
+	return nil
+}
+
 var File_api_proto protoreflect.FileDescriptor
 
 var file_api_proto_rawDesc = []byte{
@@ -166,12 +553,82 @@ var file_api_proto_rawDesc = []byte{
 	0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x65, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12,
 	0x25, 0x0a, 0x0e, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
 	0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x4d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x32, 0x48, 0x0a, 0x10, 0x56, 0x69, 0x67, 0x69, 0x6c, 0x61,
-	0x6e, 0x74, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x56, 0x65,
-	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x13, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x72, 0x70, 0x63,
-	0x2e, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x42, 0x04, 0x5a, 0x02, 0x2e, 0x2f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x22, 0x83, 0x04, 0x0a, 0x12, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x6e, 0x64,
+	0x69, 0x6e, 0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x65, 0x6e, 0x64, 0x69,
+	0x6e, 0x67, 0x12, 0x31, 0x0a, 0x15, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x73, 0x65, 0x65, 0x6e,
+	0x5f, 0x62, 0x74, 0x63, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x12, 0x66, 0x69, 0x72, 0x73, 0x74, 0x53, 0x65, 0x65, 0x6e, 0x42, 0x74, 0x63, 0x48,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x33, 0x0a, 0x16, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x5f, 0x65,
+	0x6e, 0x64, 0x65, 0x64, 0x5f, 0x62, 0x74, 0x63, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x13, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x45, 0x6e, 0x64, 0x65,
+	0x64, 0x42, 0x74, 0x63, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x13, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x65, 0x64, 0x5f, 0x62, 0x74, 0x63, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x11, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x42, 0x74, 0x63,
+	0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x3c, 0x0a, 0x1b, 0x62, 0x74, 0x63, 0x5f, 0x6c, 0x69,
+	0x67, 0x68, 0x74, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x69, 0x70, 0x5f, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x17, 0x62, 0x74, 0x63,
+	0x4c, 0x69, 0x67, 0x68, 0x74, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x54, 0x69, 0x70, 0x48, 0x65,
+	0x69, 0x67, 0x68, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x67, 0x61, 0x70, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x03, 0x67, 0x61, 0x70, 0x12, 0x2f, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x69,
+	0x76, 0x65, 0x5f, 0x62, 0x74, 0x63, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x4c, 0x69, 0x76, 0x65, 0x42, 0x74, 0x63,
+	0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x64, 0x69,
+	0x63, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4c,
+	0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x52, 0x07,
+	0x76, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0d, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x65, 0x64, 0x41, 0x74, 0x22, 0x1f, 0x0a, 0x1d,
+	0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x5b, 0x0a,
+	0x1e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x39, 0x0a, 0x0b, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x0b, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x34, 0x0a, 0x1c, 0x47, 0x65,
+	0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e,
+	0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70,
+	0x6f, 0x63, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68,
+	0x22, 0x58, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x37, 0x0a, 0x0a, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x0a,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2a, 0x87, 0x01, 0x0a, 0x0f, 0x4c,
+	0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x56, 0x65, 0x72, 0x64, 0x69, 0x63, 0x74, 0x12, 0x1e,
+	0x0a, 0x1a, 0x4c, 0x49, 0x56, 0x45, 0x4e, 0x45, 0x53, 0x53, 0x5f, 0x56, 0x45, 0x52, 0x44, 0x49,
+	0x43, 0x54, 0x5f, 0x55, 0x4e, 0x43, 0x48, 0x45, 0x43, 0x4b, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19,
+	0x0a, 0x15, 0x4c, 0x49, 0x56, 0x45, 0x4e, 0x45, 0x53, 0x53, 0x5f, 0x56, 0x45, 0x52, 0x44, 0x49,
+	0x43, 0x54, 0x5f, 0x4c, 0x49, 0x56, 0x45, 0x10, 0x01, 0x12, 0x1d, 0x0a, 0x19, 0x4c, 0x49, 0x56,
+	0x45, 0x4e, 0x45, 0x53, 0x53, 0x5f, 0x56, 0x45, 0x52, 0x44, 0x49, 0x43, 0x54, 0x5f, 0x43, 0x45,
+	0x4e, 0x53, 0x4f, 0x52, 0x45, 0x44, 0x10, 0x02, 0x12, 0x1a, 0x0a, 0x16, 0x4c, 0x49, 0x56, 0x45,
+	0x4e, 0x45, 0x53, 0x53, 0x5f, 0x56, 0x45, 0x52, 0x44, 0x49, 0x43, 0x54, 0x5f, 0x45, 0x52, 0x52,
+	0x4f, 0x52, 0x10, 0x03, 0x32, 0x8b, 0x02, 0x0a, 0x10, 0x56, 0x69, 0x67, 0x69, 0x6c, 0x61, 0x6e,
+	0x74, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x13, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x61, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x22, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x5e, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x21, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x4c,
+	0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x4c, 0x69, 0x76, 0x65, 0x6e, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x42, 0x04, 0x5a, 0x02, 0x2e, 0x2f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -186,19 +643,33 @@ func file_api_proto_rawDescGZIP() []byte {
 	return file_api_proto_rawDescData
 }
 
-var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_api_proto_goTypes = []interface{}{
-	(*VersionRequest)(nil),  // 0: rpc.VersionRequest
-	(*VersionResponse)(nil), // 1: rpc.VersionResponse
+	(LivenessVerdict)(0),                   // 0: rpc.LivenessVerdict
+	(*VersionRequest)(nil),                 // 1: rpc.VersionRequest
+	(*VersionResponse)(nil),                // 2: rpc.VersionResponse
+	(*CheckpointLiveness)(nil),             // 3: rpc.CheckpointLiveness
+	(*ListPendingCheckpointsRequest)(nil),  // 4: rpc.ListPendingCheckpointsRequest
+	(*ListPendingCheckpointsResponse)(nil), // 5: rpc.ListPendingCheckpointsResponse
+	(*GetCheckpointLivenessRequest)(nil),   // 6: rpc.GetCheckpointLivenessRequest
+	(*GetCheckpointLivenessResponse)(nil),  // 7: rpc.GetCheckpointLivenessResponse
 }
 var file_api_proto_depIdxs = []int32{
-	0, // 0: rpc.VigilanteService.Version:input_type -> rpc.VersionRequest
-	1, // 1: rpc.VigilanteService.Version:output_type -> rpc.VersionResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: rpc.CheckpointLiveness.verdict:type_name -> rpc.LivenessVerdict
+	3, // 1: rpc.ListPendingCheckpointsResponse.checkpoints:type_name -> rpc.CheckpointLiveness
+	3, // 2: rpc.GetCheckpointLivenessResponse.checkpoint:type_name -> rpc.CheckpointLiveness
+	1, // 3: rpc.VigilanteService.Version:input_type -> rpc.VersionRequest
+	4, // 4: rpc.VigilanteService.ListPendingCheckpoints:input_type -> rpc.ListPendingCheckpointsRequest
+	6, // 5: rpc.VigilanteService.GetCheckpointLiveness:input_type -> rpc.GetCheckpointLivenessRequest
+	2, // 6: rpc.VigilanteService.Version:output_type -> rpc.VersionResponse
+	5, // 7: rpc.VigilanteService.ListPendingCheckpoints:output_type -> rpc.ListPendingCheckpointsResponse
+	7, // 8: rpc.VigilanteService.GetCheckpointLiveness:output_type -> rpc.GetCheckpointLivenessResponse
+	6, // [6:9] is the sub-list for method output_type
+	3, // [3:6] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_init() }
@@ -231,19 +702,80 @@ func file_api_proto_init() {
 				return nil
 			}
 		}
+		file_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckpointLiveness); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPendingCheckpointsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPendingCheckpointsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCheckpointLivenessRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCheckpointLivenessResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      1,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_goTypes,
 		DependencyIndexes: file_api_proto_depIdxs,
+		EnumInfos:         file_api_proto_enumTypes,
 		MessageInfos:      file_api_proto_msgTypes,
 	}.Build()
 	File_api_proto = out.File
@@ -265,6 +797,11 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type VigilanteServiceClient interface {
 	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionResponse, error)
+	// ListPendingCheckpoints returns the liveness of the checkpoints that the
+	// monitor has found on BTC but not seen reported to Babylon in time yet
+	ListPendingCheckpoints(ctx context.Context, in *ListPendingCheckpointsRequest, opts ...grpc.CallOption) (*ListPendingCheckpointsResponse, error)
+	// GetCheckpointLiveness returns the liveness of the checkpoint of the epoch
+	GetCheckpointLiveness(ctx context.Context, in *GetCheckpointLivenessRequest, opts ...grpc.CallOption) (*GetCheckpointLivenessResponse, error)
 }
 
 type vigilanteServiceClient struct {
@@ -284,9 +821,32 @@ func (c *vigilanteServiceClient) Version(ctx context.Context, in *VersionRequest
 	return out, nil
 }
 
+func (c *vigilanteServiceClient) ListPendingCheckpoints(ctx context.Context, in *ListPendingCheckpointsRequest, opts ...grpc.CallOption) (*ListPendingCheckpointsResponse, error) {
+	out := new(ListPendingCheckpointsResponse)
+	err := c.cc.Invoke(ctx, "/rpc.VigilanteService/ListPendingCheckpoints", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vigilanteServiceClient) GetCheckpointLiveness(ctx context.Context, in *GetCheckpointLivenessRequest, opts ...grpc.CallOption) (*GetCheckpointLivenessResponse, error) {
+	out := new(GetCheckpointLivenessResponse)
+	err := c.cc.Invoke(ctx, "/rpc.VigilanteService/GetCheckpointLiveness", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VigilanteServiceServer is the server API for VigilanteService service.
 type VigilanteServiceServer interface {
 	Version(context.Context, *VersionRequest) (*VersionResponse, error)
+	// ListPendingCheckpoints returns the liveness of the checkpoints that the
+	// monitor has found on BTC but not seen reported to Babylon in time yet
+	ListPendingCheckpoints(context.Context, *ListPendingCheckpointsRequest) (*ListPendingCheckpointsResponse, error)
+	// GetCheckpointLiveness returns the liveness of the checkpoint of the epoch
+	GetCheckpointLiveness(context.Context, *GetCheckpointLivenessRequest) (*GetCheckpointLivenessResponse, error)
 }
 
 // UnimplementedVigilanteServiceServer can be embedded to have forward compatible implementations.
@@ -296,6 +856,12 @@ type UnimplementedVigilanteServiceServer struct {
 func (*UnimplementedVigilanteServiceServer) Version(context.Context, *VersionRequest) (*VersionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Version not implemented")
 }
+func (*UnimplementedVigilanteServiceServer) ListPendingCheckpoints(context.Context, *ListPendingCheckpointsRequest) (*ListPendingCheckpointsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingCheckpoints not implemented")
+}
+func (*UnimplementedVigilanteServiceServer) GetCheckpointLiveness(context.Context, *GetCheckpointLivenessRequest) (*GetCheckpointLivenessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCheckpointLiveness not implemented")
+}
 
 func RegisterVigilanteServiceServer(s *grpc.Server, srv VigilanteServiceServer) {
 	s.RegisterService(&_VigilanteService_serviceDesc, srv)
@@ -319,6 +885,42 @@ func _VigilanteService_Version_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VigilanteService_ListPendingCheckpoints_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingCheckpointsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VigilanteServiceServer).ListPendingCheckpoints(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.VigilanteService/ListPendingCheckpoints",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VigilanteServiceServer).ListPendingCheckpoints(ctx, req.(*ListPendingCheckpointsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VigilanteService_GetCheckpointLiveness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCheckpointLivenessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VigilanteServiceServer).GetCheckpointLiveness(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/rpc.VigilanteService/GetCheckpointLiveness",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VigilanteServiceServer).GetCheckpointLiveness(ctx, req.(*GetCheckpointLivenessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _VigilanteService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "rpc.VigilanteService",
 	HandlerType: (*VigilanteServiceServer)(nil),
@@ -327,6 +929,14 @@ var _VigilanteService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Version",
 			Handler:    _VigilanteService_Version_Handler,
 		},
+		{
+			MethodName: "ListPendingCheckpoints",
+			Handler:    _VigilanteService_ListPendingCheckpoints_Handler,
+		},
+		{
+			MethodName: "GetCheckpointLiveness",
+			Handler:    _VigilanteService_GetCheckpointLiveness_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api.proto",
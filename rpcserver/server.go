@@ -72,9 +72,9 @@ func New(
 			grpc_prometheus.UnaryServerInterceptor,
 		)),
 	)
-	reflection.Register(server)      // register reflection service
-	StartVigilanteService(server)    // register our vigilante service
-	grpc_prometheus.Register(server) // register Prometheus metrics service
+	reflection.Register(server)            // register reflection service
+	StartVigilanteService(server, monitor) // register our vigilante service
+	grpc_prometheus.Register(server)       // register Prometheus metrics service
 
 	return &Server{server, cfg, logger, submitter, reporter, monitor, bstracker}, nil
 }
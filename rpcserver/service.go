@@ -1,9 +1,14 @@
 package rpcserver
 
 import (
+	"errors"
+
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/babylonchain/vigilante/monitor"
 	pb "github.com/babylonchain/vigilante/rpcserver/api"
 )
 
@@ -15,12 +20,15 @@ const (
 	verPatch  = 1
 )
 
-type service struct{}
+type service struct {
+	monitor *monitor.Monitor
+}
 
 // StartVigilanteService creates an implementation of the VigilanteService and
-// registers it with the gRPC server.
-func StartVigilanteService(gs *grpc.Server) {
-	pb.RegisterVigilanteServiceServer(gs, &service{})
+// registers it with the gRPC server. The monitor is nil if it is not running
+// in this process.
+func StartVigilanteService(gs *grpc.Server, monitor *monitor.Monitor) {
+	pb.RegisterVigilanteServiceServer(gs, &service{monitor: monitor})
 }
 
 func (s *service) Version(ctx context.Context, req *pb.VersionRequest) (*pb.VersionResponse, error) {
@@ -31,3 +39,62 @@ func (s *service) Version(ctx context.Context, req *pb.VersionRequest) (*pb.Vers
 		Patch:         verPatch,
 	}, nil
 }
+
+func (s *service) ListPendingCheckpoints(ctx context.Context, req *pb.ListPendingCheckpointsRequest) (*pb.ListPendingCheckpointsResponse, error) {
+	if s.monitor == nil {
+		return nil, status.Error(codes.Unavailable, "the monitor is not running")
+	}
+
+	res := &pb.ListPendingCheckpointsResponse{}
+	for _, c := range s.monitor.ListPendingCheckpoints() {
+		res.Checkpoints = append(res.Checkpoints, toPbCheckpointLiveness(&c))
+	}
+	return res, nil
+}
+
+func (s *service) GetCheckpointLiveness(ctx context.Context, req *pb.GetCheckpointLivenessRequest) (*pb.GetCheckpointLivenessResponse, error) {
+	if s.monitor == nil {
+		return nil, status.Error(codes.Unavailable, "the monitor is not running")
+	}
+
+	c, err := s.monitor.GetCheckpointLiveness(req.Epoch)
+	if errors.Is(err, monitor.ErrLivenessNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no checkpoint of epoch %d is tracked by the liveness checker", req.Epoch)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.GetCheckpointLivenessResponse{Checkpoint: toPbCheckpointLiveness(&c)}, nil
+}
+
+func toPbCheckpointLiveness(c *monitor.CheckpointLiveness) *pb.CheckpointLiveness {
+	res := &pb.CheckpointLiveness{
+		Epoch:                   c.Epoch,
+		CheckpointId:            c.CheckpointID,
+		Pending:                 c.Pending,
+		FirstSeenBtcHeight:      c.FirstSeenBtcHeight,
+		EpochEndedBtcHeight:     c.EpochEndedBtcHeight,
+		Reported:                c.Reported,
+		ReportedBtcHeight:       c.ReportedBtcHeight,
+		BtcLightClientTipHeight: c.BtcLightClientTipHeight,
+		Gap:                     int64(c.Gap),
+		MaxLiveBtcHeights:       c.MaxLiveBtcHeights,
+	}
+	switch c.Verdict {
+	case monitor.LivenessLive:
+		res.Verdict = pb.LivenessVerdict_LIVENESS_VERDICT_LIVE
+	case monitor.LivenessCensored:
+		res.Verdict = pb.LivenessVerdict_LIVENESS_VERDICT_CENSORED
+	case monitor.LivenessError:
+		res.Verdict = pb.LivenessVerdict_LIVENESS_VERDICT_ERROR
+	default:
+		res.Verdict = pb.LivenessVerdict_LIVENESS_VERDICT_UNCHECKED
+	}
+	if c.Err != nil {
+		res.Error = c.Err.Error()
+	}
+	if !c.CheckedAt.IsZero() {
+		res.CheckedAt = c.CheckedAt.Unix()
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/babylonchain/vigilante/monitor"
+	pb "github.com/babylonchain/vigilante/rpcserver/api"
+)
+
+func TestToPbCheckpointLiveness(t *testing.T) {
+	checkedAt := time.Now()
+	for verdict, expected := range map[monitor.LivenessVerdict]pb.LivenessVerdict{
+		monitor.LivenessUnchecked: pb.LivenessVerdict_LIVENESS_VERDICT_UNCHECKED,
+		monitor.LivenessLive:      pb.LivenessVerdict_LIVENESS_VERDICT_LIVE,
+		monitor.LivenessCensored:  pb.LivenessVerdict_LIVENESS_VERDICT_CENSORED,
+		monitor.LivenessError:     pb.LivenessVerdict_LIVENESS_VERDICT_ERROR,
+	} {
+		c := &monitor.CheckpointLiveness{
+			Epoch:                   10,
+			CheckpointID:            "ckpt",
+			Pending:                 true,
+			FirstSeenBtcHeight:      100,
+			EpochEndedBtcHeight:     90,
+			Reported:                true,
+			ReportedBtcHeight:       105,
+			BtcLightClientTipHeight: 110,
+			Gap:                     15,
+			MaxLiveBtcHeights:       200,
+			Verdict:                 verdict,
+		}
+		if verdict == monitor.LivenessError {
+			c.Err = errors.New("babylon is down")
+		}
+		if verdict != monitor.LivenessUnchecked {
+			c.CheckedAt = checkedAt
+		}
+
+		res := toPbCheckpointLiveness(c)
+		require.Equal(t, expected, res.Verdict)
+		require.Equal(t, c.Epoch, res.Epoch)
+		require.Equal(t, c.CheckpointID, res.CheckpointId)
+		require.Equal(t, c.Pending, res.Pending)
+		require.Equal(t, c.FirstSeenBtcHeight, res.FirstSeenBtcHeight)
+		require.Equal(t, c.EpochEndedBtcHeight, res.EpochEndedBtcHeight)
+		require.Equal(t, c.Reported, res.Reported)
+		require.Equal(t, c.ReportedBtcHeight, res.ReportedBtcHeight)
+		require.Equal(t, c.BtcLightClientTipHeight, res.BtcLightClientTipHeight)
+		require.Equal(t, int64(c.Gap), res.Gap)
+		require.Equal(t, c.MaxLiveBtcHeights, res.MaxLiveBtcHeights)
+		if c.Err != nil {
+			require.Equal(t, c.Err.Error(), res.Error)
+		} else {
+			require.Empty(t, res.Error)
+		}
+		// unchecked checkpoints have no check time
+		if c.CheckedAt.IsZero() {
+			require.Zero(t, res.CheckedAt)
+		} else {
+			require.Equal(t, checkedAt.Unix(), res.CheckedAt)
+		}
+	}
+}
+
+func TestLivenessQueriesWithoutMonitor(t *testing.T) {
+	s := &service{}
+
+	_, err := s.ListPendingCheckpoints(context.Background(), &pb.ListPendingCheckpointsRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	_, err = s.GetCheckpointLiveness(context.Background(), &pb.GetCheckpointLivenessRequest{Epoch: 1})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
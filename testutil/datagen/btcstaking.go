@@ -0,0 +1,221 @@
+package datagen
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	"github.com/babylonchain/babylon/btcstaking"
+	asig "github.com/babylonchain/babylon/crypto/schnorr-adaptor-signature"
+	"github.com/babylonchain/babylon/testutil/datagen"
+	bbn "github.com/babylonchain/babylon/types"
+	bstypes "github.com/babylonchain/babylon/x/btcstaking/types"
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+// GenRandomFPKeyPairs generates the key pairs of n finality providers, sorted by
+// their PKs as the finality providers a BTC delegation is restaked to appear in
+// its staking script
+func GenRandomFPKeyPairs(r *rand.Rand, n int) ([]*btcec.PrivateKey, []*btcec.PublicKey, error) {
+	pkToSK := make(map[string]*btcec.PrivateKey, n)
+	fpPKs := make([]*btcec.PublicKey, 0, n)
+	for i := 0; i < n; i++ {
+		fpSK, fpPK, err := datagen.GenRandomBTCKeyPair(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		pkToSK[bbn.NewBIP340PubKeyFromBTCPK(fpPK).MarshalHex()] = fpSK
+		fpPKs = append(fpPKs, fpPK)
+	}
+
+	fpPKs = SortBTCPKs(fpPKs)
+	fpSKs := make([]*btcec.PrivateKey, 0, n)
+	for _, fpPK := range fpPKs {
+		fpSKs = append(fpSKs, pkToSK[bbn.NewBIP340PubKeyFromBTCPK(fpPK).MarshalHex()])
+	}
+	return fpSKs, fpPKs, nil
+}
+
+// SortBTCPKs returns the given PKs sorted as in the staking script
+func SortBTCPKs(pks []*btcec.PublicKey) []*btcec.PublicKey {
+	sortedPKs := make([]*btcec.PublicKey, 0, len(pks))
+	for _, pk := range bbn.SortBIP340PKs(NewBIP340PKsFromBTCPKs(pks)) {
+		sortedPKs = append(sortedPKs, pk.MustToBTCPK())
+	}
+	return sortedPKs
+}
+
+// GenUnsortedFPPKList returns the PKs of the given sorted finality providers in a
+// random order, as Babylon may list the finality providers a BTC delegation is
+// restaked to. The finality provider at the given index is neither first nor at
+// its sorted position in the list, which needs at least 3 finality providers.
+func GenUnsortedFPPKList(r *rand.Rand, sortedFPPKs []*btcec.PublicKey, idx int) []bbn.BIP340PubKey {
+	positions := make([]int, 0, len(sortedFPPKs))
+	for pos := 1; pos < len(sortedFPPKs); pos++ {
+		if pos != idx {
+			positions = append(positions, pos)
+		}
+	}
+	pos := positions[r.Intn(len(positions))]
+
+	otherPKs := make([]*btcec.PublicKey, 0, len(sortedFPPKs)-1)
+	otherPKs = append(otherPKs, sortedFPPKs[:idx]...)
+	otherPKs = append(otherPKs, sortedFPPKs[idx+1:]...)
+	r.Shuffle(len(otherPKs), func(i, j int) {
+		otherPKs[i], otherPKs[j] = otherPKs[j], otherPKs[i]
+	})
+
+	unsortedPKs := make([]*btcec.PublicKey, 0, len(sortedFPPKs))
+	unsortedPKs = append(unsortedPKs, otherPKs[:pos]...)
+	unsortedPKs = append(unsortedPKs, sortedFPPKs[idx])
+	unsortedPKs = append(unsortedPKs, otherPKs[pos:]...)
+	return NewBIP340PKsFromBTCPKs(unsortedPKs)
+}
+
+func NewBIP340PKsFromBTCPKs(pks []*btcec.PublicKey) []bbn.BIP340PubKey {
+	bip340PKs := make([]bbn.BIP340PubKey, 0, len(pks))
+	for _, pk := range pks {
+		bip340PKs = append(bip340PKs, *bbn.NewBIP340PubKeyFromBTCPK(pk))
+	}
+	return bip340PKs
+}
+
+// GenRandomUnbondedBTCDelegation generates a BTC delegation restaked to the given
+// finality providers, with an unbonding tx signed by the covenant committee and
+// a slashing tx of the unbonding output ready to be signed by any of the finality
+// providers. The covenant adaptor signatures of each covenant member are ordered
+// as the given finality providers, which are thus expected to be sorted.
+func GenRandomUnbondedBTCDelegation(
+	r *rand.Rand,
+	t *testing.T,
+	net *chaincfg.Params,
+	fpPKs []*btcec.PublicKey,
+	delSK *btcec.PrivateKey,
+	covenantSks []*btcec.PrivateKey,
+	covenantBtcPks []*btcec.PublicKey,
+	covenantQuorum uint32,
+	slashingAddr string,
+	slashingRate sdkmath.LegacyDec,
+	unbondingTime uint16,
+) *bstypes.BTCDelegation {
+	delAmount := datagen.RandomInt(r, 100000) + 10000
+	unbondedBTCDel, err := datagen.GenRandomBTCDelegation(
+		r,
+		t,
+		net,
+		NewBIP340PKsFromBTCPKs(fpPKs),
+		delSK,
+		covenantSks,
+		covenantBtcPks,
+		covenantQuorum,
+		slashingAddr,
+		100,
+		1100,
+		delAmount,
+		slashingRate,
+		unbondingTime,
+	)
+	require.NoError(t, err)
+
+	// get the spend information for the unbonding path of the staking output
+	stakingInfo, err := btcstaking.BuildStakingInfo(
+		unbondedBTCDel.BtcPk.MustToBTCPK(),
+		fpPKs,
+		covenantBtcPks,
+		covenantQuorum,
+		unbondedBTCDel.GetStakingTime(),
+		btcutil.Amount(unbondedBTCDel.TotalSat),
+		net,
+	)
+	require.NoError(t, err)
+	unbondingPathSpendInfo, err := stakingInfo.UnbondingPathSpendInfo()
+	require.NoError(t, err)
+	stakingMsgTx, err := bbn.NewBTCTxFromBytes(unbondedBTCDel.StakingTx)
+	require.NoError(t, err)
+	stakingTxHash := stakingMsgTx.TxHash()
+
+	outPoint := wire.NewOutPoint(&stakingTxHash, unbondedBTCDel.StakingOutputIdx)
+	unbondingSlashingInfo := datagen.GenBTCUnbondingSlashingInfo(
+		r,
+		t,
+		net,
+		delSK,
+		fpPKs,
+		covenantBtcPks,
+		covenantQuorum,
+		outPoint,
+		1000,
+		9000,
+		slashingAddr,
+		slashingRate,
+		unbondingTime,
+	)
+	slashingPathSpendInfo, err := unbondingSlashingInfo.UnbondingInfo.SlashingPathSpendInfo()
+	require.NoError(t, err)
+	delSlashingSig, err := unbondingSlashingInfo.SlashingTx.Sign(
+		unbondingSlashingInfo.UnbondingTx,
+		0,
+		slashingPathSpendInfo.GetPkScriptPath(),
+		delSK,
+	)
+	require.NoError(t, err)
+
+	covenantUnbondingSigs := make([]*bstypes.SignatureInfo, 0, len(covenantSks))
+	covenantSlashingSigs := make([]*bstypes.CovenantAdaptorSignatures, 0, len(covenantSks))
+	for _, sk := range covenantSks {
+		// covenant adaptor signatures on slashing tx, one encrypted by each
+		// finality provider
+		adaptorSigs := make([][]byte, 0, len(fpPKs))
+		for _, fpPK := range fpPKs {
+			encKey, err := asig.NewEncryptionKeyFromBTCPK(fpPK)
+			require.NoError(t, err)
+			covenantSlashingSig, err := unbondingSlashingInfo.SlashingTx.EncSign(
+				unbondingSlashingInfo.UnbondingTx,
+				0,
+				slashingPathSpendInfo.GetPkScriptPath(),
+				sk,
+				encKey,
+			)
+			require.NoError(t, err)
+			adaptorSigs = append(adaptorSigs, covenantSlashingSig.MustMarshal())
+		}
+		covenantSlashingSigs = append(covenantSlashingSigs, &bstypes.CovenantAdaptorSignatures{
+			CovPk:       bbn.NewBIP340PubKeyFromBTCPK(sk.PubKey()),
+			AdaptorSigs: adaptorSigs,
+		})
+
+		// covenant Schnorr signature on unbonding tx
+		covenantUnbondingSchnorrSig, err := btcstaking.SignTxWithOneScriptSpendInputStrict(
+			unbondingSlashingInfo.UnbondingTx,
+			stakingMsgTx,
+			unbondedBTCDel.StakingOutputIdx,
+			unbondingPathSpendInfo.GetPkScriptPath(),
+			sk,
+		)
+		require.NoError(t, err)
+		covenantUnbondingSigs = append(covenantUnbondingSigs, &bstypes.SignatureInfo{
+			Pk:  bbn.NewBIP340PubKeyFromBTCPK(sk.PubKey()),
+			Sig: bbn.NewBIP340SignatureFromBTCSig(covenantUnbondingSchnorrSig),
+		})
+	}
+
+	var unbondingTxBuffer bytes.Buffer
+	err = unbondingSlashingInfo.UnbondingTx.Serialize(&unbondingTxBuffer)
+	require.NoError(t, err)
+	unbondedBTCDel.BtcUndelegation = &bstypes.BTCUndelegation{
+		UnbondingTx:           unbondingTxBuffer.Bytes(),
+		SlashingTx:            unbondingSlashingInfo.SlashingTx,
+		DelegatorSlashingSig:  delSlashingSig,
+		DelegatorUnbondingSig: delSlashingSig,
+		// TODO: currently requires only one sig, in reality requires all of them
+		CovenantSlashingSigs:     covenantSlashingSigs,
+		CovenantUnbondingSigList: covenantUnbondingSigs,
+	}
+
+	return unbondedBTCDel
+}
@@ -85,6 +85,32 @@ func (c *CheckpointCache) Match() {
 	c.sortCheckpoints()
 }
 
+// RemoveSegmentsAbove removes the segments, and the matched checkpoints with
+// segments, in blocks above the given height, e.g., after these blocks are reorged
+func (c *CheckpointCache) RemoveSegmentsAbove(height int32) {
+	for _, segMap := range c.Segments {
+		for hash, seg := range segMap {
+			if seg.AssocBlock.Height > height {
+				delete(segMap, hash)
+			}
+		}
+	}
+
+	var ckpts []*Ckpt
+	for _, ckpt := range c.Checkpoints {
+		reorged := false
+		for _, seg := range ckpt.Segments {
+			if seg.AssocBlock.Height > height {
+				reorged = true
+			}
+		}
+		if !reorged {
+			ckpts = append(ckpts, ckpt)
+		}
+	}
+	c.Checkpoints = ckpts
+}
+
 func (c *CheckpointCache) PopEarliestCheckpoint() *Ckpt {
 	if c.HasCheckpoints() {
 		ckpt := c.Checkpoints[0]
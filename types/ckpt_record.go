@@ -1,12 +1,29 @@
 package types
 
 import (
+	btcctypes "github.com/babylonchain/babylon/x/btccheckpoint/types"
 	ckpttypes "github.com/babylonchain/babylon/x/checkpointing/types"
+	"github.com/btcsuite/btcd/wire"
 )
 
 type CheckpointRecord struct {
-	RawCheckpoint      *ckpttypes.RawCheckpoint
+	RawCheckpoint *ckpttypes.RawCheckpoint
+	// the checkpoint as encoded in the OP_RETURN data of the BTC txs
+	BtcCheckpointBytes []byte
 	FirstSeenBtcHeight uint64
+	// the BTC txs carrying the segments of the checkpoint
+	Txs []*CheckpointTxRecord
+}
+
+// CheckpointTxRecord locates a BTC tx carrying a segment of a checkpoint
+type CheckpointTxRecord struct {
+	TxId        string `json:"txid"`
+	BlockHash   string `json:"block_hash"`
+	BlockHeight uint64 `json:"block_height"`
+	// the SPV proof of the tx and the header of its block. They are only kept
+	// in memory to assemble the evidence of a fork
+	Proof       *btcctypes.BTCSpvProof `json:"-"`
+	BlockHeader *wire.BlockHeader      `json:"-"`
 }
 
 func NewCheckpointRecord(ckpt *ckpttypes.RawCheckpoint, height uint64) *CheckpointRecord {
@@ -38,6 +38,10 @@ func (ei *EpochInfo) GetEpochNumber() uint64 {
 	return ei.epochNum
 }
 
+func (ei *EpochInfo) GetValSet() ckpttypes.ValidatorWithBlsKeySet {
+	return ei.valSet
+}
+
 func (ei *EpochInfo) GetTotalPower() uint64 {
 	return ei.valSet.GetTotalPower()
 }
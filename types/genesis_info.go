@@ -18,6 +18,7 @@ import (
 )
 
 type GenesisInfo struct {
+	chainID       string
 	baseBTCHeight uint64
 	epochInterval uint64
 	checkpointTag string
@@ -25,12 +26,14 @@ type GenesisInfo struct {
 }
 
 func NewGenesisInfo(
+	chainID string,
 	baseBTCHeight uint64,
 	epochInterval uint64,
 	checkpointTag string,
 	valSet *checkpointingtypes.ValidatorWithBlsKeySet,
 ) *GenesisInfo {
 	return &GenesisInfo{
+		chainID:       chainID,
 		baseBTCHeight: baseBTCHeight,
 		epochInterval: epochInterval,
 		checkpointTag: checkpointTag,
@@ -48,7 +51,7 @@ func GetGenesisInfoFromFile(filePath string) (*GenesisInfo, error) {
 		err           error
 	)
 
-	appState, _, err := genutiltypes.GenesisStateFromGenFile(filePath)
+	appState, genDoc, err := genutiltypes.GenesisStateFromGenFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read genesis file %v, %w", filePath, err)
 	}
@@ -111,6 +114,7 @@ func GetGenesisInfoFromFile(filePath string) (*GenesisInfo, error) {
 	checkpointTag = btccheckpointGenState.Params.CheckpointTag
 
 	genesisInfo := &GenesisInfo{
+		chainID:       genDoc.ChainID,
 		baseBTCHeight: baseBTCHeight,
 		epochInterval: epochInterval,
 		checkpointTag: checkpointTag,
@@ -156,6 +160,10 @@ func GetBtccheckpointGenesisStateFromAppState(cdc codec.Codec, appState map[stri
 	return genesisState
 }
 
+func (gi *GenesisInfo) GetChainID() string {
+	return gi.chainID
+}
+
 func (gi *GenesisInfo) GetBaseBTCHeight() uint64 {
 	return gi.baseBTCHeight
 }